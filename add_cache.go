@@ -0,0 +1,37 @@
+package groupurl
+
+import "fmt"
+
+// WithAddCache keeps the classified tokens of the most recently added paths in an LRU cache of the given size.
+// Log streams often contain the same URL many times in a row, in which case Add skips classification
+// and only updates the counters. Use AddCacheStats to check whether the cache pays off.
+func WithAddCache(size int) Option {
+	return func(g *Grouper) error {
+		if size <= 0 {
+			return fmt.Errorf("add cache size must be positive, got %d", size)
+		}
+		g.addCache = newLRUCache[string, []pathToken](size)
+		return nil
+	}
+}
+
+// AddCacheStats returns the statistics of the cache configured with WithAddCache.
+func (g Grouper) AddCacheStats() CacheStats {
+	if g.addCache == nil {
+		return CacheStats{}
+	}
+	return g.addCache.stats()
+}
+
+// addTokens returns the classified tokens of a path being added, consulting the add cache if there is one.
+func (g Grouper) addTokens(path string) []pathToken {
+	if g.addCache == nil {
+		return labelPathTokens(path, g.classifiers)
+	}
+	if tokens, ok := g.addCache.get(path); ok {
+		return tokens
+	}
+	tokens := labelPathTokens(path, g.classifiers)
+	g.addCache.put(path, tokens)
+	return tokens
+}
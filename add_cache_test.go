@@ -0,0 +1,32 @@
+package groupurl
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestWithAddCache(t *testing.T) {
+	g, err := New(WithAddCache(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rawURL := range []string{"/a/1", "/a/1", "/a/1", "/a/2", "/a/3", "/a/1"} {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+
+	stats := g.AddCacheStats()
+	if stats.Hits != 2 || stats.Misses != 4 || stats.Size != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if stats.HitRate() != 2.0/6 {
+		t.Fatalf("unexpected hit rate %v", stats.HitRate())
+	}
+	if g.DepthStats().Total != 6 {
+		t.Fatalf("expected 6 URLs counted, got %d", g.DepthStats().Total)
+	}
+}
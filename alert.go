@@ -0,0 +1,137 @@
+package groupurl
+
+import (
+	"errors"
+	"net/url"
+	"time"
+)
+
+const _defaultAlertWindow = time.Minute
+
+type (
+	// RuleSpec describes when an alert fires for the groups of the URLs whose simplified path matches a glob.
+	// An alert fires when a matching group receives at least MinRate URLs per second over Window,
+	// or when at least MaxCardinality distinct paths have been seen in it. Zero values disable a condition.
+	RuleSpec struct {
+		// PatternGlob selects URLs by their simplified path. "*" matches a single segment and "**" any number of segments.
+		PatternGlob string
+		// MinRate is the rate in URLs per second at which the alert fires.
+		MinRate float64
+		// MaxCardinality is the number of distinct paths in a group at which the alert fires.
+		MaxCardinality int
+		// Window is the period the rate is measured over. Defaults to one minute.
+		Window time.Duration
+		// Cooldown is the minimum time between two alerts for the same group.
+		Cooldown time.Duration
+	}
+
+	// Alert is passed to an AlertHandler when a rule fires.
+	Alert struct {
+		Rule RuleSpec
+		// Pattern is the pattern of the group, as returned by Grouper.Pattern. Groups are keyed on their pattern
+		// rather than on the simplified path, so that a token becoming significant does not reset their counts.
+		Pattern     string
+		Rate        float64
+		Cardinality int
+		Time        time.Time
+		// Owners are the owners of the group according to the Ownership set WithOwnership, so that alerts can be
+		// routed to them.
+		Owners []string
+	}
+
+	// AlertHandler is called synchronously from Add when a rule fires.
+	AlertHandler func(Alert)
+
+	alertRule struct {
+		spec    RuleSpec
+		handler AlertHandler
+		groups  map[string]*alertState
+	}
+
+	alertState struct {
+		windowStart time.Time
+		count       int
+		paths       map[string]struct{}
+		lastFired   time.Time
+	}
+)
+
+// WithAlert fires handler whenever a group matching the rule exceeds its limits.
+// This is useful for detecting scraping and enumeration without exporting to an external system.
+// Evaluating rules simplifies every added URL, so Add becomes roughly twice as expensive.
+func WithAlert(rule RuleSpec, handler AlertHandler) Option {
+	return func(g *Grouper) error {
+		if handler == nil {
+			return errors.New("alert handler must not be nil")
+		}
+		if rule.Window <= 0 {
+			rule.Window = _defaultAlertWindow
+		}
+		g.alerts = append(g.alerts, &alertRule{
+			spec:    rule,
+			handler: handler,
+			groups:  make(map[string]*alertState),
+		})
+		return nil
+	}
+}
+
+func (g Grouper) checkAlerts(u *url.URL, weight int) {
+	simplified := g.SimplifyPath(u)
+	now := g.now()
+	owners := func() []string {
+		return g.ownership.Owners(g.tree.decode(simplified))
+	}
+	var pattern string
+	for _, rule := range g.alerts {
+		if !matchGlob(rule.spec.PatternGlob, simplified) {
+			continue
+		}
+		if pattern == "" {
+			pattern = g.Pattern(u)
+		}
+		rule.observe(pattern, u.Path, weight, now, owners)
+	}
+}
+
+// observe counts a URL of a group, and fires the alert if the group exceeds its limits. owners is only called then.
+func (r *alertRule) observe(pattern, path string, weight int, now time.Time, owners func() []string) {
+	state, ok := r.groups[pattern]
+	if !ok {
+		state = &alertState{
+			windowStart: now,
+			paths:       make(map[string]struct{}),
+		}
+		r.groups[pattern] = state
+	}
+
+	if now.Sub(state.windowStart) >= r.spec.Window {
+		state.windowStart = now
+		state.count = 0
+	}
+	state.count += weight
+	// Distinct paths are only tracked up to the limit to keep memory bounded.
+	if r.spec.MaxCardinality > 0 && len(state.paths) < r.spec.MaxCardinality {
+		state.paths[path] = struct{}{}
+	}
+
+	rate := float64(state.count) / r.spec.Window.Seconds()
+	rateExceeded := r.spec.MinRate > 0 && rate >= r.spec.MinRate
+	cardinalityExceeded := r.spec.MaxCardinality > 0 && len(state.paths) >= r.spec.MaxCardinality
+	if !rateExceeded && !cardinalityExceeded {
+		return
+	}
+	if !state.lastFired.IsZero() && now.Sub(state.lastFired) < r.spec.Cooldown {
+		return
+	}
+
+	state.lastFired = now
+	r.handler(Alert{
+		Rule:        r.spec,
+		Pattern:     pattern,
+		Rate:        rate,
+		Cardinality: len(state.paths),
+		Time:        now,
+		Owners:      owners(),
+	})
+}
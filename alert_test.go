@@ -0,0 +1,85 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestWithAlert(t *testing.T) {
+	var alerts []Alert
+	g, err := New(WithAlert(RuleSpec{
+		PatternGlob:    "/export/**",
+		MinRate:        1,
+		MaxCardinality: 500,
+		Window:         10 * time.Second,
+		Cooldown:       time.Minute,
+	}, func(a Alert) {
+		alerts = append(alerts, a)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	g.now = func() time.Time { return now }
+
+	for i := 0; i < 30; i++ {
+		u, err := url.Parse(fmt.Sprintf("/export/%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+		now = now.Add(100 * time.Millisecond)
+	}
+
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 alert during cooldown, got %d", len(alerts))
+	}
+	if alerts[0].Pattern != "/Words/Number" {
+		t.Fatalf("expected /Words/Number, got %s", alerts[0].Pattern)
+	}
+
+	if _, err := New(WithAlert(RuleSpec{PatternGlob: "/export/**", MinRate: 1}, nil)); err == nil {
+		t.Fatal("expected error for a nil handler")
+	}
+}
+
+func TestAlertSignificantToken(t *testing.T) {
+	var alerts []Alert
+	g, err := New(WithAlert(RuleSpec{PatternGlob: "/**", MaxCardinality: 25, Cooldown: time.Hour}, func(a Alert) {
+		alerts = append(alerts, a)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 30; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/export/%d", i)})
+	}
+
+	if got := g.SimplifyPath(&url.URL{Path: "/export/1"}); got != "/export/Number" {
+		t.Fatalf("expected export to become significant, got %s", got)
+	}
+	// The group keeps its counts once export is significant, so the paths seen before count towards the limit.
+	if len(alerts) != 1 || alerts[0].Pattern != "/Words/Number" || alerts[0].Cardinality != 25 {
+		t.Fatalf("expected a single alert for the group, got %+v", alerts)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	for _, tc := range []struct {
+		glob, path string
+		match      bool
+	}{
+		{"/users/*", "/users/Number", true},
+		{"/users/*", "/users/Number/edit", false},
+		{"/checkout/**", "/checkout", true},
+		{"/checkout/**", "/checkout/cart/Number", true},
+		{"/**/edit", "/users/Number/edit", true},
+		{"/[", "/[", false},
+	} {
+		if matchGlob(tc.glob, tc.path) != tc.match {
+			t.Fatalf("expected %s matching %s to be %t", tc.glob, tc.path, tc.match)
+		}
+	}
+}
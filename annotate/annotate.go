@@ -0,0 +1,171 @@
+// Package annotate enriches streams of URLs with their grouping results, producing one row per URL
+// for loading into data warehouses.
+//
+// Rows are written through the Writer interface so any columnar or row based format can be plugged in.
+// CSV and JSON lines writers are provided here, a Parquet writer lives in the annotate/parquet module.
+//
+// URLs are grouped by a Source, which is either a trained Grouper, or a SnapshotSource matching URLs against the
+// group patterns of a Snapshot.
+package annotate
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/trustleast/groupurl"
+	"github.com/trustleast/groupurl/ingest"
+)
+
+const _defaultBatchSize = 1024
+
+// Row is the grouping result of a single URL.
+type Row struct {
+	RawURL  string   `json:"raw_url" parquet:"raw_url"`
+	Pattern string   `json:"group_pattern" parquet:"group_pattern"`
+	GroupID uint64   `json:"group_id" parquet:"group_id"`
+	Labels  []string `json:"labels" parquet:"labels,list"`
+}
+
+// Writer receives batches of annotated rows.
+type Writer interface {
+	Write(rows []Row) error
+	Close() error
+}
+
+// Source groups the URLs of rows. groupurl.Grouper, groupurl.FrozenGrouper and SnapshotSource implement it.
+type Source interface {
+	SimplifyPath(u *url.URL) string
+	Labels(u *url.URL) []string
+}
+
+// NewRow annotates a single URL. URLs a SnapshotSource does not match have an empty pattern and a GroupID of 0.
+func NewRow(g Source, u *url.URL) Row {
+	row := Row{
+		RawURL:  u.String(),
+		Pattern: g.SimplifyPath(u),
+		Labels:  g.Labels(u),
+	}
+	if row.Pattern != "" {
+		row.GroupID = groupurl.GroupID(row.Pattern)
+	}
+	return row
+}
+
+// Lines annotates every newline separated URL in r and writes the rows to w in batches of batchSize.
+// Lines that are not valid URLs are skipped. The Grouper is only read from, so it should already be trained.
+// It returns the number of rows written.
+func Lines(ctx context.Context, r io.Reader, g Source, w Writer, batchSize int) (int, error) {
+	return Logs(ctx, r, parseURL, g, w, batchSize)
+}
+
+func parseURL(line string) (*url.URL, error) {
+	return url.Parse(strings.TrimSpace(line))
+}
+
+// Logs annotates the URL parse extracts from every line in r, such as a line of an access log, and writes the rows
+// to w in batches of batchSize. Gzip compressed input is detected and decompressed transparently, as by ingest.Read.
+// Lines that fail to parse are skipped. It returns the number of rows written.
+func Logs(ctx context.Context, r io.Reader, parse ingest.LineParser, g Source, w Writer, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = _defaultBatchSize
+	}
+
+	var (
+		written int
+		batch   = make([]Row, 0, batchSize)
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := w.Write(batch); err != nil {
+			return fmt.Errorf("failed to write rows: %w", err)
+		}
+		written += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	err := ingest.ScanLines(ctx, r, func(line string) error {
+		u, err := parse(line)
+		if err != nil || u == nil || u.Path == "" {
+			return nil
+		}
+		batch = append(batch, NewRow(g, u))
+		if len(batch) == batchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return written, err
+	}
+	return written, flush()
+}
+
+// CSVWriter writes rows as CSV with a header, joining labels with "/".
+type CSVWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+// NewCSVWriter creates a CSVWriter writing to w.
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+func (c *CSVWriter) Write(rows []Row) error {
+	if !c.header {
+		if err := c.w.Write([]string{"raw_url", "group_pattern", "group_id", "labels"}); err != nil {
+			return err
+		}
+		c.header = true
+	}
+	for _, row := range rows {
+		err := c.w.Write([]string{
+			row.RawURL,
+			row.Pattern,
+			strconv.FormatUint(row.GroupID, 10),
+			strings.Join(row.Labels, "/"),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *CSVWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// JSONWriter writes rows as newline delimited JSON objects.
+type JSONWriter struct {
+	enc *json.Encoder
+}
+
+// NewJSONWriter creates a JSONWriter writing to w.
+func NewJSONWriter(w io.Writer) *JSONWriter {
+	return &JSONWriter{enc: json.NewEncoder(w)}
+}
+
+func (j *JSONWriter) Write(rows []Row) error {
+	for _, row := range rows {
+		if err := j.enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *JSONWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,91 @@
+package annotate
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/trustleast/groupurl"
+)
+
+func TestLines(t *testing.T) {
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var input strings.Builder
+	for i := 0; i < 100; i++ {
+		rawURL := fmt.Sprintf("https://example.com/products/%d", i)
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+		fmt.Fprintln(&input, rawURL)
+	}
+
+	var out strings.Builder
+	w := NewCSVWriter(&out)
+	written, err := Lines(context.Background(), strings.NewReader(input.String()), g, w, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if written != 100 {
+		t.Fatalf("expected 100 rows, got %d", written)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 101 {
+		t.Fatalf("expected 101 lines, got %d", len(lines))
+	}
+	expected := fmt.Sprintf("https://example.com/products/0,/products/Number,%d,Words/Number", groupurl.GroupID("/products/Number"))
+	if lines[1] != expected {
+		t.Fatalf("expected %s, got %s", expected, lines[1])
+	}
+}
+
+func TestLogs(t *testing.T) {
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	for i := 0; i < 100; i++ {
+		u := &url.URL{Path: fmt.Sprintf("/products/%d", i)}
+		g.Add(u)
+		fmt.Fprintf(zw, "GET %s 200\n", u.Path)
+	}
+	fmt.Fprintln(zw, "malformed")
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	parse := func(line string) (*url.URL, error) {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("malformed line %q", line)
+		}
+		return url.Parse(fields[1])
+	}
+	var out strings.Builder
+	written, err := Logs(context.Background(), &compressed, parse, g, NewJSONWriter(&out), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != 100 {
+		t.Fatalf("expected 100 rows, got %d", written)
+	}
+	if !strings.Contains(out.String(), `"group_pattern":"/products/Number"`) {
+		t.Fatalf("expected rows with their pattern, got %s", out.String())
+	}
+}
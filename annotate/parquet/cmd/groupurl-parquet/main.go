@@ -0,0 +1,121 @@
+// Command groupurl-parquet writes the rows of groupurl annotate as a Parquet file. It reads the JSON lines written by
+// annotate -output json, from files or stdin, so that the groupurl command itself has no dependencies:
+//
+//	groupurl annotate -state state.json -output json access.log | groupurl-parquet --out annotated.parquet
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/trustleast/groupurl/annotate"
+	groupurlparquet "github.com/trustleast/groupurl/annotate/parquet"
+)
+
+const _batchSize = 1024
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("groupurl-parquet", flag.ExitOnError)
+	out := flags.String("out", "", "Parquet file to write the rows to")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: groupurl-parquet --out file.parquet [JSON lines written by groupurl annotate -output json, defaults to stdin]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		flags.Usage()
+		return errors.New("groupurl-parquet requires --out")
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return fmt.Errorf("failed to create output: %w", err)
+	}
+	defer f.Close()
+	w := groupurlparquet.NewWriter(f)
+
+	inputs := flags.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+	var written int
+	for _, input := range inputs {
+		n, err := convertFile(input, w)
+		written += n
+		if err != nil {
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to write rows: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write rows: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d rows\n", written)
+	return nil
+}
+
+func convertFile(path string, w annotate.Writer) (int, error) {
+	if path == "-" {
+		return convert(os.Stdin, w)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open rows: %w", err)
+	}
+	defer f.Close()
+	n, err := convert(f, w)
+	if err != nil {
+		return n, fmt.Errorf("failed to convert %s: %w", path, err)
+	}
+	return n, nil
+}
+
+// convert decodes the JSON rows of r and writes them to w in batches.
+func convert(r io.Reader, w annotate.Writer) (int, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var (
+		written int
+		batch   = make([]annotate.Row, 0, _batchSize)
+	)
+	for {
+		var row annotate.Row
+		err := dec.Decode(&row)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return written, fmt.Errorf("failed to decode row %d: %w", written+len(batch)+1, err)
+		}
+		batch = append(batch, row)
+		if len(batch) == _batchSize {
+			if err := w.Write(batch); err != nil {
+				return written, err
+			}
+			written += len(batch)
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		if err := w.Write(batch); err != nil {
+			return written, err
+		}
+		written += len(batch)
+	}
+	return written, nil
+}
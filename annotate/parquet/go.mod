@@ -0,0 +1,20 @@
+module github.com/trustleast/groupurl/annotate/parquet
+
+go 1.21
+
+require (
+	github.com/parquet-go/parquet-go v0.23.0
+	github.com/trustleast/groupurl v0.0.0-20261015121503-8ed6fbf33e78
+)
+
+require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/mattn/go-runewidth v0.0.15 // indirect
+	github.com/olekukonko/tablewriter v0.0.5 // indirect
+	github.com/pierrec/lz4/v4 v4.1.21 // indirect
+	github.com/rivo/uniseg v0.4.7 // indirect
+	github.com/segmentio/encoding v0.4.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+)
@@ -0,0 +1,29 @@
+// Package groupurlparquet writes annotated URL rows as Parquet files.
+package groupurlparquet
+
+import (
+	"io"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/trustleast/groupurl/annotate"
+)
+
+// Writer is an annotate.Writer streaming rows into a Parquet file.
+type Writer struct {
+	w *parquet.GenericWriter[annotate.Row]
+}
+
+// NewWriter creates a Writer writing a Parquet file to w.
+// The file is only complete once Close has been called.
+func NewWriter(w io.Writer, options ...parquet.WriterOption) *Writer {
+	return &Writer{w: parquet.NewGenericWriter[annotate.Row](w, options...)}
+}
+
+func (w *Writer) Write(rows []annotate.Row) error {
+	_, err := w.w.Write(rows)
+	return err
+}
+
+func (w *Writer) Close() error {
+	return w.w.Close()
+}
@@ -0,0 +1,34 @@
+package groupurlparquet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/trustleast/groupurl/annotate"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	rows := []annotate.Row{{
+		RawURL:  "https://example.com/products/1",
+		Pattern: "/products/Number",
+		GroupID: 1,
+		Labels:  []string{"Words", "Number"},
+	}}
+	if err := w.Write(rows); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := parquet.Read[annotate.Row](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 1 || read[0].Pattern != "/products/Number" || len(read[0].Labels) != 2 {
+		t.Fatalf("unexpected rows %+v", read)
+	}
+}
@@ -0,0 +1,145 @@
+package annotate
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/trustleast/groupurl"
+)
+
+// RetentionAction is what a RetentionRule does with the rows of the groups it matches.
+type RetentionAction int
+
+const (
+	// Keep writes rows unchanged.
+	Keep RetentionAction = iota
+	// Drop does not write rows.
+	Drop
+	// Redact writes rows without their raw URL, which is replaced by its scheme and host followed by the group
+	// pattern, so that rows keep their group but lose the path segments, query, fragment and user info that can hold
+	// personal data.
+	Redact
+)
+
+var _retentionActions = map[string]RetentionAction{
+	"keep":   Keep,
+	"drop":   Drop,
+	"redact": Redact,
+}
+
+func (a RetentionAction) String() string {
+	for name, action := range _retentionActions {
+		if action == a {
+			return name
+		}
+	}
+	return fmt.Sprintf("RetentionAction(%d)", int(a))
+}
+
+// RetentionRule applies an action to the rows whose group pattern matches Glob. Globs are matched segment by segment:
+// `*` matches within a single segment and a `**` segment matches any number of segments, so `/auth/callback/**`
+// covers every group under `/auth/callback`.
+type RetentionRule struct {
+	Glob   string
+	Action RetentionAction
+}
+
+// ParseRetentionRule parses a rule written as `action:glob`, such as `drop:/auth/callback/**`, where the action is
+// keep, drop or redact.
+func ParseRetentionRule(s string) (RetentionRule, error) {
+	name, glob, ok := strings.Cut(s, ":")
+	if !ok {
+		return RetentionRule{}, fmt.Errorf("retention rule %q must be written as action:glob", s)
+	}
+	action, ok := _retentionActions[name]
+	if !ok {
+		return RetentionRule{}, fmt.Errorf("unknown retention action %q, want keep, drop or redact", name)
+	}
+	rule := RetentionRule{Glob: glob, Action: action}
+	return rule, rule.validate()
+}
+
+func (r RetentionRule) validate() error {
+	if r.Glob == "" {
+		return fmt.Errorf("retention rule glob must not be empty")
+	}
+	if _, ok := _retentionActions[r.Action.String()]; !ok {
+		return fmt.Errorf("unknown retention action %d", r.Action)
+	}
+	for _, segment := range strings.Split(strings.Trim(r.Glob, "/"), "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid retention rule glob %q: %w", r.Glob, err)
+		}
+	}
+	return nil
+}
+
+// RetentionWriter applies retention rules to rows before writing them to another Writer, so that annotated data can
+// be exported for long-term storage without the groups that must not be kept, such as auth callbacks or legacy
+// routes carrying personal data. Rows are handled by the first rule matching their group pattern, and kept if none
+// does.
+type RetentionWriter struct {
+	w       Writer
+	rules   []RetentionRule
+	batch   []Row
+	dropped int
+}
+
+// NewRetentionWriter creates a RetentionWriter writing the rows rules keep or redact to w.
+func NewRetentionWriter(w Writer, rules []RetentionRule) (*RetentionWriter, error) {
+	for _, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &RetentionWriter{w: w, rules: append([]RetentionRule(nil), rules...)}, nil
+}
+
+func (r *RetentionWriter) Write(rows []Row) error {
+	r.batch = r.batch[:0]
+	for _, row := range rows {
+		switch r.action(row.Pattern) {
+		case Drop:
+			r.dropped++
+			continue
+		case Redact:
+			row.RawURL = redactURL(row.RawURL, row.Pattern)
+		}
+		r.batch = append(r.batch, row)
+	}
+	if len(r.batch) == 0 {
+		return nil
+	}
+	return r.w.Write(r.batch)
+}
+
+func (r *RetentionWriter) Close() error {
+	return r.w.Close()
+}
+
+// Dropped returns the number of rows that were not written.
+func (r *RetentionWriter) Dropped() int {
+	return r.dropped
+}
+
+func (r *RetentionWriter) action(pattern string) RetentionAction {
+	for _, rule := range r.rules {
+		if groupurl.MatchGlob(rule.Glob, pattern) {
+			return rule.Action
+		}
+	}
+	return Keep
+}
+
+// redactURL replaces a raw URL by its scheme and host followed by the pattern of its group. URLs that fail to parse
+// are replaced by the pattern alone.
+func redactURL(rawURL, pattern string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return pattern
+	}
+	redacted := url.URL{Scheme: u.Scheme, Host: u.Host}
+	return redacted.String() + pattern
+}
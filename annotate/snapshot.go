@@ -0,0 +1,70 @@
+package annotate
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/trustleast/groupurl"
+)
+
+// SnapshotSource groups URLs by the group patterns of a Snapshot, such as `/Words/Number`, so that logs can be
+// annotated with nothing but a snapshot. Unlike a Grouper, which returns simplified paths that keep significant
+// tokens, it returns the pattern of the group a URL falls into. URLs matching no group get an empty pattern.
+type SnapshotSource struct {
+	groups []snapshotGroup
+}
+
+type snapshotGroup struct {
+	pattern string
+	labels  []string
+	matcher *groupurl.PatternMatcher
+}
+
+// NewSnapshotSource compiles the group patterns of a Snapshot with the classifiers of g, which should be configured
+// like the Grouper that took the snapshot. Wildcard tails are matched first, as a Grouper does, and the other
+// patterns in the order of the snapshot.
+func NewSnapshotSource(s groupurl.Snapshot, g groupurl.Grouper) (*SnapshotSource, error) {
+	source := &SnapshotSource{}
+	for _, grp := range s.Groups {
+		m, err := g.CompilePattern(grp.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile pattern %q: %w", grp.Pattern, err)
+		}
+		source.groups = append(source.groups, snapshotGroup{
+			pattern: grp.Pattern,
+			labels:  strings.Split(strings.Trim(grp.Pattern, "/"), "/"),
+			matcher: m,
+		})
+	}
+	sort.SliceStable(source.groups, func(i, j int) bool {
+		return isWildcardTail(source.groups[i].pattern) && !isWildcardTail(source.groups[j].pattern)
+	})
+	return source, nil
+}
+
+func isWildcardTail(pattern string) bool {
+	return strings.HasSuffix(pattern, "/**")
+}
+
+func (s *SnapshotSource) match(u *url.URL) (snapshotGroup, bool) {
+	for _, grp := range s.groups {
+		if _, ok := grp.matcher.Match(u); ok {
+			return grp, true
+		}
+	}
+	return snapshotGroup{}, false
+}
+
+// SimplifyPath returns the pattern of the group a URL falls into.
+func (s *SnapshotSource) SimplifyPath(u *url.URL) string {
+	grp, _ := s.match(u)
+	return grp.pattern
+}
+
+// Labels returns the segments of the pattern of the group a URL falls into.
+func (s *SnapshotSource) Labels(u *url.URL) []string {
+	grp, _ := s.match(u)
+	return grp.labels
+}
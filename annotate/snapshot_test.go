@@ -0,0 +1,44 @@
+package annotate
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/trustleast/groupurl"
+)
+
+func TestSnapshotSource(t *testing.T) {
+	g, err := groupurl.New(groupurl.WithWildcardTails("/static"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		for _, p := range []string{fmt.Sprintf("/products/%d", i), fmt.Sprintf("/static/img/%d.png", i)} {
+			u, err := url.Parse(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.Add(u)
+		}
+	}
+
+	empty, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := NewSnapshotSource(g.Snapshot(), empty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, expected := range map[string]Row{
+		"/products/7":       {RawURL: "/products/7", Pattern: "/Words/Number", GroupID: groupurl.GroupID("/Words/Number"), Labels: []string{"Words", "Number"}},
+		"/static/img/a.png": {RawURL: "/static/img/a.png", Pattern: "/static/**", GroupID: groupurl.GroupID("/static/**"), Labels: []string{"static", "**"}},
+		"/products/7/more":  {RawURL: "/products/7/more"},
+	} {
+		if row := NewRow(source, &url.URL{Path: path}); !reflect.DeepEqual(row, expected) {
+			t.Fatalf("expected %+v for %s, got %+v", expected, path, row)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package groupurl
+
+// _slabSize is the number of nodes a nodeSlab allocates at once.
+const _slabSize = 64
+
+// nodeSlab allocates the nodes of a tree in chunks, so that trees of millions of nodes make a few large allocations
+// the garbage collector scans quickly rather than one small allocation per node. A chunk is freed once none of its
+// nodes is referenced, so nodes dropped by merges hold on to their chunk until then.
+type nodeSlab struct {
+	free []urlNode
+}
+
+// alloc returns a zeroed node from the current chunk, allocating a new chunk once it is used up.
+// A nil slab allocates nodes one by one.
+func (s *nodeSlab) alloc() *urlNode {
+	if s == nil {
+		return &urlNode{}
+	}
+	if len(s.free) == 0 {
+		s.free = make([]urlNode, _slabSize)
+	}
+	n := &s.free[0]
+	s.free = s.free[1:]
+	return n
+}
@@ -0,0 +1,84 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"runtime"
+	"testing"
+)
+
+func TestNodeSlab(t *testing.T) {
+	var s nodeSlab
+	first := s.alloc()
+	for i := 1; i < _slabSize; i++ {
+		s.alloc()
+	}
+	if len(s.free) != 0 {
+		t.Fatalf("expected the chunk to be used up, got %d free nodes", len(s.free))
+	}
+	if n := s.alloc(); n == first || len(s.free) != _slabSize-1 {
+		t.Fatal("expected a new chunk once the first is used up")
+	}
+
+	var none *nodeSlab
+	if n := none.alloc(); n == nil {
+		t.Fatal("expected a nil slab to allocate nodes")
+	}
+
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/section%s/%d/item%s", letters(i%200), i, letters(i%7))})
+	}
+	frozen := g.Freeze()
+	for i := 0; i < 1000; i += 37 {
+		u := &url.URL{Path: fmt.Sprintf("/section%s/%d/item%s", letters(i%200), i, letters(i%7))}
+		if got, want := frozen.SimplifyPath(u), g.SimplifyPath(u); got != want {
+			t.Fatalf("expected the frozen copy to simplify as the Grouper, got %s, want %s", got, want)
+		}
+	}
+}
+
+// BenchmarkTreeAdd learns a tree of many nodes, with its nodes allocated from a slab or one by one, and reports the
+// objects and bytes the garbage collector has to mark in the tree it leaves, which its pauses and background work
+// scale with.
+func BenchmarkTreeAdd(b *testing.B) {
+	// Four labels without a parent label over 8 positions make 4^8 label paths, with a node per label and position.
+	tokens := make([][]pathToken, 1<<16)
+	for i := range tokens {
+		for depth, rest := 0, i; depth < 8; depth, rest = depth+1, rest/4 {
+			label := Label{LabelFields: LabelFields{Important: true, Value: fmt.Sprintf("Label%d", rest%4)}}
+			tokens[i] = append(tokens[i], pathToken{token: letters(i % 100), label: label})
+		}
+	}
+	learn := func(slab bool) urlTree {
+		t := newURLTree(treeConfig{significance: AverageShare{Threshold: _significanceThreshold}})
+		if !slab {
+			t.nodes = nil
+		}
+		for _, path := range tokens {
+			t.add(path, "", request{}, 1, false)
+		}
+		return t
+	}
+	for _, slab := range []bool{true, false} {
+		b.Run(fmt.Sprintf("slab=%t", slab), func(b *testing.B) {
+			b.ReportAllocs()
+			var before, after runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			var t urlTree
+			for i := 0; i < b.N; i++ {
+				t = learn(slab)
+			}
+			b.StopTimer()
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+			b.ReportMetric(float64(after.HeapObjects-before.HeapObjects), "live-objects")
+			b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc), "live-B")
+			runtime.KeepAlive(t)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package groupurl
+
+import "regexp"
+
+var (
+	regexImage  = regexp.MustCompile(`(?i)^[^/]*\.(jpe?g|png|gif|webp|avif|svg|ico|bmp|tiff?)$`)
+	regexScript = regexp.MustCompile(`(?i)^[^/]*\.(js|mjs|cjs|jsx|ts|map|wasm)$`)
+	regexStyle  = regexp.MustCompile(`(?i)^[^/]*\.(css|scss|less)$`)
+	regexFont   = regexp.MustCompile(`(?i)^[^/]*\.(woff2?|ttf|otf|eot)$`)
+)
+
+// ImageClassifier returns a classifier that matches terminal segments with an image file extension.
+func ImageClassifier() RegexPathTokenClassifier {
+	return assetClassifier(regexImage, "Image", builtinInfo("image", "image files"))
+}
+
+// ScriptClassifier returns a classifier that matches terminal segments with a script file extension.
+func ScriptClassifier() RegexPathTokenClassifier {
+	return assetClassifier(regexScript, "Script", builtinInfo("script", "script files"))
+}
+
+// StyleClassifier returns a classifier that matches terminal segments with a stylesheet file extension.
+func StyleClassifier() RegexPathTokenClassifier {
+	return assetClassifier(regexStyle, "Style", builtinInfo("style", "stylesheet files"))
+}
+
+// FontClassifier returns a classifier that matches terminal segments with a font file extension.
+func FontClassifier() RegexPathTokenClassifier {
+	return assetClassifier(regexFont, "Font", builtinInfo("font", "font files"))
+}
+
+// AssetClassifiers returns classifiers that bucket static assets by the class of their file extension, so that
+// asset traffic is grouped under Image, Script, Style and Font rather than one group per file name.
+// They only match the last segment of a path and should come before other classifiers, for example:
+//
+//	WithClassifiers(append(AssetClassifiers(), DefaultClassifiers()...))
+func AssetClassifiers() []PathTokenClassifier {
+	return []PathTokenClassifier{
+		ImageClassifier(),
+		ScriptClassifier(),
+		StyleClassifier(),
+		FontClassifier(),
+	}
+}
+
+func assetClassifier(regex *regexp.Regexp, value string, info ClassifierInfo) RegexPathTokenClassifier {
+	return RegexPathTokenClassifier{
+		Regex: regex,
+		Label: Label{
+			LabelFields: LabelFields{
+				Important: false,
+				Value:     value,
+			},
+		},
+		Info: info,
+	}
+}
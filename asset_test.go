@@ -0,0 +1,42 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestAssetClassifiers(t *testing.T) {
+	g, err := New(WithClassifiers(append(AssetClassifiers(), DefaultClassifiers()...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		for _, p := range []string{"/img/photo-%d.JPG", "/js/chunk.%d.mjs", "/css/theme%d.css", "/fonts/inter-%d.woff2"} {
+			u, err := url.Parse(fmt.Sprintf(p, i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.Add(u)
+		}
+	}
+
+	for p, expected := range map[string]string{
+		"/img/banner.webp":   "/img/Image",
+		"/js/app.js":         "/js/Script",
+		"/css/site.css":      "/css/Style",
+		"/fonts/inter.woff2": "/fonts/Font",
+	} {
+		u, err := url.Parse(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := g.SimplifyPath(u); got != expected {
+			t.Fatalf("expected %s for %s, got %s", expected, p, got)
+		}
+	}
+
+	if label, _ := ImageClassifier().Check("logo.png/edit"); !label.isZero() {
+		t.Fatal("expected asset classifiers to only match terminal segments")
+	}
+}
@@ -0,0 +1,86 @@
+package groupurl
+
+import "fmt"
+
+const (
+	_defaultTuneWindow     = 100
+	_defaultTuneGrowthRate = 0.5
+)
+
+// AutoTune configures how WithAutoTune chooses cardinality limits.
+type AutoTune struct {
+	// Window is the number of URLs counted at a node between tuning decisions. Defaults to 100.
+	Window int
+	// GrowthRate is the share of new distinct tokens in a window at or above which the distinct tokens of a node are
+	// considered to grow linearly with traffic. Defaults to 0.5.
+	GrowthRate float64
+}
+
+// WithAutoTune replaces the `CardinalityLimit` of Important labels with limits chosen from the tokens observed.
+// Every window, each node compares the distinct tokens it gained with the URLs it counted. Nodes whose distinct
+// tokens keep growing with traffic, like identifiers, are capped at their current number of tokens, while nodes
+// whose tokens plateau, like a fixed set of resource names, are left open. Caps are not lifted once set.
+// The decision made for each segment is reported by Explain.
+func WithAutoTune(config AutoTune) Option {
+	return func(g *Grouper) error {
+		if config.Window == 0 {
+			config.Window = _defaultTuneWindow
+		}
+		if config.GrowthRate == 0 {
+			config.GrowthRate = _defaultTuneGrowthRate
+		}
+		if config.Window < 0 {
+			return fmt.Errorf("auto tune window must be positive, got %d", config.Window)
+		}
+		if config.GrowthRate < 0 || config.GrowthRate > 1 {
+			return fmt.Errorf("auto tune growth rate must be in (0, 1], got %v", config.GrowthRate)
+		}
+		g.tree.autoTune = &config
+		return nil
+	}
+}
+
+// nodeTuning is the state of the auto tuning of a single node.
+type nodeTuning struct {
+	windowStart int
+	population  int
+	decision    string
+}
+
+func (n *nodeTuning) clone() *nodeTuning {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	return &c
+}
+
+// tune makes a tuning decision for a node once it has counted a full window since the last one.
+func (t urlTree) tune(n *urlNode) {
+	if t.autoTune == nil || !t.important(n) || n.tokenCounts.limit > 0 {
+		return
+	}
+	if n.tuning == nil {
+		n.tuning = &nodeTuning{
+			windowStart: n.tokenCounts.total,
+			population:  n.tokenCounts.population(),
+			decision:    "open, not enough traffic to tune yet",
+		}
+		return
+	}
+
+	counted := n.tokenCounts.total - n.tuning.windowStart
+	if counted < t.autoTune.Window {
+		return
+	}
+	population := n.tokenCounts.population()
+	growth := float64(population-n.tuning.population) / float64(counted)
+	if growth >= t.autoTune.GrowthRate {
+		n.tokenCounts.limit = population
+		n.tuning.decision = fmt.Sprintf("capped at %d, %.0f%% of the last %d tokens were new", population, 100*growth, counted)
+	} else {
+		n.tuning.decision = fmt.Sprintf("open, %.0f%% of the last %d tokens were new", 100*growth, counted)
+	}
+	n.tuning.windowStart = n.tokenCounts.total
+	n.tuning.population = population
+}
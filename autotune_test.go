@@ -0,0 +1,45 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestAutoTune(t *testing.T) {
+	g, err := New(WithAutoTune(AutoTune{Window: 50}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 400; i++ {
+		// The first segment grows with traffic while the second comes from a fixed set.
+		u, err := url.Parse(fmt.Sprintf("/%s/%s", budgetWord(i), []string{"profile", "settings", "orders"}[i%3]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+
+	u, err := url.Parse("/aaa/orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := g.Explain(u)
+	if e.Simplified != g.SimplifyPath(u) {
+		t.Fatalf("expected explanation to match SimplifyPath, got %s and %s", e.Simplified, g.SimplifyPath(u))
+	}
+	if e.Simplified != "/Words/orders" {
+		t.Fatalf("unexpected simplified path %s", e.Simplified)
+	}
+	if first := e.Segments[0]; first.Limit <= 0 || !strings.HasPrefix(first.Tuning, "capped") {
+		t.Fatalf("expected the growing segment to be capped, got %+v", first)
+	}
+	if second := e.Segments[1]; second.Limit != 0 || !strings.HasPrefix(second.Tuning, "open") || !second.Kept {
+		t.Fatalf("expected the fixed segment to stay open, got %+v", second)
+	}
+
+	if _, err := New(WithAutoTune(AutoTune{GrowthRate: 2})); err == nil {
+		t.Fatal("expected error for an out of range growth rate")
+	}
+}
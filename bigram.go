@@ -0,0 +1,95 @@
+package groupurl
+
+import "strings"
+
+// _commonBigrams are the most frequent letter pairs of English text, most frequent first.
+const _commonBigrams = "th he in er an re on at en nd ti es or te of ed is it al ar st to nt ng se ha as ou io le ve co me " +
+	"de hi ri ro ic ne ea ra ce li ch ll be ma si om ur ca el ta la ns di fo ho pe ec pr no ct us ac ot il tr ly nc et " +
+	"ut ss so rs un lo wa ge ie wh ee wi em ad ol rt po we na ul ni ts mo ow pa im mi ai sh ir su id os iv ia am fi ci " +
+	"vi pl ig tu ev ld ry mp fe bl ab gh ty op wo sa ay ex ke fr oo av ag if ap gr od bo sp rd do uc bu ei ov by rm ep " +
+	"tt oc fa ef cu rn sc gi da yo cr cl du ga qu ue ff ba ey ls va um pp ua up lu go ht ru ug ds lt pi rc rr eg au ck " +
+	"ew mu br bi pt ak pu ui rg ib tl ny ki rk ys ob mm fu ph og ms ye ud mb ip ub oi rl gu dr hr cc tw ft wn nu af hu"
+
+// _minCommonBigramShare is the share of common letter pairs below which a hyphenated token is considered random.
+const _minCommonBigramShare = 0.5
+
+var _bigramTable = newBigramTable(strings.Fields(_commonBigrams))
+
+// bigramTable marks the common pairs of lower case letters.
+type bigramTable [26][26]bool
+
+func newBigramTable(bigrams []string) *bigramTable {
+	var table bigramTable
+	for _, bigram := range bigrams {
+		if len(bigram) == 2 && isLowerLetter(bigram[0]) && isLowerLetter(bigram[1]) {
+			table[bigram[0]-'a'][bigram[1]-'a'] = true
+		}
+	}
+	return &table
+}
+
+// RandomTokenClassifier is a classifier that matches hyphenated or underscored segments that look randomly generated,
+// such as `xq3k-vv9z-trh2`, rather than written by people, such as `summer-sale-2024`. A segment is random when most
+// of its parts mix letters and digits, or when few of the letter pairs in it are common in English.
+// Random tokens are labeled RandomToken, which is not Important, so they are not kept as Words would be.
+type RandomTokenClassifier struct{}
+
+func (r RandomTokenClassifier) Check(s string) (Label, string) {
+	match := regexWords.FindString(s)
+	token := strings.TrimSuffix(match, "/")
+	if !isRandomToken(token, _bigramTable, _minCommonBigramShare) {
+		return Label{}, ""
+	}
+	return Label{
+		LabelFields: LabelFields{
+			Important: false,
+			Value:     "RandomToken",
+		},
+	}, match
+}
+
+// isRandomToken reports whether a hyphenated or underscored token looks randomly generated, given the common letter
+// pairs and the share of them below which letters are considered random.
+func isRandomToken(token string, table *bigramTable, minShare float64) bool {
+	if !strings.ContainsAny(token, "-_") {
+		return false
+	}
+	var parts, mixed, bigrams, common int
+	for _, part := range strings.FieldsFunc(strings.ToLower(token), func(r rune) bool {
+		return r == '-' || r == '_'
+	}) {
+		letters, digits := 0, 0
+		for i := 0; i < len(part); i++ {
+			c := part[i]
+			if !isLowerLetter(c) {
+				digits++
+				continue
+			}
+			letters++
+			if i > 0 && isLowerLetter(part[i-1]) {
+				bigrams++
+				if table[part[i-1]-'a'][c-'a'] {
+					common++
+				}
+			}
+		}
+		if letters == 0 {
+			continue
+		}
+		parts++
+		if digits > 0 {
+			mixed++
+		}
+	}
+	if parts == 0 {
+		return false
+	}
+	if mixed*2 > parts {
+		return true
+	}
+	return bigrams >= 3 && float64(common) < minShare*float64(bigrams)
+}
+
+func isLowerLetter(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
@@ -0,0 +1,57 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestRandomTokenClassifier(t *testing.T) {
+	for s, expected := range map[string]bool{
+		"xq3k-vv9z-trh2":        true,
+		"zxqv-kwpj-bfgd/":       true,
+		"summer-sale-2024":      false,
+		"getting-started":       false,
+		"iphone-15-pro":         false,
+		"a1b2c3":                false,
+		"release_notes_v2":      false,
+		"covid19-update":        false,
+		"9f8e7d6c-5b4a-3f2e-1d": true,
+	} {
+		label, _ := RandomTokenClassifier{}.Check(s)
+		if got := label.Value == "RandomToken"; got != expected {
+			t.Fatalf("expected %t for %s, got %t", expected, s, got)
+		}
+	}
+
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		u, err := url.Parse(fmt.Sprintf("/share/%c%c%d-kq%dz-%d", 'a'+i%26, 'a'+(i*7)%26, i, i, i*i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+	u, err := url.Parse("/share/xq3k-vv9z-trh2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.SimplifyPath(u); got != "/share/RandomToken" {
+		t.Fatalf("expected random tokens to be grouped, got %s", got)
+	}
+
+	table, err := g.DecisionTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewDecisionEvaluator(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := e.SimplifyPath(u.Path); got != "/share/RandomToken" {
+		t.Fatalf("expected the decision table to group random tokens, got %s", got)
+	}
+}
@@ -0,0 +1,163 @@
+package groupurl
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// _binaryMagic starts the output of MarshalBinary, followed by _binaryVersion.
+const (
+	_binaryMagic   = "groupurl"
+	_binaryVersion = 1
+)
+
+// ErrIncompatibleModel is returned by UnmarshalBinary when the Grouper was not built with the classifiers and other
+// options the model depends on as the one that marshaled it.
+var ErrIncompatibleModel = errors.New("incompatible model")
+
+// MarshalBinary encodes everything the Grouper has learned, like WriteState, along with a description of its
+// classifiers and the other options its model depends on, so that a model trained offline can be shipped to the
+// services that use it. The state is gzip compressed, which makes it much smaller than the JSON of WriteState. As with
+// WriteState, Groupers with nodes split by ApplySplit cannot be marshaled.
+func (g Grouper) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(_binaryMagic)
+	writeUvarint(&buf, _binaryVersion)
+	config := g.modelConfig()
+	writeUvarint(&buf, uint64(len(config)))
+	for _, c := range config {
+		writeUvarint(&buf, uint64(len(c)))
+		buf.WriteString(c)
+	}
+
+	zw := gzip.NewWriter(&buf)
+	if err := g.WriteState(zw); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary replaces what the Grouper has learned with a model encoded by MarshalBinary. It returns an error
+// matching ErrIncompatibleModel, and leaves the Grouper unchanged, if the Grouper was not built with the classifiers
+// and other options of the one that marshaled the model, as described by modelConfig, since its trees would not match
+// the labels and tokens it emits.
+func (g Grouper) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(_binaryMagic)) {
+		return errors.New("not a groupurl model")
+	}
+	r := bufio.NewReader(bytes.NewReader(data[len(_binaryMagic):]))
+	version, err := binary.ReadUvarint(r)
+	if err != nil {
+		return fmt.Errorf("failed to read model version: %w", err)
+	}
+	if version != _binaryVersion {
+		return fmt.Errorf("unsupported model version %d", version)
+	}
+
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return fmt.Errorf("failed to read model configuration: %w", err)
+	}
+	if n > uint64(len(data)) {
+		return errors.New("corrupt model configuration")
+	}
+	config := make([]string, n)
+	for i := range config {
+		size, err := binary.ReadUvarint(r)
+		if err != nil || size > uint64(len(data)) {
+			return errors.New("corrupt model configuration")
+		}
+		b := make([]byte, size)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return fmt.Errorf("failed to read model configuration: %w", err)
+		}
+		config[i] = string(b)
+	}
+	if err := checkModelConfig(config, g.modelConfig()); err != nil {
+		return err
+	}
+
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to read model: %w", err)
+	}
+	defer zr.Close()
+	return g.ReadState(zr)
+}
+
+// modelConfig describes the options of the Grouper that a model depends on, since its trees would not match the labels
+// and tokens of a Grouper built differently: its cardinality limit, numeric normalization, deterministic mode, path
+// policy, the types of its Counters, its label vocabulary and its classifiers, in order. Classifiers are described by
+// their type and, when they provide them, their regular expression and label or their ClassifierInfo. Other options,
+// such as significance, priors or pinned tokens, only change how the learned counts are read, and are not checked.
+func (g Grouper) modelConfig() []string {
+	config := []string{
+		fmt.Sprintf("cardinality limit %d", g.tree.cardinalityLimit),
+		fmt.Sprintf("numeric normalization %t", g.tree.normalizeNumbers),
+		fmt.Sprintf("deterministic %t", g.tree.deterministic),
+		fmt.Sprintf("path policy %d", g.tree.pathPolicy),
+		fmt.Sprintf("counter %T", g.tree.counterFor(LabelFields{})),
+	}
+	labels := make([]string, 0, len(g.tree.labelCounters))
+	for label := range g.tree.labelCounters {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		config = append(config, fmt.Sprintf("counter of %s %T", label, g.tree.labelCounters[label]()))
+	}
+	words := make([]string, 0, len(g.vocabulary))
+	for label, word := range g.vocabulary {
+		words = append(words, label+"="+word)
+	}
+	sort.Strings(words)
+	config = append(config, "vocabulary "+strings.Join(words, ","))
+	for _, c := range g.classifiers {
+		config = append(config, describeModelClassifier(c))
+	}
+	return config
+}
+
+func describeModelClassifier(c PathTokenClassifier) string {
+	switch c := c.(type) {
+	case vocabularyClassifier:
+		// The vocabulary is described on its own.
+		return describeModelClassifier(c.classifier)
+	case RegexPathTokenClassifier:
+		return fmt.Sprintf("%T %s %+v", c, c.Regex, c.Label)
+	case DescribedClassifier:
+		info := c.Describe()
+		return fmt.Sprintf("%T %s %s", c, info.Name, strings.Join(info.Labels, ","))
+	default:
+		return fmt.Sprintf("%T", c)
+	}
+}
+
+func checkModelConfig(model, current []string) error {
+	for i := 0; i < len(model) || i < len(current); i++ {
+		switch {
+		case i >= len(current):
+			return fmt.Errorf("%w: the model was built with %q, which the Grouper does not have", ErrIncompatibleModel, model[i])
+		case i >= len(model):
+			return fmt.Errorf("%w: the Grouper has %q, which the model was not built with", ErrIncompatibleModel, current[i])
+		case model[i] != current[i]:
+			return fmt.Errorf("%w: the model was built with %q where the Grouper has %q", ErrIncompatibleModel, model[i], current[i])
+		}
+	}
+	return nil
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
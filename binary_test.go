@@ -0,0 +1,83 @@
+package groupurl
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = Grouper{}
+	_ encoding.BinaryUnmarshaler = Grouper{}
+)
+
+func TestMarshalBinary(t *testing.T) {
+	g, err := New(WithCardinalityLimit(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/users/%d/posts/%s", i, letters(i%30))})
+		g.Add(&url.URL{Path: "/health"})
+	}
+	if err := g.Tag("/Words", "team", "platform"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := New(WithCardinalityLimit(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if restored.String() != g.String() {
+		t.Fatalf("expected the restored Grouper to match, got\n%s\nwant\n%s", restored.String(), g.String())
+	}
+	for _, path := range []string{"/users/7/posts/abc", "/health"} {
+		u := &url.URL{Path: path}
+		if got, want := restored.SimplifyPath(u), g.SimplifyPath(u); got != want {
+			t.Fatalf("%s: expected %s, got %s", path, want, got)
+		}
+	}
+	if got := restored.Tags("/Words"); got["team"] != "platform" {
+		t.Fatalf("expected tags to be restored, got %v", got)
+	}
+
+	spaceSaving, err := NewSpaceSavingCounter(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, options := range [][]Option{
+		nil,
+		{WithCardinalityLimit(20), WithClassifiers(DefaultClassifiers()[1:])},
+		{WithCardinalityLimit(20), WithNumericNormalization()},
+		{WithCardinalityLimit(20), WithDeterministic()},
+		{WithCardinalityLimit(20), WithPathPolicy(PathCanonicalize)},
+		{WithCardinalityLimit(20), WithCounter(spaceSaving)},
+		{WithCardinalityLimit(20), WithLabelCounter("Words", spaceSaving)},
+		{WithCardinalityLimit(20), WithLabelVocabulary(LabelVocabulary{"Number": "num"})},
+	} {
+		other, err := New(options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := other.UnmarshalBinary(data); !errors.Is(err, ErrIncompatibleModel) {
+			t.Fatalf("expected an incompatible model, got %v", err)
+		}
+		if len(other.trees) != 0 {
+			t.Fatal("expected the Grouper to be left unchanged")
+		}
+	}
+	for _, corrupt := range [][]byte{nil, []byte("groupurl"), data[:len(data)/2], append([]byte("other"), data...)} {
+		if err := restored.UnmarshalBinary(corrupt); err == nil {
+			t.Fatalf("expected an error for %d corrupt bytes", len(corrupt))
+		}
+	}
+}
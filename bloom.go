@@ -0,0 +1,81 @@
+package groupurl
+
+import (
+	"fmt"
+	"hash/maphash"
+	"math"
+	"math/bits"
+)
+
+// bloomFilter is a fixed size Bloom filter over strings using double hashing.
+type bloomFilter struct {
+	seeds  [2]maphash.Seed
+	bits   []uint64
+	m      uint64
+	k      uint64
+	insert int
+}
+
+func newBloomFilter(expected int, falsePositiveRate float64) *bloomFilter {
+	m := math.Ceil(-float64(expected) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := math.Max(1, math.Round(m/float64(expected)*math.Ln2))
+	words := (uint64(m) + 63) / 64
+	return &bloomFilter{
+		seeds: [2]maphash.Seed{maphash.MakeSeed(), maphash.MakeSeed()},
+		bits:  make([]uint64, words),
+		m:     words * 64,
+		k:     uint64(k),
+	}
+}
+
+// add inserts s and reports whether it was definitely not present before.
+func (b *bloomFilter) add(s string) bool {
+	h1, h2 := maphash.String(b.seeds[0], s), maphash.String(b.seeds[1], s)|1
+	added := false
+	for i := uint64(0); i < b.k; i++ {
+		bit := (h1 + i*h2) % b.m
+		word, mask := bit/64, uint64(1)<<(bit%64)
+		if b.bits[word]&mask == 0 {
+			b.bits[word] |= mask
+			added = true
+		}
+	}
+	if added {
+		b.insert++
+	}
+	return added
+}
+
+// estimate returns the approximate number of distinct strings added, derived from the share of set bits.
+func (b *bloomFilter) estimate() int {
+	var set int
+	for _, w := range b.bits {
+		set += bits.OnesCount64(w)
+	}
+	if uint64(set) == b.m {
+		return b.insert
+	}
+	m := float64(b.m)
+	return int(math.Round(-m / float64(b.k) * math.Log(1-float64(set)/m)))
+}
+
+// WithSeenFilter tracks which exact paths have been added in a Bloom filter sized for the expected number of
+// distinct paths at the given false positive rate. It enables DistinctPathEstimate and the FirstSeen flag
+// passed to add hooks, without storing every path.
+func WithSeenFilter(expectedPaths int, falsePositiveRate float64) Option {
+	return func(g *Grouper) error {
+		if expectedPaths <= 0 || falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+			return fmt.Errorf("invalid seen filter for %d paths at rate %v", expectedPaths, falsePositiveRate)
+		}
+		g.seen = newBloomFilter(expectedPaths, falsePositiveRate)
+		return nil
+	}
+}
+
+// DistinctPathEstimate returns the approximate number of distinct paths added, or 0 without WithSeenFilter.
+func (g Grouper) DistinctPathEstimate() int {
+	if g.seen == nil {
+		return 0
+	}
+	return g.seen.estimate()
+}
@@ -0,0 +1,34 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestWithSeenFilter(t *testing.T) {
+	var firstSeen int
+	g, err := New(WithSeenFilter(10000, 0.01), WithAddHook(func(u *url.URL, info AddInfo) {
+		if info.FirstSeen {
+			firstSeen++
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4000; i++ {
+		u, err := url.Parse(fmt.Sprintf("/items/%d", i%2000))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+
+	if firstSeen < 1950 || firstSeen > 2000 {
+		t.Fatalf("expected about 2000 first seen paths, got %d", firstSeen)
+	}
+	if estimate := g.DistinctPathEstimate(); estimate < 1900 || estimate > 2100 {
+		t.Fatalf("expected about 2000 distinct paths, got %d", estimate)
+	}
+}
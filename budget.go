@@ -0,0 +1,125 @@
+package groupurl
+
+import "sort"
+
+// SpillPolicy decides what happens to URLs that would record new tokens once a Budget is exhausted.
+type SpillPolicy int
+
+const (
+	// SpillOverflow records the URL, but new tokens are counted under a generic label as with `CardinalityLimit`.
+	SpillOverflow SpillPolicy = iota
+	// SpillDrop ignores the URL entirely. URLs made of tokens that have already been recorded are still counted.
+	SpillDrop
+)
+
+// Budget bounds the number of distinct tokens recorded by a HostGrouper, which is what its memory grows with.
+// Global is shared by all hosts, Host applies to the Grouper of each host, and Tree to each of its trees.
+// Node is applied with WithCardinalityLimit and, as with `CardinalityLimit`, always overflows.
+// Zero values are unlimited.
+type Budget struct {
+	Global int
+	Host   int
+	Tree   int
+	Node   int
+	Policy SpillPolicy
+}
+
+// BudgetStats reports how much of a Budget has been used, with hosts ordered by usage so that the hosts
+// pressuring memory come first.
+type BudgetStats struct {
+	Budget Budget
+	Used   int
+	Hosts  []HostBudgetStats
+}
+
+// HostBudgetStats reports the budget usage of a single host.
+// Spilled and Dropped count the URLs the SpillPolicy was applied to.
+type HostBudgetStats struct {
+	Host    string
+	Used    int
+	Trees   map[int]int
+	Spilled int
+	Dropped int
+}
+
+// budget tracks the usage of a single Grouper, and through global, of all Groupers of a HostGrouper.
+type budget struct {
+	limits  Budget
+	global  *int
+	used    int
+	trees   map[int]int
+	spilled int
+	dropped int
+}
+
+func newBudget(limits Budget, global *int) *budget {
+	return &budget{
+		limits: limits,
+		global: global,
+		trees:  make(map[int]int),
+	}
+}
+
+// hasRoom reports whether a tree may record new tokens at every level of the budget.
+func (b *budget) hasRoom(key int) bool {
+	return (b.limits.Global == 0 || *b.global < b.limits.Global) &&
+		(b.limits.Host == 0 || b.used < b.limits.Host) &&
+		(b.limits.Tree == 0 || b.trees[key] < b.limits.Tree)
+}
+
+func (b *budget) charge(key, tokens int) {
+	*b.global += tokens
+	b.used += tokens
+	b.trees[key] += tokens
+}
+
+// recount recomputes the usage from the trees after they were restructured.
+func (b *budget) recount(trees map[int]urlTree) {
+	*b.global -= b.used
+	b.used = 0
+	b.trees = make(map[int]int, len(trees))
+	for key, t := range trees {
+		t.walk(func(path []*urlNode) {
+			b.trees[key] += path[len(path)-1].tokenCounts.population()
+		})
+		b.used += b.trees[key]
+	}
+	*b.global += b.used
+}
+
+func (b *budget) stats(host string) HostBudgetStats {
+	trees := make(map[int]int, len(b.trees))
+	for key, used := range b.trees {
+		trees[key] = used
+	}
+	return HostBudgetStats{
+		Host:    host,
+		Used:    b.used,
+		Trees:   trees,
+		Spilled: b.spilled,
+		Dropped: b.dropped,
+	}
+}
+
+func sortHostBudgetStats(hosts []HostBudgetStats) {
+	sort.Slice(hosts, func(i, j int) bool {
+		if hosts[i].Used != hosts[j].Used {
+			return hosts[i].Used > hosts[j].Used
+		}
+		return hosts[i].Host < hosts[j].Host
+	})
+}
+
+// known reports whether adding tokens would only count tokens that have already been recorded.
+func (t urlTree) known(tokens []pathToken) bool {
+	current := t.Root
+	for _, token := range tokens {
+		token = current.route(token)
+		child, ok := current.children.get(token.label.parentOrSelf())
+		if !ok || child.tokenCounts.get(token.token) == 0 {
+			return false
+		}
+		current = child
+	}
+	return true
+}
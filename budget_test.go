@@ -0,0 +1,65 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestBudget(t *testing.T) {
+	add := func(h *HostGrouper, raw string) {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.Add(u)
+	}
+
+	h, err := NewHostGrouper(WithBudget(Budget{Global: 100, Host: 20}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		add(h, "https://noisy.example/"+budgetWord(i))
+	}
+	add(h, "https://quiet.example/home")
+	add(h, "https://quiet.example/home")
+
+	stats := h.BudgetStats()
+	if len(stats.Hosts) != 2 || stats.Hosts[0].Host != "noisy.example" {
+		t.Fatalf("expected the noisy host first, got %+v", stats.Hosts)
+	}
+	noisy, quiet := stats.Hosts[0], stats.Hosts[1]
+	if noisy.Used > 21 || noisy.Spilled == 0 {
+		t.Fatalf("expected the noisy host to spill at its budget, got %+v", noisy)
+	}
+	if quiet.Used != 1 || quiet.Spilled != 0 {
+		t.Fatalf("expected the quiet host to be unaffected, got %+v", quiet)
+	}
+	if stats.Used != noisy.Used+quiet.Used {
+		t.Fatalf("expected global usage to sum hosts, got %d", stats.Used)
+	}
+
+	h, err = NewHostGrouper(WithBudget(Budget{Tree: 5, Policy: SpillDrop}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		add(h, "https://noisy.example/"+budgetWord(i))
+	}
+	add(h, "https://noisy.example/"+budgetWord(0))
+	add(h, "https://noisy.example/abc/def")
+	stats = h.BudgetStats()
+	if got := stats.Hosts[0]; got.Dropped != 5 || got.Trees[0] != 5 || got.Trees[1] != 2 {
+		t.Fatalf("expected URLs beyond the tree budget to be dropped, got %+v", got)
+	}
+
+	if _, err := NewHostGrouper(WithBudget(Budget{Host: -1})); err == nil {
+		t.Fatal("expected error for a negative budget")
+	}
+}
+
+// budgetWord returns a distinct word for every i, so that tokens are classified as Words.
+func budgetWord(i int) string {
+	return fmt.Sprintf("%c%c%c", 'a'+i/676%26, 'a'+i/26%26, 'a'+i%26)
+}
@@ -0,0 +1,253 @@
+package groupurl
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	// _paramValueLimit is the number of distinct values counted per query parameter of a group. Parameters reaching
+	// it are high cardinality.
+	_paramValueLimit = 100
+	// _minParamCount is the number of URLs a parameter must be seen in before it can be learned as noise.
+	_minParamCount = 100
+	// _maxNoisePresence is the share of the URLs of a group a noise parameter is found in at most. Parameters found in
+	// most URLs, such as the id of /item.php?id=123, usually select the content.
+	_maxNoisePresence = 0.5
+	// _maxParams bounds the number of distinct parameter names counted per group.
+	_maxParams = 32
+)
+
+// _defaultNoiseParams are tracking parameters that never change the content of a page. Names ending with "*" are
+// prefixes.
+var _defaultNoiseParams = []string{"utm_*", "gclid", "dclid", "fbclid", "msclkid", "yclid", "mc_cid", "mc_eid", "_ga", "_gl"}
+
+// queryParam counts the values of a query parameter of a group.
+type queryParam struct {
+	count  int
+	values caseInsensitiveStringCounter
+}
+
+// CacheKeyParams sets how CacheKey treats query parameters.
+type CacheKeyParams struct {
+	// Keep lists parameters that are never stripped, whatever was learned.
+	Keep []string
+	// Strip lists parameters that are always stripped, on top of common tracking parameters such as utm_source.
+	// Names ending with "*" are prefixes.
+	Strip []string
+}
+
+// WithQueryLearning counts the query parameters of the URLs of each group, so that CacheKey strips the parameters
+// learned as noise, and NoiseParams lists them. A parameter is noise once it was seen in at least 100 URLs of a group
+// with at least 100 distinct values, such as a cache buster, while being absent from most URLs of the group.
+// Parameters of wildcard tail groups are not counted.
+func WithQueryLearning() Option {
+	return func(g *Grouper) error {
+		g.tree.learnQuery = true
+		return nil
+	}
+}
+
+// WithCacheKeyParams sets parameters CacheKey always keeps or always strips.
+func WithCacheKeyParams(params CacheKeyParams) Option {
+	return func(g *Grouper) error {
+		g.tree.cacheKeyParams = params
+		return nil
+	}
+}
+
+// CacheKey returns a stable key for caching the response to a URL, with irrelevant variation removed. The URL is
+// canonicalized: the scheme and host are lower cased, default ports, fragments, dot segments and repeated slashes are
+// removed, and query parameters are sorted. Tracking parameters, those set by WithCacheKeyParams and those learned
+// as noise WithQueryLearning are stripped. Path segments are never collapsed into their labels, whether they are
+// Important or not, since two paths of a group usually serve different content.
+func (g Grouper) CacheKey(u *url.URL) string {
+	return cacheKey(u, g.tree, g.noiseParams(u))
+}
+
+// CacheKey returns a stable key for caching the response to a URL, the same way Grouper.CacheKey does.
+func (f FrozenGrouper) CacheKey(u *url.URL) string {
+	t := lookupTree(f.trees, u.Path, f.tree)
+	return cacheKey(u, f.tree, t.noiseParams(labelPathTokens(u.Path, f.classifiers)))
+}
+
+// NoiseParams returns the names of the query parameters learned as noise for the group with the given pattern,
+// in order.
+func (g Grouper) NoiseParams(pattern string) []string {
+	var noise []string
+	for _, t := range g.trees {
+		t.walk(func(path []*urlNode) {
+			labels := make([]string, 0, len(path))
+			for _, n := range path {
+				labels = append(labels, n.specificLabel.Value)
+			}
+			if "/"+strings.Join(labels, "/") == pattern {
+				noise = append(noise, path[len(path)-1].noiseParams()...)
+			}
+		})
+	}
+	sort.Strings(noise)
+	return noise
+}
+
+func (g Grouper) noiseParams(u *url.URL) []string {
+	if _, ok := g.tails.match(u.Path); ok {
+		return nil
+	}
+	t := lookupTree(g.trees, u.Path, g.tree)
+	return t.noiseParams(labelPathTokens(u.Path, g.classifiers))
+}
+
+// noiseParams returns the parameters learned as noise at the node the tokens end at.
+func (t urlTree) noiseParams(tokens []pathToken) []string {
+	current := t.Root
+	for _, token := range tokens {
+		token = current.route(token)
+		child, ok := current.children.get(token.label.parentOrSelf())
+		if !ok {
+			return nil
+		}
+		current = child
+	}
+	return current.noiseParams()
+}
+
+func (n *urlNode) noiseParams() []string {
+	terminal := n.terminal()
+	var noise []string
+	for name, p := range n.params {
+		if p.count >= _minParamCount && p.values.population() >= _paramValueLimit &&
+			float64(p.count) < _maxNoisePresence*float64(terminal) {
+			noise = append(noise, name)
+		}
+	}
+	return noise
+}
+
+// addParams counts the query parameters of a URL that terminates at a node.
+func (n *urlNode) addParams(rawQuery string, weight int) {
+	if rawQuery == "" {
+		return
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return
+	}
+	for name, values := range query {
+		p, ok := n.params[name]
+		if !ok {
+			if len(n.params) >= _maxParams {
+				continue
+			}
+			if n.params == nil {
+				n.params = make(map[string]*queryParam)
+			}
+			p = &queryParam{values: newCaseInsensitiveStringCounter(_paramValueLimit)}
+			n.params[name] = p
+		}
+		p.count += weight
+		for _, v := range values {
+			p.values.addN(v, weight)
+		}
+	}
+}
+
+// cacheKey canonicalizes a URL and strips noise parameters from its query.
+func cacheKey(u *url.URL, config treeConfig, noise []string) string {
+	c := url.URL{
+		Scheme: strings.ToLower(u.Scheme),
+		Host:   strings.ToLower(u.Host),
+		Path:   canonicalPath(u.Path),
+	}
+	if port := c.Port(); (c.Scheme == "http" && port == "80") || (c.Scheme == "https" && port == "443") {
+		c.Host = strings.TrimSuffix(c.Host, ":"+port)
+	}
+	if u.RawPath != "" {
+		// Keep escaped slashes and other reserved characters escaped.
+		c.RawPath = canonicalPath(u.EscapedPath())
+		if p, err := url.PathUnescape(c.RawPath); err == nil {
+			c.Path = p
+		}
+	}
+
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		c.RawQuery = u.RawQuery
+		return c.String()
+	}
+	for name := range query {
+		if stripParam(name, config.cacheKeyParams, noise) {
+			delete(query, name)
+		}
+	}
+	c.RawQuery = query.Encode()
+	return c.String()
+}
+
+func stripParam(name string, params CacheKeyParams, noise []string) bool {
+	if matchParam(name, params.Keep) {
+		return false
+	}
+	return matchParam(name, _defaultNoiseParams) || matchParam(name, params.Strip) || matchParam(name, noise)
+}
+
+func matchParam(name string, names []string) bool {
+	for _, n := range names {
+		if prefix, ok := strings.CutSuffix(n, "*"); ok && strings.HasPrefix(name, prefix) || n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalPath removes dot segments and repeated slashes, keeping a trailing slash.
+func canonicalPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	cleaned := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// cloneParams deep copies the parameters of a node.
+func cloneParams(params map[string]*queryParam) map[string]*queryParam {
+	if params == nil {
+		return nil
+	}
+	c := make(map[string]*queryParam, len(params))
+	for name, p := range params {
+		values := newCaseInsensitiveStringCounter(p.values.limit)
+		values.total = p.values.total
+		p.values.counts.Range(func(v string, count int) bool {
+			values.counts.Add(v, count)
+			return true
+		})
+		c[name] = &queryParam{count: p.count, values: values}
+	}
+	return c
+}
+
+// mergeParams adds the parameters of src to dst, creating dst if needed.
+func mergeParams(dst, src map[string]*queryParam) map[string]*queryParam {
+	for name, p := range src {
+		existing, ok := dst[name]
+		if !ok {
+			if dst == nil {
+				dst = make(map[string]*queryParam)
+			}
+			existing = &queryParam{values: newCaseInsensitiveStringCounter(_paramValueLimit)}
+			dst[name] = existing
+		}
+		existing.count += p.count
+		p.values.counts.Range(func(v string, count int) bool {
+			existing.values.addN(v, count)
+			return true
+		})
+	}
+	return dst
+}
@@ -0,0 +1,74 @@
+package groupurl
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestCacheKey(t *testing.T) {
+	g, err := New(WithCacheKeyParams(CacheKeyParams{Keep: []string{"utm_keep"}, Strip: []string{"session"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for raw, want := range map[string]string{
+		"HTTPS://Example.COM:443/a/./b/../c//d?b=2&a=1#top": "https://example.com/a/c/d?a=1&b=2",
+		"http://example.com:80/dir/":                        "http://example.com/dir/",
+		"http://example.com:8080":                           "http://example.com:8080/",
+		"/path?utm_source=news&utm_keep=1&id=5":             "/path?id=5&utm_keep=1",
+		"/path?gclid=abc&session=xyz":                       "/path",
+		"/files/a%2Fb?x=1":                                  "/files/a%2Fb?x=1",
+		"/users/123":                                        "/users/123",
+	} {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := g.CacheKey(u); got != want {
+			t.Errorf("%s: got %s, want %s", raw, got, want)
+		}
+	}
+}
+
+func TestQueryLearning(t *testing.T) {
+	g, err := New(WithQueryLearning())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 400; i++ {
+		u := &url.URL{Path: fmt.Sprintf("/items/%d", i%10), RawQuery: fmt.Sprintf("color=red&variant=%d", i)}
+		if i%3 == 0 {
+			// A cache buster on a third of the requests.
+			u.RawQuery += fmt.Sprintf("&_=%d", 1000+i)
+		}
+		g.Add(u)
+	}
+
+	if got := g.NoiseParams("/Words/Number"); len(got) != 1 || got[0] != "_" {
+		t.Fatalf("got noise params %v, want [_]", got)
+	}
+	u := &url.URL{Path: "/items/3", RawQuery: "variant=7&_=12345&color=red"}
+	want := "/items/3?color=red&variant=7"
+	if got := g.CacheKey(u); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got := g.Freeze().CacheKey(u); got != want {
+		t.Errorf("got %s from a frozen copy, want %s", got, want)
+	}
+
+	var state bytes.Buffer
+	if err := g.WriteState(&state); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := New(WithQueryLearning())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.ReadState(&state); err != nil {
+		t.Fatal(err)
+	}
+	if got := restored.CacheKey(u); got != want {
+		t.Errorf("got %s after restoring the state, want %s", got, want)
+	}
+}
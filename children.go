@@ -0,0 +1,88 @@
+package groupurl
+
+// _maxListedChildren is the number of children a node lists before indexing them by key in a map.
+const _maxListedChildren = 4
+
+// childNodes holds the children of a node by the identity of their key, so that keys differing only by their
+// cardinality limit find the same child, which keeps the key it was first set with. Most nodes have no children or a
+// few of them, which are listed and found by a linear scan, so that only the nodes with many children pay for a map.
+// The zero value holds no children.
+type childNodes struct {
+	list  []childNode
+	byKey map[LabelFields]childNode
+}
+
+type childNode struct {
+	key  LabelFields
+	node *urlNode
+}
+
+// get returns the child of a key, and whether there is one.
+func (c *childNodes) get(key LabelFields) (*urlNode, bool) {
+	if c.byKey != nil {
+		child, ok := c.byKey[key.identity()]
+		return child.node, ok
+	}
+	for _, child := range c.list {
+		if child.key.identity() == key.identity() {
+			return child.node, true
+		}
+	}
+	return nil, false
+}
+
+// set adds or replaces the child of a key, indexing the children in a map once there are too many to list.
+func (c *childNodes) set(key LabelFields, n *urlNode) {
+	if c.byKey != nil {
+		if child, ok := c.byKey[key.identity()]; ok {
+			key = child.key
+		}
+		c.byKey[key.identity()] = childNode{key: key, node: n}
+		return
+	}
+	for i := range c.list {
+		if c.list[i].key.identity() == key.identity() {
+			c.list[i].node = n
+			return
+		}
+	}
+	if len(c.list) < _maxListedChildren {
+		c.list = append(c.list, childNode{key: key, node: n})
+		return
+	}
+	c.byKey = make(map[LabelFields]childNode, len(c.list)+1)
+	for _, child := range c.list {
+		c.byKey[child.key.identity()] = child
+	}
+	c.byKey[key.identity()] = childNode{key: key, node: n}
+	c.list = nil
+}
+
+func (c *childNodes) len() int {
+	if c.byKey != nil {
+		return len(c.byKey)
+	}
+	return len(c.list)
+}
+
+// keys returns the keys of the children, in any order.
+func (c *childNodes) keys() []LabelFields {
+	keys := make([]LabelFields, 0, c.len())
+	c.each(func(key LabelFields, _ *urlNode) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+// each calls f with every child and its key, in any order.
+func (c *childNodes) each(f func(key LabelFields, n *urlNode)) {
+	if c.byKey != nil {
+		for _, child := range c.byKey {
+			f(child.key, child.node)
+		}
+		return
+	}
+	for _, child := range c.list {
+		f(child.key, child.node)
+	}
+}
@@ -0,0 +1,137 @@
+package groupurl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestChildNodes(t *testing.T) {
+	var c childNodes
+	if n, ok := c.get(LabelFields{Value: "Words"}); ok || n != nil || c.len() != 0 {
+		t.Fatal("expected no children")
+	}
+
+	nodes := make(map[LabelFields]*urlNode)
+	for i := 0; i < 2*_maxListedChildren; i++ {
+		key := LabelFields{Value: fmt.Sprintf("Label%d", i)}
+		nodes[key] = &urlNode{specificLabel: key}
+		c.set(key, nodes[key])
+		if listed := i < _maxListedChildren; listed != (c.byKey == nil) {
+			t.Fatalf("expected %d children to be listed: %t", i+1, listed)
+		}
+		for key, n := range nodes {
+			if got, ok := c.get(key); !ok || got != n {
+				t.Fatalf("expected the child of %s among %d children", key.Value, i+1)
+			}
+		}
+	}
+
+	replaced := &urlNode{}
+	c.set(LabelFields{Value: "Label0"}, replaced)
+	if got, _ := c.get(LabelFields{Value: "Label0"}); got != replaced || c.len() != 2*_maxListedChildren {
+		t.Fatal("expected the child to be replaced")
+	}
+	keys := c.keys()
+	sort.Slice(keys, func(i, j int) bool { return lessLabelFields(keys[i], keys[j]) })
+	if len(keys) != len(nodes) || keys[0].Value != "Label0" {
+		t.Fatalf("expected the keys of every child, got %v", keys)
+	}
+	var visited int
+	c.each(func(key LabelFields, n *urlNode) {
+		visited++
+	})
+	if visited != len(nodes) {
+		t.Fatalf("expected every child to be visited, got %d", visited)
+	}
+}
+
+func TestChildIdentity(t *testing.T) {
+	// A copy of the built-in classifier with a lower limit for some of its words.
+	sku := WordsClassifier()
+	sku.Regex = regexp.MustCompile(`^sku-[a-z]+`)
+	sku.Label.CardinalityLimit = 10
+	g, err := New(WithClassifiers([]PathTokenClassifier{sku, WordsClassifier()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		g.Add(&url.URL{Path: "/sku-" + letters(i%20)})
+		g.Add(&url.URL{Path: "/shoes"})
+	}
+	if got := g.String(); strings.Count(got, "/Words <words>:") != 1 || !strings.Contains(got, "(200)") {
+		t.Fatalf("expected labels only differing by their limit to share a node, got\n%s", got)
+	}
+
+	// States written before could hold siblings only differing by their limit, which are merged.
+	var buf bytes.Buffer
+	if err := g.WriteState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var state grouperState
+	if err := json.Unmarshal(buf.Bytes(), &state); err != nil {
+		t.Fatal(err)
+	}
+	root := state.Trees[0]
+	sibling := root.Children[0]
+	sibling.Key.CardinalityLimit = 10
+	sibling.Label.CardinalityLimit = 10
+	root.Children = append(root.Children, sibling)
+	state.Trees[0] = root
+	split, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.ReadState(bytes.NewReader(split)); err != nil {
+		t.Fatal(err)
+	}
+	if got := restored.String(); strings.Count(got, "/Words <words>:") != 1 || !strings.Contains(got, "(400)") {
+		t.Fatalf("expected the siblings to be merged, got\n%s", got)
+	}
+}
+
+var (
+	childNodesSink childNodes
+	childMapSink   map[LabelFields]*urlNode
+)
+
+// BenchmarkChildNodes compares the memory of childNodes with the map nodes held their children in before, by number
+// of children.
+func BenchmarkChildNodes(b *testing.B) {
+	for _, fanOut := range []int{0, 1, 2, 3, 8, 16} {
+		keys := make([]LabelFields, fanOut)
+		for i := range keys {
+			keys[i] = LabelFields{Important: true, Value: fmt.Sprintf("Label%d", i)}
+		}
+		n := &urlNode{}
+		b.Run(fmt.Sprintf("children=%d/storage=list", fanOut), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var c childNodes
+				for _, key := range keys {
+					c.set(key, n)
+				}
+				childNodesSink = c
+			}
+		})
+		b.Run(fmt.Sprintf("children=%d/storage=map", fanOut), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c := make(map[LabelFields]*urlNode)
+				for _, key := range keys {
+					c[key] = n
+				}
+				childMapSink = c
+			}
+		})
+	}
+}
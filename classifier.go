@@ -1,6 +1,7 @@
 package groupurl
 
 import (
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -18,7 +19,22 @@ var (
 	_yyyyEnd = int64(time.Now().Year())
 )
 
-const _yyyyStart = 1900
+const (
+	_yyyyStart = 1900
+
+	_strictCardinalityLimit = 10
+	_looseCardinalityLimit  = 500
+
+	_smallNumberMax = 9999
+
+	_namespaceSeparator = ":"
+)
+
+// _defaultNumberBuckets are used by a NumberBucketClassifier without Buckets.
+var _defaultNumberBuckets = []NumberBucket{
+	{Max: _smallNumberMax, Label: "SmallNumber"},
+	{Max: math.MaxInt64, Label: "BigNumber"},
+}
 
 // Labels are a wrapper that Classifiers return to indicate how a path should be treated.
 // This wrapper exists to allow the `NestedPathTokenClassifier` to specify a parent label.
@@ -39,14 +55,39 @@ func (l Label) isZero() bool {
 	return l.Value == ""
 }
 
+// qualified folds the namespaces of a label and its parent into their values.
+func (l Label) qualified() Label {
+	l.LabelFields = l.LabelFields.qualified()
+	l.parent = l.parent.qualified()
+	return l
+}
+
 // LabelFields indicates how a label should be treated by the Grouper.
 // Important implies that all fields should be preserved exactly and not grouped under a generic label.
 // CardinalityLimit tells the grouper to record fields up to a certain limit, and then group the rest under a generic label.
 // Value is the name of the label.
+// Namespace, when set, is prefixed to Value as `Namespace:Value` in patterns, so that labels of third party classifiers
+// never merge with built-in labels of the same value. Packages typically use their name.
 type LabelFields struct {
 	Important        bool
 	CardinalityLimit int
 	Value            string
+	Namespace        string
+}
+
+// qualified returns the label with its namespace folded into its value.
+func (l LabelFields) qualified() LabelFields {
+	if l.Namespace != "" && l.Value != "" {
+		l.Value = l.Namespace + _namespaceSeparator + l.Value
+		l.Namespace = ""
+	}
+	return l
+}
+
+// identity returns the fields that tell the children of a node apart. Labels that only differ by their cardinality
+// limit, as when two classifiers emit Words with different limits, are the same child.
+func (l LabelFields) identity() LabelFields {
+	return LabelFields{Important: l.Important, Value: l.Value, Namespace: l.Namespace}
 }
 
 func (l LabelFields) cardinalityLimit() int {
@@ -71,6 +112,8 @@ type PathTokenClassifier interface {
 type RegexPathTokenClassifier struct {
 	Regex *regexp.Regexp
 	Label Label
+	// Info optionally describes the classifier, see DescribedClassifier.
+	Info ClassifierInfo
 }
 
 func (r RegexPathTokenClassifier) Check(s string) (Label, string) {
@@ -108,6 +151,46 @@ func (y YearPathTokenClassifier) Check(s string) (Label, string) {
 	return Label{}, ""
 }
 
+// NumberBucket labels numbers up to and including Max that are not labeled by a previous bucket.
+type NumberBucket struct {
+	Max   int64
+	Label string
+}
+
+// NumberBucketClassifier is a classifier that labels numeric segments by their magnitude, so that `/page/2` and
+// `/item/99382171` are grouped apart. Buckets are checked in order and numbers above every bucket are not matched.
+// Without Buckets, numbers up to 9999 are labeled SmallNumber and larger ones BigNumber.
+type NumberBucketClassifier struct {
+	Buckets []NumberBucket
+}
+
+func (n NumberBucketClassifier) Check(s string) (Label, string) {
+	match := regexNumbers.FindString(s)
+	if match == "" {
+		return Label{}, ""
+	}
+	num, err := strconv.ParseInt(strings.TrimSuffix(match, "/"), 10, 64)
+	if err != nil {
+		// Numbers too large to parse are above every bucket but the unbounded one.
+		num = math.MaxInt64
+	}
+	buckets := n.Buckets
+	if len(buckets) == 0 {
+		buckets = _defaultNumberBuckets
+	}
+	for _, bucket := range buckets {
+		if num <= bucket.Max {
+			return Label{
+				LabelFields: LabelFields{
+					Important: false,
+					Value:     bucket.Label,
+				},
+			}, match
+		}
+	}
+	return Label{}, ""
+}
+
 // NestedPathTokenClassifier indicates to the grouper that if multiple children classifiers are matched in a segment,
 // the segment should be grouped under the parent.
 // For example, assume you have a parent that is Letters and Numbers, and you have children that is either Letters or Numbers.
@@ -147,6 +230,7 @@ func YYYYMMDDClassifier() RegexPathTokenClassifier {
 				Value:     "YYYY/MM/DD",
 			},
 		},
+		Info: builtinInfo("yyyymmdd", "dates in the format YYYY/MM/DD"),
 	}
 }
 
@@ -160,6 +244,7 @@ func AlphaNumericClassifier() RegexPathTokenClassifier {
 				Value:     "AlphaNumeric",
 			},
 		},
+		Info: builtinInfo("alphanumeric", "alphanumeric segments"),
 	}
 }
 
@@ -173,6 +258,7 @@ func NumberClassifier() RegexPathTokenClassifier {
 				Value:     "Number",
 			},
 		},
+		Info: builtinInfo("number", "numeric segments"),
 	}
 }
 
@@ -187,6 +273,7 @@ func WordsClassifier() RegexPathTokenClassifier {
 				Value:            "Words",
 			},
 		},
+		Info: builtinInfo("words", "words delimited by dashes"),
 	}
 }
 
@@ -201,10 +288,36 @@ func LettersClassifier() RegexPathTokenClassifier {
 				Value:            "Letters",
 			},
 		},
+		Info: builtinInfo("letters", "segments made of letters"),
 	}
 }
 
 func DefaultClassifiers() []PathTokenClassifier {
+	return defaultClassifiers(AlphaNumericClassifier(), WordsClassifier(), LettersClassifier())
+}
+
+// StrictDefaultClassifiers returns the default classifiers tuned for precision: only words from small vocabularies
+// are preserved, so simplified paths rarely leak identifiers at the cost of merging some distinct routes.
+func StrictDefaultClassifiers() []PathTokenClassifier {
+	words, letters := WordsClassifier(), LettersClassifier()
+	words.Label.CardinalityLimit = _strictCardinalityLimit
+	letters.Label.CardinalityLimit = _strictCardinalityLimit
+	return defaultClassifiers(AlphaNumericClassifier(), words, letters)
+}
+
+// LooseDefaultClassifiers returns the default classifiers tuned for recall: words from large vocabularies, and
+// significant tokens that mix words and numbers, are preserved, so distinct routes are rarely merged at the cost of
+// more groups.
+func LooseDefaultClassifiers() []PathTokenClassifier {
+	alphaNumeric, words, letters := AlphaNumericClassifier(), WordsClassifier(), LettersClassifier()
+	alphaNumeric.Label.Important = true
+	alphaNumeric.Label.CardinalityLimit = _looseCardinalityLimit
+	words.Label.CardinalityLimit = _looseCardinalityLimit
+	letters.Label.CardinalityLimit = _looseCardinalityLimit
+	return defaultClassifiers(alphaNumeric, words, letters)
+}
+
+func defaultClassifiers(alphaNumeric, words, letters PathTokenClassifier) []PathTokenClassifier {
 	return []PathTokenClassifier{
 		YYYYMMDDClassifier(),
 		YearPathTokenClassifier{
@@ -212,11 +325,12 @@ func DefaultClassifiers() []PathTokenClassifier {
 			End:   _yyyyEnd,
 		},
 		NestedPathTokenClassifier{
-			Parent: AlphaNumericClassifier(),
+			Parent: alphaNumeric,
 			Children: []PathTokenClassifier{
 				NumberClassifier(),
-				WordsClassifier(),
-				LettersClassifier(),
+				RandomTokenClassifier{},
+				words,
+				letters,
 			},
 		},
 	}
@@ -262,7 +376,7 @@ func labelPathTokens(path string, classifiers []PathTokenClassifier) []pathToken
 func labelPathToken(path string, classifiers []PathTokenClassifier) (Label, string) {
 	for _, classifier := range classifiers {
 		if label, match := classifier.Check(path); !label.isZero() {
-			return label, match
+			return label.qualified(), match
 		}
 	}
 	return Label{
@@ -0,0 +1,141 @@
+package groupurl
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// _builtinSource is the Source of the classifiers provided by this package.
+const _builtinSource = "groupurl"
+
+// ClassifierInfo is optional metadata about a classifier, provided by implementing DescribedClassifier.
+// It is shown by Explain, String and the exports, and used by New to detect classifiers that emit the same labels.
+type ClassifierInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Source identifies where the classifier comes from, such as the package providing it.
+	Source string `json:"source,omitempty"`
+	// Labels are the label values the classifier emits.
+	Labels []string `json:"labels,omitempty"`
+}
+
+// DescribedClassifier is implemented by classifiers that provide metadata about themselves.
+// Classifiers that do not implement it are not checked for label collisions.
+type DescribedClassifier interface {
+	Describe() ClassifierInfo
+}
+
+// Describe returns Info, with the classifier's label as its only label.
+func (r RegexPathTokenClassifier) Describe() ClassifierInfo {
+	info := r.Info
+	info.Labels = []string{r.Label.LabelFields.qualified().Value}
+	return info
+}
+
+func (y YearPathTokenClassifier) Describe() ClassifierInfo {
+	info := builtinInfo("year", fmt.Sprintf("years between %d and %d", y.Start, y.End))
+	info.Labels = []string{"YYYY"}
+	return info
+}
+
+func (n NumberBucketClassifier) Describe() ClassifierInfo {
+	info := builtinInfo("numberbucket", "numbers labeled by their magnitude")
+	buckets := n.Buckets
+	if len(buckets) == 0 {
+		buckets = _defaultNumberBuckets
+	}
+	for _, bucket := range buckets {
+		info.Labels = append(info.Labels, bucket.Label)
+	}
+	return info
+}
+
+func (r RandomTokenClassifier) Describe() ClassifierInfo {
+	info := builtinInfo("random", "hyphenated tokens that look randomly generated")
+	info.Labels = []string{"RandomToken"}
+	return info
+}
+
+func builtinInfo(name, description string) ClassifierInfo {
+	return ClassifierInfo{
+		Name:        name,
+		Description: description,
+		Source:      _builtinSource,
+	}
+}
+
+// describeLabels maps the labels emitted by classifiers to the classifier emitting them, including the parents and
+// children of NestedPathTokenClassifier. A label emitted by classifiers with different names or sources is a collision,
+// since their tokens would be grouped together. The first classifier emitting a label is kept when there are collisions.
+func describeLabels(classifiers []PathTokenClassifier) (map[string]ClassifierInfo, error) {
+	infos := make(map[string]ClassifierInfo)
+	var errs []error
+	stack := append([]PathTokenClassifier(nil), classifiers...)
+	for len(stack) > 0 {
+		c := stack[0]
+		stack = stack[1:]
+		if nested, ok := c.(NestedPathTokenClassifier); ok {
+			stack = append(append([]PathTokenClassifier{nested.Parent}, nested.Children...), stack...)
+			continue
+		}
+		described, ok := c.(DescribedClassifier)
+		if !ok {
+			continue
+		}
+		info := described.Describe()
+		for _, label := range info.Labels {
+			existing, ok := infos[label]
+			if !ok {
+				infos[label] = info
+				continue
+			}
+			if existing.Name != info.Name || existing.Source != info.Source {
+				errs = append(errs, fmt.Errorf("label %q is emitted by both %s and %s", label, existing, info))
+			}
+		}
+	}
+	return infos, errors.Join(errs...)
+}
+
+// String returns the source and name of the classifier.
+func (i ClassifierInfo) String() string {
+	name := i.Name
+	if name == "" {
+		name = "an unnamed classifier"
+	}
+	if i.Source == "" {
+		return name
+	}
+	return i.Source + "/" + name
+}
+
+// classifierInfos returns the distinct classifiers of labels ordered by source and name, each with the labels it is
+// known to emit.
+func classifierInfos(labels map[string]ClassifierInfo) []ClassifierInfo {
+	type key struct {
+		source, name string
+	}
+	byKey := make(map[key]*ClassifierInfo)
+	for label, info := range labels {
+		k := key{info.Source, info.Name}
+		if _, ok := byKey[k]; !ok {
+			c := info
+			c.Labels = nil
+			byKey[k] = &c
+		}
+		byKey[k].Labels = append(byKey[k].Labels, label)
+	}
+	infos := make([]ClassifierInfo, 0, len(byKey))
+	for _, info := range byKey {
+		sort.Strings(info.Labels)
+		infos = append(infos, *info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Source != infos[j].Source {
+			return infos[i].Source < infos[j].Source
+		}
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
@@ -0,0 +1,52 @@
+package groupurl
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestClassifierInfo(t *testing.T) {
+	words := RegexPathTokenClassifier{
+		Regex: regexp.MustCompile(`^[a-z]+(/|$)`),
+		Label: Label{LabelFields: LabelFields{Important: true, Value: "Words"}},
+		Info:  ClassifierInfo{Name: "lowercase", Source: "acme"},
+	}
+	if _, err := New(WithClassifiers(append([]PathTokenClassifier{words}, DefaultClassifiers()...))); err == nil {
+		t.Fatal("expected an error for classifiers emitting the same label")
+	}
+	if _, err := New(WithClassifiers(append(DefaultClassifiers(), DefaultClassifiers()...))); err != nil {
+		t.Fatalf("expected the same classifiers not to collide, got %v", err)
+	}
+
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse("/users/42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Add(u)
+
+	explanation := g.Explain(u)
+	if got := explanation.Segments[1].Classifier; got.Name != "number" || got.Source != "groupurl" {
+		t.Fatalf("expected the number classifier to be described, got %+v", got)
+	}
+	if s := g.String(); !strings.Contains(s, "/Number <number>: (1)") {
+		t.Fatalf("expected classifier names in %s", s)
+	}
+	var found bool
+	for _, info := range g.Snapshot().Classifiers {
+		if info.Name == "words" {
+			found = len(info.Labels) == 1 && info.Labels[0] == "Words"
+		}
+	}
+	if !found {
+		t.Fatalf("expected the words classifier in %+v", g.Snapshot().Classifiers)
+	}
+	if got := g.TreeJSON().Trees[0].Children[0].Classifier; got != "words" {
+		t.Fatalf("expected the words classifier in the tree JSON, got %q", got)
+	}
+}
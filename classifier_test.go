@@ -0,0 +1,88 @@
+package groupurl
+
+import (
+	"net/url"
+	"regexp"
+	"testing"
+)
+
+func TestNumberBucketClassifier(t *testing.T) {
+	for s, expected := range map[string]string{
+		"2":                        "SmallNumber",
+		"9999/":                    "SmallNumber",
+		"99382171":                 "BigNumber",
+		"123456789012345678901234": "BigNumber",
+		"12a":                      "",
+	} {
+		if label, _ := (NumberBucketClassifier{}).Check(s); label.Value != expected {
+			t.Fatalf("expected %q for %s, got %q", expected, s, label.Value)
+		}
+	}
+
+	custom, err := NewClassifier("numberbucket", map[string]any{
+		"buckets": []any{
+			map[string]any{"max": 100, "label": "Page"},
+			map[string]any{"max": 100000, "label": "Item"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := New(WithClassifiers(append([]PathTokenClassifier{custom}, DefaultClassifiers()...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for p, expected := range map[string][]string{
+		"/list/2":          {"Words", "Page"},
+		"/list/99382":      {"Words", "Item"},
+		"/list/9938217100": {"Words", "Number"},
+	} {
+		u, err := url.Parse(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if labels := g.Labels(u); len(labels) != 2 || labels[0] != expected[0] || labels[1] != expected[1] {
+			t.Fatalf("expected %v for %s, got %v", expected, p, labels)
+		}
+	}
+}
+
+func TestLabelNamespace(t *testing.T) {
+	special := RegexPathTokenClassifier{
+		Regex: regexp.MustCompile(`^foo-[a-z]+(/|$)`),
+		Label: Label{LabelFields: LabelFields{Value: "Words", Namespace: "acme"}},
+		Info:  ClassifierInfo{Name: "special", Source: "acme"},
+	}
+	g, err := New(WithClassifiers(append([]PathTokenClassifier{special}, DefaultClassifiers()...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for p, expected := range map[string][]string{
+		"/foo-bar/1": {"acme:Words", "Number"},
+		"/bar/1":     {"Words", "Number"},
+	} {
+		u, err := url.Parse(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+		if labels := g.Labels(u); len(labels) != 2 || labels[0] != expected[0] || labels[1] != expected[1] {
+			t.Fatalf("expected %v for %s, got %v", expected, p, labels)
+		}
+	}
+	if got := len(g.Snapshot().Groups); got != 2 {
+		t.Fatalf("expected namespaced labels to be grouped apart, got %d groups", got)
+	}
+
+	table, err := g.DecisionTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewDecisionEvaluator(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := e.SimplifyPath("/foo-baz/2"); got != "/acme:Words/Number" {
+		t.Fatalf("expected the decision table to keep the namespace, got %s", got)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/trustleast/groupurl/annotate"
+	"github.com/trustleast/groupurl/ingest"
+)
+
+func runAnnotate(args []string) error {
+	flags := flag.NewFlagSet("annotate", flag.ExitOnError)
+	state := flags.String("state", "", "file written by train -state to simplify URLs with")
+	snapshot := flags.String("snapshot", "", "file written by train -o to match URLs against the group patterns of, when there is no -state")
+	format := flags.String("format", "url", "format of the log lines, common for the Common Log Format or url for one URL per line")
+	out := flags.String("out", "", "file to write the rows to, defaults to stdout")
+	output := flags.String("output", "", "format of the rows, csv or json for JSON lines, defaults to the extension of -out or csv")
+	batch := flags.Int("batch", 0, "number of rows written at once, defaults to 1024")
+	retention := flags.String("retention", "", "comma separated retention rules written as action:glob, such as drop:/auth/**,redact:/legacy/**, applied to the group pattern of rows")
+	grouper := addGrouperFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: groupurl annotate [flags] [log files, gzip compressed or not, defaults to stdin]")
+		flags.PrintDefaults()
+	}
+	inputs, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if (*state == "") == (*snapshot == "") {
+		flags.Usage()
+		return errors.New("annotate requires one of -state or -snapshot")
+	}
+
+	parser, err := lineParser(*format)
+	if err != nil {
+		return err
+	}
+	g, err := grouper.grouper()
+	if err != nil {
+		return err
+	}
+	var source annotate.Source
+	if *state != "" {
+		if err := readState(g, *state); err != nil {
+			return err
+		}
+		source = g.Freeze()
+	} else {
+		s, err := readSnapshot(*snapshot)
+		if err != nil {
+			return err
+		}
+		if source, err = annotate.NewSnapshotSource(s, g); err != nil {
+			return err
+		}
+	}
+
+	rules, err := retentionRules(*retention)
+	if err != nil {
+		return err
+	}
+	rows, err := rowWriter(*output, *out)
+	if err != nil {
+		return err
+	}
+	var retained *annotate.RetentionWriter
+	if len(rules) > 0 {
+		if retained, err = annotate.NewRetentionWriter(rows, rules); err != nil {
+			return err
+		}
+		rows = retained
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+	var written int
+	for _, input := range inputs {
+		n, err := annotateFile(ctx, input, parser, source, rows, *batch)
+		written += n
+		if err != nil {
+			return err
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("failed to write rows: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "annotated %d URLs\n", written)
+	if retained != nil {
+		fmt.Fprintf(os.Stderr, "dropped %d rows by retention rules\n", retained.Dropped())
+	}
+	return nil
+}
+
+// retentionRules parses the comma separated rules of -retention.
+func retentionRules(s string) ([]annotate.RetentionRule, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var rules []annotate.RetentionRule
+	for _, r := range strings.Split(s, ",") {
+		rule, err := annotate.ParseRetentionRule(strings.TrimSpace(r))
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func annotateFile(ctx context.Context, path string, parser ingest.LineParser, source annotate.Source, w annotate.Writer, batch int) (int, error) {
+	if path == "-" {
+		return annotate.Logs(ctx, os.Stdin, parser, source, w, batch)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open logs: %w", err)
+	}
+	defer f.Close()
+	n, err := annotate.Logs(ctx, f, parser, source, w, batch)
+	if err != nil {
+		return n, fmt.Errorf("failed to annotate %s: %w", path, err)
+	}
+	return n, nil
+}
+
+// rowWriter creates the output file, or uses stdout if path is empty, and returns a writer of the -output format,
+// inferred from the extension of the file when empty. Closing the writer closes the file.
+func rowWriter(format, path string) (annotate.Writer, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	var newWriter func(io.Writer) annotate.Writer
+	switch format {
+	case "", "csv":
+		newWriter = func(w io.Writer) annotate.Writer { return annotate.NewCSVWriter(w) }
+	case "json", "jsonl", "ndjson":
+		newWriter = func(w io.Writer) annotate.Writer { return annotate.NewJSONWriter(w) }
+	case "parquet":
+		// Parquet support lives in its own module so that this one has no dependencies.
+		return nil, errors.New("write parquet by piping -output json to groupurl-parquet --out, from the annotate/parquet module")
+	default:
+		return nil, fmt.Errorf("unknown output format %q, want csv or json", format)
+	}
+
+	if path == "" {
+		return newWriter(os.Stdout), nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output: %w", err)
+	}
+	return fileWriter{Writer: newWriter(f), f: f}, nil
+}
+
+// fileWriter closes its file after its rows.
+type fileWriter struct {
+	annotate.Writer
+	f *os.File
+}
+
+func (w fileWriter) Close() error {
+	if err := w.Writer.Close(); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// parseInterspersed parses flags that may follow the positional arguments, as in `annotate in.log -out out.csv`,
+// and returns the positional arguments.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/trustleast/groupurl/ingest"
+)
+
+// _benchMemInterval is the number of URLs added between samples of the heap size.
+const _benchMemInterval = 10000
+
+func runBench(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	format := flags.String("format", "url", "format of the corpus lines, url for one URL per line or common for the Common Log Format")
+	cpuProfile := flags.String("cpuprofile", "", "file to write a CPU profile of the ingestion to")
+	memProfile := flags.String("memprofile", "", "file to write a heap profile to once the corpus is ingested")
+	grouper := addGrouperFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: groupurl bench [flags] <corpus>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("bench takes a single corpus file")
+	}
+
+	var parse ingest.LineParser
+	switch *format {
+	case "url":
+		parse = url.Parse
+	case "common":
+		parse = ingest.ParseCommonLog
+	default:
+		return fmt.Errorf("unknown format %q, want url or common", *format)
+	}
+	urls, skipped, err := readCorpus(flags.Arg(0), parse)
+	if err != nil {
+		return err
+	}
+
+	g, err := grouper.grouper()
+	if err != nil {
+		return err
+	}
+
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			return fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+	}
+
+	runtime.GC()
+	var before, mem runtime.MemStats
+	runtime.ReadMemStats(&before)
+	peak := before.HeapAlloc
+
+	start := time.Now()
+	for i, u := range urls {
+		g.Add(u)
+		if (i+1)%_benchMemInterval == 0 {
+			runtime.ReadMemStats(&mem)
+			if mem.HeapAlloc > peak {
+				peak = mem.HeapAlloc
+			}
+		}
+	}
+	elapsed := time.Since(start)
+
+	if *cpuProfile != "" {
+		pprof.StopCPUProfile()
+	}
+	runtime.ReadMemStats(&mem)
+	if mem.HeapAlloc > peak {
+		peak = mem.HeapAlloc
+	}
+	allocs := mem.Mallocs - before.Mallocs
+	allocated := mem.TotalAlloc - before.TotalAlloc
+
+	if *memProfile != "" {
+		if err := writeHeapProfile(*memProfile); err != nil {
+			return err
+		}
+	}
+
+	runtime.GC()
+	runtime.ReadMemStats(&mem)
+	// The corpus is part of the baseline, so it must outlive the measurement.
+	runtime.KeepAlive(urls)
+	size := g.TreeSize()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "urls\t%d\n", len(urls))
+	fmt.Fprintf(w, "skipped lines\t%d\n", skipped)
+	fmt.Fprintf(w, "duration\t%s\n", elapsed.Round(time.Microsecond))
+	fmt.Fprintf(w, "throughput\t%.0f urls/s\n", float64(len(urls))/elapsed.Seconds())
+	fmt.Fprintf(w, "allocations\t%d (%.1f/url)\n", allocs, perURL(allocs, len(urls)))
+	fmt.Fprintf(w, "allocated\t%s (%.0f B/url)\n", formatBytes(allocated), perURL(allocated, len(urls)))
+	fmt.Fprintf(w, "peak heap growth\t%s\n", formatBytes(peak-before.HeapAlloc))
+	fmt.Fprintf(w, "retained heap growth\t%s\n", formatBytes(growth(mem.HeapAlloc, before.HeapAlloc)))
+	fmt.Fprintf(w, "trees\t%d\n", size.Trees)
+	fmt.Fprintf(w, "nodes\t%d\n", size.Nodes)
+	fmt.Fprintf(w, "tokens\t%d\n", size.Tokens)
+	fmt.Fprintf(w, "groups\t%d\n", size.Groups)
+	return w.Flush()
+}
+
+// readCorpus parses every line of a corpus upfront, so that reading and parsing is not part of the measurements.
+func readCorpus(path string, parse ingest.LineParser) ([]*url.URL, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open corpus: %w", err)
+	}
+	defer f.Close()
+
+	var urls []*url.URL
+	var skipped int
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		u, err := parse(line)
+		if err != nil || u == nil {
+			skipped++
+			continue
+		}
+		urls = append(urls, u)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read corpus: %w", err)
+	}
+	return urls, skipped, nil
+}
+
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create heap profile: %w", err)
+	}
+	defer f.Close()
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return fmt.Errorf("failed to write heap profile: %w", err)
+	}
+	return f.Close()
+}
+
+func perURL(n uint64, urls int) float64 {
+	if urls == 0 {
+		return 0
+	}
+	return float64(n) / float64(urls)
+}
+
+// growth returns a-b, or 0 if a is smaller.
+func growth(a, b uint64) uint64 {
+	if a < b {
+		return 0
+	}
+	return a - b
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+)
+
+func runCohort(args []string) error {
+	flags := flag.NewFlagSet("cohort", flag.ExitOnError)
+	state := flags.String("state", "", "file written by train -state to select the groups from")
+	glob := flags.String("glob", "", "glob selecting the groups, such as /checkout/**")
+	grouper := addGrouperFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *state == "" || *glob == "" {
+		flags.Usage()
+		return errors.New("cohort requires -state and -glob")
+	}
+
+	g, err := grouper.grouper()
+	if err != nil {
+		return err
+	}
+	if err := readState(g, *state); err != nil {
+		return err
+	}
+	_, err = g.Cohort(*glob).WriteTo(os.Stdout)
+	return err
+}
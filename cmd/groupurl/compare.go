@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/trustleast/groupurl"
+)
+
+func runCompare(args []string) error {
+	flags := flag.NewFlagSet("compare", flag.ExitOnError)
+	format := flags.String("format", "text", "output format, one of text, markdown or json")
+	sortBy := flags.String("sort", "delta", "row order, one of delta, relative, after or pattern")
+	top := flags.Int("top", 0, "only show this many rows, 0 for all")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: groupurl compare [flags] <before snapshot> <after snapshot>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return errors.New("compare takes two snapshot files")
+	}
+
+	var order groupurl.ComparisonOrder
+	switch *sortBy {
+	case "delta":
+		order = groupurl.ByDelta
+	case "relative":
+		order = groupurl.ByRelative
+	case "after":
+		order = groupurl.ByAfter
+	case "pattern":
+		order = groupurl.ByPattern
+	default:
+		return fmt.Errorf("unknown sort %q, want delta, relative, after or pattern", *sortBy)
+	}
+
+	before, err := readSnapshot(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	after, err := readSnapshot(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	c := groupurl.Compare(before, after)
+	c.Sort(order)
+	if *top > 0 && len(c.Rows) > *top {
+		c.Rows = c.Rows[:*top]
+	}
+
+	switch *format {
+	case "text":
+		return c.WriteText(os.Stdout)
+	case "markdown":
+		return c.WriteMarkdown(os.Stdout)
+	case "json":
+		return json.NewEncoder(os.Stdout).Encode(c)
+	default:
+		return fmt.Errorf("unknown format %q, want text, markdown or json", *format)
+	}
+}
+
+func readSnapshot(path string) (groupurl.Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return groupurl.Snapshot{}, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer f.Close()
+	s, err := groupurl.ReadSnapshot(f)
+	if err != nil {
+		return groupurl.Snapshot{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
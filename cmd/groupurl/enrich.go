@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/trustleast/groupurl/enrich"
+	"github.com/trustleast/groupurl/serve"
+	"github.com/trustleast/groupurl/watch"
+)
+
+func runEnrich(args []string) error {
+	flags := flag.NewFlagSet("enrich", flag.ExitOnError)
+	addr := flags.String("addr", ":8081", "address to listen on")
+	socket := flags.String("socket", "", "Unix socket to listen on instead of -addr")
+	state := flags.String("state", "", "file written by train -state to group with, reloaded when it changes, defaults to learning from the events")
+	urlField := flags.String("url-field", "url", "field holding the URL of events, nested fields are separated by dots")
+	groupField := flags.String("group-field", "url_group", "field to write the group to, nested fields are separated by dots")
+	grouper := addGrouperFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var group func(*url.URL) string
+	if *state != "" {
+		options, err := grouper.options()
+		if err != nil {
+			return err
+		}
+		w, err := watch.New(context.Background(), watch.FileStore{Path: *state},
+			watch.WithGrouperOptions(options...),
+			watch.WithInterval(10*time.Second),
+			watch.WithErrorHook(func(err error) { log.Printf("failed to reload state: %v", err) }))
+		if err != nil {
+			return err
+		}
+		go w.Run(context.Background())
+		group = w.SimplifyPath
+	} else {
+		g, err := grouper.grouper()
+		if err != nil {
+			return err
+		}
+		s, err := serve.New(g)
+		if err != nil {
+			return fmt.Errorf("failed to build server: %w", err)
+		}
+		group = s.Record
+	}
+
+	h, err := enrich.New(group, enrich.WithURLField(*urlField), enrich.WithGroupField(*groupField))
+	if err != nil {
+		return err
+	}
+
+	var l net.Listener
+	if *socket != "" {
+		os.Remove(*socket)
+		l, err = net.Listen("unix", *socket)
+	} else {
+		l, err = net.Listen("tcp", *addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	srv := &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return srv.Serve(l)
+}
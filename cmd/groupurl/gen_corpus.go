@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/trustleast/groupurl/corpus"
+)
+
+func runGenCorpus(args []string) error {
+	flags := flag.NewFlagSet("gen-corpus", flag.ExitOnError)
+	profile := flags.String("profile", "ecommerce", fmt.Sprintf("kind of site to generate URLs for, one of %s", strings.Join(corpus.Profiles(), ", ")))
+	n := flags.Int("n", 10000, "number of URLs to generate")
+	seed := flags.Int64("seed", 1, "seed of the generator, the same seed produces the same corpus")
+	host := flags.String("host", "", "scheme and host prefixing every URL, defaults to https://example.com, / for paths only")
+	cardinality := flags.String("cardinality", "", "comma separated name=count overrides of the number of distinct values of parameters, such as product=100,user=10")
+	out := flags.String("o", "", "file to write the corpus to, defaults to stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	p, ok := corpus.LookupProfile(*profile)
+	if !ok {
+		return fmt.Errorf("unknown profile %q, want one of %s", *profile, strings.Join(corpus.Profiles(), ", "))
+	}
+	cardinalities, err := parseCardinalities(*cardinality)
+	if err != nil {
+		return err
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("failed to create corpus: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	if err := corpus.Generate(bw, p, *n, corpus.Options{Seed: *seed, Host: *host, Cardinalities: cardinalities}); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write corpus: %w", err)
+	}
+	if *out != "" {
+		return w.Close()
+	}
+	return nil
+}
+
+func parseCardinalities(s string) (map[string]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	cardinalities := make(map[string]int)
+	for _, pair := range strings.Split(s, ",") {
+		name, count, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid cardinality %q, want name=count", pair)
+		}
+		c, err := strconv.Atoi(count)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cardinality %q: %w", pair, err)
+		}
+		cardinalities[strings.TrimSpace(name)] = c
+	}
+	return cardinalities, nil
+}
@@ -0,0 +1,60 @@
+// Command groupurl groups URLs from the command line.
+//
+// Usage:
+//
+//	groupurl <command> [flags]
+//
+// Run a command with -h for its flags.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var _commands = []command{
+	{name: "serve", usage: "serve a Grouper over HTTP", run: runServe},
+	{name: "gen-corpus", usage: "generate a synthetic URL corpus", run: runGenCorpus},
+	{name: "train", usage: "learn groups from access logs", run: runTrain},
+	{name: "stream", usage: "simplify URLs from stdin as they arrive", run: runStream},
+	{name: "enrich", usage: "add URL groups to log events over HTTP", run: runEnrich},
+	{name: "compare", usage: "compare the groups of two snapshots", run: runCompare},
+	{name: "sitemap", usage: "write a sitemap of sample URLs from a saved state", run: runSitemap},
+	{name: "annotate", usage: "append groups to historical logs for backfills", run: runAnnotate},
+	{name: "cohort", usage: "select a family of routes from a saved state", run: runCohort},
+	{name: "bench", usage: "measure ingesting a corpus", run: runBench},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, c := range _commands {
+		if c.name == os.Args[1] {
+			if err := c.run(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: groupurl <command> [flags]")
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, c := range _commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.usage)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/trustleast/groupurl"
+)
+
+// grouperFlags holds the flags shared by every command that builds a Grouper.
+type grouperFlags struct {
+	classifiers string
+	preset      string
+	labels      string
+}
+
+func addGrouperFlags(flags *flag.FlagSet) *grouperFlags {
+	f := &grouperFlags{}
+	flags.StringVar(&f.classifiers, "classifiers", "", "JSON file with a list of {\"name\", \"config\"} classifier references, defaults to the default classifiers")
+	flags.StringVar(&f.preset, "preset", "", fmt.Sprintf("built-in classifier set, one of %s, ignored when -classifiers is set", strings.Join(groupurl.Presets(), ", ")))
+	flags.StringVar(&f.labels, "labels", "", "comma separated Label=word pairs renaming labels, such as Number=num,Words=slug")
+	return f
+}
+
+func (f *grouperFlags) options() ([]groupurl.Option, error) {
+	var options []groupurl.Option
+	if f.classifiers != "" {
+		classifiers, err := loadClassifiers(f.classifiers)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, groupurl.WithClassifiers(classifiers))
+	} else if f.preset != "" {
+		options = append(options, groupurl.WithPreset(f.preset))
+	}
+	if f.labels != "" {
+		vocabulary, err := groupurl.ParseLabelVocabulary(f.labels)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, groupurl.WithLabelVocabulary(vocabulary))
+	}
+	return options, nil
+}
+
+func (f *grouperFlags) grouper() (groupurl.Grouper, error) {
+	options, err := f.options()
+	if err != nil {
+		return groupurl.Grouper{}, err
+	}
+	g, err := groupurl.New(options...)
+	if err != nil {
+		return groupurl.Grouper{}, fmt.Errorf("failed to build grouper: %w", err)
+	}
+	return g, nil
+}
+
+func loadClassifiers(path string) ([]groupurl.PathTokenClassifier, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read classifiers: %w", err)
+	}
+	var configs []groupurl.ClassifierConfig
+	if err := json.Unmarshal(b, &configs); err != nil {
+		return nil, fmt.Errorf("failed to parse classifiers: %w", err)
+	}
+	return groupurl.ClassifiersFromConfig(configs)
+}
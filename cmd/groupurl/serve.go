@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/trustleast/groupurl/serve"
+)
+
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "address to listen on")
+	window := flags.Duration("window", 0, "size of the time buckets counts are kept in, 0 keeps totals only")
+	retention := flags.Int("retention", 60, "number of time buckets to keep when -window is set")
+	watch := flags.Duration("watch", 0, "how often to check the -classifiers file for changes, 0 only reloads on SIGHUP")
+	graphql := flags.Bool("graphql", false, "serve a GraphQL endpoint under /graphql")
+	grouper := addGrouperFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	g, err := grouper.grouper()
+	if err != nil {
+		return err
+	}
+
+	var options []serve.Option
+	if *window > 0 {
+		options = append(options, serve.WithWindow(*window, *retention))
+	}
+	if *graphql {
+		options = append(options, serve.WithGraphQL())
+	}
+	s, err := serve.New(g, options...)
+	if err != nil {
+		return fmt.Errorf("failed to build server: %w", err)
+	}
+
+	if grouper.classifiers != "" {
+		go reloadClassifiers(s, grouper.classifiers, *watch)
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return srv.ListenAndServe()
+}
+
+// reloadClassifiers reloads the classifiers file on SIGHUP, and whenever its modification time changes if interval is set.
+func reloadClassifiers(s *serve.Server, path string, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	modTime := fileModTime(path)
+	for {
+		select {
+		case <-hup:
+		case <-tick:
+			if m := fileModTime(path); m.Equal(modTime) {
+				continue
+			}
+		}
+
+		modTime = fileModTime(path)
+		classifiers, err := loadClassifiers(path)
+		if err != nil {
+			log.Println("Failed to reload classifiers:", err)
+			continue
+		}
+		s.Reload(classifiers)
+		log.Println("Reloaded classifiers from", path)
+	}
+}
+
+func fileModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
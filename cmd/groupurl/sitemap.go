@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/trustleast/groupurl"
+)
+
+func runSitemap(args []string) error {
+	flags := flag.NewFlagSet("sitemap", flag.ExitOnError)
+	state := flags.String("state", "", "file written by train -state to take the sample URLs from")
+	base := flags.String("base", "", "scheme and host of the site, such as https://example.com, required for xml")
+	format := flags.String("format", "xml", "output format, xml for a sitemap.xml or list for one URL per line")
+	perGroup := flags.Int("per-group", 0, "maximum number of URLs per group, 0 for every sample")
+	minCount := flags.Int("min-count", 0, "omit groups with fewer URLs")
+	grouper := addGrouperFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *state == "" {
+		flags.Usage()
+		return errors.New("sitemap requires -state")
+	}
+
+	opts := groupurl.SitemapOptions{PerGroup: *perGroup, MinCount: *minCount}
+	if *base != "" {
+		u, err := url.Parse(*base)
+		if err != nil {
+			return fmt.Errorf("invalid base URL: %w", err)
+		}
+		opts.BaseURL = u
+	}
+
+	g, err := grouper.grouper()
+	if err != nil {
+		return err
+	}
+	if err := readState(g, *state); err != nil {
+		return err
+	}
+	switch *format {
+	case "xml":
+		return g.ExportSitemap(os.Stdout, opts)
+	case "list":
+		return g.ExportURLList(os.Stdout, opts)
+	default:
+		return fmt.Errorf("unknown format %q, want xml or list", *format)
+	}
+}
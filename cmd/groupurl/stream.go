@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/trustleast/groupurl"
+)
+
+func runStream(args []string) error {
+	flags := flag.NewFlagSet("stream", flag.ExitOnError)
+	state := flags.String("state", "", "file written by train -state to simplify with, without learning from the input, defaults to learning as URLs arrive")
+	grouper := addGrouperFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	g, err := grouper.grouper()
+	if err != nil {
+		return err
+	}
+	simplify := func(u *url.URL) string {
+		g.Add(u)
+		return g.SimplifyPath(u)
+	}
+	if *state != "" {
+		if err := readState(g, *state); err != nil {
+			return err
+		}
+		frozen := g.Freeze()
+		simplify = frozen.SimplifyPath
+	}
+	return streamLines(os.Stdin, os.Stdout, simplify)
+}
+
+// streamLines writes every line of r to w followed by a tab and its simplified path, or nothing if it is not a URL.
+// Output is flushed whenever no more input is buffered, so that results are written as soon as the input pauses.
+func streamLines(r io.Reader, w io.Writer, simplify func(*url.URL) string) error {
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+	for {
+		line, err := br.ReadString('\n')
+		if line = strings.TrimRight(line, "\r\n"); line != "" {
+			var simplified string
+			if u, err := url.Parse(line); err == nil {
+				simplified = simplify(u)
+			}
+			fmt.Fprintf(bw, "%s\t%s\n", line, simplified)
+		}
+		if err == io.EOF {
+			return bw.Flush()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		if br.Buffered() == 0 {
+			if err := bw.Flush(); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+		}
+	}
+}
+
+func readState(g groupurl.Grouper, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open state: %w", err)
+	}
+	defer f.Close()
+	return g.ReadState(bufio.NewReader(f))
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/trustleast/groupurl"
+	"github.com/trustleast/groupurl/ingest"
+)
+
+// _progressBarWidth is the number of cells of the progress bar.
+const _progressBarWidth = 30
+
+func runTrain(args []string) error {
+	flags := flag.NewFlagSet("train", flag.ExitOnError)
+	format := flags.String("format", "common", "format of the log lines, common for the Common Log Format or url for one URL per line")
+	out := flags.String("o", "", "file to write a JSON snapshot of the groups to, defaults to a text report on stdout")
+	state := flags.String("state", "", "file to write the learned state to, for use with stream -state")
+	checkpoint := flags.String("checkpoint", "", "file to periodically save progress to, an existing checkpoint is resumed from and removed once training completes")
+	checkpointInterval := flags.Duration("checkpoint-interval", 5*time.Minute, "how often to save a checkpoint when -checkpoint is set")
+	progress := flags.Bool("progress", isTerminal(os.Stderr), "show a progress bar on stderr, defaults to whether stderr is a terminal")
+	grouper := addGrouperFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: groupurl train [flags] <file or directory>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("train takes a single file or directory of logs")
+	}
+
+	loader, err := newLoader(flags.Arg(0), *format)
+	if err != nil {
+		return err
+	}
+	if *progress {
+		loader.Progress = progressBar(os.Stderr)
+	}
+
+	g, err := grouper.grouper()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	var stats ingest.Stats
+	if *checkpoint == "" {
+		stats, err = loader.Load(ctx, g)
+	} else {
+		stats, err = resumeLoad(ctx, loader, g, *checkpoint, *checkpointInterval)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "read %d lines of %d objects, added %d and skipped %d\n", stats.Lines, stats.Objects, stats.Added, stats.Skipped)
+
+	if *state != "" {
+		if err := writeState(g, *state); err != nil {
+			return err
+		}
+	}
+	if *out == "" {
+		return g.ExportText(os.Stdout, groupurl.TextOptions{Counts: true})
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer f.Close()
+	if _, err := g.Snapshot().WriteTo(f); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return f.Close()
+}
+
+func writeState(g groupurl.Grouper, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create state: %w", err)
+	}
+	defer f.Close()
+	if err := g.WriteState(f); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	return f.Close()
+}
+
+// resumeLoad loads with checkpoints, resuming from the checkpoint file if it exists. The file is removed once the
+// load completes.
+func resumeLoad(ctx context.Context, loader ingest.Loader, g groupurl.Grouper, path string, interval time.Duration) (ingest.Stats, error) {
+	loader.Checkpoint = ingest.CheckpointFile(path)
+	loader.CheckpointInterval = interval
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		stats, err := loader.Load(ctx, g)
+		if err != nil {
+			return stats, err
+		}
+		return stats, removeCheckpoint(path)
+	}
+	if err != nil {
+		return ingest.Stats{}, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	checkpoint, err := ingest.ReadCheckpoint(f)
+	f.Close()
+	if err != nil {
+		return ingest.Stats{}, err
+	}
+	fmt.Fprintf(os.Stderr, "resuming from line %d of %s\n", checkpoint.Line, checkpoint.Key)
+	stats, err := loader.ResumeFrom(ctx, checkpoint, g)
+	if err != nil {
+		return stats, err
+	}
+	return stats, removeCheckpoint(path)
+}
+
+func removeCheckpoint(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
+
+// newLoader returns a Loader reading the file at path, or every file under it if it is a directory.
+func newLoader(path, format string) (ingest.Loader, error) {
+	parser, err := lineParser(format)
+	if err != nil {
+		return ingest.Loader{}, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return ingest.Loader{}, fmt.Errorf("failed to open logs: %w", err)
+	}
+	loader := ingest.Loader{Parser: parser}
+	if format == "common" {
+		loader.RequestParser = ingest.ParseCommonLogRequest
+	}
+	if info.IsDir() {
+		loader.Bucket = ingest.FSBucket{FS: os.DirFS(path)}
+	} else {
+		loader.Bucket = ingest.FSBucket{FS: os.DirFS(filepath.Dir(path))}
+		loader.Prefix = filepath.Base(path)
+	}
+	return loader, nil
+}
+
+// lineParser returns the parser of a -format flag.
+func lineParser(format string) (ingest.LineParser, error) {
+	switch format {
+	case "common":
+		return ingest.ParseCommonLog, nil
+	case "url":
+		return url.Parse, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, want common or url", format)
+	}
+}
+
+// progressBar returns a ProgressFunc drawing a progress bar on a single line of w.
+func progressBar(w io.Writer) ingest.ProgressFunc {
+	return func(p ingest.Progress) {
+		var line string
+		if p.TotalBytes > 0 {
+			filled := int(p.Fraction() * _progressBarWidth)
+			line = fmt.Sprintf("[%s%s] %3.0f%% ", strings.Repeat("=", filled), strings.Repeat(" ", _progressBarWidth-filled), p.Fraction()*100)
+		}
+		line += fmt.Sprintf("%d lines, %.0f lines/s", p.Lines, p.Rate)
+		if p.ETA > 0 && !p.Done {
+			line += fmt.Sprintf(", ETA %s", p.ETA.Round(time.Second))
+		}
+		// Clear what is left of a longer previous line.
+		fmt.Fprintf(w, "\r%s\033[K", line)
+		if p.Done {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,90 @@
+package groupurl
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path"
+)
+
+// Cohort is a family of routes selected by a glob, such as every checkout route, along with their sample URLs and
+// counts. It serves as the definition of the targets of synthetic monitoring or canary analysis.
+type Cohort struct {
+	Glob string `json:"glob"`
+	// Count is the number of URLs of the groups of the cohort.
+	Count  int           `json:"count"`
+	Groups []CohortGroup `json:"groups"`
+}
+
+// CohortGroup is a group of a Cohort.
+type CohortGroup struct {
+	Pattern string `json:"pattern"`
+	Count   int    `json:"count"`
+	// Samples holds the sample URLs of the group whose simplified path matches the glob of the cohort.
+	Samples []string `json:"samples,omitempty"`
+}
+
+// Cohort selects the groups matching a glob, such as `/checkout/**`. Globs are matched segment by segment as in
+// RuleSpec, and a segment of a group matches when either its label or one of its significant tokens does, so that
+// `/checkout/**` selects the groups whose first segment keeps the `checkout` token, and `/Words/Number` the group of
+// that pattern. A prefix such as `/checkout` is selected with the glob `/checkout/**`.
+//
+// Since groups share their nodes between the tokens they keep, counts are those of whole groups, which may also hold
+// URLs of other route families. Groups are ordered as in Snapshot.
+func (g Grouper) Cohort(glob string) Cohort {
+	c := Cohort{Glob: glob}
+	globSegments := splitSegments(glob)
+	for _, grp := range g.groups() {
+		if !matchGroupSegments(globSegments, grp.segments) {
+			continue
+		}
+		cg := CohortGroup{Pattern: grp.pattern, Count: grp.count}
+		for _, sample := range grp.samples {
+			if matchGlob(glob, g.tree.decode(g.SimplifyPath(&url.URL{Path: sample}))) {
+				cg.Samples = append(cg.Samples, sample)
+			}
+		}
+		c.Groups = append(c.Groups, cg)
+		c.Count += grp.count
+	}
+	return c
+}
+
+// WriteTo writes the Cohort as JSON.
+func (c Cohort) WriteTo(w io.Writer) (int64, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(b, '\n'))
+	return int64(n), err
+}
+
+// matchGroupSegments matches a glob against the segments of a group like matchSegments, with each segment matched by
+// its label or any of its significant tokens.
+func matchGroupSegments(glob []string, segments []groupSegment) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchGroupSegments(glob[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 || !matchGroupSegment(glob[0], segments[0]) {
+			return false
+		}
+		glob, segments = glob[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
+
+func matchGroupSegment(glob string, s groupSegment) bool {
+	for _, candidate := range append([]string{s.label.Value}, s.tokens...) {
+		if ok, err := path.Match(glob, candidate); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCohort(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/checkout/%d", i)})
+		g.Add(&url.URL{Path: fmt.Sprintf("/checkout/%d/confirm", i)})
+		g.Add(&url.URL{Path: fmt.Sprintf("/products/%d/reviews/%d", i, i)})
+	}
+
+	c := g.Cohort("/checkout/**")
+	if c.Count != 100 || len(c.Groups) != 2 {
+		t.Fatalf("unexpected cohort %+v", c)
+	}
+	for _, grp := range c.Groups {
+		if len(grp.Samples) == 0 || !strings.HasPrefix(grp.Samples[0], "/checkout/") {
+			t.Errorf("unexpected samples of %s: %v", grp.Pattern, grp.Samples)
+		}
+	}
+
+	if c := g.Cohort("/Words/Number"); len(c.Groups) != 1 || c.Groups[0].Pattern != "/Words/Number" || c.Count != 50 {
+		t.Errorf("unexpected cohort by pattern %+v", c)
+	}
+	if c := g.Cohort("/**/reviews/*"); len(c.Groups) != 1 || c.Count != 50 {
+		t.Errorf("unexpected cohort of reviews %+v", c)
+	}
+	if c := g.Cohort("/cart/**"); len(c.Groups) != 0 || c.Count != 0 {
+		t.Errorf("expected an empty cohort, got %+v", c)
+	}
+
+	var sb strings.Builder
+	if _, err := c.WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sb.String(), `{"glob":"/checkout/**","count":100,"groups":[`) {
+		t.Errorf("unexpected JSON %s", sb.String())
+	}
+}
@@ -0,0 +1,40 @@
+package groupurl
+
+import "fmt"
+
+// WithColdStart simplifies the segments the Grouper has seen in fewer than threshold URLs by heuristics alone, so
+// that simplified paths are sensible from the very first request rather than echoing raw identifiers. The heuristics
+// keep the tokens of Important labels, which are usually route names, and replace the others with their label.
+// Each node switches to what the Grouper learned once it has seen threshold URLs, the busy prefixes of paths first,
+// so that output blends towards the learned behavior as traffic accumulates. Segments of paths the Grouper has not
+// seen at all are simplified by the heuristics too, whatever the Fallback.
+func WithColdStart(threshold int) Option {
+	return func(g *Grouper) error {
+		if threshold <= 0 {
+			return fmt.Errorf("cold start threshold must be positive, got %d", threshold)
+		}
+		g.tree.coldStart = threshold
+		return nil
+	}
+}
+
+// cold reports whether a node has seen too few URLs to be simplified by what it learned.
+func (t urlTree) cold(n *urlNode) bool {
+	return n.tokenCounts.total < t.coldStart
+}
+
+// heuristic returns the output for a segment at a position simplified by heuristics alone.
+func (t urlTree) heuristic(token pathToken, depth int) string {
+	if t.keepsHeuristic(token, depth) {
+		return t.encodeToken(token.token)
+	}
+	return t.encodeLabel(token.label.Value)
+}
+
+// keepsHeuristic reports whether the heuristics keep a segment as is.
+func (t urlTree) keepsHeuristic(token pathToken, depth int) bool {
+	if keep, ok := t.override(token.token); ok {
+		return keep
+	}
+	return t.atPosition(token.label.LabelFields, depth).Important && t.privacyK == 0
+}
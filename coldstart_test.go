@@ -0,0 +1,61 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestColdStart(t *testing.T) {
+	g, err := New(WithColdStart(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	simplify := func(p string) string {
+		u, err := url.Parse(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return g.SimplifyPath(u)
+	}
+	add := func(p string) {
+		u, err := url.Parse(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+
+	if got := simplify("/users/123/profile"); got != "/users/Number/profile" {
+		t.Fatalf("expected heuristics before the first URL, got %s", got)
+	}
+
+	words := []string{"apple", "banana", "cherry", "grape", "lemon", "mango", "olive", "peach", "plum", "melon"}
+	for _, w := range words[:3] {
+		add("/users/" + w)
+	}
+	if got := simplify("/users/apple"); got != "/users/apple" {
+		t.Fatalf("expected heuristics to keep words below the threshold, got %s", got)
+	}
+	u, err := url.Parse("/users/apple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reason := g.Explain(u).Segments[1].Reason; reason != "node was seen fewer than 5 times" {
+		t.Fatalf("expected the cold start as the reason, got %s", reason)
+	}
+
+	for i := 0; i < 100; i++ {
+		add(fmt.Sprintf("/users/%s%s", words[i%10], words[(i/10)%10]))
+	}
+	if got := simplify("/users/apple"); got != "/users/Words" {
+		t.Fatalf("expected the learned behavior past the threshold, got %s", got)
+	}
+
+	if _, err := g.DecisionTable(); err == nil {
+		t.Fatal("expected error for cold start heuristics")
+	}
+	if _, err := New(WithColdStart(0)); err == nil {
+		t.Fatal("expected error for a non-positive threshold")
+	}
+}
@@ -0,0 +1,170 @@
+package groupurl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+)
+
+// Comparison is a table of the groups of two snapshots taken over different periods, as used in reviews of how the
+// structure of traffic changes from one week to the next.
+type Comparison struct {
+	// Before and After are the total URL counts of each period.
+	Before int             `json:"before"`
+	After  int             `json:"after"`
+	Rows   []ComparisonRow `json:"rows"`
+}
+
+// ComparisonRow is a group's counts in each period of a Comparison.
+type ComparisonRow struct {
+	Pattern string `json:"pattern"`
+	// RenamedFrom is the pattern of the group in the earlier period, when the later snapshot's lineage renamed it.
+	RenamedFrom string `json:"renamed_from,omitempty"`
+	Before      int    `json:"before"`
+	After       int    `json:"after"`
+	// Delta is the change of the count, After - Before.
+	Delta int `json:"delta"`
+	// Relative is Delta as a fraction of Before. It is 0 for new groups.
+	Relative float64 `json:"relative"`
+	// New is set for groups only present in the later period.
+	New bool `json:"new,omitempty"`
+	// Vanished is set for groups only present in the earlier period.
+	Vanished bool `json:"vanished,omitempty"`
+}
+
+// ComparisonOrder is an order for the rows of a Comparison.
+type ComparisonOrder int
+
+const (
+	// ByDelta orders rows by decreasing absolute Delta.
+	ByDelta ComparisonOrder = iota
+	// ByRelative orders rows by decreasing absolute Relative change, new groups first.
+	ByRelative
+	// ByAfter orders rows by decreasing count in the later period.
+	ByAfter
+	// ByPattern orders rows by pattern.
+	ByPattern
+)
+
+// Compare lines up the groups of two snapshots, ordered ByDelta. Groups of the earlier snapshot that the later
+// snapshot's lineage renamed are compared with the group they were renamed to.
+func Compare(before, after Snapshot) Comparison {
+	afterCounts := snapshotCounts(after)
+	rows := make(map[string]*ComparisonRow, len(afterCounts))
+	var c Comparison
+	for pattern, count := range afterCounts {
+		rows[pattern] = &ComparisonRow{Pattern: pattern, After: count, New: true}
+		c.After += count
+	}
+
+	for pattern, count := range snapshotCounts(before) {
+		c.Before += count
+		var renamedFrom string
+		if _, ok := afterCounts[pattern]; !ok {
+			if to, _, ok := applyLineage(pattern, after.Lineage); ok {
+				if _, exists := afterCounts[to]; exists {
+					renamedFrom, pattern = pattern, to
+				}
+			}
+		}
+		row, ok := rows[pattern]
+		if !ok {
+			row = &ComparisonRow{Pattern: pattern, Vanished: true}
+			rows[pattern] = row
+		}
+		row.Before += count
+		row.New = false
+		if renamedFrom != "" {
+			row.RenamedFrom = renamedFrom
+		}
+	}
+
+	c.Rows = make([]ComparisonRow, 0, len(rows))
+	for _, row := range rows {
+		row.Delta = row.After - row.Before
+		if row.Before > 0 {
+			row.Relative = float64(row.Delta) / float64(row.Before)
+		}
+		c.Rows = append(c.Rows, *row)
+	}
+	c.Sort(ByDelta)
+	return c
+}
+
+// Sort orders the rows of the Comparison. Ties are ordered by pattern.
+func (c Comparison) Sort(order ComparisonOrder) {
+	sort.Slice(c.Rows, func(i, j int) bool {
+		a, b := c.Rows[i], c.Rows[j]
+		switch order {
+		case ByDelta:
+			if da, db := abs(a.Delta), abs(b.Delta); da != db {
+				return da > db
+			}
+		case ByRelative:
+			if a.New != b.New {
+				return a.New
+			}
+			if ra, rb := math.Abs(a.Relative), math.Abs(b.Relative); ra != rb {
+				return ra > rb
+			}
+		case ByAfter:
+			if a.After != b.After {
+				return a.After > b.After
+			}
+		}
+		return a.Pattern < b.Pattern
+	})
+}
+
+// WriteText writes the Comparison as an aligned plain text table.
+func (c Comparison) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Pattern\tBefore\tAfter\tDelta\tChange\t\t")
+	for _, row := range c.Rows {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%+d\t%s\t%s\t\n", row.Pattern, row.Before, row.After, row.Delta, row.change(), row.flag())
+	}
+	fmt.Fprintf(tw, "Total\t%d\t%d\t%+d\t\t\t\n", c.Before, c.After, c.After-c.Before)
+	return tw.Flush()
+}
+
+// WriteMarkdown writes the Comparison as a Markdown table.
+func (c Comparison) WriteMarkdown(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "| Pattern | Before | After | Delta | Change | |")
+	fmt.Fprintln(bw, "| --- | ---: | ---: | ---: | ---: | --- |")
+	for _, row := range c.Rows {
+		fmt.Fprintf(bw, "| %s | %d | %d | %+d | %s | %s |\n",
+			markdownCode(row.Pattern), row.Before, row.After, row.Delta, row.change(), row.flag())
+	}
+	fmt.Fprintf(bw, "| Total | %d | %d | %+d | | |\n", c.Before, c.After, c.After-c.Before)
+	return bw.Flush()
+}
+
+func (r ComparisonRow) change() string {
+	if r.New {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", 100*r.Relative)
+}
+
+func (r ComparisonRow) flag() string {
+	switch {
+	case r.New:
+		return "new"
+	case r.Vanished:
+		return "vanished"
+	case r.RenamedFrom != "":
+		return "renamed from " + r.RenamedFrom
+	}
+	return ""
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,72 @@
+package groupurl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	before := Snapshot{Groups: []SnapshotGroup{
+		{Pattern: "/users/Number", Count: 100},
+		{Pattern: "/posts/Number", Count: 50},
+		{Pattern: "/old", Count: 10},
+		{Pattern: "/blog/Words", Count: 20},
+	}}
+	after := Snapshot{
+		Groups: []SnapshotGroup{
+			{Pattern: "/users/Number", Count: 150},
+			{Pattern: "/posts/Number", Count: 40},
+			{Pattern: "/new", Count: 5},
+			{Pattern: "/articles/Words", Count: 30},
+		},
+		Lineage: []Lineage{{From: "/blog", To: "/articles", Reason: "merged"}},
+	}
+
+	c := Compare(before, after)
+	if c.Before != 180 || c.After != 225 {
+		t.Errorf("got totals %d and %d, want 180 and 225", c.Before, c.After)
+	}
+	want := []ComparisonRow{
+		{Pattern: "/users/Number", Before: 100, After: 150, Delta: 50, Relative: 0.5},
+		{Pattern: "/articles/Words", RenamedFrom: "/blog/Words", Before: 20, After: 30, Delta: 10, Relative: 0.5},
+		{Pattern: "/old", Before: 10, Delta: -10, Relative: -1, Vanished: true},
+		{Pattern: "/posts/Number", Before: 50, After: 40, Delta: -10, Relative: -0.2},
+		{Pattern: "/new", After: 5, Delta: 5, New: true},
+	}
+	if len(c.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(c.Rows), len(want), c.Rows)
+	}
+	for i := range want {
+		if c.Rows[i] != want[i] {
+			t.Errorf("row %d: got %+v, want %+v", i, c.Rows[i], want[i])
+		}
+	}
+
+	c.Sort(ByRelative)
+	if c.Rows[0].Pattern != "/new" || c.Rows[1].Pattern != "/old" {
+		t.Errorf("unexpected order by relative change: %+v", c.Rows)
+	}
+	c.Sort(ByPattern)
+	if c.Rows[0].Pattern != "/articles/Words" {
+		t.Errorf("unexpected order by pattern: %+v", c.Rows)
+	}
+
+	var text bytes.Buffer
+	if err := c.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"+50.0%", "vanished", "renamed from /blog/Words", "+45"} {
+		if !strings.Contains(text.String(), s) {
+			t.Errorf("text table is missing %q:\n%s", s, text.String())
+		}
+	}
+
+	var md bytes.Buffer
+	if err := c.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md.String(), "| `/new` | 0 | 5 | +5 | - | new |") {
+		t.Errorf("unexpected markdown table:\n%s", md.String())
+	}
+}
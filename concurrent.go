@@ -0,0 +1,146 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ConcurrentGrouper wraps a Grouper for concurrent use, such as from an HTTP middleware handling many requests at
+// once. Its methods serialize access to the Grouper, which shares its trees, counters and caches between every
+// path and so cannot be locked in shards. With WithSnapshotReads, SimplifyPath and Labels instead read a frozen copy
+// of the Grouper without taking any lock, so that lookups never wait for Add.
+type ConcurrentGrouper struct {
+	mu sync.Mutex
+	g  Grouper
+	// refreshEvery is the number of URLs added between snapshots, 0 if reads go through the lock.
+	refreshEvery int
+	added        int
+	snapshot     atomic.Pointer[FrozenGrouper]
+}
+
+// ConcurrentOption configures a ConcurrentGrouper.
+type ConcurrentOption func(*ConcurrentGrouper) error
+
+// WithSnapshotReads makes SimplifyPath and Labels read a frozen copy of the Grouper, taken again every refreshEvery
+// added URLs, rather than the Grouper itself. Reads are then lock free but lag behind by up to refreshEvery URLs, and
+// every snapshot copies the trees, so refreshEvery trades the freshness of groups against the cost of Add.
+func WithSnapshotReads(refreshEvery int) ConcurrentOption {
+	return func(c *ConcurrentGrouper) error {
+		if refreshEvery <= 0 {
+			return fmt.Errorf("snapshot refresh interval must be positive, got %d", refreshEvery)
+		}
+		c.refreshEvery = refreshEvery
+		return nil
+	}
+}
+
+// NewConcurrent wraps a Grouper for concurrent use. The Grouper should not be used directly afterwards, use the
+// methods of the ConcurrentGrouper, or Do, instead.
+func NewConcurrent(g Grouper, options ...ConcurrentOption) (*ConcurrentGrouper, error) {
+	c := &ConcurrentGrouper{g: g}
+	for _, option := range options {
+		if err := option(c); err != nil {
+			return nil, err
+		}
+	}
+	if c.refreshEvery > 0 {
+		c.refresh()
+	}
+	return c, nil
+}
+
+// Add adds a url to the Grouper.
+func (c *ConcurrentGrouper) Add(u *url.URL) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.g.Add(u)
+	c.added++
+	c.maybeRefresh()
+}
+
+// AddRequest adds a url to the Grouper along with the method of its request.
+func (c *ConcurrentGrouper) AddRequest(method string, u *url.URL) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.g.AddRequest(method, u)
+	c.added++
+	c.maybeRefresh()
+}
+
+// Observe adds a url to the Grouper along with the status and latency of its response.
+func (c *ConcurrentGrouper) Observe(u *url.URL, status int, latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.g.Observe(u, status, latency)
+	c.added++
+	c.maybeRefresh()
+}
+
+// SimplifyPath simplifies a URL, from the latest snapshot WithSnapshotReads.
+func (c *ConcurrentGrouper) SimplifyPath(u *url.URL) string {
+	if f := c.snapshot.Load(); f != nil {
+		return f.SimplifyPath(u)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.g.SimplifyPath(u)
+}
+
+// Labels returns the label each segment of a URL is grouped under, from the latest snapshot WithSnapshotReads.
+func (c *ConcurrentGrouper) Labels(u *url.URL) []string {
+	if f := c.snapshot.Load(); f != nil {
+		return f.Labels(u)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.g.Labels(u)
+}
+
+// Freeze returns a read-only copy of the current state of the Grouper.
+func (c *ConcurrentGrouper) Freeze() FrozenGrouper {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.g.Freeze()
+}
+
+// Refresh takes a new snapshot for SimplifyPath and Labels right away, rather than after the next refreshEvery added
+// URLs. It does nothing without WithSnapshotReads.
+func (c *ConcurrentGrouper) Refresh() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.refreshEvery > 0 {
+		c.refresh()
+	}
+}
+
+// Do calls f with the Grouper while holding the lock, for the methods the ConcurrentGrouper does not wrap. f must not
+// keep the Grouper, nor anything sharing its state, after it returns. Changes made by f are visible to snapshot reads
+// after the next refresh.
+func (c *ConcurrentGrouper) Do(f func(g Grouper)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f(c.g)
+}
+
+// String pretty prints the Grouper.
+func (c *ConcurrentGrouper) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.g.String()
+}
+
+func (c *ConcurrentGrouper) maybeRefresh() {
+	if c.refreshEvery > 0 && c.added >= c.refreshEvery {
+		c.refresh()
+	}
+}
+
+// refresh stores a new snapshot. It must be called with the lock held.
+func (c *ConcurrentGrouper) refresh() {
+	f := c.g.Freeze()
+	c.snapshot.Store(&f)
+	c.added = 0
+}
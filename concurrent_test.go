@@ -0,0 +1,85 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentGrouper(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		options []ConcurrentOption
+	}{
+		{name: "locked"},
+		{name: "snapshots", options: []ConcurrentOption{WithSnapshotReads(100)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, err := NewConcurrent(g, tc.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var wg sync.WaitGroup
+			for worker := 0; worker < 8; worker++ {
+				wg.Add(1)
+				go func(worker int) {
+					defer wg.Done()
+					for i := 0; i < 200; i++ {
+						u := &url.URL{Path: fmt.Sprintf("/users/%d/posts/%d", worker, i)}
+						switch i % 3 {
+						case 0:
+							c.Add(u)
+						case 1:
+							c.AddRequest("GET", u)
+						default:
+							c.Observe(u, 200, 0)
+						}
+						c.SimplifyPath(u)
+						c.Labels(u)
+					}
+				}(worker)
+			}
+			wg.Wait()
+
+			c.Refresh()
+			u := &url.URL{Path: "/users/42/posts/7"}
+			if got := c.SimplifyPath(u); got != "/users/Number/posts/Number" {
+				t.Fatalf("unexpected simplified path %s", got)
+			}
+			c.Do(func(g Grouper) {
+				if total := g.DepthStats().Total; total != 1600 {
+					t.Fatalf("expected every URL to be added, got %d", total)
+				}
+			})
+		})
+	}
+
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewConcurrent(g, WithSnapshotReads(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 9; i++ {
+		c.Add(&url.URL{Path: fmt.Sprintf("/items/%d", i)})
+	}
+	if got := c.SimplifyPath(&url.URL{Path: "/items/1"}); got != "/items/1" {
+		t.Fatalf("expected reads to lag behind until the next snapshot, got %s", got)
+	}
+	c.Add(&url.URL{Path: "/items/9"})
+	if got := c.SimplifyPath(&url.URL{Path: "/items/1"}); got != "/items/Number" {
+		t.Fatalf("expected a snapshot after 10 URLs, got %s", got)
+	}
+
+	if _, err := NewConcurrent(g, WithSnapshotReads(0)); err == nil {
+		t.Fatal("expected error for a zero refresh interval")
+	}
+}
@@ -0,0 +1,79 @@
+package groupurl
+
+import (
+	"mime"
+	"strings"
+)
+
+const (
+	// ContentTypeNone counts URLs whose final segment has no extension, which are usually pages or API resources.
+	ContentTypeNone = "none"
+	// ContentTypeUnknown counts URLs whose final segment has an extension without a known media type.
+	ContentTypeUnknown = "unknown"
+	// ContentTypeOther counts URLs of a group once it has seen more than a handful of content types.
+	ContentTypeOther = "other"
+
+	_maxContentTypes = 16
+)
+
+// ContentTypes returns the content types inferred from the extensions of the final segments of the URLs in the group
+// with the given pattern, along with the number of URLs of each. Crawl planners can use it to skip groups that serve
+// no HTML. Besides media types such as "text/html" or "image/png", the keys may be ContentTypeNone,
+// ContentTypeUnknown, and ContentTypeOther. It returns nil if there is no such group.
+func (g Grouper) ContentTypes(pattern string) map[string]int {
+	for _, grp := range g.groups() {
+		if grp.pattern == pattern {
+			return copyCounts(grp.contentTypes)
+		}
+	}
+	return nil
+}
+
+// contentType infers the media type of a path from the extension of its final segment, without any parameters.
+func contentType(path string) string {
+	segment := path[strings.LastIndexByte(path, '/')+1:]
+	dot := strings.LastIndexByte(segment, '.')
+	if dot < 0 || dot == len(segment)-1 {
+		return ContentTypeNone
+	}
+	t := mime.TypeByExtension(strings.ToLower(segment[dot:]))
+	if t == "" {
+		return ContentTypeUnknown
+	}
+	if mediaType, _, err := mime.ParseMediaType(t); err == nil {
+		return mediaType
+	}
+	return t
+}
+
+// addContentType counts the content type of a path that terminates at a node. The number of distinct types per node
+// is bounded, with the rest counted as ContentTypeOther.
+func addContentType(types map[string]int, path string, weight int) map[string]int {
+	if types == nil {
+		types = make(map[string]int)
+	}
+	t := contentType(path)
+	if _, ok := types[t]; !ok && len(types) >= _maxContentTypes {
+		t = ContentTypeOther
+	}
+	types[t] += weight
+	return types
+}
+
+func copyCounts(counts map[string]int) map[string]int {
+	if counts == nil {
+		return nil
+	}
+	return mergeCounts(make(map[string]int, len(counts)), counts)
+}
+
+// mergeCounts adds the counts of src to dst, creating dst if needed.
+func mergeCounts(dst, src map[string]int) map[string]int {
+	if dst == nil && len(src) > 0 {
+		dst = make(map[string]int, len(src))
+	}
+	for k, count := range src {
+		dst[k] += count
+	}
+	return dst
+}
@@ -0,0 +1,52 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestContentTypes(t *testing.T) {
+	g, err := New(WithClassifiers(append(AssetClassifiers(), DefaultClassifiers()...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		for _, p := range []string{"/img/photo-%d.PNG", "/docs/page-%d.html", "/docs/page-%d", "/docs/page-%d.xyz"} {
+			u, err := url.Parse(fmt.Sprintf(p, i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.Add(u)
+		}
+	}
+
+	if got, expected := g.ContentTypes("/Words/Image"), map[string]int{"image/png": 100}; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	expected := map[string]int{"text/html": 100, ContentTypeNone: 100, ContentTypeUnknown: 100}
+	if got := g.ContentTypes("/Words/AlphaNumeric"); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	if got := g.ContentTypes("/missing"); got != nil {
+		t.Fatalf("expected no content types for a missing group, got %v", got)
+	}
+
+	for _, grp := range g.Snapshot().Groups {
+		if grp.Pattern == "/Words/AlphaNumeric" && !reflect.DeepEqual(grp.ContentTypes, expected) {
+			t.Fatalf("expected snapshot content types %v, got %v", expected, grp.ContentTypes)
+		}
+	}
+}
+
+func TestContentTypeBound(t *testing.T) {
+	types := map[string]int{}
+	for i := 0; i < _maxContentTypes; i++ {
+		types[fmt.Sprintf("type/%d", i)] = 1
+	}
+	types = addContentType(types, "/file.png", 1)
+	if types[ContentTypeOther] != 1 || types["image/png"] != 0 {
+		t.Fatalf("expected new types past the bound to be counted as other, got %v", types)
+	}
+}
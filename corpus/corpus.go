@@ -0,0 +1,345 @@
+// Package corpus generates synthetic URL corpora for tests, demos and benchmarks, so realistic traffic can be shared
+// without sharing real logs.
+//
+// A Profile describes the routes of a kind of site as templates such as `/products/{product}/reviews`, along with
+// the number of distinct values of each parameter. Parameter values are drawn with a skewed distribution, so a few
+// values are much more frequent than the rest like in real traffic.
+package corpus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// KindNumber is a numeric identifier.
+	KindNumber = "number"
+	// KindSlug is a few hyphenated words.
+	KindSlug = "slug"
+	// KindUUID is a random UUID.
+	KindUUID = "uuid"
+	// KindHex is a random hexadecimal token.
+	KindHex = "hex"
+	// KindWord is a single word.
+	KindWord = "word"
+
+	_defaultHost = "https://example.com"
+	// _skew is the exponent of the Zipf distribution parameter values are drawn from.
+	_skew = 1.1
+)
+
+type (
+	// Param is a parameter of the routes of a Profile.
+	Param struct {
+		// Kind is how values look, one of the Kind constants.
+		Kind string
+		// Cardinality is the number of distinct values.
+		Cardinality int
+	}
+
+	// Route is a route template, with parameters in braces such as `/users/{user}`.
+	Route struct {
+		Template string
+		// Weight is the share of traffic of the route relative to the other routes of the Profile.
+		Weight int
+	}
+
+	// Profile describes the traffic of a kind of site.
+	Profile struct {
+		Name   string
+		Routes []Route
+		Params map[string]Param
+	}
+
+	// Options tunes a Generator.
+	Options struct {
+		// Seed seeds the generator, the same seed always produces the same corpus.
+		Seed int64
+		// Host prefixes every URL, defaults to https://example.com. Set it to "/" for paths only.
+		Host string
+		// Cardinalities overrides the cardinality of parameters by name.
+		Cardinalities map[string]int
+	}
+
+	// Generator produces the URLs of a Profile.
+	Generator struct {
+		rand   *rand.Rand
+		host   string
+		routes []compiledRoute
+		total  int
+	}
+
+	compiledRoute struct {
+		parts  []string
+		params []*param
+		weight int
+	}
+
+	param struct {
+		name string
+		kind string
+		zipf *rand.Zipf
+	}
+)
+
+var _profiles = map[string]Profile{
+	"ecommerce": {
+		Name: "ecommerce",
+		Routes: []Route{
+			{Template: "/", Weight: 20},
+			{Template: "/products/{product}", Weight: 300},
+			{Template: "/products/{product}/reviews", Weight: 40},
+			{Template: "/products/{product}/reviews/page/{page}", Weight: 10},
+			{Template: "/category/{category}", Weight: 120},
+			{Template: "/category/{category}/page/{page}", Weight: 60},
+			{Template: "/search/{query}", Weight: 80},
+			{Template: "/cart", Weight: 40},
+			{Template: "/checkout/{session}", Weight: 20},
+			{Template: "/orders/{order}", Weight: 25},
+			{Template: "/users/{user}/wishlist", Weight: 15},
+			{Template: "/static/img/{image}.jpg", Weight: 200},
+			{Template: "/api/v1/products/{product}/stock", Weight: 50},
+		},
+		Params: map[string]Param{
+			"product":  {Kind: KindSlug, Cardinality: 5000},
+			"category": {Kind: KindWord, Cardinality: 40},
+			"page":     {Kind: KindNumber, Cardinality: 50},
+			"query":    {Kind: KindWord, Cardinality: 2000},
+			"session":  {Kind: KindUUID, Cardinality: 100000},
+			"order":    {Kind: KindNumber, Cardinality: 50000},
+			"user":     {Kind: KindNumber, Cardinality: 20000},
+			"image":    {Kind: KindHex, Cardinality: 10000},
+		},
+	},
+	"blog": {
+		Name: "blog",
+		Routes: []Route{
+			{Template: "/", Weight: 30},
+			{Template: "/{year}/{month}/{post}", Weight: 300},
+			{Template: "/{year}/{month}/{post}/comments", Weight: 30},
+			{Template: "/tag/{tag}", Weight: 60},
+			{Template: "/tag/{tag}/page/{page}", Weight: 20},
+			{Template: "/author/{author}", Weight: 30},
+			{Template: "/feed", Weight: 40},
+			{Template: "/wp-content/uploads/{year}/{month}/{image}.png", Weight: 150},
+		},
+		Params: map[string]Param{
+			"year":   {Kind: KindNumber, Cardinality: 10},
+			"month":  {Kind: KindNumber, Cardinality: 12},
+			"post":   {Kind: KindSlug, Cardinality: 3000},
+			"tag":    {Kind: KindWord, Cardinality: 200},
+			"page":   {Kind: KindNumber, Cardinality: 30},
+			"author": {Kind: KindWord, Cardinality: 20},
+			"image":  {Kind: KindSlug, Cardinality: 5000},
+		},
+	},
+	"api": {
+		Name: "api",
+		Routes: []Route{
+			{Template: "/v1/users/{user}", Weight: 200},
+			{Template: "/v1/users/{user}/orders", Weight: 80},
+			{Template: "/v1/users/{user}/orders/{order}", Weight: 60},
+			{Template: "/v1/accounts/{account}/tokens/{token}", Weight: 40},
+			{Template: "/v2/files/{file}", Weight: 100},
+			{Template: "/v2/files/{file}/versions/{version}", Weight: 20},
+			{Template: "/health", Weight: 50},
+		},
+		Params: map[string]Param{
+			"user":    {Kind: KindNumber, Cardinality: 50000},
+			"order":   {Kind: KindNumber, Cardinality: 200000},
+			"account": {Kind: KindUUID, Cardinality: 5000},
+			"token":   {Kind: KindHex, Cardinality: 100000},
+			"file":    {Kind: KindUUID, Cardinality: 100000},
+			"version": {Kind: KindNumber, Cardinality: 20},
+		},
+	},
+}
+
+var _words = []string{
+	"alpha", "amber", "anchor", "apple", "arrow", "autumn", "basket", "beacon", "berry", "blossom", "breeze", "bridge",
+	"candle", "canyon", "cedar", "cherry", "cloud", "comet", "copper", "coral", "cotton", "crystal", "dawn", "desert",
+	"echo", "ember", "falcon", "feather", "forest", "garden", "glacier", "harbor", "hazel", "horizon", "island",
+	"jasmine", "lantern", "lemon", "maple", "meadow", "mirror", "monsoon", "nectar", "ocean", "orchid", "pebble",
+	"pepper", "prairie", "quartz", "river", "saffron", "shadow", "silver", "spruce", "summit", "thunder", "timber",
+	"velvet", "willow", "winter",
+}
+
+// Profiles returns the names of the built-in profiles.
+func Profiles() []string {
+	names := make([]string, 0, len(_profiles))
+	for name := range _profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupProfile returns the built-in profile with the given name.
+func LookupProfile(name string) (Profile, bool) {
+	p, ok := _profiles[name]
+	return p, ok
+}
+
+// New returns a Generator of the URLs of p.
+func New(p Profile, opts Options) (*Generator, error) {
+	if len(p.Routes) == 0 {
+		return nil, fmt.Errorf("profile %q has no routes", p.Name)
+	}
+	host := opts.Host
+	if host == "" {
+		host = _defaultHost
+	}
+	g := &Generator{
+		rand: rand.New(rand.NewSource(opts.Seed)),
+		host: strings.TrimSuffix(host, "/"),
+	}
+
+	params := make(map[string]*param)
+	for _, r := range p.Routes {
+		if r.Weight <= 0 {
+			return nil, fmt.Errorf("route %q must have a positive weight", r.Template)
+		}
+		compiled := compiledRoute{weight: r.Weight}
+		rest := r.Template
+		for {
+			open := strings.IndexByte(rest, '{')
+			if open < 0 {
+				break
+			}
+			end := strings.IndexByte(rest[open:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("route %q has an unterminated parameter", r.Template)
+			}
+			name := rest[open+1 : open+end]
+			prm, ok := params[name]
+			if !ok {
+				var err error
+				if prm, err = g.newParam(name, p.Params, opts.Cardinalities); err != nil {
+					return nil, fmt.Errorf("route %q: %w", r.Template, err)
+				}
+				params[name] = prm
+			}
+			compiled.parts = append(compiled.parts, rest[:open])
+			compiled.params = append(compiled.params, prm)
+			rest = rest[open+end+1:]
+		}
+		compiled.parts = append(compiled.parts, rest)
+		g.routes = append(g.routes, compiled)
+		g.total += r.Weight
+	}
+	for name := range opts.Cardinalities {
+		if _, ok := params[name]; !ok {
+			return nil, fmt.Errorf("profile %q has no parameter %q", p.Name, name)
+		}
+	}
+	return g, nil
+}
+
+func (g *Generator) newParam(name string, params map[string]Param, cardinalities map[string]int) (*param, error) {
+	p, ok := params[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown parameter %q", name)
+	}
+	switch p.Kind {
+	case KindNumber, KindSlug, KindUUID, KindHex, KindWord:
+	default:
+		return nil, fmt.Errorf("parameter %q has unknown kind %q", name, p.Kind)
+	}
+	if c, ok := cardinalities[name]; ok {
+		p.Cardinality = c
+	}
+	if p.Cardinality <= 0 {
+		return nil, fmt.Errorf("parameter %q must have a positive cardinality", name)
+	}
+	return &param{
+		name: name,
+		kind: p.Kind,
+		zipf: rand.NewZipf(g.rand, _skew, 1, uint64(p.Cardinality-1)),
+	}, nil
+}
+
+// Next returns the next URL.
+func (g *Generator) Next() string {
+	pick := g.rand.Intn(g.total)
+	r := &g.routes[len(g.routes)-1]
+	for i := range g.routes {
+		if pick < g.routes[i].weight {
+			r = &g.routes[i]
+			break
+		}
+		pick -= g.routes[i].weight
+	}
+
+	var b strings.Builder
+	b.WriteString(g.host)
+	for i, p := range r.params {
+		b.WriteString(r.parts[i])
+		b.WriteString(p.value(p.zipf.Uint64()))
+	}
+	b.WriteString(r.parts[len(r.parts)-1])
+	return b.String()
+}
+
+// Generate writes n URLs of p to w, one per line.
+func Generate(w io.Writer, p Profile, n int, opts Options) error {
+	g, err := New(p, opts)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	for i := 0; i < n; i++ {
+		bw.WriteString(g.Next())
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// value returns the i-th distinct value of the parameter. Values only depend on the parameter name and i, so the
+// same value is produced for the same index whichever the seed.
+func (p *param) value(i uint64) string {
+	h := mix(i ^ hashString(p.name))
+	switch p.kind {
+	case KindNumber:
+		return strconv.FormatUint(i+1, 10)
+	case KindWord:
+		word := _words[i%uint64(len(_words))]
+		if i < uint64(len(_words)) {
+			return word
+		}
+		return word + strconv.FormatUint(i/uint64(len(_words)), 10)
+	case KindSlug:
+		words := make([]string, 0, 4)
+		for j := uint64(0); j < 2+h%3; j++ {
+			words = append(words, _words[mix(h+j)%uint64(len(_words))])
+		}
+		return strings.Join(words, "-") + "-" + strconv.FormatUint(i, 10)
+	case KindUUID:
+		hi, lo := h, mix(h)
+		return fmt.Sprintf("%08x-%04x-4%03x-%04x-%012x",
+			hi>>32, hi>>16&0xffff, hi&0xfff, lo>>48&0x3fff|0x8000, lo&0xffffffffffff)
+	default:
+		return fmt.Sprintf("%016x", h)
+	}
+}
+
+// mix is the finalizer of splitmix64.
+func mix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+func hashString(s string) uint64 {
+	var h uint64 = 14695981039346656037
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= 1099511628211
+	}
+	return h
+}
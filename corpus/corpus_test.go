@@ -0,0 +1,111 @@
+package corpus
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/trustleast/groupurl"
+)
+
+func TestGenerateDeterministic(t *testing.T) {
+	p, ok := LookupProfile("ecommerce")
+	if !ok {
+		t.Fatal("missing ecommerce profile")
+	}
+	var a, b bytes.Buffer
+	if err := Generate(&a, p, 1000, Options{Seed: 7}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Generate(&b, p, 1000, Options{Seed: 7}); err != nil {
+		t.Fatal(err)
+	}
+	if a.String() != b.String() {
+		t.Error("same seed produced different corpora")
+	}
+	if lines := strings.Count(a.String(), "\n"); lines != 1000 {
+		t.Errorf("got %d lines, want 1000", lines)
+	}
+}
+
+func TestCardinalities(t *testing.T) {
+	p := Profile{
+		Name:   "test",
+		Routes: []Route{{Template: "/items/{item}", Weight: 1}},
+		Params: map[string]Param{"item": {Kind: KindSlug, Cardinality: 1000}},
+	}
+	g, err := New(p, Options{Host: "/", Cardinalities: map[string]int{"item": 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	distinct := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		path := g.Next()
+		if !strings.HasPrefix(path, "/items/") {
+			t.Fatalf("unexpected path %q", path)
+		}
+		distinct[path] = true
+	}
+	if len(distinct) != 3 {
+		t.Errorf("got %d distinct paths, want 3", len(distinct))
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := map[string]struct {
+		profile Profile
+		opts    Options
+	}{
+		"no routes": {profile: Profile{Name: "empty"}},
+		"unknown parameter": {
+			profile: Profile{Routes: []Route{{Template: "/{missing}", Weight: 1}}},
+		},
+		"unknown kind": {
+			profile: Profile{
+				Routes: []Route{{Template: "/{id}", Weight: 1}},
+				Params: map[string]Param{"id": {Kind: "emoji", Cardinality: 1}},
+			},
+		},
+		"unused cardinality": {
+			profile: Profile{Routes: []Route{{Template: "/", Weight: 1}}},
+			opts:    Options{Cardinalities: map[string]int{"id": 1}},
+		},
+		"unterminated": {
+			profile: Profile{Routes: []Route{{Template: "/{id", Weight: 1}}},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := New(tc.profile, tc.opts); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestProfilesGroup(t *testing.T) {
+	for _, name := range Profiles() {
+		t.Run(name, func(t *testing.T) {
+			p, _ := LookupProfile(name)
+			gen, err := New(p, Options{Seed: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			g, err := groupurl.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 20000; i++ {
+				u, err := url.Parse(gen.Next())
+				if err != nil {
+					t.Fatal(err)
+				}
+				g.Add(u)
+			}
+			if groups := len(strings.Split(g.String(), "\n")); groups > 20*len(p.Routes) {
+				t.Errorf("got %d lines of groups for %d routes", groups, len(p.Routes))
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package groupurl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Counter stores how many times each token was counted at a position of a Grouper. The Grouper lower cases tokens
+// and applies cardinality limits and the overflow before counting them, so that a Counter only stores counts: an
+// exact map by default, but it can be bounded, approximate or backed by an external store. Like the Grouper, it is
+// not called concurrently.
+//
+// Counts that are approximate make simplification approximate too: a token whose count is overestimated may be
+// preserved although it is rare. Everything a Counter ranges over is written by WriteState and restored with Add.
+type Counter interface {
+	// Add counts a token n more times.
+	Add(token string, n int)
+	// Count returns the number of times a token was counted, 0 if it is not stored.
+	Count(token string) int
+	// Delete forgets a token, as when the tokens of a label are split to a node of their own.
+	Delete(token string)
+	// Len returns the number of distinct tokens stored.
+	Len() int
+	// Range calls f with every token stored and its count, in any order, until f returns false.
+	Range(f func(token string, count int) bool)
+}
+
+// MapCounter is the default Counter, which counts every token exactly in a map.
+type MapCounter map[string]int
+
+// NewMapCounter creates an empty MapCounter, and can be passed to WithCounter or WithLabelCounter.
+func NewMapCounter() Counter {
+	return MapCounter{}
+}
+
+// Add implements Counter.
+func (c MapCounter) Add(token string, n int) {
+	c[token] += n
+}
+
+// Count implements Counter.
+func (c MapCounter) Count(token string) int {
+	return c[token]
+}
+
+// Delete implements Counter.
+func (c MapCounter) Delete(token string) {
+	delete(c, token)
+}
+
+// Len implements Counter.
+func (c MapCounter) Len() int {
+	return len(c)
+}
+
+// Range implements Counter.
+func (c MapCounter) Range(f func(token string, count int) bool) {
+	for token, count := range c {
+		if !f(token, count) {
+			return
+		}
+	}
+}
+
+// SpaceSavingCounter is a Counter bounded to a number of tokens, which keeps the most frequent ones with the
+// Space-Saving algorithm: once full, a new token replaces the least counted one and inherits its count. Counts are
+// overestimated by at most the count of the least counted token, and every token counted more than total/capacity
+// times is stored. It suits positions with too many distinct tokens for a cardinality limit to keep the frequent
+// ones, as the limit keeps the first tokens seen instead.
+type SpaceSavingCounter struct {
+	capacity int
+	counts   map[string]int
+}
+
+// NewSpaceSavingCounter returns a function creating SpaceSavingCounters storing up to capacity tokens, to be passed to
+// WithCounter or WithLabelCounter. Adding a new token to a full counter scans its tokens, so capacities are best kept
+// to a few thousands.
+func NewSpaceSavingCounter(capacity int) (func() Counter, error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("space saving capacity must be positive, got %d", capacity)
+	}
+	return func() Counter {
+		return &SpaceSavingCounter{capacity: capacity, counts: make(map[string]int, capacity)}
+	}, nil
+}
+
+// Add implements Counter.
+func (c *SpaceSavingCounter) Add(token string, n int) {
+	if _, ok := c.counts[token]; ok || len(c.counts) < c.capacity {
+		c.counts[token] += n
+		return
+	}
+	least, leastCount := "", 0
+	for t, count := range c.counts {
+		if least == "" || count < leastCount || (count == leastCount && t < least) {
+			least, leastCount = t, count
+		}
+	}
+	delete(c.counts, least)
+	c.counts[token] = leastCount + n
+}
+
+// Count implements Counter.
+func (c *SpaceSavingCounter) Count(token string) int {
+	return c.counts[token]
+}
+
+// Delete implements Counter.
+func (c *SpaceSavingCounter) Delete(token string) {
+	delete(c.counts, token)
+}
+
+// Len implements Counter.
+func (c *SpaceSavingCounter) Len() int {
+	return len(c.counts)
+}
+
+// Range implements Counter.
+func (c *SpaceSavingCounter) Range(f func(token string, count int) bool) {
+	for token, count := range c.counts {
+		if !f(token, count) {
+			return
+		}
+	}
+}
+
+// WithCounter sets how the tokens of every position are counted, unless WithLabelCounter sets it for their label.
+// The default is NewMapCounter.
+func WithCounter(newCounter func() Counter) Option {
+	return func(g *Grouper) error {
+		if newCounter == nil {
+			return errors.New("counter must not be nil")
+		}
+		g.tree.newCounter = newCounter
+		return nil
+	}
+}
+
+// WithLabelCounter sets how the tokens of the positions created with a label are counted, such as a
+// SpaceSavingCounter for a label with many distinct tokens. A position keeps its Counter when its label is promoted
+// to a parent label.
+func WithLabelCounter(label string, newCounter func() Counter) Option {
+	return func(g *Grouper) error {
+		if label == "" {
+			return errors.New("cannot set the counter of an empty label")
+		}
+		if newCounter == nil {
+			return fmt.Errorf("counter of %s must not be nil", label)
+		}
+		if g.tree.labelCounters == nil {
+			g.tree.labelCounters = make(map[string]func() Counter)
+		}
+		g.tree.labelCounters[label] = newCounter
+		return nil
+	}
+}
+
+// counterFor creates the Counter of a position created with a label.
+func (c treeConfig) counterFor(label LabelFields) Counter {
+	if newCounter, ok := c.labelCounters[label.Value]; ok {
+		return newCounter()
+	}
+	if c.newCounter != nil {
+		return c.newCounter()
+	}
+	return MapCounter{}
+}
+
+// counterMap copies the counts of a Counter to a map, nil if it is empty.
+func counterMap(c Counter) map[string]int {
+	if c.Len() == 0 {
+		return nil
+	}
+	m := make(map[string]int, c.Len())
+	c.Range(func(token string, count int) bool {
+		m[token] = count
+		return true
+	})
+	return m
+}
@@ -0,0 +1,103 @@
+package groupurl
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestSpaceSavingCounter(t *testing.T) {
+	newCounter, err := NewSpaceSavingCounter(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newCounter()
+	c.Add("a", 5)
+	c.Add("b", 2)
+	c.Add("c", 1)
+
+	if c.Len() != 2 {
+		t.Fatalf("expected 2, got %d", c.Len())
+	}
+	if c.Count("b") != 0 {
+		t.Fatalf("expected the least counted token to be replaced, got %d", c.Count("b"))
+	}
+	if c.Count("c") != 3 {
+		t.Fatalf("expected the new token to inherit the count it replaced, got %d", c.Count("c"))
+	}
+	if c.Count("a") != 5 {
+		t.Fatalf("expected 5, got %d", c.Count("a"))
+	}
+	c.Delete("a")
+	c.Add("d", 1)
+	if c.Count("d") != 1 || c.Len() != 2 {
+		t.Fatalf("expected a deleted token to make room, got %d tokens", c.Len())
+	}
+
+	if _, err := NewSpaceSavingCounter(0); err == nil {
+		t.Fatal("expected error for a non-positive capacity")
+	}
+}
+
+func TestWithLabelCounter(t *testing.T) {
+	newCounter, err := NewSpaceSavingCounter(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var numbers int
+	options := []Option{
+		WithCounter(newCounter),
+		WithLabelCounter("Number", func() Counter {
+			numbers++
+			return NewMapCounter()
+		}),
+	}
+	g, err := New(options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/%d/sku%s", i, letters(i%100))})
+		g.Add(&url.URL{Path: fmt.Sprintf("/%d/featured", i)})
+	}
+
+	if numbers != 1 {
+		t.Fatalf("expected the counter of the label to count its position, got %d", numbers)
+	}
+	for _, n := range g.trees[1].Root.sortedChildren() {
+		for _, child := range n.sortedChildren() {
+			if c, ok := child.tokenCounts.counts.(*SpaceSavingCounter); !ok || c.Len() != 20 {
+				t.Fatalf("expected other labels to be counted by the bounded counter, got %T", child.tokenCounts.counts)
+			}
+		}
+	}
+	if got := g.SimplifyPath(&url.URL{Path: "/7/featured"}); got != "/Number/featured" {
+		t.Fatalf("expected the frequent token to be kept by the bounded counter, got %s", got)
+	}
+	if got := g.SimplifyPath(&url.URL{Path: "/7/skuc"}); got != "/Number/Words" {
+		t.Fatalf("expected rare tokens to be grouped, got %s", got)
+	}
+
+	var buf bytes.Buffer
+	if err := g.WriteState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := New(options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.ReadState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if restored.String() != g.String() {
+		t.Errorf("restored trees differ\ngot:\n%s\nwant:\n%s", restored.String(), g.String())
+	}
+
+	if _, err := New(WithCounter(nil)); err == nil {
+		t.Fatal("expected error for a nil counter")
+	}
+	if _, err := New(WithLabelCounter("", NewMapCounter)); err == nil {
+		t.Fatal("expected error for an empty label")
+	}
+}
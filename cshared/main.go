@@ -0,0 +1,142 @@
+//go:build cgo
+
+// Command cshared builds groupurl as a C shared library so Python, Ruby, and other languages
+// can use it through their FFI instead of reimplementing the heuristics.
+//
+// Build it with:
+//
+//	go build -buildmode=c-shared -o libgroupurl.so ./cshared
+//
+// which also writes libgroupurl.h. Groupers are referred to by integer handles:
+//
+//	long long g = GroupurlNew();
+//	GroupurlAdd(g, "https://example.com/users/1");
+//	char *simplified = GroupurlSimplify(g, "https://example.com/users/2");
+//	GroupurlFree(simplified);
+//	GroupurlClose(g);
+//
+// Strings returned by the library must be released with GroupurlFree.
+// Every call locks its Grouper, so handles may be shared between threads.
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"net/url"
+	"sync"
+	"unsafe"
+
+	"github.com/trustleast/groupurl"
+)
+
+type handle struct {
+	mu sync.Mutex
+	g  groupurl.Grouper
+}
+
+var (
+	_handlesMu sync.Mutex
+	_handles   = make(map[int64]*handle)
+	_nextID    int64
+)
+
+func main() {}
+
+func lookup(id C.longlong) *handle {
+	_handlesMu.Lock()
+	defer _handlesMu.Unlock()
+	return _handles[int64(id)]
+}
+
+// GroupurlNew creates a Grouper with the default classifiers and returns its handle, or 0 on failure.
+//
+//export GroupurlNew
+func GroupurlNew() C.longlong {
+	g, err := groupurl.New()
+	if err != nil {
+		return 0
+	}
+
+	_handlesMu.Lock()
+	defer _handlesMu.Unlock()
+	_nextID++
+	_handles[_nextID] = &handle{g: g}
+	return C.longlong(_nextID)
+}
+
+// GroupurlClose releases a Grouper. The handle must not be used afterwards.
+//
+//export GroupurlClose
+func GroupurlClose(id C.longlong) {
+	_handlesMu.Lock()
+	defer _handlesMu.Unlock()
+	delete(_handles, int64(id))
+}
+
+// GroupurlAdd adds a URL to a Grouper. It returns 0 on success and -1 for unknown handles or invalid URLs.
+//
+//export GroupurlAdd
+func GroupurlAdd(id C.longlong, rawURL *C.char) C.int {
+	h := lookup(id)
+	if h == nil {
+		return -1
+	}
+	u, err := url.Parse(C.GoString(rawURL))
+	if err != nil {
+		return -1
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.g.Add(u)
+	return 0
+}
+
+// GroupurlSimplify returns the simplified path of a URL, or NULL for unknown handles or invalid URLs.
+//
+//export GroupurlSimplify
+func GroupurlSimplify(id C.longlong, rawURL *C.char) *C.char {
+	h := lookup(id)
+	if h == nil {
+		return nil
+	}
+	u, err := url.Parse(C.GoString(rawURL))
+	if err != nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return C.CString(h.g.SimplifyPath(u))
+}
+
+// GroupurlSnapshot returns the JSON encoded snapshot of a Grouper, or NULL for unknown handles.
+//
+//export GroupurlSnapshot
+func GroupurlSnapshot(id C.longlong) *C.char {
+	h := lookup(id)
+	if h == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	snapshot := h.g.Snapshot()
+	h.mu.Unlock()
+
+	var buf bytes.Buffer
+	if _, err := snapshot.WriteTo(&buf); err != nil {
+		return nil
+	}
+	return C.CString(buf.String())
+}
+
+// GroupurlFree releases a string returned by the library.
+//
+//export GroupurlFree
+func GroupurlFree(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
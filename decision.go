@@ -0,0 +1,420 @@
+package groupurl
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DecisionTable is a self-contained, JSON serializable description of how a Grouper simplifies paths,
+// meant to be evaluated by runtimes that cannot link Go, such as nginx/Lua or CDN workers.
+//
+// Evaluating a path works in two steps that mirror the Grouper:
+//
+//  1. The path is split into tokens by trying the Classifiers in order on the rest of the path.
+//     Leading slashes are skipped, the first classifier that matches determines the label and how much
+//     of the path is consumed. If none match, the rest of the path becomes a single "Unknown" token.
+//  2. The tree for the number of slashes in the trimmed path is walked, following the child whose Key
+//     equals the token's key label. The token is kept if it is in Keep (compared in lower case)
+//     or KeepAll is set, otherwise it is replaced by the node's Label. Once no child matches,
+//     the remaining tokens are emitted unchanged.
+//
+// Tokens in Redacted are never kept, and are replaced by the Value of the label their classifier emits once no child matches.
+// Tokens in Pinned are always kept. Both are compared in lower case and take precedence over the trees.
+// When PrivacyK is set, remaining tokens that are not pinned are replaced by their label too.
+// When EncodeLabels is set, labels are output in angle brackets and kept tokens are escaped, as with WithEncodedLabels.
+//
+// DecisionEvaluator is the reference implementation of these rules.
+type DecisionTable struct {
+	Version     int                     `json:"version"`
+	Classifiers []DecisionClassifier    `json:"classifiers"`
+	Trees       map[string]DecisionNode `json:"trees"`
+	Pinned      []string                `json:"pinned,omitempty"`
+	Redacted    []string                `json:"redacted,omitempty"`
+	// PrivacyK is the k of WithPrivacyMode when the Grouper was in privacy mode.
+	PrivacyK int `json:"privacy_k,omitempty"`
+	// EncodeLabels is set when the Grouper was created WithEncodedLabels.
+	EncodeLabels bool `json:"encode_labels,omitempty"`
+}
+
+// DecisionClassifier describes one classifier. Type is "regex", "year", "random", or "nested".
+type DecisionClassifier struct {
+	Type string `json:"type"`
+	// Pattern is the regular expression matched against the start of the rest of the path, for "regex", "year",
+	// and "random".
+	Pattern string `json:"pattern,omitempty"`
+	// Label is emitted on a match, for "regex", "year", and "random".
+	Label *DecisionLabel `json:"label,omitempty"`
+	// Start and End bound the year parsed from the first four characters of the match, for "year".
+	Start int64 `json:"start,omitempty"`
+	End   int64 `json:"end,omitempty"`
+	// Bigrams are the common pairs of lower case letters and MinShare the share of them in the match below which it
+	// is random, for "random". The match, without its trailing slash, must contain a hyphen or underscore and is
+	// split into parts on them. It is random when more than half of the parts with letters also have other
+	// characters, or when it has at least 3 pairs of adjacent lower cased letters within parts and fewer than
+	// MinShare of them are in Bigrams.
+	Bigrams  []string `json:"bigrams,omitempty"`
+	MinShare float64  `json:"min_share,omitempty"`
+	// Parent determines the match, and Children refine its label by being matched against the parent's match, for "nested".
+	// The key label of a refined token is the parent's label.
+	Parent   *DecisionClassifier  `json:"parent,omitempty"`
+	Children []DecisionClassifier `json:"children,omitempty"`
+}
+
+// DecisionLabel mirrors LabelFields, with the namespace folded into Value.
+type DecisionLabel struct {
+	Value            string `json:"value"`
+	Important        bool   `json:"important,omitempty"`
+	CardinalityLimit int    `json:"cardinality_limit,omitempty"`
+}
+
+// DecisionNode is a node of a tree in a DecisionTable.
+type DecisionNode struct {
+	Key      DecisionLabel  `json:"key"`
+	Label    string         `json:"label"`
+	Keep     []string       `json:"keep,omitempty"`
+	KeepAll  bool           `json:"keep_all,omitempty"`
+	Children []DecisionNode `json:"children,omitempty"`
+}
+
+const _decisionTableVersion = 1
+
+// DecisionTable exports the learned grouping as a DecisionTable.
+// Only the classifiers provided by this package can be exported; custom classifiers result in an error.
+func (g Grouper) DecisionTable() (DecisionTable, error) {
+	classifiers := make([]DecisionClassifier, 0, len(g.classifiers))
+	for _, c := range g.classifiers {
+		dc, err := decisionClassifier(c)
+		if err != nil {
+			return DecisionTable{}, err
+		}
+		classifiers = append(classifiers, dc)
+	}
+
+	if len(g.tails.prefixes) > 0 {
+		return DecisionTable{}, errors.New("wildcard tails cannot be exported to a decision table")
+	}
+	if len(g.overrides.rules) > 0 {
+		return DecisionTable{}, errors.New("override rules cannot be exported to a decision table")
+	}
+	if len(g.tree.priors) > 0 {
+		return DecisionTable{}, errors.New("position priors cannot be exported to a decision table")
+	}
+	if g.tree.coldStart > 0 {
+		return DecisionTable{}, errors.New("cold start heuristics cannot be exported to a decision table")
+	}
+	trees := make(map[string]DecisionNode, len(g.trees))
+	for key, t := range g.trees {
+		if t.hasSplits() {
+			return DecisionTable{}, errors.New("trees with split nodes cannot be exported to a decision table")
+		}
+		trees[strconv.Itoa(key)] = t.decisionNode(LabelFields{}, t.Root)
+	}
+	return DecisionTable{
+		Version:     _decisionTableVersion,
+		Classifiers: classifiers,
+		Trees:       trees,
+		Pinned:      sortedTokens(g.tree.pinned),
+		Redacted:    sortedTokens(g.tree.redacted),
+		PrivacyK:    g.tree.privacyK,
+
+		EncodeLabels: g.tree.encodeLabels,
+	}, nil
+}
+
+func decisionClassifier(c PathTokenClassifier) (DecisionClassifier, error) {
+	switch c := c.(type) {
+	case RegexPathTokenClassifier:
+		return DecisionClassifier{
+			Type:    "regex",
+			Pattern: c.Regex.String(),
+			Label:   decisionLabel(c.Label.LabelFields),
+		}, nil
+	case YearPathTokenClassifier:
+		return DecisionClassifier{
+			Type:    "year",
+			Pattern: regexYYYY.String(),
+			Label:   decisionLabel(LabelFields{Value: "YYYY"}),
+			Start:   c.Start,
+			End:     c.End,
+		}, nil
+	case RandomTokenClassifier:
+		return DecisionClassifier{
+			Type:     "random",
+			Pattern:  regexWords.String(),
+			Label:    decisionLabel(LabelFields{Value: "RandomToken"}),
+			Bigrams:  strings.Fields(_commonBigrams),
+			MinShare: _minCommonBigramShare,
+		}, nil
+	case vocabularyClassifier:
+		dc, err := decisionClassifier(c.classifier)
+		if err != nil {
+			return DecisionClassifier{}, err
+		}
+		if dc.Label != nil {
+			renamed := c.vocabulary.rename(LabelFields{Value: dc.Label.Value})
+			dc.Label.Value = renamed.Value
+		}
+		return dc, nil
+	case NestedPathTokenClassifier:
+		parent, err := decisionClassifier(c.Parent)
+		if err != nil {
+			return DecisionClassifier{}, err
+		}
+		dc := DecisionClassifier{
+			Type:   "nested",
+			Parent: &parent,
+		}
+		for _, child := range c.Children {
+			dcChild, err := decisionClassifier(child)
+			if err != nil {
+				return DecisionClassifier{}, err
+			}
+			dc.Children = append(dc.Children, dcChild)
+		}
+		return dc, nil
+	default:
+		return DecisionClassifier{}, fmt.Errorf("classifier %T cannot be exported to a decision table", c)
+	}
+}
+
+// identity returns the fields that tell the children of a node apart, as LabelFields.identity does, with the namespace
+// folded into Value.
+func (l DecisionLabel) identity() LabelFields {
+	return LabelFields{Important: l.Important, Value: l.Value}.identity()
+}
+
+func decisionLabel(l LabelFields) *DecisionLabel {
+	l = l.qualified()
+	return &DecisionLabel{
+		Value:            l.Value,
+		Important:        l.Important,
+		CardinalityLimit: l.CardinalityLimit,
+	}
+}
+
+// decisionNode converts a node and its children. The depth of trees is bounded by the number of path segments.
+func (t urlTree) decisionNode(key LabelFields, n *urlNode) DecisionNode {
+	dn := DecisionNode{
+		Key:   *decisionLabel(key),
+		Label: n.specificLabel.Value,
+	}
+	if n.specificLabel.Important && n.tokenCounts.total > 0 {
+		// A token that was never seen is only significant when every token is.
+		if t.isSignificant(n, "\x00") {
+			dn.KeepAll = true
+		} else {
+			for _, token := range n.tokenCounts.topN(n.tokenCounts.counts.Len()) {
+				if t.isSignificant(n, token) {
+					dn.Keep = append(dn.Keep, token)
+				}
+			}
+		}
+	}
+
+	children := n.sortedChildren()
+	keys := make(map[*urlNode]LabelFields, n.children.len())
+	n.children.each(func(k LabelFields, child *urlNode) {
+		keys[child] = k
+	})
+	for _, child := range children {
+		dn.Children = append(dn.Children, t.decisionNode(keys[child], child))
+	}
+	return dn
+}
+
+// DecisionEvaluator evaluates a DecisionTable. It is the reference implementation for ports to other runtimes.
+type DecisionEvaluator struct {
+	table       DecisionTable
+	classifiers []compiledDecisionClassifier
+	// overrides holds the pinned and redacted tokens and the label encoding of the table.
+	overrides treeConfig
+}
+
+type compiledDecisionClassifier struct {
+	DecisionClassifier
+	regex    *regexp.Regexp
+	bigrams  *bigramTable
+	parent   *compiledDecisionClassifier
+	children []compiledDecisionClassifier
+}
+
+// NewDecisionEvaluator compiles a DecisionTable for evaluation.
+func NewDecisionEvaluator(table DecisionTable) (*DecisionEvaluator, error) {
+	if table.Version != _decisionTableVersion {
+		return nil, fmt.Errorf("unsupported decision table version %d", table.Version)
+	}
+	e := &DecisionEvaluator{table: table}
+	e.overrides.encodeLabels = table.EncodeLabels
+	if err := addTokenOverrides(&e.overrides.pinned, nil, table.Pinned, "pinned", "redacted"); err != nil {
+		return nil, err
+	}
+	if err := addTokenOverrides(&e.overrides.redacted, e.overrides.pinned, table.Redacted, "redacted", "pinned"); err != nil {
+		return nil, err
+	}
+	for _, c := range table.Classifiers {
+		compiled, err := compileDecisionClassifier(c)
+		if err != nil {
+			return nil, err
+		}
+		e.classifiers = append(e.classifiers, compiled)
+	}
+	return e, nil
+}
+
+func compileDecisionClassifier(c DecisionClassifier) (compiledDecisionClassifier, error) {
+	compiled := compiledDecisionClassifier{DecisionClassifier: c}
+	switch c.Type {
+	case "regex", "year", "random":
+		regex, err := regexp.Compile(c.Pattern)
+		if err != nil {
+			return compiled, err
+		}
+		compiled.regex = regex
+		compiled.bigrams = newBigramTable(c.Bigrams)
+	case "nested":
+		if c.Parent == nil {
+			return compiled, fmt.Errorf("nested classifier without parent")
+		}
+		parent, err := compileDecisionClassifier(*c.Parent)
+		if err != nil {
+			return compiled, err
+		}
+		compiled.parent = &parent
+		for _, child := range c.Children {
+			compiledChild, err := compileDecisionClassifier(child)
+			if err != nil {
+				return compiled, err
+			}
+			compiled.children = append(compiled.children, compiledChild)
+		}
+	default:
+		return compiled, fmt.Errorf("unknown classifier type %q", c.Type)
+	}
+	return compiled, nil
+}
+
+// check returns the key label and output label of a match, mirroring PathTokenClassifier.Check.
+func (c compiledDecisionClassifier) check(s string) (key, label DecisionLabel, match string) {
+	switch c.Type {
+	case "regex":
+		if match = c.regex.FindString(s); match != "" {
+			return *c.Label, *c.Label, match
+		}
+	case "year":
+		if match = c.regex.FindString(s); match != "" {
+			if year, err := strconv.ParseInt(match[:4], 10, 64); err == nil && year >= c.Start && year <= c.End {
+				return *c.Label, *c.Label, match
+			}
+		}
+	case "random":
+		if match = c.regex.FindString(s); match != "" && isRandomToken(strings.TrimSuffix(match, "/"), c.bigrams, c.MinShare) {
+			return *c.Label, *c.Label, match
+		}
+	case "nested":
+		parentKey, _, match := c.parent.check(s)
+		if match == "" {
+			return DecisionLabel{}, DecisionLabel{}, ""
+		}
+		for _, child := range c.children {
+			if _, childLabel, childMatch := child.check(match); childMatch != "" {
+				return parentKey, childLabel, match
+			}
+		}
+		return parentKey, parentKey, match
+	}
+	return DecisionLabel{}, DecisionLabel{}, ""
+}
+
+type decisionToken struct {
+	token string
+	key   DecisionLabel
+	label DecisionLabel
+}
+
+// SimplifyPath simplifies a path according to the table.
+func (e *DecisionEvaluator) SimplifyPath(path string) string {
+	tokens := e.tokens(path)
+	node, ok := e.table.Trees[strconv.Itoa(treeKey(path))]
+
+	var replaced []string
+	for idx, token := range tokens {
+		var child *DecisionNode
+		if ok {
+			for i := range node.Children {
+				if node.Children[i].Key.identity() == token.key.identity() {
+					child = &node.Children[i]
+					break
+				}
+			}
+		}
+		if child == nil {
+			for _, rest := range tokens[idx:] {
+				keep, ok := e.overrides.override(rest.token)
+				if !ok {
+					keep = e.table.PrivacyK == 0
+				}
+				if keep {
+					replaced = append(replaced, e.overrides.encodeToken(rest.token))
+				} else {
+					replaced = append(replaced, e.overrides.encodeLabel(rest.label.Value))
+				}
+			}
+			break
+		}
+
+		keep, ok := e.overrides.override(token.token)
+		if !ok {
+			keep = child.keeps(token.token)
+		}
+		if keep {
+			replaced = append(replaced, e.overrides.encodeToken(token.token))
+		} else {
+			replaced = append(replaced, e.overrides.encodeLabel(child.Label))
+		}
+		node = *child
+	}
+	return "/" + strings.Join(replaced, "/")
+}
+
+func (n DecisionNode) keeps(token string) bool {
+	if n.KeepAll {
+		return true
+	}
+	token = strings.ToLower(token)
+	for _, keep := range n.Keep {
+		if keep == token {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *DecisionEvaluator) tokens(path string) []decisionToken {
+	unknown := DecisionLabel{Value: "Unknown"}
+
+	var tokens []decisionToken
+	for path != "" {
+		if path[0] == '/' {
+			path = path[1:]
+			continue
+		}
+
+		key, label, match := unknown, unknown, path
+		for _, c := range e.classifiers {
+			if k, l, m := c.check(path); m != "" {
+				key, label, match = k, l, m
+				break
+			}
+		}
+		if !strings.HasPrefix(path, match) {
+			tokens = append(tokens, decisionToken{token: path, key: unknown, label: unknown})
+			break
+		}
+		tokens = append(tokens, decisionToken{token: strings.TrimRight(match, "/"), key: key, label: label})
+		path = path[len(match):]
+	}
+	return tokens
+}
@@ -0,0 +1,89 @@
+package groupurl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"testing"
+)
+
+func TestDecisionTable(t *testing.T) {
+	g, err := loadFixture("examples/test.urls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	urls, err := readFixtureURLs("examples/test.urls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table, err := g.DecisionTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded DecisionTable
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := NewDecisionEvaluator(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range append([]string{"/", "/unseen/depth/of/five/x", "/2013/11/20/new-slug"}, mapSlice(urls, func(u *url.URL) string {
+		return u.Path
+	})...) {
+		u := &url.URL{Path: path}
+		if expected, got := g.SimplifyPath(u), e.SimplifyPath(path); expected != got {
+			t.Fatalf("expected %s for %s, got %s", expected, path, got)
+		}
+	}
+
+	custom, err := New(WithClassifiers([]PathTokenClassifier{unexportableClassifier{}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := custom.DecisionTable(); err == nil {
+		t.Fatal("expected error for custom classifier")
+	}
+}
+
+type unexportableClassifier struct{}
+
+func (unexportableClassifier) Check(string) (Label, string) {
+	return Label{}, ""
+}
+
+func TestDecisionEvaluatorChildIdentity(t *testing.T) {
+	// The acme classifier emits Words in its own namespace, so its tokens must not follow the children of Words.
+	acme := RegexPathTokenClassifier{
+		Regex: regexp.MustCompile(`^acme-[a-z]+(/|$)`),
+		Label: Label{LabelFields: LabelFields{Value: "Words", Namespace: "acme", Important: true}},
+	}
+	g, err := New(WithClassifiers(append([]PathTokenClassifier{acme}, DefaultClassifiers()...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/docs/%d", i)})
+		g.Add(&url.URL{Path: fmt.Sprintf("/acme-docs/%d", i)})
+	}
+	table, err := g.DecisionTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewDecisionEvaluator(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/docs/1", "/other/1", "/acme-docs/1", "/acme-other/1"} {
+		if expected, got := g.SimplifyPath(&url.URL{Path: path}), e.SimplifyPath(path); expected != got {
+			t.Fatalf("expected %s for %s, got %s", expected, path, got)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package groupurl
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// DepthStats describes the distribution of path depths seen by a Grouper.
+// The depth of a path is the number of segments in it, so "/" has a depth of 0 and "/a/b" has a depth of 2.
+type DepthStats struct {
+	// Histogram maps a path depth to the number of URLs added with that depth.
+	Histogram map[int]int
+	// Trees maps the key of each internal tree to the number of URLs it holds.
+	// Paths of depth 0 and 1 share the first tree, otherwise a path of depth N is held by tree N-1.
+	Trees map[int]int
+	// Total is the number of URLs added.
+	Total int
+}
+
+// DepthStats returns the distribution of path depths seen so far.
+// Unusually deep paths are often a sign of crawler traps or malformed links.
+func (g Grouper) DepthStats() DepthStats {
+	stats := DepthStats{
+		Histogram: make(map[int]int, len(g.depths)),
+		Trees:     make(map[int]int, len(g.trees)),
+	}
+	for depth, count := range g.depths {
+		stats.Histogram[depth] = count
+		stats.Trees[depthTreeKey(depth)] += count
+		stats.Total += count
+	}
+	return stats
+}
+
+// Max returns the deepest path depth seen, or 0 if no URLs were added.
+func (d DepthStats) Max() int {
+	var max int
+	for depth := range d.Histogram {
+		if depth > max {
+			max = depth
+		}
+	}
+	return max
+}
+
+// Percentile returns the smallest depth such that at least p percent of URLs are no deeper than it.
+// p is clamped to the range [0, 100].
+func (d DepthStats) Percentile(p float64) int {
+	if d.Total == 0 {
+		return 0
+	}
+	p = math.Max(0, math.Min(100, p))
+
+	depths := make([]int, 0, len(d.Histogram))
+	for depth := range d.Histogram {
+		depths = append(depths, depth)
+	}
+	sort.Ints(depths)
+
+	rank := int(math.Ceil(p / 100 * float64(d.Total)))
+	var seen int
+	for _, depth := range depths {
+		seen += d.Histogram[depth]
+		if seen >= rank {
+			return depth
+		}
+	}
+	return depths[len(depths)-1]
+}
+
+func pathDepth(path string) int {
+	if strings.Trim(path, "/") == "" {
+		return 0
+	}
+	return treeKey(path) + 1
+}
+
+func depthTreeKey(depth int) int {
+	if depth == 0 {
+		return 0
+	}
+	return depth - 1
+}
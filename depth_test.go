@@ -0,0 +1,47 @@
+package groupurl
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestDepthStats(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rawURL := range []string{
+		"https://example.com/",
+		"https://example.com/a",
+		"https://example.com/a/b",
+		"https://example.com/a/c",
+		"https://example.com/a/b/c/d/e/f",
+	} {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+
+	stats := g.DepthStats()
+	if stats.Total != 5 {
+		t.Fatalf("expected 5, got %d", stats.Total)
+	}
+	if stats.Histogram[2] != 2 {
+		t.Fatalf("expected 2 URLs of depth 2, got %d", stats.Histogram[2])
+	}
+	if stats.Trees[0] != 2 {
+		t.Fatalf("expected 2 URLs in tree 0, got %d", stats.Trees[0])
+	}
+	if stats.Max() != 6 {
+		t.Fatalf("expected max depth 6, got %d", stats.Max())
+	}
+	if p := stats.Percentile(50); p != 2 {
+		t.Fatalf("expected median depth 2, got %d", p)
+	}
+	if p := stats.Percentile(100); p != 6 {
+		t.Fatalf("expected p100 depth 6, got %d", p)
+	}
+}
@@ -0,0 +1,26 @@
+package groupurl
+
+import "math/rand"
+
+// _deterministicSeed seeds the sampling of deterministic Groupers.
+const _deterministicSeed = 1
+
+// WithDeterministic makes what a Grouper learns independent of the order URLs are added in, so that golden tests
+// built on Snapshot or the exports are reliable. Every token is counted, rather than folding tokens into the generic
+// label once a label's cardinality limit is reached, and the limit is applied to the counts instead. This way a node
+// whose label is promoted to a parent label has the same counts whichever label it saw first, at the cost of memory
+// proportional to the distinct tokens seen. WithSampling, if used, draws from a fixed seed.
+// Lineage and the decisions of WithAutoTune still follow the order URLs are added in.
+func WithDeterministic() Option {
+	return func(g *Grouper) error {
+		g.tree.deterministic = true
+		return nil
+	}
+}
+
+// applyDeterministic reseeds the sampler once every option has been applied, whatever their order.
+func (g *Grouper) applyDeterministic() {
+	if g.tree.deterministic && g.sampling != nil {
+		g.sampling.rand = rand.New(rand.NewSource(_deterministicSeed))
+	}
+}
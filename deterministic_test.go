@@ -0,0 +1,65 @@
+package groupurl
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestDeterministic(t *testing.T) {
+	var paths []string
+	for i := 0; i < 80; i++ {
+		for j := 0; j <= i%5; j++ {
+			paths = append(paths, fmt.Sprintf("/tags/%s", budgetWord(i)))
+		}
+		paths = append(paths, fmt.Sprintf("/items/%d", i))
+	}
+	// Numbers and words share a node whose label is promoted to the Important AlphaNumeric label of the loose preset.
+	for i := 0; i < 200; i++ {
+		paths = append(paths, "/mixed/colors/1", "/mixed/colors/2", "/mixed/colors/red")
+	}
+
+	train := func(order []string) Grouper {
+		g, err := New(WithPreset("loose"), WithDeterministic())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range order {
+			u, err := url.Parse(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.Add(u)
+		}
+		return g
+	}
+
+	reversed := make([]string, len(paths))
+	for i, p := range paths {
+		reversed[len(paths)-1-i] = p
+	}
+	shuffled := append([]string(nil), paths...)
+	rand.New(rand.NewSource(7)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	expected := train(paths)
+	for _, order := range [][]string{reversed, shuffled} {
+		g := train(order)
+		if got, want := g.String(), expected.String(); got != want {
+			t.Fatalf("expected the same trees regardless of order, got\n%s\nwant\n%s", got, want)
+		}
+		got, want := g.Snapshot(), expected.Snapshot()
+		for _, s := range []*Snapshot{&got, &want} {
+			s.Lineage = nil
+			for i := range s.Groups {
+				s.Groups[i].Samples = nil
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected the same snapshot regardless of order, got %+v, want %+v", got, want)
+		}
+	}
+}
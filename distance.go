@@ -0,0 +1,65 @@
+package groupurl
+
+import "math"
+
+// Distance measures how much the traffic structure of two Groupers differs, from 0 for the same distribution of
+// traffic over the same groups to 1 for traffic over disjoint groups. It is the Jensen-Shannon distance between the
+// shares of traffic of each group pattern, so groups only one of the Groupers has seen count as much as groups whose
+// traffic shifted. It is a metric, so distances to a common baseline can be compared with each other.
+func Distance(a, b Grouper) float64 {
+	return countsDistance(groupCounts(a), groupCounts(b))
+}
+
+// SnapshotDistance is Distance between the Groupers two snapshots were taken from, for comparing today's traffic
+// with a stored baseline.
+func SnapshotDistance(a, b Snapshot) float64 {
+	return countsDistance(snapshotCounts(a), snapshotCounts(b))
+}
+
+func groupCounts(g Grouper) map[string]int {
+	counts := make(map[string]int)
+	for _, grp := range g.groups() {
+		counts[grp.pattern] += grp.count
+	}
+	return counts
+}
+
+// countsDistance returns the Jensen-Shannon distance in bits between the distributions of two sets of counts.
+// Two empty sets are identical, and an empty set is as far as can be from any other.
+func countsDistance(a, b map[string]int) float64 {
+	totalA, totalB := sumCounts(a), sumCounts(b)
+	switch {
+	case totalA == 0 && totalB == 0:
+		return 0
+	case totalA == 0 || totalB == 0:
+		return 1
+	}
+
+	var divergence float64
+	add := func(p, q float64) {
+		if p > 0 {
+			divergence += p / 2 * math.Log2(2*p/(p+q))
+		}
+		if q > 0 {
+			divergence += q / 2 * math.Log2(2*q/(p+q))
+		}
+	}
+	for pattern, count := range a {
+		add(float64(count)/float64(totalA), float64(b[pattern])/float64(totalB))
+	}
+	for pattern, count := range b {
+		if _, ok := a[pattern]; !ok {
+			add(0, float64(count)/float64(totalB))
+		}
+	}
+	// Rounding can leave the divergence of identical distributions slightly below 0.
+	return math.Sqrt(math.Max(0, math.Min(1, divergence)))
+}
+
+func sumCounts(counts map[string]int) int {
+	var total int
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
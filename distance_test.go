@@ -0,0 +1,54 @@
+package groupurl
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	train := func(users, items int) Grouper {
+		g, err := New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < users; i++ {
+			g.Add(&url.URL{Path: fmt.Sprintf("/users/%d", i)})
+		}
+		for i := 0; i < items; i++ {
+			g.Add(&url.URL{Path: fmt.Sprintf("/items/%d/reviews", i)})
+		}
+		return g
+	}
+	empty := train(0, 0)
+	baseline := train(500, 500)
+	same := train(1000, 1000)
+	shifted := train(900, 100)
+	onlyUsers := train(500, 0)
+	onlyItems := train(0, 500)
+
+	if d := Distance(baseline, same); d > 0.01 {
+		t.Errorf("expected the same distribution to be close, got %v", d)
+	}
+	if d := Distance(onlyUsers, onlyItems); math.Abs(d-1) > 1e-9 {
+		t.Errorf("expected disjoint groups to be 1 apart, got %v", d)
+	}
+	if d := Distance(empty, empty); d != 0 {
+		t.Errorf("expected empty Groupers to be identical, got %v", d)
+	}
+	if d := Distance(empty, baseline); d != 1 {
+		t.Errorf("expected an empty Grouper to be 1 away, got %v", d)
+	}
+
+	d := Distance(baseline, shifted)
+	if d <= 0.1 || d >= 1 {
+		t.Errorf("expected a shift of traffic to be a partial distance, got %v", d)
+	}
+	if back := Distance(shifted, baseline); math.Abs(back-d) > 1e-12 {
+		t.Errorf("expected the distance to be symmetric, got %v and %v", d, back)
+	}
+	if s := SnapshotDistance(baseline.Snapshot(), shifted.Snapshot()); math.Abs(s-d) > 1e-12 {
+		t.Errorf("expected snapshots to be as far apart as their Groupers, got %v and %v", s, d)
+	}
+}
@@ -0,0 +1,344 @@
+// Package drift detects when the structure of traffic departs from a baseline, such as after a site restructure or
+// a routing bug.
+//
+// A Monitor counts recent URLs by the pattern of their group in time buckets, and periodically compares the shares of
+// traffic of the recent window with those of a baseline Snapshot using groupurl.SnapshotDistance. Hooks, and
+// optionally a webhook, are notified once when the distance exceeds the threshold, and again only after it has fallen
+// back below it.
+package drift
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/trustleast/groupurl"
+)
+
+const (
+	_defaultWindow  = time.Hour
+	_defaultBuckets = 12
+	_defaultMinURLs = 1000
+	// _maxChanges is the number of groups whose share changed the most reported with an Event.
+	_maxChanges = 10
+)
+
+type (
+	// Event describes traffic that drifted from the baseline.
+	Event struct {
+		Time      time.Time `json:"time"`
+		Distance  float64   `json:"distance"`
+		Threshold float64   `json:"threshold"`
+		// URLs is the number of URLs in the recent window.
+		URLs int `json:"urls"`
+		// Changes holds the groups whose share of traffic changed the most, largest change first.
+		Changes []Change `json:"changes"`
+	}
+
+	// Change is the share of traffic of a group in the baseline and in the recent window.
+	Change struct {
+		Pattern  string  `json:"pattern"`
+		Baseline float64 `json:"baseline"`
+		Recent   float64 `json:"recent"`
+	}
+
+	// Monitor compares recent traffic with a baseline. It is safe for concurrent use, as long as the pattern
+	// function it was created with is.
+	Monitor struct {
+		baseline  groupurl.Snapshot
+		pattern   func(*url.URL) string
+		threshold float64
+		window    time.Duration
+		buckets   int
+		minURLs   int
+		interval  time.Duration
+		hooks     []func(Event)
+		webhook   string
+		client    *http.Client
+		onError   func(error)
+		now       func() time.Time
+
+		mu       sync.Mutex
+		counts   []bucket
+		drifting bool
+	}
+
+	bucket struct {
+		start  time.Time
+		counts map[string]int
+	}
+
+	Option func(*Monitor) error
+)
+
+// WithWindow sets the period of recent traffic compared with the baseline, and the number of buckets it is counted
+// in. The oldest bucket is dropped as a new one starts, so the window slides by window/buckets at a time.
+// Defaults to an hour in 12 buckets.
+func WithWindow(window time.Duration, buckets int) Option {
+	return func(m *Monitor) error {
+		if window <= 0 || buckets <= 0 {
+			return fmt.Errorf("invalid window of %d buckets over %s", buckets, window)
+		}
+		m.window = window
+		m.buckets = buckets
+		return nil
+	}
+}
+
+// WithMinURLs sets the number of URLs the recent window must hold to be compared, 1000 by default, so that a quiet
+// period is not mistaken for drift.
+func WithMinURLs(n int) Option {
+	return func(m *Monitor) error {
+		m.minURLs = n
+		return nil
+	}
+}
+
+// WithInterval sets how often Run compares the recent window with the baseline, once per bucket by default.
+func WithInterval(interval time.Duration) Option {
+	return func(m *Monitor) error {
+		if interval <= 0 {
+			return fmt.Errorf("interval must be positive, got %s", interval)
+		}
+		m.interval = interval
+		return nil
+	}
+}
+
+// WithHook calls f synchronously with every Event.
+func WithHook(f func(Event)) Option {
+	return func(m *Monitor) error {
+		m.hooks = append(m.hooks, f)
+		return nil
+	}
+}
+
+// WithWebhook posts every Event as JSON to a webhook.
+func WithWebhook(webhook string) Option {
+	return func(m *Monitor) error {
+		if _, err := url.ParseRequestURI(webhook); err != nil {
+			return fmt.Errorf("invalid webhook: %w", err)
+		}
+		m.webhook = webhook
+		return nil
+	}
+}
+
+// WithHTTPClient sets the client used to post to the webhook.
+func WithHTTPClient(client *http.Client) Option {
+	return func(m *Monitor) error {
+		m.client = client
+		return nil
+	}
+}
+
+// WithErrorHook calls f with the errors posting to the webhook.
+func WithErrorHook(f func(error)) Option {
+	return func(m *Monitor) error {
+		m.onError = f
+		return nil
+	}
+}
+
+// New creates a Monitor comparing recent traffic with baseline, which fires once the distance exceeds threshold.
+// pattern returns the group pattern of a URL, such as the Pattern method of the Grouper or FrozenGrouper the
+// baseline was taken from.
+func New(baseline groupurl.Snapshot, pattern func(*url.URL) string, threshold float64, options ...Option) (*Monitor, error) {
+	if threshold <= 0 || threshold >= 1 {
+		return nil, fmt.Errorf("threshold must be between 0 and 1, got %v", threshold)
+	}
+	m := &Monitor{
+		baseline:  baseline,
+		pattern:   pattern,
+		threshold: threshold,
+		window:    _defaultWindow,
+		buckets:   _defaultBuckets,
+		minURLs:   _defaultMinURLs,
+		client:    http.DefaultClient,
+		now:       time.Now,
+	}
+	for _, option := range options {
+		if err := option(m); err != nil {
+			return nil, err
+		}
+	}
+	if m.interval == 0 {
+		m.interval = m.bucketSize()
+	}
+	return m, nil
+}
+
+// Record counts a URL towards the recent window.
+func (m *Monitor) Record(u *url.URL) {
+	pattern := m.pattern(u)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.currentBucket().counts[pattern]++
+}
+
+// Check compares the recent window with the baseline and returns the resulting Event, along with whether traffic is
+// drifting. Hooks are only notified when traffic starts drifting.
+func (m *Monitor) Check(ctx context.Context) (Event, bool) {
+	m.mu.Lock()
+	recent := m.recent()
+	event := Event{
+		Time:      m.now(),
+		Threshold: m.threshold,
+	}
+	for _, grp := range recent.Groups {
+		event.URLs += grp.Count
+	}
+	if event.URLs < m.minURLs {
+		m.mu.Unlock()
+		return event, false
+	}
+	event.Distance = groupurl.SnapshotDistance(m.baseline, recent)
+	event.Changes = changes(m.baseline, recent)
+
+	drifting := event.Distance > m.threshold
+	started := drifting && !m.drifting
+	m.drifting = drifting
+	m.mu.Unlock()
+
+	if started {
+		m.fire(ctx, event)
+	}
+	return event, drifting
+}
+
+// Run checks for drift every interval until the context is done, and returns its error.
+func (m *Monitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			m.Check(ctx)
+		}
+	}
+}
+
+func (m *Monitor) fire(ctx context.Context, event Event) {
+	for _, hook := range m.hooks {
+		hook(event)
+	}
+	if m.webhook == "" {
+		return
+	}
+	if err := m.post(ctx, event); err != nil && m.onError != nil {
+		m.onError(err)
+	}
+}
+
+func (m *Monitor) post(ctx context.Context, event Event) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.webhook, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post drift event: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+func (m *Monitor) bucketSize() time.Duration {
+	return m.window / time.Duration(m.buckets)
+}
+
+// currentBucket returns the bucket for the current time, rotating out buckets that left the window.
+// The caller must hold m.mu.
+func (m *Monitor) currentBucket() bucket {
+	start := m.now().Truncate(m.bucketSize())
+	if n := len(m.counts); n > 0 && m.counts[n-1].start.Equal(start) {
+		return m.counts[n-1]
+	}
+	b := bucket{
+		start:  start,
+		counts: make(map[string]int),
+	}
+	m.counts = append(m.expire(), b)
+	return b
+}
+
+// expire drops the buckets that left the window. The caller must hold m.mu.
+func (m *Monitor) expire() []bucket {
+	cutoff := m.now().Add(-m.window)
+	for len(m.counts) > 0 && !m.counts[0].start.After(cutoff) {
+		m.counts = m.counts[1:]
+	}
+	return m.counts
+}
+
+// recent returns the counts of the window as a Snapshot. The caller must hold m.mu.
+func (m *Monitor) recent() groupurl.Snapshot {
+	counts := make(map[string]int)
+	for _, b := range m.expire() {
+		for pattern, count := range b.counts {
+			counts[pattern] += count
+		}
+	}
+	s := groupurl.Snapshot{Groups: make([]groupurl.SnapshotGroup, 0, len(counts))}
+	for pattern, count := range counts {
+		s.Groups = append(s.Groups, groupurl.SnapshotGroup{Pattern: pattern, Count: count})
+	}
+	return s
+}
+
+// changes returns the groups whose share of traffic differs the most between two snapshots.
+func changes(baseline, recent groupurl.Snapshot) []Change {
+	baseShares, recentShares := shares(baseline), shares(recent)
+	var all []Change
+	for pattern, share := range baseShares {
+		all = append(all, Change{Pattern: pattern, Baseline: share, Recent: recentShares[pattern]})
+	}
+	for pattern, share := range recentShares {
+		if _, ok := baseShares[pattern]; !ok {
+			all = append(all, Change{Pattern: pattern, Recent: share})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		di, dj := math.Abs(all[i].Recent-all[i].Baseline), math.Abs(all[j].Recent-all[j].Baseline)
+		if di != dj {
+			return di > dj
+		}
+		return all[i].Pattern < all[j].Pattern
+	})
+	if len(all) > _maxChanges {
+		all = all[:_maxChanges]
+	}
+	return all
+}
+
+func shares(s groupurl.Snapshot) map[string]float64 {
+	var total int
+	for _, grp := range s.Groups {
+		total += grp.Count
+	}
+	shares := make(map[string]float64, len(s.Groups))
+	if total == 0 {
+		return shares
+	}
+	for _, grp := range s.Groups {
+		shares[grp.Pattern] += float64(grp.Count) / float64(total)
+	}
+	return shares
+}
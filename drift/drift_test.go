@@ -0,0 +1,119 @@
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/trustleast/groupurl"
+)
+
+func pathPattern(u *url.URL) string {
+	return u.Path
+}
+
+func baseline() groupurl.Snapshot {
+	return groupurl.Snapshot{Groups: []groupurl.SnapshotGroup{
+		{Pattern: "/a", Count: 50},
+		{Pattern: "/b", Count: 50},
+	}}
+}
+
+func record(m *Monitor, path string, n int) {
+	for i := 0; i < n; i++ {
+		m.Record(&url.URL{Path: path})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var events []Event
+	m, err := New(baseline(), pathPattern, 0.3,
+		WithMinURLs(10),
+		WithWindow(time.Hour, 4),
+		WithHook(func(e Event) { events = append(events, e) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.now = func() time.Time { return now }
+
+	record(m, "/a", 5)
+	if _, drifting := m.Check(context.Background()); drifting {
+		t.Fatal("drifting below the minimum number of URLs")
+	}
+	record(m, "/b", 5)
+	if e, drifting := m.Check(context.Background()); drifting || e.Distance != 0 {
+		t.Fatalf("got distance %v, drifting %v for traffic matching the baseline", e.Distance, drifting)
+	}
+
+	record(m, "/c", 30)
+	e, drifting := m.Check(context.Background())
+	if !drifting {
+		t.Fatalf("not drifting at distance %v", e.Distance)
+	}
+	if e.URLs != 40 || e.Changes[0].Pattern != "/c" || e.Changes[0].Recent != 0.75 {
+		t.Errorf("unexpected event %+v", e)
+	}
+	m.Check(context.Background())
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want a single one while drifting", len(events))
+	}
+
+	// Once the window has slid past the drifting traffic, the monitor re-arms.
+	now = now.Add(time.Hour)
+	record(m, "/a", 10)
+	record(m, "/b", 10)
+	if _, drifting := m.Check(context.Background()); drifting {
+		t.Fatal("still drifting after the window slid")
+	}
+	record(m, "/d", 100)
+	m.Check(context.Background())
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want another after re-arming", len(events))
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	events := make(chan Event, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e Event
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Error(err)
+		}
+		events <- e
+	}))
+	defer srv.Close()
+
+	m, err := New(baseline(), pathPattern, 0.5, WithMinURLs(1), WithWebhook(srv.URL),
+		WithErrorHook(func(err error) { t.Error(err) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	record(m, "/c", 10)
+	m.Check(context.Background())
+
+	e := <-events
+	if e.Distance != 1 || e.Threshold != 0.5 || e.URLs != 10 {
+		t.Errorf("unexpected event %+v", e)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	for name, options := range map[string][]Option{
+		"window":   {WithWindow(0, 4)},
+		"interval": {WithInterval(-time.Second)},
+		"webhook":  {WithWebhook("not a url")},
+	} {
+		if _, err := New(baseline(), pathPattern, 0.5, options...); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := New(baseline(), pathPattern, 1); err == nil {
+		t.Error("expected an error for a threshold of 1")
+	}
+}
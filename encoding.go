@@ -0,0 +1,115 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	_labelOpen  = "<"
+	_labelClose = ">"
+)
+
+// _segmentEscaper escapes the characters of kept segments that would make encoded output ambiguous.
+var _segmentEscaper = strings.NewReplacer("%", "%25", "/", "%2F", "<", "%3C", ">", "%3E")
+
+// SimplifiedSegment is a segment of a simplified path, as decoded by ParseSimplified.
+// Value is either a label, such as Number, or a segment of the URL that was kept as is.
+type SimplifiedSegment struct {
+	Value string
+	Label bool
+}
+
+// WithEncodedLabels makes SimplifyPath tell labels apart from the segments it keeps, which is otherwise ambiguous
+// when a site has a segment named like a label, such as `/Number`. Labels are wrapped in angle brackets, as in
+// `/users/<Number>`, and the `%`, `/`, `<` and `>` characters of kept segments are percent encoded, so that a
+// segment literally named `<Number>` is output as `%3CNumber%3E`. ParseSimplified decodes the output.
+// Explain uses the same encoding, while group patterns, which are only made of labels, are not encoded.
+func WithEncodedLabels() Option {
+	return func(g *Grouper) error {
+		g.tree.encodeLabels = true
+		return nil
+	}
+}
+
+// ParseSimplified decodes a path simplified WithEncodedLabels into its segments.
+func ParseSimplified(simplified string) ([]SimplifiedSegment, error) {
+	var segments []SimplifiedSegment
+	rest := strings.TrimPrefix(simplified, "/")
+	for rest != "" {
+		if strings.HasPrefix(rest, _labelOpen) {
+			// Labels may span several segments, such as YYYY/MM/DD, so they end at the closing bracket.
+			end := strings.Index(rest, _labelClose+"/")
+			if end < 0 {
+				if !strings.HasSuffix(rest, _labelClose) {
+					return nil, fmt.Errorf("unterminated label in %q", simplified)
+				}
+				end = len(rest) - len(_labelClose)
+			}
+			label := rest[len(_labelOpen):end]
+			if label == "" {
+				return nil, fmt.Errorf("empty label in %q", simplified)
+			}
+			segments = append(segments, SimplifiedSegment{Value: label, Label: true})
+			rest = strings.TrimPrefix(rest[end+len(_labelClose):], "/")
+			continue
+		}
+
+		segment, next, _ := strings.Cut(rest, "/")
+		if strings.ContainsAny(segment, _labelOpen+_labelClose) {
+			return nil, fmt.Errorf("unescaped label bracket in segment %q", segment)
+		}
+		value, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, fmt.Errorf("invalid segment %q: %w", segment, err)
+		}
+		segments = append(segments, SimplifiedSegment{Value: value})
+		rest = next
+	}
+	return segments, nil
+}
+
+// encodeLabel returns the output of a segment replaced by a label.
+func (c treeConfig) encodeLabel(label string) string {
+	if !c.encodeLabels {
+		return label
+	}
+	return _labelOpen + label + _labelClose
+}
+
+// encodeToken returns the output of a segment that is kept.
+func (c treeConfig) encodeToken(token string) string {
+	if !c.encodeLabels {
+		return token
+	}
+	return _segmentEscaper.Replace(token)
+}
+
+// encodeTail encodes the pattern of a wildcard tail, whose segments are kept but for the trailing wildcard.
+func (c treeConfig) encodeTail(pattern string) string {
+	if !c.encodeLabels {
+		return pattern
+	}
+	segments := pathSegments(pattern)
+	last := len(segments) - 1
+	for i := range segments[:last] {
+		segments[i] = c.encodeToken(segments[i])
+	}
+	segments[last] = c.encodeLabel(segments[last])
+	return "/" + strings.Join(segments, "/")
+}
+
+// decode returns a simplified path without its encoding, so that it can be matched against globs of labels.
+func (c treeConfig) decode(simplified string) string {
+	if !c.encodeLabels {
+		return simplified
+	}
+	segments, err := ParseSimplified(simplified)
+	if err != nil {
+		return simplified
+	}
+	return "/" + strings.Join(mapSlice(segments, func(s SimplifiedSegment) string {
+		return s.Value
+	}), "/")
+}
@@ -0,0 +1,97 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestEncodedLabels(t *testing.T) {
+	g, err := New(WithEncodedLabels(), WithWildcardTails("/static"), WithPinnedTokens("Words"),
+		WithOverrideRules(OverrideRule{Glob: "/legacy/**", Pattern: "/legacy/Archive"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		for _, p := range []string{fmt.Sprintf("/pages/post-%d", i), "/pages/Words", "/static/css/site.css"} {
+			u, err := url.Parse(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.Add(u)
+		}
+	}
+
+	for path, expected := range map[string]string{
+		"/pages/post-7":      "/pages/<Words>",
+		"/pages/Words":       "/pages/Words",
+		"/pages/%3CWords%3E": "/pages/%3CWords%3E",
+		"/static/js/app.js":  "/static/<**>",
+		"/unseen/100%25":     "/unseen/100%25",
+		"/legacy/2019/a":     "/legacy/<Archive>",
+	} {
+		u, err := url.Parse(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := g.SimplifyPath(u); got != expected {
+			t.Fatalf("expected %s for %s, got %s", expected, path, got)
+		}
+		if got := g.Freeze().SimplifyPath(u); got != expected {
+			t.Fatalf("expected %s for %s when frozen, got %s", expected, path, got)
+		}
+		if got := g.Explain(u).Simplified; got != expected {
+			t.Fatalf("expected %s for %s when explained, got %s", expected, path, got)
+		}
+	}
+
+	table, err := g.DecisionTable()
+	if err == nil {
+		t.Fatal("expected error for wildcard tails")
+	}
+	g, err = New(WithEncodedLabels())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/users/%d", i)})
+	}
+	if table, err = g.DecisionTable(); err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewDecisionEvaluator(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := e.SimplifyPath("/users/7"); got != "/users/<Number>" {
+		t.Fatalf("expected the decision table to encode labels, got %s", got)
+	}
+}
+
+func TestParseSimplified(t *testing.T) {
+	segments, err := ParseSimplified("/users/<Number>/%3CNumber%3E/<YYYY/MM/DD>/a%2Fb/100%25")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []SimplifiedSegment{
+		{Value: "users"},
+		{Value: "Number", Label: true},
+		{Value: "<Number>"},
+		{Value: "YYYY/MM/DD", Label: true},
+		{Value: "a/b"},
+		{Value: "100%"},
+	}
+	if !reflect.DeepEqual(segments, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, segments)
+	}
+
+	if segments, err := ParseSimplified("/"); err != nil || len(segments) != 0 {
+		t.Fatalf("expected no segments for the root, got %+v, %v", segments, err)
+	}
+	for _, invalid := range []string{"/<Number", "/<>", "/a>b", "/100%"} {
+		if _, err := ParseSimplified(invalid); err == nil {
+			t.Fatalf("expected error for %s", invalid)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Package enrich adds the group of a URL to log events, for log pipelines such as Vector or Fluent Bit that call out
+// to an HTTP service from their transform stages.
+//
+// The Handler serves the following endpoints, over TCP or a Unix socket:
+//
+//	POST /enrich        enriches the JSON object in the request body
+//	POST /enrich/batch  enriches the newline delimited JSON objects in the request body, in the same order
+//
+// The URL is read from the "url" field of each event and its group written to the "url_group" field, e.g.
+//
+//	$ curl -s --data-binary '{"url":"/users/42","status":200}' localhost:8081/enrich
+//	{"status":200,"url":"/users/42","url_group":"/users/Number"}
+//
+// Events without the URL field, or whose URL cannot be parsed, are returned unchanged. Batching events keeps the
+// overhead of a request per event out of the pipeline.
+package enrich
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	_defaultURLField   = "url"
+	_defaultGroupField = "url_group"
+	_maxEventSize      = 1024 * 1024
+)
+
+type (
+	// Handler enriches log events with the groups of their URLs.
+	Handler struct {
+		mux        *http.ServeMux
+		group      func(*url.URL) string
+		urlField   []string
+		groupField []string
+	}
+
+	Option func(*Handler) error
+)
+
+// WithURLField sets the field the URL is read from. Nested fields are separated by dots, such as "http.url".
+func WithURLField(field string) Option {
+	return func(h *Handler) error {
+		path, err := fieldPath(field)
+		if err != nil {
+			return err
+		}
+		h.urlField = path
+		return nil
+	}
+}
+
+// WithGroupField sets the field the group is written to. Nested fields are separated by dots, and missing objects
+// along the way are created.
+func WithGroupField(field string) Option {
+	return func(h *Handler) error {
+		path, err := fieldPath(field)
+		if err != nil {
+			return err
+		}
+		h.groupField = path
+		return nil
+	}
+}
+
+// New creates a Handler that groups URLs with group, such as the SimplifyPath method of a FrozenGrouper, a
+// watch.Watcher or a remote.Client, or the Record method of a serve.Server to keep learning from the events.
+// group must be safe for concurrent use.
+func New(group func(*url.URL) string, options ...Option) (*Handler, error) {
+	h := &Handler{
+		mux:        http.NewServeMux(),
+		group:      group,
+		urlField:   []string{_defaultURLField},
+		groupField: []string{_defaultGroupField},
+	}
+	for _, option := range options {
+		if err := option(h); err != nil {
+			return nil, err
+		}
+	}
+	h.mux.HandleFunc("/enrich", h.handleEnrich)
+	h.mux.HandleFunc("/enrich/batch", h.handleBatch)
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// Enrich adds the group of the URL of an event to it.
+func (h *Handler) Enrich(event map[string]any) {
+	raw, ok := lookup(event, h.urlField).(string)
+	if !ok {
+		return
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return
+	}
+	set(event, h.groupField, h.group(u))
+}
+
+func (h *Handler) handleEnrich(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	event, err := decodeEvent(io.LimitReader(r.Body, _maxEventSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode event: %s", err), http.StatusBadRequest)
+		return
+	}
+	h.Enrich(event)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(event); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) handleBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), _maxEventSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		event, err := decodeEvent(bytes.NewReader(scanner.Bytes()))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to decode event on line %d: %s", line, err), http.StatusBadRequest)
+			return
+		}
+		h.Enrich(event)
+		if err := enc.Encode(event); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		http.Error(w, fmt.Sprintf("failed to read body: %s", err), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Write(out.Bytes())
+}
+
+// decodeEvent decodes a JSON object, keeping numbers as they were written so that large integers survive.
+func decodeEvent(r io.Reader) (map[string]any, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var event map[string]any
+	if err := dec.Decode(&event); err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, errors.New("event is not an object")
+	}
+	return event, nil
+}
+
+func fieldPath(field string) ([]string, error) {
+	path := strings.Split(field, ".")
+	for _, name := range path {
+		if name == "" {
+			return nil, fmt.Errorf("invalid field %q", field)
+		}
+	}
+	return path, nil
+}
+
+// lookup returns the value of a nested field, or nil if it does not exist.
+func lookup(event map[string]any, path []string) any {
+	var v any = event
+	for _, name := range path {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = obj[name]
+	}
+	return v
+}
+
+// set writes a nested field, replacing values along the way that are not objects.
+func set(event map[string]any, path []string, value any) {
+	obj := event
+	for _, name := range path[:len(path)-1] {
+		next, ok := obj[name].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			obj[name] = next
+		}
+		obj = next
+	}
+	obj[path[len(path)-1]] = value
+}
@@ -0,0 +1,78 @@
+package enrich
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func group(u *url.URL) string {
+	return "/group" + u.Path
+}
+
+func TestEnrich(t *testing.T) {
+	h, err := New(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/enrich", strings.NewReader(`{"url":"/a?b=c","id":12345678901234567890}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if got, want := rec.Body.String(), `{"id":12345678901234567890,"url":"/a?b=c","url_group":"/group/a"}`+"\n"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestEnrichBatch(t *testing.T) {
+	h, err := New(group, WithURLField("http.url"), WithGroupField("labels.group"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := `{"http":{"url":"/a"}}
+
+{"http":{"url":"/b"},"labels":"replaced"}
+{"message":"no url"}
+`
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/enrich/batch", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	want := `{"http":{"url":"/a"},"labels":{"group":"/group/a"}}
+{"http":{"url":"/b"},"labels":{"group":"/group/b"}}
+{"message":"no url"}
+`
+	if got := rec.Body.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEnrichErrors(t *testing.T) {
+	h, err := New(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		method, path, body string
+		code               int
+	}{
+		{http.MethodGet, "/enrich", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/enrich", "[1]", http.StatusBadRequest},
+		{http.MethodPost, "/enrich", "null", http.StatusBadRequest},
+		{http.MethodPost, "/enrich/batch", "{}\nnot json\n", http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+		if rec.Code != tc.code {
+			t.Errorf("%s %s %q: got %d, want %d", tc.method, tc.path, tc.body, rec.Code, tc.code)
+		}
+	}
+	if _, err := New(group, WithURLField("a..b")); err == nil {
+		t.Error("expected an error for an invalid field")
+	}
+}
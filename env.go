@@ -0,0 +1,144 @@
+package groupurl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Environment variables read by NewFromEnv.
+const (
+	// EnvPreset names a built-in set of classifiers as accepted by WithPreset: "default", "strict" for
+	// StrictDefaultClassifiers, "loose" for LooseDefaultClassifiers, "assets" for the default
+	// classifiers preceded by AssetClassifiers, "probes" for the default classifiers preceded by ProbeClassifier,
+	// "api" for APIClassifiers, "commerce" for CommerceClassifiers, or "blog" for BlogClassifiers.
+	EnvPreset = "GROUPURL_PRESET"
+	// EnvClassifiers is a JSON list of ClassifierConfig, which takes precedence over EnvPreset.
+	EnvClassifiers = "GROUPURL_CLASSIFIERS"
+	// EnvClassifiersFile is the path of a file holding a JSON list of ClassifierConfig.
+	EnvClassifiersFile = "GROUPURL_CLASSIFIERS_FILE"
+	// EnvCardinalityLimit is an integer passed to WithCardinalityLimit.
+	EnvCardinalityLimit = "GROUPURL_CARDINALITY_LIMIT"
+	// EnvSignificanceThreshold is a float passed to WithSignificanceThreshold.
+	EnvSignificanceThreshold = "GROUPURL_SIGNIFICANCE_THRESHOLD"
+	// EnvSamplingRate is a float passed to WithSampling.
+	EnvSamplingRate = "GROUPURL_SAMPLING_RATE"
+	// EnvAddCacheSize is an integer passed to WithAddCache.
+	EnvAddCacheSize = "GROUPURL_ADD_CACHE_SIZE"
+	// EnvSimplifyCacheSize is an integer passed to WithSimplifyCache.
+	EnvSimplifyCacheSize = "GROUPURL_SIMPLIFY_CACHE_SIZE"
+	// EnvLabelVocabulary is a list of Label=word pairs parsed by ParseLabelVocabulary and passed to
+	// WithLabelVocabulary.
+	EnvLabelVocabulary = "GROUPURL_LABEL_VOCABULARY"
+	// EnvPathPolicy is a PathPolicy parsed by ParsePathPolicy and passed to WithPathPolicy.
+	EnvPathPolicy = "GROUPURL_PATH_POLICY"
+)
+
+// NewFromEnv creates a new Grouper configured from the GROUPURL_* environment variables, so that deployments
+// can tune it without code changes. Unset variables keep their defaults. The provided options are applied
+// after the environment and take precedence over it.
+func NewFromEnv(options ...Option) (Grouper, error) {
+	return newFromEnv(os.LookupEnv, options...)
+}
+
+func newFromEnv(lookup func(string) (string, bool), options ...Option) (Grouper, error) {
+	envOptions, err := optionsFromEnv(lookup)
+	if err != nil {
+		return Grouper{}, err
+	}
+	return New(append(envOptions, options...)...)
+}
+
+func optionsFromEnv(lookup func(string) (string, bool)) ([]Option, error) {
+	var options []Option
+
+	classifiers, err := classifiersFromEnv(lookup)
+	if err != nil {
+		return nil, err
+	}
+	if classifiers != nil {
+		options = append(options, WithClassifiers(classifiers))
+	}
+
+	if v, ok := lookup(EnvCardinalityLimit); ok {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", EnvCardinalityLimit, err)
+		}
+		options = append(options, WithCardinalityLimit(limit))
+	}
+	if v, ok := lookup(EnvSignificanceThreshold); ok {
+		threshold, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", EnvSignificanceThreshold, err)
+		}
+		options = append(options, WithSignificanceThreshold(threshold))
+	}
+	if v, ok := lookup(EnvSamplingRate); ok {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", EnvSamplingRate, err)
+		}
+		options = append(options, WithSampling(rate))
+	}
+	if v, ok := lookup(EnvAddCacheSize); ok {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", EnvAddCacheSize, err)
+		}
+		options = append(options, WithAddCache(size))
+	}
+	if v, ok := lookup(EnvSimplifyCacheSize); ok {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", EnvSimplifyCacheSize, err)
+		}
+		options = append(options, WithSimplifyCache(size))
+	}
+	if v, ok := lookup(EnvLabelVocabulary); ok {
+		vocabulary, err := ParseLabelVocabulary(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", EnvLabelVocabulary, err)
+		}
+		options = append(options, WithLabelVocabulary(vocabulary))
+	}
+	if v, ok := lookup(EnvPathPolicy); ok {
+		policy, err := ParsePathPolicy(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", EnvPathPolicy, err)
+		}
+		options = append(options, WithPathPolicy(policy))
+	}
+	return options, nil
+}
+
+// classifiersFromEnv returns nil if none of the classifier variables are set.
+func classifiersFromEnv(lookup func(string) (string, bool)) ([]PathTokenClassifier, error) {
+	var raw []byte
+	if v, ok := lookup(EnvClassifiers); ok {
+		raw = []byte(v)
+	} else if path, ok := lookup(EnvClassifiersFile); ok {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", EnvClassifiersFile, err)
+		}
+		raw = b
+	}
+	if raw != nil {
+		var configs []ClassifierConfig
+		if err := json.Unmarshal(raw, &configs); err != nil {
+			return nil, fmt.Errorf("failed to parse classifiers: %w", err)
+		}
+		return ClassifiersFromConfig(configs)
+	}
+
+	if name, ok := lookup(EnvPreset); ok {
+		preset, ok := _presets[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown %s %q", EnvPreset, name)
+		}
+		return preset(), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,93 @@
+package groupurl
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	env := map[string]string{
+		EnvClassifiers:           `[{"name": "regex", "config": {"pattern": "^[a-z0-9]+(/|$)", "label": "ID", "important": true}}]`,
+		EnvCardinalityLimit:      "2",
+		EnvSignificanceThreshold: "0.5",
+		EnvAddCacheSize:          "10",
+		EnvPathPolicy:            "reject",
+	}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	g, err := newFromEnv(lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"/a", "/b", "/c", "/d", "/a"} {
+		u, err := url.Parse(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+
+	u, err := url.Parse("/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.SimplifyPath(u); got != "/ID" {
+		t.Fatalf("expected the cardinality limit to group tokens, got %s", got)
+	}
+	if stats := g.AddCacheStats(); stats.Hits != 1 {
+		t.Fatalf("expected the add cache to be configured, got %+v", stats)
+	}
+	if got := g.SimplifyPath(&url.URL{Path: "/a/../b"}); got != "/Rejected" {
+		t.Fatalf("expected the path policy to be configured, got %s", got)
+	}
+
+	env[EnvPathPolicy] = "strip"
+	if _, err := newFromEnv(lookup); err == nil {
+		t.Fatal("expected error for unknown path policy")
+	}
+	delete(env, EnvPathPolicy)
+	env[EnvSamplingRate] = "two"
+	if _, err := newFromEnv(lookup); err == nil {
+		t.Fatal("expected error for invalid sampling rate")
+	}
+	delete(env, EnvSamplingRate)
+	delete(env, EnvClassifiers)
+	env[EnvPreset] = "missing"
+	if _, err := newFromEnv(lookup); err == nil {
+		t.Fatal("expected error for unknown preset")
+	}
+}
+
+func TestSignificanceThreshold(t *testing.T) {
+	build := func(options ...Option) Grouper {
+		g, err := New(options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 200; i++ {
+			u, err := url.Parse("/" + string(rune('a'+i%20)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.Add(u)
+		}
+		return g
+	}
+
+	u, err := url.Parse("/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := build().SimplifyPath(u); got != "/Words" {
+		t.Fatalf("expected the default threshold to group, got %s", got)
+	}
+	if got := build(WithSignificanceThreshold(0.2)).SimplifyPath(u); got != "/a" {
+		t.Fatalf("expected a higher threshold to preserve tokens, got %s", got)
+	}
+	if _, err := New(WithSignificanceThreshold(2)); err == nil {
+		t.Fatal("expected error for an out of range threshold")
+	}
+}
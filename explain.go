@@ -0,0 +1,141 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Explanation describes how SimplifyPath arrives at the simplified form of a URL, segment by segment.
+// Override is the rule that simplified the path, if any, in which case Segments describe the pattern of the rule.
+type Explanation struct {
+	Path       string
+	Simplified string
+	Segments   []SegmentExplanation
+	Override   *OverrideRule
+}
+
+// SegmentExplanation describes how a single segment of a path was simplified.
+// Count is the number of times the token was counted at its node, out of Total, across Distinct tokens.
+// Limit is the cardinality limit of the node, where 0 is unlimited and -1 means tokens are never kept.
+// Tuning is the last decision made by WithAutoTune, if enabled.
+// Classifier describes the classifier that emitted Label, if it is a DescribedClassifier.
+// Labels is the distribution of the labels the segments of the node were classified under.
+type SegmentExplanation struct {
+	Token    string
+	Label    string
+	Output   string
+	Kept     bool
+	Reason   string
+	Count    int
+	Total    int
+	Distinct int
+	Limit    int
+	Tuning   string
+	Labels   []LabelCount
+
+	Classifier ClassifierInfo
+}
+
+// Explain reports why each segment of a URL is kept or replaced by SimplifyPath.
+func (g Grouper) Explain(u *url.URL) Explanation {
+	hardened, ok := g.tree.harden(u)
+	if !ok {
+		return Explanation{Path: u.Path, Simplified: g.tree.rejected()}
+	}
+	u = hardened
+	if rule, ok := g.overrides.match(u.Path); ok {
+		return Explanation{
+			Path:       u.Path,
+			Simplified: rule.simplify(u.Path, g.tree),
+			Segments:   rule.explain(u.Path, g.tree),
+			Override:   &rule,
+		}
+	}
+	if pattern, ok := g.tails.match(u.Path); ok {
+		return Explanation{
+			Path:       u.Path,
+			Simplified: g.tree.encodeTail(pattern),
+			Segments:   g.tails.explain(u.Path, g.tree),
+		}
+	}
+	tokens := labelPathTokens(u.Path, g.classifiers)
+	t := lookupTree(g.trees, u.Path, g.tree)
+	segments := t.explain(tokens)
+	for i := range segments {
+		segments[i].Classifier = g.labelInfo[segments[i].Label]
+	}
+	return Explanation{
+		Path: u.Path,
+		Simplified: "/" + strings.Join(mapSlice(segments, func(s SegmentExplanation) string {
+			return s.Output
+		}), "/"),
+		Segments: segments,
+	}
+}
+
+// explain follows the same steps as path.
+func (t urlTree) explain(tokens []pathToken) []SegmentExplanation {
+	var segments []SegmentExplanation
+	current := t.Root
+	for idx, token := range tokens {
+		token = current.route(token)
+		child, ok := current.children.get(token.label.parentOrSelf())
+		if !ok {
+			for i := idx; i < len(tokens); i++ {
+				segments = append(segments, SegmentExplanation{
+					Token:  tokens[i].token,
+					Label:  tokens[i].label.Value,
+					Output: t.unseenAt(tokens[i], i),
+					Kept:   t.keepsUnseen(tokens[i], i),
+					Reason: "path has not been seen",
+				})
+			}
+			return segments
+		}
+
+		segment := SegmentExplanation{
+			Token:    token.token,
+			Label:    child.specificLabel.Value,
+			Output:   t.encodeLabel(child.specificLabel.Value),
+			Count:    child.tokenCounts.get(token.token),
+			Total:    child.tokenCounts.total,
+			Distinct: child.tokenCounts.population(),
+			Limit:    child.tokenCounts.limit,
+			Labels:   child.labelDistribution(),
+		}
+		if child.tuning != nil {
+			segment.Tuning = child.tuning.decision
+		}
+		keep, overridden := t.override(token.token)
+		switch {
+		case !overridden && t.cold(child):
+			segment.Output = t.heuristic(token, idx)
+			segment.Kept = t.keepsHeuristic(token, idx)
+			segment.Reason = fmt.Sprintf("node was seen fewer than %d times", t.coldStart)
+		case overridden && keep:
+			segment.Output = t.encodeToken(token.token)
+			segment.Kept = true
+			segment.Reason = "token is pinned"
+		case overridden:
+			segment.Reason = "token is redacted"
+		case !t.important(child):
+			segment.Reason = "label is not important"
+		case child.merged:
+			segment.Reason = "tokens were merged"
+		case child.tokenCounts.limit > 0 && child.tokenCounts.population() >= child.tokenCounts.limit:
+			segment.Reason = "cardinality limit reached"
+		case !t.anonymous(child, token.token):
+			segment.Reason = fmt.Sprintf("token was seen fewer than %d times", t.privacyK)
+		case t.isSignificant(child, token.token):
+			segment.Output = t.encodeToken(token.token)
+			segment.Kept = true
+			segment.Reason = "token is significant"
+		default:
+			segment.Reason = "token is not significant"
+		}
+		segments = append(segments, segment)
+		current = child
+	}
+	return segments
+}
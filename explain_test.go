@@ -0,0 +1,42 @@
+package groupurl
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	g, err := loadFixture("examples/test.urls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, raw := range []string{
+		"https://example.com/2006/05/19/dish-retail-snub-starved-perennial",
+		"https://example.com/unless/anything/index.html",
+		"https://example.com/a/b/c/d/e/f",
+	} {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := g.Explain(u)
+		if e.Simplified != g.SimplifyPath(u) {
+			t.Fatalf("expected %s to match SimplifyPath %s", e.Simplified, g.SimplifyPath(u))
+		}
+		for _, s := range e.Segments {
+			if s.Reason == "" {
+				t.Fatalf("expected a reason for every segment, got %+v", s)
+			}
+		}
+	}
+
+	u, err := url.Parse("https://example.com/2006/05/19/dish")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := g.Explain(u)
+	if len(e.Segments) != 2 || e.Segments[0].Reason != "label is not important" || e.Segments[1].Reason != "cardinality limit reached" {
+		t.Fatalf("unexpected explanation %+v", e)
+	}
+}
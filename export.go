@@ -0,0 +1,65 @@
+package groupurl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const _textReportHeader = "# groupurl text report v1"
+
+// TextOptions controls the content of ExportText reports.
+type TextOptions struct {
+	// Counts includes the number of URLs in each group.
+	// Leave it off for a pure route inventory that only changes when routes do.
+	Counts bool
+	// Tokens includes the significant tokens of each segment.
+	Tokens bool
+	// Tags includes the tags attached to each group with Tag, as sorted, comma separated key=value pairs, with "-"
+	// for groups without any.
+	Tags bool
+	// MinCount omits groups with fewer URLs.
+	MinCount int
+}
+
+// ExportText writes a canonical, line oriented report of the groups the Grouper has learned.
+// Each line holds one group and groups are written in a stable order, so two reports of the same data are byte for byte
+// identical and reports of different runs diff cleanly. Optional columns are separated by tabs.
+// Segment tokens are written as sorted, comma separated lists per segment joined by "/", with "*" for segments without any.
+func (g Grouper) ExportText(w io.Writer, opts TextOptions) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, _textReportHeader)
+
+	for _, grp := range g.groups() {
+		if grp.count < opts.MinCount {
+			continue
+		}
+
+		columns := []string{grp.pattern}
+		if opts.Counts {
+			columns = append(columns, strconv.Itoa(grp.count))
+		}
+		if opts.Tokens {
+			columns = append(columns, strings.Join(mapSlice(grp.segments, func(s groupSegment) string {
+				if len(s.tokens) == 0 {
+					return "*"
+				}
+				tokens := append([]string(nil), s.tokens...)
+				sort.Strings(tokens)
+				return strings.Join(tokens, ",")
+			}), "/"))
+		}
+		if opts.Tags {
+			tags := formatTags(g.Tags(grp.pattern))
+			if tags == "" {
+				tags = "-"
+			}
+			columns = append(columns, tags)
+		}
+		fmt.Fprintln(bw, strings.Join(columns, "\t"))
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,61 @@
+package groupurl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExportText(t *testing.T) {
+	g, err := loadFixture("examples/test.urls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var first, second strings.Builder
+	if err := g.ExportText(&first, TextOptions{Counts: true, Tokens: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.ExportText(&second, TextOptions{Counts: true, Tokens: true}); err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != second.String() {
+		t.Fatal("expected identical reports")
+	}
+
+	lines := strings.Split(strings.TrimSpace(first.String()), "\n")
+	if lines[0] != _textReportHeader {
+		t.Fatalf("expected header, got %s", lines[0])
+	}
+	var found bool
+	for _, line := range lines[1:] {
+		columns := strings.Split(line, "\t")
+		if len(columns) != 3 {
+			t.Fatalf("expected 3 columns, got %q", line)
+		}
+		if columns[0] == "/YYYY/MM/DD/Words" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected /YYYY/MM/DD/Words group in %s", first.String())
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	g, err := loadFixture("examples/test.urls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := g.ExportMarkdown(&sb); err != nil {
+		t.Fatal(err)
+	}
+
+	out := sb.String()
+	for _, expected := range []string{"## Top groups", "## Depth 4", "| `/YYYY/MM/DD/Words` | 200 | 14.3% | `/"} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("expected %q in %s", expected, out)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package groupurl
+
+import "fmt"
+
+// Fallback controls how SimplifyPath treats the segments of paths the Grouper has not learned.
+type Fallback int
+
+const (
+	// FallbackRaw keeps segments the Grouper has not learned as they are. This is the default.
+	FallbackRaw Fallback = iota
+	// FallbackLabels replaces segments the Grouper has not learned with their label, so that high cardinality
+	// values never leak into the output, at the cost of also hiding low cardinality ones.
+	FallbackLabels
+	// FallbackNearestTree simplifies paths with a number of segments the Grouper has never seen with the tree
+	// of the nearest number of segments, and replaces segments neither tree has learned with their label.
+	FallbackNearestTree
+)
+
+// WithFallback sets how SimplifyPath treats the segments of paths the Grouper has not learned.
+func WithFallback(fallback Fallback) Option {
+	return func(g *Grouper) error {
+		if fallback < FallbackRaw || fallback > FallbackNearestTree {
+			return fmt.Errorf("unknown fallback %d", fallback)
+		}
+		g.tree.fallback = fallback
+		return nil
+	}
+}
+
+// unseen returns the outputs for the segments from a position on, which the tree has not learned.
+func (t urlTree) unseen(tokens []pathToken, depth int) []string {
+	outputs := make([]string, 0, len(tokens)-depth)
+	for i := depth; i < len(tokens); i++ {
+		outputs = append(outputs, t.unseenAt(tokens[i], i))
+	}
+	return outputs
+}
+
+// unseenAt returns the output for a segment at a position the tree has not learned.
+func (t urlTree) unseenAt(token pathToken, depth int) string {
+	if t.coldStart > 0 {
+		return t.heuristic(token, depth)
+	}
+	if t.keepsUnseen(token, depth) {
+		return t.encodeToken(token.token)
+	}
+	return t.encodeLabel(token.label.Value)
+}
+
+// keepsUnseen reports whether a segment the tree has not learned is kept as is.
+func (t urlTree) keepsUnseen(token pathToken, depth int) bool {
+	if t.coldStart > 0 {
+		return t.keepsHeuristic(token, depth)
+	}
+	if keep, ok := t.override(token.token); ok {
+		return keep
+	}
+	return t.fallback == FallbackRaw && t.privacyK == 0
+}
+
+// nearestTree returns the tree whose key is closest to key, preferring shallower trees on ties.
+func nearestTree(trees map[int]urlTree, key int) (urlTree, bool) {
+	var (
+		nearest  urlTree
+		distance = -1
+		best     int
+	)
+	for k, t := range trees {
+		d := k - key
+		if d < 0 {
+			d = -d
+		}
+		if distance < 0 || d < distance || (d == distance && k < best) {
+			nearest, distance, best = t, d, k
+		}
+	}
+	return nearest, distance >= 0
+}
@@ -0,0 +1,48 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestFallback(t *testing.T) {
+	for _, test := range []struct {
+		fallback Fallback
+		expected string
+	}{
+		{FallbackRaw, "/users/12345/settings"},
+		{FallbackLabels, "/Words/Number/Words"},
+		{FallbackNearestTree, "/users/Number/Words"},
+	} {
+		g, err := New(WithFallback(test.fallback), WithSimplifyCache(10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 100; i++ {
+			u, err := url.Parse(fmt.Sprintf("/users/%d", i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.Add(u)
+		}
+
+		u, err := url.Parse("/users/12345/settings")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := g.SimplifyPath(u); got != test.expected {
+			t.Fatalf("expected %s with fallback %d, got %s", test.expected, test.fallback, got)
+		}
+		if got := g.Freeze().SimplifyPath(u); got != test.expected {
+			t.Fatalf("expected %s from a frozen copy with fallback %d, got %s", test.expected, test.fallback, got)
+		}
+		if got := g.Explain(u).Simplified; got != test.expected {
+			t.Fatalf("expected %s to be explained with fallback %d, got %s", test.expected, test.fallback, got)
+		}
+	}
+
+	if _, err := New(WithFallback(Fallback(-1))); err == nil {
+		t.Fatal("expected error for an unknown fallback")
+	}
+}
@@ -0,0 +1,160 @@
+package groupurl
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// FrozenGrouper is a read-only copy of a Grouper.
+// Unlike a Grouper it is safe for concurrent use, as long as the classifiers it was built with are.
+type FrozenGrouper struct {
+	classifiers []PathTokenClassifier
+	trees       map[int]urlTree
+	tree        treeConfig
+	tails       *wildcardTails
+	overrides   *overrideRules
+	ownership   *Ownership
+}
+
+// Freeze returns a read-only copy of the Grouper's current state.
+// The Grouper can keep learning afterwards without affecting the copy.
+func (g Grouper) Freeze() FrozenGrouper {
+	trees := make(map[int]urlTree, len(g.trees))
+	for key, t := range g.trees {
+		trees[key] = t.clone()
+	}
+	return FrozenGrouper{
+		classifiers: g.classifiers,
+		trees:       trees,
+		tree:        g.tree,
+		tails:       g.tails.clone(),
+		overrides:   g.overrides.clone(),
+		ownership:   g.ownership,
+	}
+}
+
+// SimplifyPath simplifies a URL the same way Grouper.SimplifyPath does.
+func (f FrozenGrouper) SimplifyPath(u *url.URL) string {
+	u, ok := f.tree.harden(u)
+	if !ok {
+		return f.tree.rejected()
+	}
+	if rule, ok := f.overrides.match(u.Path); ok {
+		return rule.simplify(u.Path, f.tree)
+	}
+	if pattern, ok := f.tails.match(u.Path); ok {
+		return f.tree.encodeTail(pattern)
+	}
+	tokens := labelPathTokens(u.Path, f.classifiers)
+	t := lookupTree(f.trees, u.Path, f.tree)
+	return "/" + strings.Join(t.path(tokens), "/")
+}
+
+// Labels returns the label each segment of a URL is grouped under, the same way Grouper.Labels does.
+func (f FrozenGrouper) Labels(u *url.URL) []string {
+	u, ok := f.tree.harden(u)
+	if !ok {
+		return []string{_rejectedLabel}
+	}
+	if rule, ok := f.overrides.match(u.Path); ok {
+		return pathSegments(rule.Pattern)
+	}
+	if _, ok := f.tails.match(u.Path); ok {
+		return f.tails.labels(u.Path)
+	}
+	tokens := labelPathTokens(u.Path, f.classifiers)
+	t := lookupTree(f.trees, u.Path, f.tree)
+	return t.labels(tokens)
+}
+
+// SimplifyAll simplifies many URLs across parallelism goroutines.
+// The result holds the simplified path of each URL at the same index.
+func (f FrozenGrouper) SimplifyAll(urls []*url.URL, parallelism int) []string {
+	simplified := make([]string, len(urls))
+	if len(urls) == 0 {
+		return simplified
+	}
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	chunk := (len(urls) + parallelism - 1) / parallelism
+	var wg sync.WaitGroup
+	for start := 0; start < len(urls); start += chunk {
+		end := start + chunk
+		if end > len(urls) {
+			end = len(urls)
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				simplified[i] = f.SimplifyPath(urls[i])
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	return simplified
+}
+
+// SimplifyAll simplifies many URLs across parallelism goroutines against a frozen copy of the Grouper.
+// The result holds the simplified path of each URL at the same index.
+func (g Grouper) SimplifyAll(urls []*url.URL, parallelism int) []string {
+	return g.Freeze().SimplifyAll(urls, parallelism)
+}
+
+// clone deep copies a tree. Written iteratively for the same reason as add.
+func (t urlTree) clone() urlTree {
+	nodes := &nodeSlab{}
+	root := t.Root.cloneShallow(nodes)
+	type pair struct {
+		from, to *urlNode
+	}
+	stack := []pair{{t.Root, root}}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		p.from.children.each(func(key LabelFields, child *urlNode) {
+			c := child.cloneShallow(nodes)
+			p.to.children.set(key, c)
+			stack = append(stack, pair{child, c})
+		})
+	}
+	return urlTree{Root: root, treeConfig: t.treeConfig, nodes: nodes}
+}
+
+// cloneShallow copies a node without its children, allocating it from nodes.
+func (n *urlNode) cloneShallow(nodes *nodeSlab) *urlNode {
+	counts := MapCounter(counterMap(n.tokenCounts.counts))
+	if counts == nil {
+		counts = MapCounter{}
+	}
+	c := nodes.alloc()
+	*c = urlNode{
+		specificLabel: n.specificLabel,
+		depth:         n.depth,
+		tokenCounts: caseInsensitiveStringCounter{
+			limit:    n.tokenCounts.limit,
+			total:    n.tokenCounts.total,
+			counts:   counts,
+			overflow: n.tokenCounts.overflow,
+
+			normalizeNumbers: n.tokenCounts.normalizeNumbers,
+			deferLimit:       n.tokenCounts.deferLimit,
+		},
+		labels: cloneLabelCounts(n.labels),
+		// Frozen trees do not add tokens, so the sketch is shared.
+		overflowSketch: n.overflowSketch,
+		samples:        append([]string(nil), n.samples...),
+		tuning:         n.tuning.clone(),
+		splits:         cloneSplits(n.splits),
+		merged:         n.merged,
+
+		contentTypes: copyCounts(n.contentTypes),
+		languages:    copyCounts(n.languages),
+		methods:      copyCounts(n.methods),
+		stats:        cloneStats(n.stats),
+		params:       cloneParams(n.params),
+	}
+	return c
+}
@@ -0,0 +1,58 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestSimplifyAll(t *testing.T) {
+	g, err := loadFixture("examples/test.urls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	urls, err := readFixtureURLs("examples/test.urls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frozen := g.Freeze()
+	simplified := frozen.SimplifyAll(urls, 4)
+	for i, u := range urls {
+		if simplified[i] != g.SimplifyPath(u) {
+			t.Fatalf("expected %s, got %s", g.SimplifyPath(u), simplified[i])
+		}
+	}
+
+	// Learning after freezing must not affect the frozen copy.
+	for i := 0; i < 1000; i++ {
+		u, err := url.Parse(fmt.Sprintf("/random/%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+	if got := frozen.SimplifyPath(urls[0]); got != simplified[0] {
+		t.Fatalf("expected %s, got %s", simplified[0], got)
+	}
+}
+
+func BenchmarkSimplifyAll(b *testing.B) {
+	g, err := loadFixture("examples/test.urls")
+	if err != nil {
+		b.Fatal(err)
+	}
+	urls, err := readFixtureURLs("examples/test.urls")
+	if err != nil {
+		b.Fatal(err)
+	}
+	frozen := g.Freeze()
+
+	for _, parallelism := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("parallelism=%d", parallelism), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				frozen.SimplifyAll(urls, parallelism)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package groupurl
+
+import (
+	"path"
+	"strings"
+)
+
+// matchGlob reports whether a simplified path matches a glob.
+// Globs are matched segment by segment: "*" and the other path.Match syntax match within a single segment,
+// and a "**" segment matches any number of segments, including none.
+// A malformed glob matches nothing.
+func matchGlob(glob, p string) bool {
+	return matchSegments(splitSegments(glob), splitSegments(p))
+}
+
+// MatchGlob reports whether a simplified path or group pattern matches a glob, as the globs of override rules, cohorts
+// and alerts do, so that packages building on groups select them the same way.
+func MatchGlob(glob, p string) bool {
+	return matchGlob(glob, p)
+}
+
+func matchSegments(glob, segments []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(glob[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, err := path.Match(glob[0], segments[0]); err != nil || !ok {
+			return false
+		}
+		glob, segments = glob[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
+
+func splitSegments(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
@@ -0,0 +1,209 @@
+package groupurl
+
+import (
+	"hash/fnv"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const _topTokens = 20
+
+// GroupID returns a stable numeric identifier for a simplified path, for use as a compact join key in data stores.
+func GroupID(pattern string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(pattern))
+	return h.Sum64()
+}
+
+// Pattern returns the pattern of the group a URL falls into, as found in Snapshot and the exports. Unlike the
+// simplified path, the pattern only holds labels, so URLs whose significant tokens differ share it.
+func (g Grouper) Pattern(u *url.URL) string {
+	return "/" + strings.Join(g.Labels(u), "/")
+}
+
+// Pattern returns the pattern of the group a URL falls into, the same way Grouper.Pattern does.
+func (f FrozenGrouper) Pattern(u *url.URL) string {
+	return "/" + strings.Join(f.Labels(u), "/")
+}
+
+// Group is a group of URLs the Grouper has learned, for callers rendering their own reports or feeding dashboards.
+type Group struct {
+	// Pattern is the pattern of the group, as found in Snapshot and returned by Grouper.Pattern, such as
+	// /Words/YYYY/MM/DD/Words. URLs that SimplifyPath renders differently, such as /blog/YYYY/MM/DD/Words and
+	// /news/YYYY/MM/DD/Words, share it.
+	Pattern string `json:"pattern"`
+	// Count is the number of URLs in the group.
+	Count int `json:"count"`
+	// Segments describes each segment of the pattern.
+	Segments []GroupSegment `json:"segments"`
+	// Samples holds sample URLs of the group.
+	Samples []string `json:"samples,omitempty"`
+}
+
+// GroupSegment is a segment of the pattern of a Group.
+type GroupSegment struct {
+	// Label is the label of the segment, such as Words.
+	Label string `json:"label"`
+	// Tokens holds the significant tokens of the segment, which SimplifyPath keeps in place of Label, such as blog and
+	// news for the first segment of /Words/YYYY/MM/DD/Words, by decreasing count. Counts and shares are those of every
+	// URL that went through the segment, including the URLs of longer groups sharing it.
+	Tokens []TokenCount `json:"tokens,omitempty"`
+}
+
+// Groups returns every group the Grouper has learned, one per pattern, ordered by tree and then by pattern, with the
+// significant tokens of each segment, up to 20 of them. The tokens of a segment are counted regardless of those of
+// the other segments, so the groups do not enumerate the combinations of tokens SimplifyPath can render.
+func (g Grouper) Groups() []Group {
+	return mapSlice(g.groups(), func(grp group) Group {
+		return Group{
+			Pattern: grp.pattern,
+			Count:   grp.count,
+			Segments: mapSlice(grp.segments, func(s groupSegment) GroupSegment {
+				segment := GroupSegment{Label: s.label.Value}
+				for i, token := range s.tokens {
+					segment.Tokens = append(segment.Tokens, TokenCount{
+						Token: token,
+						Count: s.counts[i],
+						Share: float64(s.counts[i]) / float64(s.total),
+					})
+				}
+				return segment
+			}),
+			Samples: append([]string(nil), grp.samples...),
+		}
+	})
+}
+
+// group is a distinct sequence of labels URLs have terminated at in one of the trees.
+type group struct {
+	tree     int
+	pattern  string
+	count    int
+	segments []groupSegment
+	samples  []string
+	// contentTypes counts the URLs of the group by the content type inferred from their final segment.
+	contentTypes map[string]int
+	// languages counts the URLs of the group by the language detected in their tokens.
+	languages map[string]int
+	// methods counts the requests of the group by HTTP method.
+	methods map[string]int
+	// stats aggregates the responses of the requests of the group added with Observe.
+	stats *GroupStats
+}
+
+// groupSegment describes one segment of a group. counts holds the number of URLs of each token, out of the total
+// URLs that went through the segment.
+type groupSegment struct {
+	label  LabelFields
+	tokens []string
+	counts []int
+	total  int
+}
+
+// groups returns every group in the Grouper ordered by tree and then by pattern, so that output built on it is stable.
+func (g Grouper) groups() []group {
+	keys := make([]int, 0, len(g.trees))
+	for key := range g.trees {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	var groups []group
+	for _, key := range keys {
+		for _, grp := range g.trees[key].groups() {
+			grp.tree = key
+			groups = append(groups, grp)
+		}
+	}
+	return append(groups, g.tails.groupList()...)
+}
+
+func (t urlTree) groups() []group {
+	var groups []group
+	t.walk(func(path []*urlNode) {
+		node := path[len(path)-1]
+		terminal := node.terminal()
+		if terminal <= 0 {
+			return
+		}
+
+		segments := make([]groupSegment, 0, len(path))
+		labels := make([]string, 0, len(path))
+		for _, n := range path {
+			tokens := t.significantTokens(n)
+			segments = append(segments, groupSegment{
+				label:  n.specificLabel,
+				tokens: tokens,
+				counts: mapSlice(tokens, func(token string) int { return n.tokenCounts.counts.Count(token) }),
+				total:  n.tokenCounts.total,
+			})
+			labels = append(labels, n.specificLabel.Value)
+		}
+		groups = append(groups, group{
+			pattern:  "/" + strings.Join(labels, "/"),
+			count:    terminal,
+			segments: segments,
+			samples:  node.samples,
+
+			contentTypes: node.contentTypes,
+			languages:    node.languages,
+			methods:      node.methods,
+			stats:        node.stats,
+		})
+	})
+	return groups
+}
+
+// walk calls f with the path from the root to every node in the tree, visiting children in a stable order.
+// Written iteratively for the same reason as add.
+func (t urlTree) walk(f func(path []*urlNode)) {
+	var stack [][]*urlNode
+	push := func(parent []*urlNode, node *urlNode) {
+		children := node.sortedChildren()
+		for i := len(children) - 1; i >= 0; i-- {
+			path := make([]*urlNode, len(parent)+1)
+			copy(path, parent)
+			path[len(parent)] = children[i]
+			stack = append(stack, path)
+		}
+	}
+
+	push(nil, t.Root)
+	for len(stack) > 0 {
+		path := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		f(path)
+		push(path, path[len(path)-1])
+	}
+}
+
+// terminal returns the number of URLs that ended at a node rather than continuing to its children.
+func (n *urlNode) terminal() int {
+	terminal := n.tokenCounts.total
+	n.children.each(func(_ LabelFields, child *urlNode) {
+		terminal -= child.tokenCounts.total
+	})
+	return terminal
+}
+
+func (n *urlNode) sortedChildren() []*urlNode {
+	children := make([]*urlNode, 0, n.children.len())
+	n.children.each(func(_ LabelFields, child *urlNode) {
+		children = append(children, child)
+	})
+	sort.Slice(children, func(i, j int) bool {
+		return lessLabelFields(children[i].specificLabel, children[j].specificLabel)
+	})
+	return children
+}
+
+func lessLabelFields(a, b LabelFields) bool {
+	if a.Value != b.Value {
+		return a.Value < b.Value
+	}
+	if a.Important != b.Important {
+		return !a.Important
+	}
+	return a.CardinalityLimit < b.CardinalityLimit
+}
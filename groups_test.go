@@ -0,0 +1,67 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestGroups(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 60; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/blog/2024/01/%02d/%s", i%28+1, letters(i))})
+		if i%3 == 0 {
+			g.Add(&url.URL{Path: fmt.Sprintf("/news/2024/02/%02d/%s", i%28+1, letters(i))})
+		}
+	}
+
+	groups := g.Groups()
+	if len(groups) != 1 {
+		t.Fatalf("expected a single group, got %+v", groups)
+	}
+	grp := groups[0]
+	if grp.Pattern != "/Words/YYYY/MM/DD/Words" || grp.Count != 80 || len(grp.Samples) == 0 {
+		t.Fatalf("unexpected group %+v", grp)
+	}
+	if got := g.Pattern(&url.URL{Path: "/news/2024/02/01/hello"}); got != grp.Pattern {
+		t.Fatalf("expected the pattern of Groups to be that of Pattern, got %s", got)
+	}
+	if len(grp.Segments) != 3 || grp.Segments[1].Label != "YYYY/MM/DD" {
+		t.Fatalf("unexpected segments %+v", grp.Segments)
+	}
+	first := grp.Segments[0]
+	if len(first.Tokens) != 2 || first.Tokens[0] != (TokenCount{Token: "blog", Count: 60, Share: 0.75}) || first.Tokens[1].Token != "news" {
+		t.Fatalf("expected the significant tokens of the first segment by decreasing count, got %+v", first.Tokens)
+	}
+	if tokens := grp.Segments[2].Tokens; len(tokens) != 0 {
+		t.Fatalf("expected no significant tokens for the slugs, got %+v", tokens)
+	}
+	if got := g.SimplifyPath(&url.URL{Path: "/blog/2024/01/01/hello"}); got != "/blog/YYYY/MM/DD/Words" {
+		t.Fatalf("expected the tokens of Groups to be those SimplifyPath keeps, got %s", got)
+	}
+}
+
+func TestGroupsDeepPaths(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 20 distinct paths of 5 segments, each segment keeping several significant tokens.
+	for i := 0; i < 4000; i++ {
+		p := i % 20
+		g.Add(&url.URL{Path: fmt.Sprintf("/a%s/b%s/c%s/d%s/e%s", letters(p%4), letters(p%5), letters(p%2), letters(p%10), letters(p%20))})
+	}
+
+	groups := g.Groups()
+	if len(groups) != 1 || groups[0].Count != 4000 {
+		t.Fatalf("expected a single group for the pattern, got %d groups", len(groups))
+	}
+	for i, segment := range groups[0].Segments {
+		if len(segment.Tokens) == 0 || len(segment.Tokens) > _topTokens {
+			t.Fatalf("expected the significant tokens of segment %d, got %+v", i, segment.Tokens)
+		}
+	}
+}
@@ -5,26 +5,48 @@ import (
 	"net/url"
 	"sort"
 	"strings"
+	"time"
 )
 
 type (
 	// Grouper is a struct that groups URLs based on their path components.
-	// It is not safe for concurrent use.
+	// It is not safe for concurrent use, NewConcurrent wraps it for use from several goroutines.
 	// It can only keep track of a single host at a time so callers are encouraged to create a new Grouper per host.
 	// The memory utilization of the Grouper is proportional to the number of unique paths it has seen.
 	// However, it is possible to bound this memory by using Classifiers that emit labels marked as not `Important`,
 	// or with `CardinalityLimit` set.
 	Grouper struct {
-		classifiers []PathTokenClassifier
-		trees       map[int]urlTree
+		classifiers    []PathTokenClassifier
+		trees          map[int]urlTree
+		depths         map[int]int
+		lineage        *[]Lineage
+		alerts         []*alertRule
+		now            func() time.Time
+		sampling       *sampler
+		sampleTargets  *SampleTargets
+		addCache       *lruCache[string, []pathToken]
+		seen           *bloomFilter
+		addHooks       []AddHook
+		promotionHooks []PromotionHook
+		simplifyCache  *simplifyCache
+		tree           treeConfig
+		budget         *budget
+		tails          *wildcardTails
+		transitions    *transitions
+		overrides      *overrideRules
+		tags           *groupTags
+		ownership      *Ownership
+		vocabulary     LabelVocabulary
+		// labelInfo maps the labels of the classifiers to the classifiers emitting them.
+		labelInfo map[string]ClassifierInfo
 	}
 
 	Option func(*Grouper) error
 )
 
 const (
-	_cardinalityLabel      = "cardinality"
 	_significanceThreshold = 0.01
+	_maxSamples            = 3
 )
 
 // WithClassifiers sets the classifiers to be used by the Grouper.
@@ -36,11 +58,31 @@ func WithClassifiers(classifiers []PathTokenClassifier) Option {
 	}
 }
 
+// WithCardinalityLimit bounds the number of distinct tokens recorded for Important labels that do not set a
+// `CardinalityLimit` of their own. Tokens beyond the limit are grouped under a generic label.
+func WithCardinalityLimit(limit int) Option {
+	return func(g *Grouper) error {
+		if limit <= 0 {
+			return fmt.Errorf("cardinality limit must be positive, got %d", limit)
+		}
+		g.tree.cardinalityLimit = limit
+		return nil
+	}
+}
+
 // New creates a new Grouper with the provided options.
 func New(options ...Option) (Grouper, error) {
 	g := Grouper{
 		classifiers: DefaultClassifiers(),
 		trees:       make(map[int]urlTree),
+		depths:      make(map[int]int),
+		lineage:     &[]Lineage{},
+		tails:       newWildcardTails(),
+		transitions: newTransitions(),
+		overrides:   &overrideRules{},
+		tags:        newGroupTags(),
+		now:         time.Now,
+		tree:        treeConfig{significance: AverageShare{Threshold: _significanceThreshold}},
 	}
 	for _, option := range options {
 		if err := option(&g); err != nil {
@@ -48,6 +90,14 @@ func New(options ...Option) (Grouper, error) {
 		}
 	}
 
+	g.applyDeterministic()
+	g.classifiers = g.vocabulary.apply(g.classifiers)
+
+	labelInfo, err := describeLabels(g.classifiers)
+	if err != nil {
+		return Grouper{}, fmt.Errorf("classifiers emit colliding labels: %w", err)
+	}
+	g.labelInfo = labelInfo
 	return g, nil
 }
 
@@ -55,75 +105,243 @@ func New(options ...Option) (Grouper, error) {
 // Groupers do not keep track of hosts URLs are associated with so it is suggested you use a different
 // Grouper per host.
 func (g Grouper) Add(u *url.URL) {
-	tokens := labelPathTokens(u.Path, g.classifiers)
+	g.add(u, request{})
+}
+
+// request holds what is known of the request of an added URL besides its path.
+type request struct {
+	method string
+	// observed is set for URLs added with Observe, which also sets status and latency.
+	observed bool
+	status   int
+	latency  time.Duration
+	// query is the raw query of the URL, only set WithQueryLearning.
+	query string
+}
+
+// add adds a URL along with what is known of its request, and returns the weight it was counted with, which is 0 if
+// it was skipped by sampling, dropped by a Budget or rejected by the PathPolicy.
+func (g Grouper) add(u *url.URL, req request) int {
+	u, ok := g.tree.harden(u)
+	if !ok {
+		return 0
+	}
+	weight := g.sampleWeight()
+	if weight == 0 {
+		return 0
+	}
+	if g.tree.learnQuery {
+		req.query = u.RawQuery
+	}
+
+	if pattern, ok := g.tails.match(u.Path); ok {
+		g.tails.add(pattern, u.Path, req, weight)
+	} else if !g.addToTree(u, req, weight) {
+		return 0
+	}
+	g.depths[pathDepth(u.Path)] += weight
+	if len(g.alerts) > 0 {
+		g.checkAlerts(u, weight)
+	}
+
+	info := AddInfo{Weight: weight, Method: req.method}
+	if g.seen != nil {
+		info.FirstSeen = g.seen.add(u.Path)
+	}
+	for _, hook := range g.addHooks {
+		hook(u, info)
+	}
+	return weight
+}
+
+// addToTree adds a url to its tree, and reports whether it was recorded rather than dropped by a Budget.
+func (g Grouper) addToTree(u *url.URL, req request, weight int) bool {
+	tokens := g.addTokens(u.Path)
 	t := g.getTree(u)
-	t.add(tokens)
+	spill := false
+	if g.budget != nil && !g.budget.hasRoom(treeKey(u.Path)) && !t.known(tokens) {
+		if g.budget.limits.Policy == SpillDrop {
+			g.budget.dropped++
+			return false
+		}
+		g.budget.spilled++
+		spill = true
+	}
+	promotions, recorded, reshaped := t.add(tokens, u.Path, req, weight, spill)
+	for _, p := range promotions {
+		p.Tree = treeKey(u.Path)
+		*g.lineage = append(*g.lineage, p.Lineage)
+		for _, hook := range g.promotionHooks {
+			hook(p)
+		}
+	}
+	if g.budget != nil {
+		g.budget.charge(treeKey(u.Path), recorded)
+	}
+	if reshaped && g.simplifyCache != nil {
+		g.simplifyCache.invalidate(treeKey(u.Path))
+	}
+	return true
 }
 
 // Simplify simplifies a URL replacing path components with tokens representing original values.
 // In the case that some tokens are low cardinality, the original value will be preserved.
 func (g Grouper) SimplifyPath(u *url.URL) string {
+	u, ok := g.tree.harden(u)
+	if !ok {
+		return g.tree.rejected()
+	}
+	if rule, ok := g.overrides.match(u.Path); ok {
+		return rule.simplify(u.Path, g.tree)
+	}
+	if pattern, ok := g.tails.match(u.Path); ok {
+		return g.tree.encodeTail(pattern)
+	}
+	if g.simplifyCache != nil {
+		return g.cachedSimplifyPath(u)
+	}
 	tokens := labelPathTokens(u.Path, g.classifiers)
-	t := g.getTree(u)
+	t := lookupTree(g.trees, u.Path, g.tree)
 	replaced := t.path(tokens)
 	return "/" + strings.Join(replaced, "/")
 }
 
+// Labels returns the label each segment of a URL is grouped under.
+// Segments of paths the Grouper has never seen are labeled by the classifiers alone.
+func (g Grouper) Labels(u *url.URL) []string {
+	u, ok := g.tree.harden(u)
+	if !ok {
+		return []string{_rejectedLabel}
+	}
+	if rule, ok := g.overrides.match(u.Path); ok {
+		return pathSegments(rule.Pattern)
+	}
+	if _, ok := g.tails.match(u.Path); ok {
+		return g.tails.labels(u.Path)
+	}
+	tokens := labelPathTokens(u.Path, g.classifiers)
+	t := lookupTree(g.trees, u.Path, g.tree)
+	return t.labels(tokens)
+}
+
 // String pretty prints the internal trees to stdout to imply a nesting structure.
+// Trees are ordered by depth and nodes by label, so the output is stable.
 func (g Grouper) String() string {
+	keys := make([]int, 0, len(g.trees))
+	for key := range g.trees {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
 	sb := strings.Builder{}
-	for _, t := range g.trees {
-		sb.WriteString(t.String())
+	for _, key := range keys {
+		t := g.trees[key]
+		t.string(t.Root, &sb, 0, g.labelInfo)
 	}
 	return sb.String()
 }
 
 func (g Grouper) getTree(u *url.URL) urlTree {
-	originalTokenCount := strings.Count(strings.TrimRight(strings.TrimLeft(u.Path, "/"), "/"), "/")
-	t, ok := g.trees[originalTokenCount]
+	key := treeKey(u.Path)
+	t, ok := g.trees[key]
 	if !ok {
-		t = newURLTree()
-		g.trees[originalTokenCount] = t
+		t = newURLTree(g.tree)
+		g.trees[key] = t
 	}
 	return t
 }
 
+// lookupTree returns the tree of a path without creating it, so that lookups never modify the trees.
+// With FallbackNearestTree, paths of unseen depths get the tree of the nearest depth instead.
+func lookupTree(trees map[int]urlTree, path string, config treeConfig) urlTree {
+	key := treeKey(path)
+	if t, ok := trees[key]; ok {
+		return t
+	}
+	if config.fallback == FallbackNearestTree {
+		if t, ok := nearestTree(trees, key); ok {
+			return t
+		}
+	}
+	return newURLTree(config)
+}
+
+// treeKey returns the key of the tree a path is stored in, which is the number of separators between its segments.
+func treeKey(path string) int {
+	return strings.Count(strings.TrimRight(strings.TrimLeft(path, "/"), "/"), "/")
+}
+
 type caseInsensitiveStringCounter struct {
-	limit       int
-	total       int
-	tokenCounts map[string]int
+	limit int
+	total int
+	// counts stores the counts of the tokens within the limit, as set by WithCounter and WithLabelCounter.
+	counts Counter
+	// overflow counts the tokens beyond the limit, apart from counts so that no token can collide with it.
+	overflow int
+	// normalizeNumbers counts numeric tokens by their value, as set by WithNumericNormalization.
+	normalizeNumbers bool
+	// deferLimit counts every token and applies the limit to the counts, as set by WithDeterministic.
+	deferLimit bool
 }
 
 func newCaseInsensitiveStringCounter(limit int) caseInsensitiveStringCounter {
 	return caseInsensitiveStringCounter{
-		limit:       limit,
-		tokenCounts: make(map[string]int),
+		limit:  limit,
+		counts: MapCounter{},
 	}
 }
 
 func (c *caseInsensitiveStringCounter) add(s string) {
-	key := strings.ToLower(s)
-	if _, ok := c.tokenCounts[key]; ok || c.limit == 0 || len(c.tokenCounts) < c.limit {
-		c.tokenCounts[key]++
+	c.addN(s, 1)
+}
+
+func (c *caseInsensitiveStringCounter) addN(s string, n int) {
+	key := c.key(s)
+	if c.fits(key) {
+		c.counts.Add(key, n)
 	} else {
-		c.tokenCounts[_cardinalityLabel]++
+		c.overflow += n
 	}
-	c.total++
+	c.total += n
+}
+
+// addOverflow counts tokens in the overflow regardless of the limit, as the SpillOverflow policy of a Budget does.
+func (c *caseInsensitiveStringCounter) addOverflow(n int) {
+	c.overflow += n
+	c.total += n
 }
 
+// fits reports whether a token, already keyed, is counted on its own rather than in the overflow.
+func (c caseInsensitiveStringCounter) fits(key string) bool {
+	return c.counts.Count(key) > 0 || c.limit == 0 || c.counts.Len() < c.limit || c.deferLimit
+}
+
+// population is the number of distinct tokens counted, with the overflow counted as one.
 func (c caseInsensitiveStringCounter) population() int {
-	return len(c.tokenCounts)
+	if c.overflow > 0 {
+		return c.counts.Len() + 1
+	}
+	return c.counts.Len()
 }
 
 func (c caseInsensitiveStringCounter) get(s string) int {
-	return c.tokenCounts[strings.ToLower(s)]
+	return c.counts.Count(c.key(s))
+}
+
+func (c caseInsensitiveStringCounter) key(s string) string {
+	if c.normalizeNumbers {
+		s = normalizeNumber(s)
+	}
+	return strings.ToLower(s)
 }
 
 func (c caseInsensitiveStringCounter) isSignificant(s string) bool {
-	averageCountPerToken := float64(c.population()) / float64(c.total)
-	tokenShareOfCounts := float64(c.get(s)) / float64(c.total)
-	return (len(c.tokenCounts) < c.limit || c.limit == 0) && (averageCountPerToken < _significanceThreshold ||
-		tokenShareOfCounts > averageCountPerToken)
+	return c.isSignificantBy(s, AverageShare{Threshold: _significanceThreshold})
+}
+
+// isSignificantBy applies a Significance to a token, unless the counter has reached its limit.
+func (c caseInsensitiveStringCounter) isSignificantBy(s string, significance Significance) bool {
+	return (c.counts.Len() < c.limit || c.limit == 0) && significance.Significant(c.get(s), c.total, c.population())
 }
 
 func (c caseInsensitiveStringCounter) topN(n int) []string {
@@ -132,15 +350,19 @@ func (c caseInsensitiveStringCounter) topN(n int) []string {
 		token string
 	}
 	var cardinalityAndTokens []cardinalityAndToken
-	for k, v := range c.tokenCounts {
+	c.counts.Range(func(k string, v int) bool {
 		cardinalityAndTokens = append(cardinalityAndTokens, cardinalityAndToken{
 			count: v,
 			token: k,
 		})
-	}
+		return true
+	})
 
 	sort.Slice(cardinalityAndTokens, func(i, j int) bool {
-		return cardinalityAndTokens[i].count > cardinalityAndTokens[j].count
+		if cardinalityAndTokens[i].count != cardinalityAndTokens[j].count {
+			return cardinalityAndTokens[i].count > cardinalityAndTokens[j].count
+		}
+		return cardinalityAndTokens[i].token < cardinalityAndTokens[j].token
 	})
 
 	topN := n
@@ -155,74 +377,240 @@ func (c caseInsensitiveStringCounter) topN(n int) []string {
 
 type urlTree struct {
 	Root *urlNode
+	treeConfig
+	nodes *nodeSlab
 }
 
-func newURLTree() urlTree {
+// treeConfig holds the options of a Grouper that its trees apply as they learn.
+type treeConfig struct {
+	significance     Significance
+	cardinalityLimit int
+	autoTune         *AutoTune
+	fallback         Fallback
+	normalizeNumbers bool
+	detectLanguages  bool
+	deterministic    bool
+	// pinned and redacted hold the lower cased tokens set by WithPinnedTokens and WithRedactedTokens.
+	pinned   map[string]bool
+	redacted map[string]bool
+	// privacyK is the minimum count of emitted tokens set by WithPrivacyMode.
+	privacyK int
+	// newCounter and labelCounters create the Counters set by WithCounter and WithLabelCounter.
+	newCounter    func() Counter
+	labelCounters map[string]func() Counter
+	// learnQuery counts the query parameters of each group, as set by WithQueryLearning.
+	learnQuery     bool
+	cacheKeyParams CacheKeyParams
+	// encodeLabels wraps labels and escapes kept segments in simplified paths, as set by WithEncodedLabels.
+	encodeLabels bool
+	// coldStart is the number of URLs below which nodes are simplified by heuristics, as set by WithColdStart.
+	coldStart int
+	// priors adjust importance and significance by position, as set by WithPositionPriors.
+	priors []PositionPrior
+	// labelShare is the share of URLs a node needs to keep its label, as set by WithMajorityLabels.
+	labelShare float64
+	// recountLog is the number of uncounted tokens each node logs, as set by WithPromotionRecount.
+	recountLog int
+	pathPolicy PathPolicy
+}
+
+func newURLTree(config treeConfig) urlTree {
+	root := newURLNode(LabelFields{})
+	root.depth = -1
 	return urlTree{
-		Root: newURLNode(LabelFields{}),
+		Root:       root,
+		treeConfig: config,
+		nodes:      &nodeSlab{},
+	}
+}
+
+// newNode creates a node for a label at a position with the tree's options applied to its counter.
+func (t urlTree) newNode(label LabelFields, depth int) *urlNode {
+	n := t.nodes.alloc()
+	n.specificLabel = label
+	n.depth = depth
+	effective := t.atPosition(label, depth)
+	n.tokenCounts = caseInsensitiveStringCounter{
+		limit:            t.withTreeLimit(effective, effective.cardinalityLimit()),
+		counts:           t.counterFor(label),
+		normalizeNumbers: t.normalizeNumbers,
+		deferLimit:       t.deterministic,
+	}
+	return n
+}
+
+// withTreeLimit returns limit, or the limit the tree's options set for Important labels.
+// Auto tuned trees start Important labels without a limit, otherwise the tree's cardinality limit applies to
+// Important labels that set none of their own.
+func (t urlTree) withTreeLimit(label LabelFields, limit int) int {
+	switch {
+	case !label.Important:
+		return limit
+	case t.autoTune != nil:
+		return 0
+	case label.CardinalityLimit == 0:
+		return t.cardinalityLimit
+	}
+	return limit
+}
+
+// isSignificant reports whether a token at a node is frequent enough to be preserved.
+// Pinned and redacted tokens are decided by their option instead.
+func (t urlTree) isSignificant(n *urlNode, token string) bool {
+	if keep, ok := t.override(token); ok {
+		return keep
+	}
+	return !n.merged && t.anonymous(n, token) && n.tokenCounts.isSignificantBy(token, t.significanceAt(n.depth))
+}
+
+// significantTokens returns the tokens of an Important node that SimplifyPath would preserve.
+func (t urlTree) significantTokens(n *urlNode) []string {
+	if !t.important(n) {
+		return nil
 	}
+	return filterSlice(n.tokenCounts.topN(_topTokens), func(token string) bool {
+		return t.isSignificant(n, token)
+	})
 }
 
 func (t urlTree) String() string {
 	sb := &strings.Builder{}
-	t.string(t.Root, sb, 0)
+	t.string(t.Root, sb, 0, nil)
 	return sb.String()
 }
 
-func (t urlTree) string(node *urlNode, sb *strings.Builder, depth int) {
-	for _, child := range node.children {
+// string writes the nodes below node, naming the classifier of each label found in labelInfo.
+func (t urlTree) string(node *urlNode, sb *strings.Builder, depth int, labelInfo map[string]ClassifierInfo) {
+	for _, child := range node.sortedChildren() {
 		indent := strings.Repeat("  ", depth)
 
-		tokens := filterSlice(child.tokenCounts.topN(20), child.tokenCounts.isSignificant)
-		if len(tokens) > 0 && child.specificLabel.Important {
-			sb.WriteString(fmt.Sprintf("%s/%s: %v(%d)\n", indent, child.specificLabel.Value, tokens, child.tokenCounts.total))
+		label := child.specificLabel.Value
+		if info, ok := labelInfo[label]; ok && info.Name != "" {
+			label = fmt.Sprintf("%s <%s>", label, info.Name)
+		}
+		tokens := t.significantTokens(child)
+		if len(tokens) > 0 {
+			sb.WriteString(fmt.Sprintf("%s/%s: %v(%d)", indent, label, tokens, child.tokenCounts.total))
 		} else {
-			sb.WriteString(fmt.Sprintf("%s/%s: (%d)\n", indent, child.specificLabel.Value, child.tokenCounts.total))
+			sb.WriteString(fmt.Sprintf("%s/%s: (%d)", indent, label, child.tokenCounts.total))
 		}
+		if overflow := child.tokenCounts.overflow; overflow > 0 && child.tokenCounts.limit >= 0 {
+			sb.WriteString(fmt.Sprintf(" overflow %d (~%d distinct)", overflow, child.overflowSketch.estimate()))
+		}
+		sb.WriteString("\n")
 
-		t.string(child, sb, depth+1)
+		t.string(child, sb, depth+1, labelInfo)
 	}
 }
 
 // Written iteratively instead of recursively to avoid deep stacks as these URLs can come from external clients.
-func (t urlTree) add(tokens []pathToken) {
+// The original path is kept as a sample on the node the URL terminates at, along with its content type and language.
+// Any nodes whose label was promoted to a parent label are reported as Lineage, along with the number of
+// distinct tokens recorded for the first time. The weight is the number of URLs the added one stands for.
+// When spill is set, tokens that have not been recorded yet are counted in the overflow.
+// The URL reshapes the tree when it creates or promotes a node, records a token a node had not counted, or changes
+// whether a node keeps its token, which are the changes that make cached simplified paths stale.
+func (t urlTree) add(tokens []pathToken, path string, req request, weight int, spill bool) ([]Promotion, int, bool) {
+	var (
+		promotions []Promotion
+		labels     = make([]string, 0, len(tokens))
+		recorded   int
+		reshaped   bool
+	)
 	current := t.Root
 	for _, token := range tokens {
+		token = current.route(token)
 		parent := token.label.parentOrSelf()
-		child, ok := current.children[parent]
+		child, ok := current.children.get(parent)
 		if !ok {
-			child = newURLNode(token.label.LabelFields)
-			current.children[parent] = child
+			child = t.newNode(token.label.LabelFields, current.depth+1)
+			current.children.set(parent, child)
+			reshaped = true
 		}
+		cold, kept, limit := t.cold(child), t.keeps(child, token.token), child.tokenCounts.limit
 
 		// If we've found a child with a different label than the current token, we should mark it as a parent
 		// so they are grouped together. At this point we also need to update our counters to reflect the new
-		// labeling.
-		if child.specificLabel.Value != token.label.LabelFields.Value {
-			child.specificLabel = parent
-			child.tokenCounts.limit = parent.CardinalityLimit
+		// labeling. With WithMajorityLabels, the distribution of labels decides instead.
+		child.observeLabel(token.label.LabelFields, weight)
+		if resolved := t.resolveLabel(child, token.label); resolved != child.specificLabel {
+			reason := fmt.Sprintf("%s and %s merged into %s", child.specificLabel.Value, token.label.Value, parent.Value)
+			if resolved != parent {
+				reason = fmt.Sprintf("%s became the label of most URLs", resolved.Value)
+			}
+			promotion := Promotion{Lineage: Lineage{
+				From:   "/" + strings.Join(append(labels, child.specificLabel.Value), "/"),
+				To:     "/" + strings.Join(append(labels, resolved.Value), "/"),
+				Reason: reason,
+			}}
+			limit := resolved.CardinalityLimit
+			if resolved != parent {
+				limit = resolved.cardinalityLimit()
+			}
+			child.specificLabel = resolved
+			child.tokenCounts.limit = t.withTreeLimit(t.atPosition(resolved, child.depth), limit)
+			child.tuning = nil
+			if t.recountLog > 0 {
+				promotion.Recounted = child.recount()
+			}
+			promotions = append(promotions, promotion)
+			reshaped = true
 		}
 
-		child.tokenCounts.add(token.token)
+		population := child.tokenCounts.population()
+		if spill && child.tokenCounts.get(token.token) == 0 {
+			child.tokenCounts.addOverflow(weight)
+		} else {
+			if key := child.tokenCounts.key(token.token); !child.tokenCounts.fits(key) {
+				child.overflow(key, t.recountLog)
+			}
+			child.tokenCounts.addN(token.token, weight)
+		}
+		recorded += child.tokenCounts.population() - population
+		t.tune(child)
+		if child.tokenCounts.population() != population || child.tokenCounts.limit != limit ||
+			t.cold(child) != cold || t.keeps(child, token.token) != kept {
+			reshaped = true
+		}
+		labels = append(labels, child.specificLabel.Value)
 		current = child
 	}
+	if t.privacyK == 0 {
+		current.addSample(path)
+	}
+	current.contentTypes = addContentType(current.contentTypes, path, weight)
+	current.methods = addMethod(current.methods, req.method, weight)
+	if req.observed {
+		current.stats = current.stats.observe(req, weight)
+	}
+	current.addParams(req.query, weight)
+	if t.detectLanguages {
+		if language := detectLanguage(tokens); language != "" {
+			if current.languages == nil {
+				current.languages = make(map[string]int)
+			}
+			current.languages[language] += weight
+		}
+	}
+	return promotions, recorded, reshaped
 }
 
 func (t urlTree) path(tokens []pathToken) []string {
 	var replaced []string
 	current := t.Root
 	for idx, token := range tokens {
+		token = current.route(token)
 		parent := token.label.parentOrSelf()
-		child, ok := current.children[parent]
+		child, ok := current.children.get(parent)
 		if !ok {
-			return append(replaced, mapSlice(tokens[idx:], func(v pathToken) string {
-				return v.token
-			})...)
+			return append(replaced, t.unseen(tokens, idx)...)
 		}
-		if child.specificLabel.Important && child.tokenCounts.isSignificant(token.token) {
-			replaced = append(replaced, token.token)
+		if t.cold(child) {
+			replaced = append(replaced, t.heuristic(token, idx))
+		} else if t.keeps(child, token.token) {
+			replaced = append(replaced, t.encodeToken(token.token))
 		} else {
-			replaced = append(replaced, child.specificLabel.Value)
+			replaced = append(replaced, t.encodeLabel(child.specificLabel.Value))
 		}
 
 		current = child
@@ -230,20 +618,68 @@ func (t urlTree) path(tokens []pathToken) []string {
 	return replaced
 }
 
+func (t urlTree) labels(tokens []pathToken) []string {
+	var labels []string
+	current := t.Root
+	for idx, token := range tokens {
+		token = current.route(token)
+		child, ok := current.children.get(token.label.parentOrSelf())
+		if !ok {
+			return append(labels, mapSlice(tokens[idx:], func(v pathToken) string {
+				return v.label.Value
+			})...)
+		}
+		labels = append(labels, child.specificLabel.Value)
+		current = child
+	}
+	return labels
+}
+
 type urlNode struct {
 	specificLabel LabelFields
-	children      map[LabelFields]*urlNode
+	children      childNodes
 	tokenCounts   caseInsensitiveStringCounter
+	samples       []string
+	tuning        *nodeTuning
+	splits        map[LabelFields][]PathTokenClassifier
+	merged        bool
+	contentTypes  map[string]int
+	languages     map[string]int
+	methods       map[string]int
+	stats         *GroupStats
+	params        map[string]*queryParam
+	// depth is the position of the node's segment in paths, -1 for the root.
+	depth int
+	// labels counts the labels the node's segments were classified under.
+	labels map[LabelFields]int
+	// overflowLog holds the last tokens the node could not count, as set by WithPromotionRecount, overwritten
+	// from overflowNext once full.
+	overflowLog  []string
+	overflowNext int
+	// overflowSketch estimates the distinct tokens beyond the cardinality limit of the node.
+	overflowSketch overflowSketch
 }
 
 func newURLNode(label LabelFields) *urlNode {
 	return &urlNode{
 		specificLabel: label,
-		children:      make(map[LabelFields]*urlNode),
 		tokenCounts:   newCaseInsensitiveStringCounter(label.cardinalityLimit()),
 	}
 }
 
+// addSample keeps the first few distinct paths that terminate at a node as examples for reports.
+func (n *urlNode) addSample(path string) {
+	if len(n.samples) >= _maxSamples {
+		return
+	}
+	for _, sample := range n.samples {
+		if sample == path {
+			return
+		}
+	}
+	n.samples = append(n.samples, path)
+}
+
 func mapSlice[X any, Y any](in []X, f func(X) Y) []Y {
 	var result []Y
 	for _, v := range in {
@@ -48,25 +48,10 @@ func TestFile(t *testing.T) {
 }
 
 func loadFixture(path string) (Grouper, error) {
-	file, err := os.Open(path)
+	urls, err := readFixtureURLs(path)
 	if err != nil {
 		return Grouper{}, err
 	}
-	defer file.Close()
-
-	var urls []*url.URL
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		u, err := url.Parse(scanner.Text())
-		if err != nil {
-			return Grouper{}, err
-		}
-
-		urls = append(urls, u)
-	}
-	if err := scanner.Err(); err != nil {
-		return Grouper{}, err
-	}
 
 	rand.Shuffle(len(urls), func(i, j int) {
 		urls[i], urls[j] = urls[j], urls[i]
@@ -83,6 +68,25 @@ func loadFixture(path string) (Grouper, error) {
 	return g, nil
 }
 
+func readFixtureURLs(path string) ([]*url.URL, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var urls []*url.URL
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		u, err := url.Parse(scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, u)
+	}
+	return urls, scanner.Err()
+}
+
 func TestNew(t *testing.T) {
 	g, err := New()
 	if err != nil {
@@ -140,8 +144,29 @@ func TestCaseInsensitiveStringCounter(t *testing.T) {
 	if c.get("test4") != 0 {
 		t.Fatalf("expected 0, got %d", c.get("test4"))
 	}
+	if c.overflow != 2 {
+		t.Fatalf("expected 2, got %d", c.overflow)
+	}
+	if c.get("cardinality") != 0 {
+		t.Fatalf("expected 0, got %d", c.get("cardinality"))
+	}
+}
+
+func TestCaseInsensitiveStringCounterCardinalityToken(t *testing.T) {
+	c := newCaseInsensitiveStringCounter(2)
+	c.add("cardinality")
+	c.add("test")
+	c.add("test1")
+	c.add("cardinality")
+
 	if c.get("cardinality") != 2 {
-		t.Fatalf("expected 2, got %d", c.get(_cardinalityLabel))
+		t.Fatalf("expected 2, got %d", c.get("cardinality"))
+	}
+	if c.overflow != 1 {
+		t.Fatalf("expected 1, got %d", c.overflow)
+	}
+	if c.population() != 3 {
+		t.Fatalf("expected 3, got %d", c.population())
 	}
 }
 
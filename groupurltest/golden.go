@@ -0,0 +1,72 @@
+// Package groupurltest provides helpers for testing applications that embed groupurl.
+//
+// Golden compares the groups a Grouper has learned with a golden file, so that changes to classifier
+// configurations show up as diffs in review. Run the tests with GROUPURLTEST_UPDATE=1 to write the golden
+// files instead:
+//
+//	GROUPURLTEST_UPDATE=1 go test ./...
+//
+// Train the Grouper WithDeterministic so the output does not depend on the order URLs are added in.
+package groupurltest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/trustleast/groupurl"
+)
+
+// EnvUpdate is the environment variable that makes Golden write golden files rather than compare with them.
+const EnvUpdate = "GROUPURLTEST_UPDATE"
+
+// Golden compares the canonical text report of g, with counts and tokens, with the file at goldenPath,
+// and fails t with the first differing line if they differ.
+func Golden(t testing.TB, g groupurl.Grouper, goldenPath string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := g.ExportText(&buf, groupurl.TextOptions{Counts: true, Tokens: true}); err != nil {
+		t.Fatalf("failed to export groups: %v", err)
+	}
+
+	if os.Getenv(EnvUpdate) != "" {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+			t.Fatalf("failed to create golden file directory: %v", err)
+		}
+		if err := os.WriteFile(goldenPath, buf.Bytes(), 0o644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("failed to read golden file, run with %s=1 to create it: %v", EnvUpdate, err)
+	}
+	if got := buf.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("groups differ from %s, run with %s=1 to update it\n%s", goldenPath, EnvUpdate, firstDifference(string(got), string(want)))
+	}
+}
+
+// firstDifference describes the first line that differs between got and want.
+func firstDifference(got, want string) string {
+	gotLines := strings.Split(got, "\n")
+	wantLines := strings.Split(want, "\n")
+	for i := 0; i < len(gotLines) || i < len(wantLines); i++ {
+		var g, w string
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if g != w {
+			return "line " + strconv.Itoa(i+1) + ":\n got: " + g + "\nwant: " + w
+		}
+	}
+	return ""
+}
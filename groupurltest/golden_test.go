@@ -0,0 +1,67 @@
+package groupurltest
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/trustleast/groupurl"
+)
+
+func newGrouper(t *testing.T) groupurl.Grouper {
+	t.Helper()
+	g, err := groupurl.New(groupurl.WithDeterministic())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/users/%d/profile", i)})
+		g.Add(&url.URL{Path: fmt.Sprintf("/orders/%d", 1000+i)})
+	}
+	return g
+}
+
+func TestGolden(t *testing.T) {
+	Golden(t, newGrouper(t), filepath.Join("testdata", "golden.txt"))
+}
+
+type recorder struct {
+	testing.TB
+	failed bool
+	msg    string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failed = true
+	r.msg = fmt.Sprintf(format, args...)
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golden.txt")
+	if err := os.WriteFile(path, []byte("stale\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &recorder{TB: t}
+	Golden(r, newGrouper(t), path)
+	if !r.failed {
+		t.Fatal("expected a mismatch")
+	}
+	if !strings.Contains(r.msg, "line 1:") || !strings.Contains(r.msg, "want: stale") {
+		t.Errorf("unexpected message %q", r.msg)
+	}
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	t.Setenv(EnvUpdate, "1")
+	path := filepath.Join(t.TempDir(), "nested", "golden.txt")
+	Golden(t, newGrouper(t), path)
+
+	t.Setenv(EnvUpdate, "")
+	Golden(t, newGrouper(t), path)
+}
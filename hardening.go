@@ -0,0 +1,165 @@
+package groupurl
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// _rejectedLabel is the label SimplifyPath, Labels and Pattern return for the paths PathReject rejects.
+const _rejectedLabel = "Rejected"
+
+// ErrUnsafePath is returned by CheckPath for paths with encoded slashes, dot segments, null bytes or control
+// characters.
+var ErrUnsafePath = errors.New("unsafe path")
+
+// PathPolicy sets what a Grouper does with paths that could confuse tokenization or the consumers of its output:
+// encoded slashes, which are decoded into separators and shift the segments after them, `.` and `..` segments,
+// which name another path, and null bytes and control characters, which end up in kept segments.
+type PathPolicy int
+
+const (
+	// PathAsIs, the default, groups paths as they are decoded by net/url.
+	PathAsIs PathPolicy = iota
+	// PathCanonicalize keeps encoded slashes as `%2F` within their segment, resolves dot segments, without going
+	// above the root, and strips null bytes and control characters, so that `/a/..%2Fb/./c%00` is grouped as
+	// `/a/..%2Fb/c` and `/a/b/../c` as `/a/c`.
+	PathCanonicalize
+	// PathReject does not record URLs with such paths, and groups them under a single `Rejected` label.
+	PathReject
+)
+
+// WithPathPolicy sets what the Grouper does with hostile paths, see PathPolicy. The frozen copies of the Grouper
+// apply the same policy.
+func WithPathPolicy(policy PathPolicy) Option {
+	return func(g *Grouper) error {
+		if policy < PathAsIs || policy > PathReject {
+			return fmt.Errorf("unknown path policy %d", policy)
+		}
+		g.tree.pathPolicy = policy
+		return nil
+	}
+}
+
+var _pathPolicies = map[string]PathPolicy{
+	"as-is":        PathAsIs,
+	"canonicalize": PathCanonicalize,
+	"reject":       PathReject,
+}
+
+// ParsePathPolicy parses the name of a PathPolicy: as-is, canonicalize or reject.
+func ParsePathPolicy(s string) (PathPolicy, error) {
+	if policy, ok := _pathPolicies[s]; ok {
+		return policy, nil
+	}
+	return PathAsIs, fmt.Errorf("unknown path policy %q, want as-is, canonicalize or reject", s)
+}
+
+// CheckPath returns an error matching ErrUnsafePath if the path of a URL has encoded slashes, dot segments, null
+// bytes or control characters, so that callers can reject such URLs before they reach the Grouper.
+func CheckPath(u *url.URL) error {
+	if _, unsafe := canonicalizePath(u); unsafe != "" {
+		return fmt.Errorf("%w: %s", ErrUnsafePath, unsafe)
+	}
+	return nil
+}
+
+// harden applies the path policy to a URL. It returns the URL to group, which is only copied when its path changes,
+// and false if the URL is rejected.
+func (c treeConfig) harden(u *url.URL) (*url.URL, bool) {
+	if c.pathPolicy == PathAsIs {
+		return u, true
+	}
+	canonical, unsafe := canonicalizePath(u)
+	switch {
+	case unsafe == "":
+		return u, true
+	case c.pathPolicy == PathReject:
+		return nil, false
+	}
+	hardened := *u
+	hardened.Path, hardened.RawPath = canonical, ""
+	return &hardened, true
+}
+
+// rejected returns the simplified path of rejected URLs.
+func (c treeConfig) rejected() string {
+	return "/" + c.encodeLabel(_rejectedLabel)
+}
+
+// canonicalizePath returns the canonical path of a URL, as grouped under PathCanonicalize, along with what made the
+// path unsafe, empty if nothing did.
+func canonicalizePath(u *url.URL) (string, string) {
+	if u.RawPath == "" && !strings.Contains(u.Path, ".") && !hasControl(u.Path) {
+		return u.Path, ""
+	}
+	var unsafe []string
+	flag := func(reason string) {
+		for _, r := range unsafe {
+			if r == reason {
+				return
+			}
+		}
+		unsafe = append(unsafe, reason)
+	}
+
+	escaped := strings.Split(u.EscapedPath(), "/")
+	segments := make([]string, 0, len(escaped))
+	for i, s := range escaped {
+		segment, err := url.PathUnescape(s)
+		if err != nil {
+			segment = s
+		}
+		if strings.Contains(segment, "/") {
+			flag("encoded slash")
+			segment = strings.ReplaceAll(segment, "/", "%2F")
+		}
+		if hasControl(segment) {
+			flag("control character")
+			segment = strings.Map(func(r rune) rune {
+				if isControl(r) {
+					return -1
+				}
+				return r
+			}, segment)
+		}
+		switch segment {
+		case ".":
+			flag("dot segment")
+			if i == len(escaped)-1 {
+				segments = append(segments, "")
+			}
+			continue
+		case "..":
+			flag("dot segment")
+			if len(segments) > 1 {
+				segments = segments[:len(segments)-1]
+			}
+			if i == len(escaped)-1 {
+				segments = append(segments, "")
+			}
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	canonical := strings.Join(segments, "/")
+	if strings.HasPrefix(u.Path, "/") && !strings.HasPrefix(canonical, "/") {
+		canonical = "/" + canonical
+	}
+	return canonical, strings.Join(unsafe, ", ")
+}
+
+func hasControl(s string) bool {
+	for _, r := range s {
+		if isControl(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// isControl reports whether a rune is a null byte or an ASCII control character.
+func isControl(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
@@ -0,0 +1,99 @@
+package groupurl
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalizePath(t *testing.T) {
+	for _, tc := range []struct {
+		raw       string
+		canonical string
+		unsafe    string
+	}{
+		{raw: "/users/42/profile", canonical: "/users/42/profile"},
+		{raw: "/static/app.js", canonical: "/static/app.js"},
+		{raw: "/files/a%2Fb/raw", canonical: "/files/a%2Fb/raw", unsafe: "encoded slash"},
+		{raw: "/files/%2F%2F", canonical: "/files/%2F%2F", unsafe: "encoded slash"},
+		{raw: "/a/b/../c", canonical: "/a/c", unsafe: "dot segment"},
+		{raw: "/../../etc/passwd", canonical: "/etc/passwd", unsafe: "dot segment"},
+		{raw: "/a/%2e%2e/b", canonical: "/b", unsafe: "dot segment"},
+		{raw: "/a/./b/.", canonical: "/a/b/", unsafe: "dot segment"},
+		{raw: "/a/b/..", canonical: "/a/", unsafe: "dot segment"},
+		{raw: "/a/..%2Fb", canonical: "/a/..%2Fb", unsafe: "encoded slash"},
+		{raw: "/name%00.php", canonical: "/name.php", unsafe: "control character"},
+		{raw: "/line%0d%0abreak/%7f", canonical: "/linebreak/", unsafe: "control character"},
+		{raw: "/a%2F../%00", canonical: "/a%2F../", unsafe: "encoded slash, control character"},
+	} {
+		u, err := url.Parse(tc.raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		canonical, unsafe := canonicalizePath(u)
+		if canonical != tc.canonical || unsafe != tc.unsafe {
+			t.Errorf("%s: expected %q (%s), got %q (%s)", tc.raw, tc.canonical, tc.unsafe, canonical, unsafe)
+		}
+		if err := CheckPath(u); (err != nil) != (tc.unsafe != "") || err != nil && !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("%s: unexpected CheckPath error %v", tc.raw, err)
+		}
+	}
+}
+
+func TestPathPolicy(t *testing.T) {
+	parse := func(raw string) *url.URL {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	learn := func(policy PathPolicy) Grouper {
+		g, err := New(WithPathPolicy(policy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 100; i++ {
+			g.Add(parse(fmt.Sprintf("/files/%d/raw", i)))
+			g.Add(parse(fmt.Sprintf("/files/%d%%2F..%%2F..%%2Fsecret/raw", i)))
+		}
+		return g
+	}
+
+	// Encoded slashes are decoded into separators and make hostile paths look deeper.
+	asIs := learn(PathAsIs)
+	if _, ok := asIs.trees[5]; !ok {
+		t.Fatal("expected encoded slashes to shift segments without a policy")
+	}
+
+	canonical := learn(PathCanonicalize)
+	if len(canonical.trees) != 1 {
+		t.Fatalf("expected encoded slashes to stay within their segment, got %d trees", len(canonical.trees))
+	}
+	if got := canonical.SimplifyPath(parse("/files/7/../../etc/passwd")); got != "/etc/passwd" {
+		t.Fatalf("expected dot segments to be resolved, got %s", got)
+	}
+	if got := canonical.Freeze().SimplifyPath(parse("/files/1%00/raw")); got != "/files/Number/raw" {
+		t.Fatalf("expected control characters to be stripped, got %s", got)
+	}
+
+	rejected := learn(PathReject)
+	if got := rejected.DepthStats().Total; got != 100 {
+		t.Fatalf("expected hostile URLs not to be recorded, got %d URLs", got)
+	}
+	if got := rejected.SimplifyPath(parse("/files/1%2F..%2Fsecret/raw")); got != "/Rejected" {
+		t.Fatalf("expected hostile URLs to be rejected, got %s", got)
+	}
+	if got := rejected.Freeze().Labels(parse("/files/../raw")); !reflect.DeepEqual(got, []string{"Rejected"}) {
+		t.Fatalf("expected hostile URLs to be rejected, got %v", got)
+	}
+	if got := rejected.SimplifyPath(parse("/files/1/raw")); got != "/files/Number/raw" {
+		t.Fatalf("expected safe URLs to be grouped, got %s", got)
+	}
+
+	if _, err := New(WithPathPolicy(PathPolicy(7))); err == nil {
+		t.Fatal("expected error for an unknown policy")
+	}
+}
@@ -0,0 +1,103 @@
+package groupurl
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net/url"
+)
+
+const (
+	_defaultSampleFull    = 1000
+	_defaultSampleMinRate = 0.001
+)
+
+// SampleTargets sets the rates at which SampleDecision keeps the URLs of each group.
+type SampleTargets struct {
+	// Full is the number of URLs a group may have while all of them are kept. Busier groups are kept at the rate
+	// Full/count, so that each contributes about the same volume downstream. Defaults to 1000.
+	Full int
+	// MinRate is the lowest rate of any group, so that the hottest groups remain visible. Defaults to 0.001.
+	MinRate float64
+	// Rates sets fixed rates for the groups with the given patterns, overriding Full and MinRate.
+	Rates map[string]float64
+}
+
+// WithSampleTargets sets the per-group rates of SampleDecision. Zero Full and MinRate are set to their defaults.
+func WithSampleTargets(targets SampleTargets) Option {
+	return func(g *Grouper) error {
+		if targets.Full < 0 {
+			return fmt.Errorf("full sampling count must not be negative, got %d", targets.Full)
+		}
+		if targets.Full == 0 {
+			targets.Full = _defaultSampleFull
+		}
+		if targets.MinRate == 0 {
+			targets.MinRate = _defaultSampleMinRate
+		}
+		if err := checkRate(targets.MinRate); err != nil {
+			return err
+		}
+		for pattern, rate := range targets.Rates {
+			if err := checkRate(rate); err != nil {
+				return fmt.Errorf("%s: %w", pattern, err)
+			}
+		}
+		g.sampleTargets = &targets
+		return nil
+	}
+}
+
+// SampleDecision reports whether a URL should be kept by a head sampler in a logging or tracing pipeline, keeping
+// every URL of rare groups and a small fraction of those of the hottest ones, as set WithSampleTargets. The decision
+// is random with the probability of SampleRate. The URL is not added.
+func (g Grouper) SampleDecision(u *url.URL) bool {
+	rate := g.SampleRate(u)
+	return rate >= 1 || rand.Float64() < rate
+}
+
+// SampleRate returns the probability that SampleDecision keeps a URL, which kept URLs can record to weigh their
+// counts by 1/rate. URLs of groups the Grouper has not seen are always kept.
+func (g Grouper) SampleRate(u *url.URL) float64 {
+	targets := SampleTargets{Full: _defaultSampleFull, MinRate: _defaultSampleMinRate}
+	if g.sampleTargets != nil {
+		targets = *g.sampleTargets
+	}
+	if rate, ok := targets.Rates[g.Pattern(u)]; ok {
+		return rate
+	}
+
+	count := g.groupCount(u)
+	if count <= targets.Full {
+		return 1
+	}
+	return math.Max(targets.MinRate, float64(targets.Full)/float64(count))
+}
+
+// groupCount returns the number of URLs of the group a URL falls into.
+func (g Grouper) groupCount(u *url.URL) int {
+	if pattern, ok := g.tails.match(u.Path); ok {
+		return g.tails.groups[pattern].count
+	}
+	t, ok := g.trees[treeKey(u.Path)]
+	if !ok {
+		return 0
+	}
+	current := t.Root
+	for _, token := range labelPathTokens(u.Path, g.classifiers) {
+		token = current.route(token)
+		child, ok := current.children.get(token.label.parentOrSelf())
+		if !ok {
+			return 0
+		}
+		current = child
+	}
+	return current.terminal()
+}
+
+func checkRate(rate float64) error {
+	if rate < 0 || rate > 1 || math.IsNaN(rate) {
+		return fmt.Errorf("sampling rate must be in [0, 1], got %v", rate)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestSampleRate(t *testing.T) {
+	g, err := New(WithSampleTargets(SampleTargets{
+		Full:    10,
+		MinRate: 0.05,
+		Rates:   map[string]float64{"/Words": 0},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/users/%d", i)})
+	}
+	for i := 0; i < 1000; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/posts/%d/comments", i)})
+	}
+	for i := 0; i < 5; i++ {
+		g.Add(&url.URL{Path: "/about"})
+	}
+
+	for path, want := range map[string]float64{
+		"/about":             0,
+		"/users/1":           0.1,
+		"/posts/1/comments":  0.05,
+		"/never/seen/at/all": 1,
+	} {
+		if got := g.SampleRate(&url.URL{Path: path}); got != want {
+			t.Errorf("%s: got rate %v, want %v", path, got, want)
+		}
+	}
+	if !g.SampleDecision(&url.URL{Path: "/never/seen/at/all"}) {
+		t.Error("expected a URL of a rare group to be kept")
+	}
+	if g.SampleDecision(&url.URL{Path: "/about"}) {
+		t.Error("expected a URL of a group with a rate of 0 to be dropped")
+	}
+
+	kept := 0
+	for i := 0; i < 10000; i++ {
+		if g.SampleDecision(&url.URL{Path: "/users/1"}) {
+			kept++
+		}
+	}
+	if kept < 800 || kept > 1200 {
+		t.Errorf("kept %d of 10000 URLs at a rate of 0.1", kept)
+	}
+
+	if _, err := New(WithSampleTargets(SampleTargets{Rates: map[string]float64{"/": 2}})); err == nil {
+		t.Error("expected an error for a rate above 1")
+	}
+}
+
+func TestSampleRatePartialTargets(t *testing.T) {
+	g, err := New(WithSampleTargets(SampleTargets{Rates: map[string]float64{"/Words": 0}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/users/%d", i)})
+	}
+	for i := 0; i < 4000; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/posts/%d/comments", i)})
+	}
+	g.Add(&url.URL{Path: "/about"})
+
+	for path, want := range map[string]float64{
+		"/about":   0,
+		"/users/1": 1,
+	} {
+		if got := g.SampleRate(&url.URL{Path: path}); got != want {
+			t.Errorf("%s: got rate %v, want %v", path, got, want)
+		}
+	}
+	if got := g.SampleRate(&url.URL{Path: "/posts/1/comments"}); got < 0.2 || got > 0.3 {
+		t.Errorf("expected a group of about 4000 URLs to be kept at about the default 1000/count, got %v", got)
+	}
+}
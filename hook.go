@@ -0,0 +1,28 @@
+package groupurl
+
+import "net/url"
+
+type (
+	// AddInfo describes an added URL to add hooks.
+	AddInfo struct {
+		// FirstSeen reports whether the exact path had not been added before.
+		// It is only set when WithSeenFilter is used, and false positives of the filter
+		// can occasionally report a new path as already seen.
+		FirstSeen bool
+		// Weight is the number of URLs the added one was counted as, which is above 1 when sampling.
+		Weight int
+		// Method is the HTTP method of URLs added with AddRequest.
+		Method string
+	}
+
+	// AddHook is called synchronously at the end of every Add that was not skipped by sampling.
+	AddHook func(u *url.URL, info AddInfo)
+)
+
+// WithAddHook registers a hook called for every added URL.
+func WithAddHook(hook AddHook) Option {
+	return func(g *Grouper) error {
+		g.addHooks = append(g.addHooks, hook)
+		return nil
+	}
+}
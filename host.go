@@ -0,0 +1,143 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// HostGrouper keeps a separate Grouper per host, as Groupers only track the paths of a single host.
+// It is not safe for concurrent use.
+type HostGrouper struct {
+	options   []Option
+	budget    Budget
+	used      *int
+	hosts     map[string]Grouper
+	fold      bool
+	partition HostPartition
+	origins   map[string]*HostOriginStats
+}
+
+// HostOption configures a HostGrouper.
+type HostOption func(*HostGrouper) error
+
+// WithGrouperOptions sets the options every host's Grouper is created with.
+func WithGrouperOptions(options ...Option) HostOption {
+	return func(h *HostGrouper) error {
+		h.options = append(h.options, options...)
+		return nil
+	}
+}
+
+// WithBudget bounds the distinct tokens recorded across hosts, per host, per tree and per node.
+// Usage is reported by BudgetStats.
+func WithBudget(b Budget) HostOption {
+	return func(h *HostGrouper) error {
+		if b.Global < 0 || b.Host < 0 || b.Tree < 0 || b.Node < 0 {
+			return fmt.Errorf("budgets must not be negative, got %+v", b)
+		}
+		if b.Policy != SpillOverflow && b.Policy != SpillDrop {
+			return fmt.Errorf("unknown spill policy %d", b.Policy)
+		}
+		h.budget = b
+		return nil
+	}
+}
+
+// WithHomoglyphFolding folds the characters of other scripts that look like Latin letters into them before keying
+// Groupers by host, so that lookalike hosts, such as `аpple.com` written with a Cyrillic `а`, share the Grouper of the
+// host they imitate.
+func WithHomoglyphFolding() HostOption {
+	return func(h *HostGrouper) error {
+		h.fold = true
+		return nil
+	}
+}
+
+// NewHostGrouper creates a new HostGrouper with the provided options.
+func NewHostGrouper(options ...HostOption) (*HostGrouper, error) {
+	h := &HostGrouper{
+		used:      new(int),
+		hosts:     make(map[string]Grouper),
+		partition: PartitionPort,
+		origins:   make(map[string]*HostOriginStats),
+	}
+	for _, option := range options {
+		if err := option(h); err != nil {
+			return nil, err
+		}
+	}
+	if h.budget.Node > 0 {
+		h.options = append(h.options, WithCardinalityLimit(h.budget.Node))
+	}
+
+	// Build a Grouper up front so that invalid options are reported here rather than on Add.
+	if _, err := New(h.options...); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Add adds a url to the Grouper of its host, creating it if needed. Hosts are normalized: case, a trailing dot, default
+// ports and the encoding of internationalized names do not make distinct hosts.
+func (h *HostGrouper) Add(u *url.URL) {
+	host := h.partitionKey(u, true)
+	g, ok := h.hosts[host]
+	if !ok {
+		// The options were validated by NewHostGrouper.
+		g, _ = New(h.options...)
+		g.budget = newBudget(h.budget, h.used)
+		h.hosts[host] = g
+	}
+	g.Add(u)
+}
+
+// SimplifyPath simplifies the path of a URL with the Grouper of its host.
+// Paths of hosts that have never been added are returned unchanged.
+func (h *HostGrouper) SimplifyPath(u *url.URL) string {
+	g, ok := h.hosts[h.partitionKey(u, false)]
+	if !ok {
+		return u.Path
+	}
+	return g.SimplifyPath(u)
+}
+
+// Grouper returns the Grouper of a host, which is normalized as by Add. With PartitionScheme, the host is prefixed by
+// its scheme, as listed by Hosts.
+func (h *HostGrouper) Grouper(host string) (Grouper, bool) {
+	u := &url.URL{Host: host}
+	if i := strings.Index(host, "://"); i >= 0 {
+		u.Scheme, u.Host = host[:i], host[i+len("://"):]
+	}
+	g, ok := h.hosts[h.partitionKey(u, false)]
+	return g, ok
+}
+
+// Hosts returns the sorted hosts that have been added, normalized, with internationalized names in their ASCII form.
+func (h *HostGrouper) Hosts() []string {
+	hosts := make([]string, 0, len(h.hosts))
+	for host := range h.hosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// BudgetStats returns the budget usage of every host.
+func (h *HostGrouper) BudgetStats() BudgetStats {
+	stats := BudgetStats{
+		Budget: h.budget,
+		Used:   *h.used,
+		Hosts:  make([]HostBudgetStats, 0, len(h.hosts)),
+	}
+	for host, g := range h.hosts {
+		stats.Hosts = append(stats.Hosts, g.budget.stats(host))
+	}
+	sortHostBudgetStats(stats.Hosts)
+	return stats
+}
+
+func (h *HostGrouper) hostKey(host string) string {
+	return normalizeHost(host, h.fold)
+}
@@ -0,0 +1,262 @@
+package groupurl
+
+import (
+	"errors"
+	"math"
+	"net"
+	"strings"
+	"unicode/utf8"
+)
+
+// Punycode parameters, from RFC 3492.
+const (
+	_punycodeBase        = 36
+	_punycodeTMin        = 1
+	_punycodeTMax        = 26
+	_punycodeSkew        = 38
+	_punycodeDamp        = 700
+	_punycodeInitialBias = 72
+	_punycodeInitialN    = 128
+	_acePrefix           = "xn--"
+)
+
+// _homoglyphs maps characters of other scripts that look like Latin letters or digits to them, following the
+// Unicode confusables most used in lookalike domains.
+var _homoglyphs = map[rune]rune{
+	// Cyrillic.
+	'а': 'a', 'в': 'b', 'с': 'c', 'ԁ': 'd', 'е': 'e', 'ё': 'e', 'һ': 'h', 'і': 'i', 'ї': 'i', 'ј': 'j', 'к': 'k',
+	'ӏ': 'l', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'ԛ': 'q', 'г': 'r', 'ѕ': 's', 'т': 't', 'ս': 'u', 'ѵ': 'v',
+	'ԝ': 'w', 'х': 'x', 'у': 'y', 'ӡ': 'z',
+	// Greek.
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u',
+	'χ': 'x', 'γ': 'y',
+	// Latin lookalikes.
+	'ı': 'i', 'ɡ': 'g', 'ɩ': 'i', 'ɑ': 'a', 'ʟ': 'l', 'ᴄ': 'c', 'ᴏ': 'o', 'ᴠ': 'v', 'ᴡ': 'w', 'ᴢ': 'z',
+}
+
+// _labelSeparators are the characters IDNA treats as dots between the labels of a host.
+var _labelSeparators = strings.NewReplacer("。", ".", "．", ".", "｡", ".")
+
+// normalizeHost returns the canonical form of a host: lower cased, without a trailing dot, with internationalized
+// labels in their ASCII form so that `bücher.example` and `xn--bcher-kva.example` are the same host. With fold,
+// labels only made of characters that look like Latin letters are replaced by the Latin label, so that `аpple.com`
+// written with a Cyrillic `а` is `apple.com`. The port, if any, is kept. Labels that are not valid punycode are only lower cased.
+func normalizeHost(host string, fold bool) string {
+	name, port := host, ""
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		name, port = h, p
+	}
+	if strings.HasPrefix(name, "[") {
+		// IPv6 literals are only lower cased.
+		return strings.ToLower(host)
+	}
+	name = strings.TrimSuffix(_labelSeparators.Replace(strings.ToLower(name)), ".")
+
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		labels[i] = normalizeLabel(label, fold)
+	}
+	name = strings.Join(labels, ".")
+	if port != "" {
+		return net.JoinHostPort(name, port)
+	}
+	return name
+}
+
+// normalizeLabel returns the ASCII form of a label of a host, folding homoglyphs if fold is set.
+func normalizeLabel(label string, fold bool) string {
+	runes := []rune(label)
+	if strings.HasPrefix(label, _acePrefix) {
+		decoded, err := decodePunycode(label[len(_acePrefix):])
+		if err != nil {
+			return label
+		}
+		runes = []rune(strings.ToLower(string(decoded)))
+	}
+	if fold {
+		runes = foldHomoglyphs(runes)
+	}
+	if isASCII(runes) {
+		return string(runes)
+	}
+	encoded, err := encodePunycode(runes)
+	if err != nil {
+		return label
+	}
+	return _acePrefix + encoded
+}
+
+// foldHomoglyphs returns the Latin label a label imitates, or the label itself if it has characters that do not look
+// like Latin ones, so that names genuinely written in another script are kept.
+func foldHomoglyphs(runes []rune) []rune {
+	folded := make([]rune, len(runes))
+	for i, r := range runes {
+		if f, ok := _homoglyphs[r]; ok {
+			r = f
+		}
+		if r >= utf8.RuneSelf {
+			return runes
+		}
+		folded[i] = r
+	}
+	return folded
+}
+
+func isASCII(runes []rune) bool {
+	for _, r := range runes {
+		if r >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+var errPunycodeOverflow = errors.New("punycode overflow")
+
+// encodePunycode encodes a label with the Punycode algorithm of RFC 3492.
+func encodePunycode(input []rune) (string, error) {
+	var output strings.Builder
+	for _, r := range input {
+		if r < utf8.RuneSelf {
+			output.WriteRune(r)
+		}
+	}
+	basic := output.Len()
+	handled := basic
+	if basic > 0 {
+		output.WriteByte('-')
+	}
+
+	n, delta, bias := _punycodeInitialN, 0, _punycodeInitialBias
+	for handled < len(input) {
+		m := math.MaxInt32
+		for _, r := range input {
+			if int(r) >= n && int(r) < m {
+				m = int(r)
+			}
+		}
+		if (m - n) > (math.MaxInt32-delta)/(handled+1) {
+			return "", errPunycodeOverflow
+		}
+		delta += (m - n) * (handled + 1)
+		n = m
+		for _, r := range input {
+			if int(r) < n {
+				delta++
+			}
+			if int(r) != n {
+				continue
+			}
+			q := delta
+			for k := _punycodeBase; ; k += _punycodeBase {
+				t := punycodeThreshold(k, bias)
+				if q < t {
+					break
+				}
+				output.WriteByte(punycodeDigit(t + (q-t)%(_punycodeBase-t)))
+				q = (q - t) / (_punycodeBase - t)
+			}
+			output.WriteByte(punycodeDigit(q))
+			bias = punycodeAdapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+		delta++
+		n++
+	}
+	return output.String(), nil
+}
+
+// decodePunycode decodes a label encoded with the Punycode algorithm of RFC 3492.
+func decodePunycode(encoded string) ([]rune, error) {
+	var output []rune
+	rest := encoded
+	if i := strings.LastIndexByte(encoded, '-'); i >= 0 {
+		for _, r := range encoded[:i] {
+			if r >= utf8.RuneSelf {
+				return nil, errors.New("non-basic code point before the delimiter")
+			}
+			output = append(output, r)
+		}
+		rest = encoded[i+1:]
+	}
+
+	n, i, bias := _punycodeInitialN, 0, _punycodeInitialBias
+	for pos := 0; pos < len(rest); {
+		previous, w := i, 1
+		for k := _punycodeBase; ; k += _punycodeBase {
+			if pos == len(rest) {
+				return nil, errors.New("truncated punycode")
+			}
+			digit := punycodeDigitValue(rest[pos])
+			pos++
+			if digit < 0 {
+				return nil, errors.New("invalid punycode digit")
+			}
+			if digit > (math.MaxInt32-i)/w {
+				return nil, errPunycodeOverflow
+			}
+			i += digit * w
+			t := punycodeThreshold(k, bias)
+			if digit < t {
+				break
+			}
+			w *= _punycodeBase - t
+		}
+		bias = punycodeAdapt(i-previous, len(output)+1, previous == 0)
+		n += i / (len(output) + 1)
+		i %= len(output) + 1
+		if n > utf8.MaxRune {
+			return nil, errPunycodeOverflow
+		}
+		output = append(output, 0)
+		copy(output[i+1:], output[i:])
+		output[i] = rune(n)
+		i++
+	}
+	return output, nil
+}
+
+func punycodeThreshold(k, bias int) int {
+	switch {
+	case k <= bias:
+		return _punycodeTMin
+	case k >= bias+_punycodeTMax:
+		return _punycodeTMax
+	}
+	return k - bias
+}
+
+func punycodeAdapt(delta, points int, first bool) int {
+	if first {
+		delta /= _punycodeDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / points
+	k := 0
+	for delta > ((_punycodeBase-_punycodeTMin)*_punycodeTMax)/2 {
+		delta /= _punycodeBase - _punycodeTMin
+		k += _punycodeBase
+	}
+	return k + (_punycodeBase-_punycodeTMin+1)*delta/(delta+_punycodeSkew)
+}
+
+func punycodeDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+func punycodeDigitValue(c byte) int {
+	switch {
+	case c >= 'a' && c <= 'z':
+		return int(c - 'a')
+	case c >= 'A' && c <= 'Z':
+		return int(c - 'A')
+	case c >= '0' && c <= '9':
+		return int(c-'0') + 26
+	}
+	return -1
+}
@@ -0,0 +1,100 @@
+package groupurl
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestPunycode(t *testing.T) {
+	for _, tc := range []struct {
+		unicode string
+		ascii   string
+	}{
+		{unicode: "bücher", ascii: "bcher-kva"},
+		{unicode: "münchen", ascii: "mnchen-3ya"},
+		{unicode: "ü", ascii: "tda"},
+		{unicode: "пример", ascii: "e1afmkfd"},
+		{unicode: "испытание", ascii: "80akhbyknj4f"},
+		{unicode: "例え", ascii: "r8jz45g"},
+	} {
+		encoded, err := encodePunycode([]rune(tc.unicode))
+		if err != nil || encoded != tc.ascii {
+			t.Errorf("%s: expected %s, got %s (%v)", tc.unicode, tc.ascii, encoded, err)
+		}
+		decoded, err := decodePunycode(tc.ascii)
+		if err != nil || string(decoded) != tc.unicode {
+			t.Errorf("%s: expected %s, got %s (%v)", tc.ascii, tc.unicode, string(decoded), err)
+		}
+	}
+	for _, invalid := range []string{"bcher-kv", "bcher-k!a", "ü-tda", "99999999999"} {
+		if _, err := decodePunycode(invalid); err == nil {
+			t.Errorf("%s: expected an error", invalid)
+		}
+	}
+}
+
+func TestNormalizeHost(t *testing.T) {
+	for _, tc := range []struct {
+		host       string
+		normalized string
+		folded     string
+	}{
+		{host: "Example.COM", normalized: "example.com", folded: "example.com"},
+		{host: "example.com.", normalized: "example.com", folded: "example.com"},
+		{host: "example.com:8080", normalized: "example.com:8080", folded: "example.com:8080"},
+		{host: "BÜCHER.example", normalized: "xn--bcher-kva.example", folded: "xn--bcher-kva.example"},
+		{host: "xn--bcher-kva.example", normalized: "xn--bcher-kva.example", folded: "xn--bcher-kva.example"},
+		{host: "XN--BCHER-KVA.example", normalized: "xn--bcher-kva.example", folded: "xn--bcher-kva.example"},
+		{host: "пример。испытание", normalized: "xn--e1afmkfd.xn--80akhbyknj4f", folded: "xn--e1afmkfd.xn--80akhbyknj4f"},
+		// A Cyrillic а and a Cyrillic р, as Unicode or punycode.
+		{host: "аpple.com", normalized: "xn--pple-43d.com", folded: "apple.com"},
+		{host: "xn--pple-43d.com", normalized: "xn--pple-43d.com", folded: "apple.com"},
+		{host: "аррӏе.com:443", normalized: "xn--80ak6aa92e.com:443", folded: "apple.com:443"},
+		// Labels with characters that do not look like Latin letters are not folded.
+		{host: "bücher.example", normalized: "xn--bcher-kva.example", folded: "xn--bcher-kva.example"},
+		// Greek omicrons.
+		{host: "gοοgle.com", normalized: "xn--ggle-0nda.com", folded: "google.com"},
+		{host: "xn--invalid-!.com", normalized: "xn--invalid-!.com", folded: "xn--invalid-!.com"},
+		{host: "[::1]:80", normalized: "[::1]:80", folded: "[::1]:80"},
+	} {
+		if got := normalizeHost(tc.host, false); got != tc.normalized {
+			t.Errorf("%s: expected %s, got %s", tc.host, tc.normalized, got)
+		}
+		if got := normalizeHost(tc.host, true); got != tc.folded {
+			t.Errorf("%s: expected %s once folded, got %s", tc.host, tc.folded, got)
+		}
+	}
+}
+
+func TestHostGrouperNormalization(t *testing.T) {
+	add := func(h *HostGrouper, hosts ...string) {
+		for _, host := range hosts {
+			h.Add(&url.URL{Host: host, Path: "/users/1"})
+		}
+	}
+	hosts := []string{"bücher.example", "xn--bcher-kva.example.", "аpple.com", "apple.com"}
+
+	h, err := NewHostGrouper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	add(h, hosts...)
+	if got := h.Hosts(); len(got) != 3 || got[1] != "xn--bcher-kva.example" {
+		t.Fatalf("expected internationalized hosts to share a Grouper, got %v", got)
+	}
+	if _, ok := h.Grouper("BÜCHER.example"); !ok {
+		t.Fatal("expected host lookups to be normalized")
+	}
+
+	folded, err := NewHostGrouper(WithHomoglyphFolding())
+	if err != nil {
+		t.Fatal(err)
+	}
+	add(folded, hosts...)
+	if got := folded.Hosts(); len(got) != 2 || got[0] != "apple.com" {
+		t.Fatalf("expected lookalike hosts to share a Grouper, got %v", got)
+	}
+	if g, ok := folded.Grouper("xn--pple-43d.com"); !ok || g.DepthStats().Total != 2 {
+		t.Fatal("expected the lookalike host to find the Grouper of the host it imitates")
+	}
+}
@@ -0,0 +1,106 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// HostPartition sets which parts of a URL, besides its host, pick the Grouper of a HostGrouper.
+type HostPartition int
+
+const (
+	// PartitionPort keeps a Grouper per non-default port of a host, so that `example.com:8080` is grouped apart
+	// from `example.com`. Default ports, 80 for http and 443 for https, never partition a host.
+	PartitionPort HostPartition = 1 << iota
+	// PartitionScheme keeps a Grouper per scheme of a host, so that `http://example.com` is grouped apart from
+	// `https://example.com`. Hosts are then listed with their scheme.
+	PartitionScheme
+)
+
+// _defaultPorts are the ports that are normalized away for their scheme.
+var _defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+}
+
+// WithHostPartition sets which parts of a URL, besides its host, pick its Grouper, see HostPartition. The default,
+// PartitionPort, keeps a Grouper per non-default port; a zero partition keeps a single Grouper per host.
+func WithHostPartition(p HostPartition) HostOption {
+	return func(h *HostGrouper) error {
+		if p&^(PartitionPort|PartitionScheme) != 0 {
+			return fmt.Errorf("unknown host partition %d", p)
+		}
+		h.partition = p
+		return nil
+	}
+}
+
+// HostOriginStats reports the schemes and ports URLs of a host were added with, whether or not they partition it.
+// Ports count the port URLs were sent to, which is the default port of their scheme if they have no explicit port,
+// and empty if the scheme has none.
+type HostOriginStats struct {
+	Host    string
+	Schemes map[string]int
+	Ports   map[string]int
+}
+
+// OriginStats returns the scheme and port distribution of every host, sorted by host.
+func (h *HostGrouper) OriginStats() []HostOriginStats {
+	stats := make([]HostOriginStats, 0, len(h.origins))
+	for host, origins := range h.origins {
+		s := HostOriginStats{
+			Host:    host,
+			Schemes: make(map[string]int, len(origins.Schemes)),
+			Ports:   make(map[string]int, len(origins.Ports)),
+		}
+		for scheme, n := range origins.Schemes {
+			s.Schemes[scheme] = n
+		}
+		for port, n := range origins.Ports {
+			s.Ports[port] = n
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Host < stats[j].Host })
+	return stats
+}
+
+// partitionKey returns the key of the Grouper of a URL, and records its scheme and port.
+func (h *HostGrouper) partitionKey(u *url.URL, record bool) string {
+	scheme := strings.ToLower(u.Scheme)
+	name, port := splitPort(h.hostKey(u.Host))
+	if port == "" {
+		port = _defaultPorts[scheme]
+	}
+	if record {
+		origins, ok := h.origins[name]
+		if !ok {
+			origins = &HostOriginStats{Host: name, Schemes: make(map[string]int), Ports: make(map[string]int)}
+			h.origins[name] = origins
+		}
+		origins.Schemes[scheme]++
+		origins.Ports[port]++
+	}
+
+	key := name
+	if h.partition&PartitionPort != 0 && port != "" && port != _defaultPorts[scheme] {
+		key += ":" + port
+	}
+	if h.partition&PartitionScheme != 0 && scheme != "" {
+		key = scheme + "://" + key
+	}
+	return key
+}
+
+// splitPort splits a normalized host from its port, which is empty if it has none.
+func splitPort(host string) (string, string) {
+	i := strings.LastIndexByte(host, ':')
+	if i < 0 || strings.Contains(host[i:], "]") {
+		return host, ""
+	}
+	return host[:i], host[i+1:]
+}
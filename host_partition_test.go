@@ -0,0 +1,81 @@
+package groupurl
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestHostPartition(t *testing.T) {
+	raws := []string{
+		"http://example.com/users/1",
+		"https://example.com/users/2",
+		"https://example.com:443/users/3",
+		"http://EXAMPLE.com:80/users/4",
+		"http://example.com:8080/users/5",
+		"https://internal.example:8443/items/1",
+	}
+	learn := func(options ...HostOption) *HostGrouper {
+		h, err := NewHostGrouper(options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, raw := range raws {
+			u, err := url.Parse(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			h.Add(u)
+		}
+		return h
+	}
+
+	for _, tc := range []struct {
+		partition HostPartition
+		hosts     []string
+	}{
+		{partition: 0, hosts: []string{"example.com", "internal.example"}},
+		{partition: PartitionPort, hosts: []string{"example.com", "example.com:8080", "internal.example:8443"}},
+		{partition: PartitionScheme, hosts: []string{"http://example.com", "https://example.com", "https://internal.example"}},
+		{partition: PartitionPort | PartitionScheme, hosts: []string{
+			"http://example.com", "http://example.com:8080", "https://example.com", "https://internal.example:8443",
+		}},
+	} {
+		h := learn(WithHostPartition(tc.partition))
+		if got := h.Hosts(); !reflect.DeepEqual(got, tc.hosts) {
+			t.Errorf("partition %d: expected hosts %v, got %v", tc.partition, tc.hosts, got)
+		}
+		for _, host := range tc.hosts {
+			if _, ok := h.Grouper(host); !ok {
+				t.Errorf("partition %d: expected the Grouper of %s", tc.partition, host)
+			}
+		}
+	}
+
+	h := learn()
+	if got := h.Hosts(); len(got) != 3 {
+		t.Fatalf("expected non-default ports to partition hosts by default, got %v", got)
+	}
+	if g, ok := h.Grouper("https://example.com:443"); !ok || g.DepthStats().Total != 4 {
+		t.Fatal("expected default ports to be normalized away")
+	}
+	expected := []HostOriginStats{
+		{
+			Host:    "example.com",
+			Schemes: map[string]int{"http": 3, "https": 2},
+			Ports:   map[string]int{"80": 2, "443": 2, "8080": 1},
+		},
+		{
+			Host:    "internal.example",
+			Schemes: map[string]int{"https": 1},
+			Ports:   map[string]int{"8443": 1},
+		},
+	}
+	if got := h.OriginStats(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected origin stats %+v, got %+v", expected, got)
+	}
+
+	if _, err := NewHostGrouper(WithHostPartition(8)); err == nil {
+		t.Fatal("expected error for an unknown partition")
+	}
+}
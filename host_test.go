@@ -0,0 +1,41 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestHostGrouper(t *testing.T) {
+	h, err := NewHostGrouper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		for _, raw := range []string{"https://a.example/users/%d", "https://B.example/items/%d/details"} {
+			u, err := url.Parse(fmt.Sprintf(raw, i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			h.Add(u)
+		}
+	}
+
+	hosts := h.Hosts()
+	if len(hosts) != 2 || hosts[0] != "a.example" || hosts[1] != "b.example" {
+		t.Fatalf("unexpected hosts %v", hosts)
+	}
+	u, err := url.Parse("https://b.example/items/42/details")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := h.SimplifyPath(u); got != "/items/Number/details" {
+		t.Fatalf("unexpected simplified path %s", got)
+	}
+	if _, ok := h.Grouper("B.example"); !ok {
+		t.Fatal("expected host lookups to ignore case")
+	}
+	if _, err := NewHostGrouper(WithGrouperOptions(WithSampling(2))); err == nil {
+		t.Fatal("expected invalid grouper options to be reported")
+	}
+}
@@ -0,0 +1,138 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/trustleast/groupurl"
+)
+
+const (
+	_checkpointVersion         = 1
+	_defaultCheckpointInterval = 5 * time.Minute
+)
+
+// Checkpoint records how far a Loader has got, along with the state of the Grouper at that point, so that a load
+// that died midway can be resumed with ResumeFrom instead of starting over.
+type Checkpoint struct {
+	Version int `json:"version"`
+	// Key is the object being read, of which the first Line lines have been ingested.
+	Key  string `json:"key"`
+	Line int    `json:"line"`
+	// Stats is the work done up to the checkpoint. Objects does not count the object being read.
+	Stats Stats `json:"stats"`
+	// State is the state of the Grouper as written by Grouper.WriteState.
+	State json.RawMessage `json:"state"`
+}
+
+// CheckpointFunc receives checkpoints. It is called from the goroutine running Load, which waits for it to return.
+type CheckpointFunc func(Checkpoint) error
+
+// WriteTo writes the Checkpoint as JSON.
+func (c Checkpoint) WriteTo(w io.Writer) (int64, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(b, '\n'))
+	return int64(n), err
+}
+
+// ReadCheckpoint reads a Checkpoint written by Checkpoint.WriteTo.
+func ReadCheckpoint(r io.Reader) (Checkpoint, error) {
+	var c Checkpoint
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return Checkpoint{}, fmt.Errorf("failed to decode checkpoint: %w", err)
+	}
+	if c.Version != _checkpointVersion {
+		return Checkpoint{}, fmt.Errorf("unsupported checkpoint version %d", c.Version)
+	}
+	return c, nil
+}
+
+// CheckpointFile returns a CheckpointFunc writing checkpoints to a file. Each checkpoint is written to a temporary
+// file first and then renamed over the previous one, so a crash while writing never loses the last checkpoint.
+func CheckpointFile(path string) CheckpointFunc {
+	return func(c Checkpoint) error {
+		f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+		if err != nil {
+			return fmt.Errorf("failed to create checkpoint: %w", err)
+		}
+		defer os.Remove(f.Name())
+		if _, err := c.WriteTo(f); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write checkpoint: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to write checkpoint: %w", err)
+		}
+		return os.Rename(f.Name(), path)
+	}
+}
+
+// ResumeFrom restores the state of a Grouper from a checkpoint and ingests the rest of the objects, skipping the
+// lines of the checkpoint's object that it already covers. The Grouper must be built with the options of the one
+// the checkpoint was taken from. The returned Stats include the work done before the checkpoint.
+func (l Loader) ResumeFrom(ctx context.Context, c Checkpoint, g groupurl.Grouper) (Stats, error) {
+	if c.Version != _checkpointVersion {
+		return Stats{}, fmt.Errorf("unsupported checkpoint version %d", c.Version)
+	}
+	if err := g.ReadState(bytes.NewReader(c.State)); err != nil {
+		return Stats{}, fmt.Errorf("failed to restore grouper: %w", err)
+	}
+	return l.load(ctx, g, c)
+}
+
+// checkpointer takes a checkpoint at most once per interval.
+type checkpointer struct {
+	save     CheckpointFunc
+	interval time.Duration
+	last     time.Time
+	grouper  groupurl.Grouper
+	lines    int
+}
+
+func newCheckpointer(save CheckpointFunc, interval time.Duration, g groupurl.Grouper) *checkpointer {
+	if interval <= 0 {
+		interval = _defaultCheckpointInterval
+	}
+	return &checkpointer{
+		save:     save,
+		interval: interval,
+		last:     time.Now(),
+		grouper:  g,
+	}
+}
+
+// line is called after every line of the object with the given key, whose first skip lines were ingested before,
+// with the stats of the objects already ingested and of the current one.
+func (c *checkpointer) line(key string, skip int, done, current Stats) error {
+	c.lines++
+	if c.lines%_progressCheckLines != 0 {
+		return nil
+	}
+	now := time.Now()
+	if now.Sub(c.last) < c.interval {
+		return nil
+	}
+	c.last = now
+
+	var state bytes.Buffer
+	if err := c.grouper.WriteState(&state); err != nil {
+		return fmt.Errorf("failed to write grouper state: %w", err)
+	}
+	done.merge(current)
+	return c.save(Checkpoint{
+		Version: _checkpointVersion,
+		Key:     key,
+		Line:    skip + current.Lines,
+		Stats:   done,
+		State:   state.Bytes(),
+	})
+}
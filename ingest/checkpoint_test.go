@@ -0,0 +1,88 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/trustleast/groupurl"
+)
+
+func TestResumeFrom(t *testing.T) {
+	var a, b bytes.Buffer
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&a, "127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] \"GET /items/%d HTTP/1.1\" 200 10\n", i)
+		fmt.Fprintf(&b, "127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] \"GET /docs/page%d HTTP/1.1\" 200 10\n", i%7)
+	}
+	bucket := FSBucket{FS: fstest.MapFS{
+		"logs/a.log": {Data: a.Bytes()},
+		"logs/b.log": {Data: b.Bytes()},
+	}}
+
+	want, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantStats, err := Loader{Bucket: bucket, Prefix: "logs/"}.Load(context.Background(), want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Crash after the first checkpoint in the middle of the first object.
+	errCrash := errors.New("crash")
+	var checkpoint Checkpoint
+	crashed, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader := Loader{
+		Bucket:             bucket,
+		Prefix:             "logs/",
+		CheckpointInterval: 1,
+		Checkpoint: func(c Checkpoint) error {
+			checkpoint = c
+			return errCrash
+		},
+	}
+	if _, err := loader.Load(context.Background(), crashed); !errors.Is(err, errCrash) {
+		t.Fatalf("expected the load to crash, got %v", err)
+	}
+	if checkpoint.Key != "logs/a.log" || checkpoint.Line == 0 || checkpoint.Line >= 1000 {
+		t.Fatalf("unexpected checkpoint position %s:%d", checkpoint.Key, checkpoint.Line)
+	}
+
+	// Resume through a file, as a restarted process would.
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	if err := CheckpointFile(path)(checkpoint); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	read, err := ReadCheckpoint(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := Loader{Bucket: bucket, Prefix: "logs/"}.ResumeFrom(context.Background(), read, resumed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats != wantStats {
+		t.Errorf("resumed stats %+v, want %+v", stats, wantStats)
+	}
+	if resumed.String() != want.String() {
+		t.Errorf("resumed grouper differs\ngot:\n%s\nwant:\n%s", resumed.String(), want.String())
+	}
+}
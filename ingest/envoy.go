@@ -0,0 +1,138 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+
+	"github.com/trustleast/groupurl"
+)
+
+// EnvoyFields names the keys of an Envoy JSON access log entry that hold the request details.
+type EnvoyFields struct {
+	Path         string
+	Authority    string
+	ResponseCode string
+}
+
+// DefaultEnvoyFields returns the keys used by the Envoy and Istio default JSON access log formats.
+func DefaultEnvoyFields() EnvoyFields {
+	return EnvoyFields{
+		Path:         "path",
+		Authority:    "authority",
+		ResponseCode: "response_code",
+	}
+}
+
+// EnvoyEntry is the request information reconstructed from an Envoy access log line.
+type EnvoyEntry struct {
+	URL          *url.URL
+	ResponseCode int
+}
+
+// ParseEnvoyLine parses a single Envoy JSON access log line.
+// A missing or non-numeric response code is reported as 0.
+func ParseEnvoyLine(line string, fields EnvoyFields) (EnvoyEntry, error) {
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return EnvoyEntry{}, err
+	}
+
+	path, _ := entry[fields.Path].(string)
+	if path == "" || path == "-" {
+		return EnvoyEntry{}, fmt.Errorf("missing %s in %q", fields.Path, line)
+	}
+	u, err := url.ParseRequestURI(path)
+	if err != nil {
+		return EnvoyEntry{}, err
+	}
+	if authority, _ := entry[fields.Authority].(string); authority != "" && authority != "-" {
+		u.Host = authority
+	}
+
+	var code int
+	switch v := entry[fields.ResponseCode].(type) {
+	case float64:
+		code = int(v)
+	case string:
+		code, _ = strconv.Atoi(v)
+	}
+
+	return EnvoyEntry{
+		URL:          u,
+		ResponseCode: code,
+	}, nil
+}
+
+// EnvoyParser returns a LineParser for Envoy JSON access logs, for use with Read and Loader.
+func EnvoyParser(fields EnvoyFields) LineParser {
+	return func(line string) (*url.URL, error) {
+		entry, err := ParseEnvoyLine(line, fields)
+		return entry.URL, err
+	}
+}
+
+// StatusGroupers keeps a separate Grouper per response code class so that, for example,
+// URLs of 404 responses from scanners do not influence the grouping of successful requests.
+type StatusGroupers struct {
+	options  []groupurl.Option
+	groupers map[int]groupurl.Grouper
+}
+
+// NewStatusGroupers creates a StatusGroupers whose Groupers are built with the provided options.
+func NewStatusGroupers(options ...groupurl.Option) *StatusGroupers {
+	return &StatusGroupers{
+		options:  options,
+		groupers: make(map[int]groupurl.Grouper),
+	}
+}
+
+// Add adds a URL to the Grouper of the class of the response code, e.g. class 2 for 2xx responses.
+func (s *StatusGroupers) Add(code int, u *url.URL) error {
+	class := code / 100
+	g, ok := s.groupers[class]
+	if !ok {
+		var err error
+		g, err = groupurl.New(s.options...)
+		if err != nil {
+			return err
+		}
+		s.groupers[class] = g
+	}
+	g.Add(u)
+	return nil
+}
+
+// Grouper returns the Grouper of a response code class, if any URLs were added to it.
+func (s *StatusGroupers) Grouper(class int) (groupurl.Grouper, bool) {
+	g, ok := s.groupers[class]
+	return g, ok
+}
+
+// ReadEnvoy adds the URLs of every Envoy JSON access log line in r to the Grouper of its response code class.
+func ReadEnvoy(ctx context.Context, r io.Reader, fields EnvoyFields, groupers *StatusGroupers) (Stats, error) {
+	var (
+		stats  Stats
+		addErr error
+	)
+	err := ScanLines(ctx, r, func(line string) error {
+		stats.Lines++
+		entry, err := ParseEnvoyLine(line, fields)
+		if err != nil {
+			stats.Skipped++
+			return nil
+		}
+		if err := groupers.Add(entry.ResponseCode, entry.URL); err != nil && addErr == nil {
+			addErr = err
+		}
+		stats.Added++
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+	return stats, addErr
+}
@@ -0,0 +1,42 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestReadEnvoy(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&sb, `{"method":"GET","path":"/carts/%d","authority":"shop.example.com","response_code":200}`+"\n", i)
+	}
+	sb.WriteString(`{"method":"GET","path":"/.env","authority":"shop.example.com","response_code":"404"}` + "\n")
+	sb.WriteString("not json\n")
+
+	groupers := NewStatusGroupers()
+	stats, err := ReadEnvoy(context.Background(), strings.NewReader(sb.String()), DefaultEnvoyFields(), groupers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Added != 101 || stats.Skipped != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	ok, found := groupers.Grouper(2)
+	if !found {
+		t.Fatal("expected a grouper for 2xx responses")
+	}
+	u, err := url.Parse("https://shop.example.com/carts/7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path := ok.SimplifyPath(u); path != "/carts/Number" {
+		t.Fatalf("expected /carts/Number, got %s", path)
+	}
+	if _, found := groupers.Grouper(4); !found {
+		t.Fatal("expected a grouper for 4xx responses")
+	}
+}
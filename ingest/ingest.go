@@ -0,0 +1,306 @@
+// Package ingest trains Groupers from access logs stored in files or object storage.
+//
+// Object stores such as S3 and GCS are accessed through the small Bucket interface so this package
+// does not depend on any vendor SDK. Callers wrap their client of choice, or use FSBucket for local
+// directories and mounted buckets.
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/trustleast/groupurl"
+)
+
+// Bucket lists and opens log objects.
+type Bucket interface {
+	// List returns the keys of all objects starting with prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Open returns a reader for the content of an object.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// FSBucket is a Bucket backed by an fs.FS, such as os.DirFS.
+type FSBucket struct {
+	FS fs.FS
+}
+
+func (b FSBucket) List(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := fs.WalkDir(b.FS, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasPrefix(path, prefix) {
+			keys = append(keys, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (b FSBucket) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	return b.FS.Open(key)
+}
+
+// LineParser extracts the URL from a single log line.
+// Returning a nil URL and nil error skips the line.
+type LineParser func(line string) (*url.URL, error)
+
+// RequestParser extracts the HTTP method and the URL from a single log line, so that URLs are also counted by
+// method as by Grouper.AddRequest. An empty method adds the URL without one.
+// Returning a nil URL and nil error skips the line.
+type RequestParser func(line string) (string, *url.URL, error)
+
+// request returns a RequestParser reporting no method.
+func (p LineParser) request() RequestParser {
+	return func(line string) (string, *url.URL, error) {
+		u, err := p(line)
+		return "", u, err
+	}
+}
+
+// Stats reports the work done while ingesting logs.
+type Stats struct {
+	Objects int
+	Lines   int
+	Added   int
+	Skipped int
+}
+
+func (s *Stats) merge(o Stats) {
+	s.Objects += o.Objects
+	s.Lines += o.Lines
+	s.Added += o.Added
+	s.Skipped += o.Skipped
+}
+
+// Loader reads every object under a prefix of a Bucket and adds the URLs found in them to a Grouper.
+type Loader struct {
+	Bucket Bucket
+	Prefix string
+	// Parser extracts URLs from lines.
+	Parser LineParser
+	// RequestParser extracts the method and URL of lines instead of Parser if set. Lines are parsed with
+	// ParseCommonLogRequest if neither is.
+	RequestParser RequestParser
+	// Progress, if set, receives reports of the progress of Load every ProgressInterval, which defaults to a
+	// second, and once more when it returns. When resuming, only the work left is reported.
+	Progress         ProgressFunc
+	ProgressInterval time.Duration
+	// Checkpoint, if set, receives a Checkpoint every CheckpointInterval, which defaults to five minutes.
+	// An error returned by it stops the load.
+	Checkpoint         CheckpointFunc
+	CheckpointInterval time.Duration
+}
+
+// Load ingests all objects under the Loader's prefix in lexical key order.
+// Lines that fail to parse are counted as skipped, failures reading objects stop the load.
+func (l Loader) Load(ctx context.Context, g groupurl.Grouper) (Stats, error) {
+	return l.load(ctx, g, Checkpoint{})
+}
+
+// load ingests the objects from the position of a checkpoint on, starting from its stats.
+func (l Loader) load(ctx context.Context, g groupurl.Grouper, from Checkpoint) (Stats, error) {
+	stats := from.Stats
+	keys, err := l.Bucket.List(ctx, l.Prefix)
+	if err != nil {
+		return stats, fmt.Errorf("failed to list objects: %w", err)
+	}
+	sort.Strings(keys)
+	keys = keys[sort.SearchStrings(keys, from.Key):]
+
+	var tracker *progressTracker
+	if l.Progress != nil {
+		tracker = newProgressTracker(l.Progress, l.ProgressInterval, l.totalSize(ctx, keys))
+		defer tracker.finish()
+	}
+	var checkpoints *checkpointer
+	if l.Checkpoint != nil {
+		checkpoints = newCheckpointer(l.Checkpoint, l.CheckpointInterval, g)
+	}
+
+	for _, key := range keys {
+		var skip int
+		if key == from.Key {
+			skip = from.Line
+		}
+		var line func(Stats) error
+		if tracker != nil || checkpoints != nil {
+			line = func(current Stats) error {
+				if tracker != nil {
+					tracker.line(current)
+				}
+				if checkpoints != nil {
+					return checkpoints.line(key, skip, stats, current)
+				}
+				return nil
+			}
+		}
+		objectStats, err := l.loadObject(ctx, key, skip, g, tracker, line)
+		stats.merge(objectStats)
+		if tracker != nil {
+			tracker.object(objectStats)
+		}
+		if err != nil {
+			return stats, fmt.Errorf("failed to load %s: %w", key, err)
+		}
+	}
+	return stats, nil
+}
+
+// loadObject ingests an object after skipping its first lines, calling line if set after every other line.
+func (l Loader) loadObject(ctx context.Context, key string, skip int, g groupurl.Grouper, tracker *progressTracker,
+	line func(Stats) error) (Stats, error) {
+	rc, err := l.Bucket.Open(ctx, key)
+	if err != nil {
+		return Stats{}, err
+	}
+	defer rc.Close()
+
+	parser := l.RequestParser
+	if parser == nil {
+		parser = ParseCommonLogRequest
+		if l.Parser != nil {
+			parser = l.Parser.request()
+		}
+	}
+	var r io.Reader = rc
+	if tracker != nil {
+		r = countingReader{r: rc, tracker: tracker}
+	}
+	stats, err := read(ctx, r, parser, g, skip, line)
+	stats.Objects++
+	return stats, err
+}
+
+// totalSize returns the size of the objects, or 0 if it is unknown.
+func (l Loader) totalSize(ctx context.Context, keys []string) int64 {
+	sized, ok := l.Bucket.(SizedBucket)
+	if !ok {
+		return 0
+	}
+	var total int64
+	for _, key := range keys {
+		size, err := sized.Size(ctx, key)
+		if err != nil {
+			return 0
+		}
+		total += size
+	}
+	return total
+}
+
+// Read adds the URLs of every line in r to a Grouper.
+// Gzip compressed input is detected and decompressed transparently.
+func Read(ctx context.Context, r io.Reader, parser LineParser, g groupurl.Grouper) (Stats, error) {
+	return read(ctx, r, parser.request(), g, 0, nil)
+}
+
+// ReadRequests adds the URLs of every line in r to a Grouper along with their HTTP method, as Read does.
+func ReadRequests(ctx context.Context, r io.Reader, parser RequestParser, g groupurl.Grouper) (Stats, error) {
+	return read(ctx, r, parser, g, 0, nil)
+}
+
+// read is Read ignoring the first skip lines, and calling progress if set with the stats so far after every other
+// line. An error returned by progress stops reading.
+func read(ctx context.Context, r io.Reader, parser RequestParser, g groupurl.Grouper, skip int,
+	progress func(Stats) error) (Stats, error) {
+	var stats Stats
+	err := ScanLines(ctx, r, func(line string) error {
+		if skip > 0 {
+			skip--
+			return nil
+		}
+		stats.Lines++
+		if method, u, err := parser(line); err != nil || u == nil {
+			stats.Skipped++
+		} else {
+			g.AddRequest(method, u)
+			stats.Added++
+		}
+		if progress != nil {
+			return progress(stats)
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// ScanLines calls f with every line of r, such as a line of an access log, until ctx is done or f returns an error.
+// Gzip compressed input is detected and decompressed transparently.
+func ScanLines(ctx context.Context, r io.Reader, f func(line string) error) error {
+	r, err := decompress(r)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := f(scanner.Text()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+var _gzipMagic = []byte{0x1f, 0x8b}
+
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(_gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if !bytes.Equal(magic, _gzipMagic) {
+		return br, nil
+	}
+	return gzip.NewReader(br)
+}
+
+// ParseCommonLog extracts the request URL from a line in the Common or Combined Log Format, e.g.
+//
+//	127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326
+func ParseCommonLog(line string) (*url.URL, error) {
+	_, u, err := ParseCommonLogRequest(line)
+	return u, err
+}
+
+// ParseCommonLogRequest extracts the request method and URL from a line in the Common or Combined Log Format.
+func ParseCommonLogRequest(line string) (string, *url.URL, error) {
+	start := strings.IndexByte(line, '"')
+	if start < 0 {
+		return "", nil, fmt.Errorf("missing request in %q", line)
+	}
+	end := strings.IndexByte(line[start+1:], '"')
+	if end < 0 {
+		return "", nil, fmt.Errorf("unterminated request in %q", line)
+	}
+
+	fields := strings.Fields(line[start+1 : start+1+end])
+	if len(fields) < 2 {
+		return "", nil, fmt.Errorf("malformed request in %q", line)
+	}
+	u, err := url.ParseRequestURI(fields[1])
+	if err != nil {
+		return "", nil, err
+	}
+	return fields[0], u, nil
+}
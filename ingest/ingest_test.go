@@ -0,0 +1,55 @@
+package ingest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+	"testing/fstest"
+
+	"github.com/trustleast/groupurl"
+)
+
+func TestLoader(t *testing.T) {
+	var plain, compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&plain, "127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] \"GET /items/%d HTTP/1.1\" 200 10\n", i)
+		fmt.Fprintf(gz, "127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] \"GET /items/%d HTTP/1.1\" 200 10\n", i+100)
+	}
+	plain.WriteString("garbage\n")
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	bucket := FSBucket{FS: fstest.MapFS{
+		"logs/a.log":    {Data: plain.Bytes()},
+		"logs/b.log.gz": {Data: compressed.Bytes()},
+		"other/c.log":   {Data: []byte("ignored")},
+	}}
+
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := Loader{Bucket: bucket, Prefix: "logs/"}.Load(context.Background(), g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Objects != 2 || stats.Added != 200 || stats.Skipped != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	u, err := url.Parse("/items/5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path := g.SimplifyPath(u); path != "/items/Number" {
+		t.Fatalf("expected /items/Number, got %s", path)
+	}
+	if got := g.Methods("/Words/Number"); got["GET"] != 200 {
+		t.Fatalf("expected common log lines to be counted by method, got %v", got)
+	}
+}
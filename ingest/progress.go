@@ -0,0 +1,137 @@
+package ingest
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"time"
+)
+
+const (
+	_defaultProgressInterval = time.Second
+	// _progressCheckLines is the number of lines read between checks of whether progress is due.
+	_progressCheckLines = 256
+)
+
+// SizedBucket is implemented by Buckets that can report the size of their objects without reading them,
+// which lets a Loader estimate the time remaining.
+type SizedBucket interface {
+	Bucket
+	// Size returns the size in bytes of an object as stored, before any decompression.
+	Size(ctx context.Context, key string) (int64, error)
+}
+
+func (b FSBucket) Size(_ context.Context, key string) (int64, error) {
+	info, err := fs.Stat(b.FS, key)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Progress reports how far a Loader has got through its objects.
+type Progress struct {
+	Stats
+	// Bytes is the number of bytes read from objects as stored, before any decompression.
+	Bytes int64
+	// TotalBytes is the size of all objects, or 0 if the Bucket is not a SizedBucket.
+	TotalBytes int64
+	Elapsed    time.Duration
+	// Rate is the number of lines read per second.
+	Rate float64
+	// ETA estimates the time remaining from the rate bytes are read at, or 0 if TotalBytes is unknown.
+	ETA time.Duration
+	// Done is set on the last report of a load, whether it succeeded or not.
+	Done bool
+}
+
+// Fraction returns the share of TotalBytes read so far, or 0 if it is unknown.
+func (p Progress) Fraction() float64 {
+	if p.TotalBytes <= 0 {
+		return 0
+	}
+	if p.Bytes >= p.TotalBytes {
+		return 1
+	}
+	return float64(p.Bytes) / float64(p.TotalBytes)
+}
+
+// ProgressFunc receives progress reports. It is called from the goroutine running Load.
+type ProgressFunc func(Progress)
+
+// progressTracker accumulates the progress of a load and reports it at most once per interval.
+type progressTracker struct {
+	report   ProgressFunc
+	interval time.Duration
+	start    time.Time
+	last     time.Time
+	total    int64
+	bytes    int64
+	done     Stats
+	lines    int
+}
+
+func newProgressTracker(report ProgressFunc, interval time.Duration, total int64) *progressTracker {
+	if interval <= 0 {
+		interval = _defaultProgressInterval
+	}
+	now := time.Now()
+	return &progressTracker{
+		report:   report,
+		interval: interval,
+		start:    now,
+		last:     now,
+		total:    total,
+	}
+}
+
+// line is called after every line of the current object, whose stats so far are current.
+func (t *progressTracker) line(current Stats) {
+	t.lines++
+	if t.lines%_progressCheckLines != 0 {
+		return
+	}
+	if now := time.Now(); now.Sub(t.last) >= t.interval {
+		t.last = now
+		t.report(t.progress(current, now, false))
+	}
+}
+
+// object is called once an object has been read.
+func (t *progressTracker) object(stats Stats) {
+	t.done.merge(stats)
+}
+
+func (t *progressTracker) finish() {
+	t.report(t.progress(Stats{}, time.Now(), true))
+}
+
+func (t *progressTracker) progress(current Stats, now time.Time, done bool) Progress {
+	p := Progress{
+		Stats:      t.done,
+		Bytes:      t.bytes,
+		TotalBytes: t.total,
+		Elapsed:    now.Sub(t.start),
+		Done:       done,
+	}
+	p.Stats.merge(current)
+	if seconds := p.Elapsed.Seconds(); seconds > 0 {
+		p.Rate = float64(p.Lines) / seconds
+		if p.TotalBytes > p.Bytes && p.Bytes > 0 {
+			p.ETA = time.Duration(float64(p.TotalBytes-p.Bytes) / (float64(p.Bytes) / seconds) * float64(time.Second))
+		}
+	}
+	return p
+}
+
+// countingReader counts the bytes read through it into a progressTracker.
+type countingReader struct {
+	r       io.Reader
+	tracker *progressTracker
+}
+
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.tracker.bytes += int64(n)
+	return n, err
+}
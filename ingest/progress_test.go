@@ -0,0 +1,77 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/trustleast/groupurl"
+)
+
+func TestLoaderProgress(t *testing.T) {
+	var a, b bytes.Buffer
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&a, "127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] \"GET /items/%d HTTP/1.1\" 200 10\n", i)
+		fmt.Fprintf(&b, "127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] \"GET /users/%d HTTP/1.1\" 200 10\n", i)
+	}
+	bucket := FSBucket{FS: fstest.MapFS{
+		"logs/a.log": {Data: a.Bytes()},
+		"logs/b.log": {Data: b.Bytes()},
+	}}
+
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reports []Progress
+	loader := Loader{
+		Bucket:           bucket,
+		Prefix:           "logs/",
+		Progress:         func(p Progress) { reports = append(reports, p) },
+		ProgressInterval: 1,
+	}
+	if _, err := loader.Load(context.Background(), g); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reports) < 2 {
+		t.Fatalf("expected intermediate reports, got %d", len(reports))
+	}
+	for i := 1; i < len(reports); i++ {
+		if reports[i].Lines < reports[i-1].Lines || reports[i].Bytes < reports[i-1].Bytes {
+			t.Errorf("progress went backwards from %+v to %+v", reports[i-1], reports[i])
+		}
+	}
+	for _, p := range reports[:len(reports)-1] {
+		if p.Done {
+			t.Errorf("intermediate report %+v is done", p)
+		}
+	}
+
+	last := reports[len(reports)-1]
+	total := int64(a.Len() + b.Len())
+	if !last.Done || last.Lines != 2000 || last.Objects != 2 || last.TotalBytes != total || last.Bytes != total {
+		t.Errorf("unexpected final report %+v, want 2000 lines of 2 objects and %d bytes", last, total)
+	}
+	if last.Fraction() != 1 {
+		t.Errorf("expected the final report to be complete, got %v", last.Fraction())
+	}
+}
+
+func TestProgressFraction(t *testing.T) {
+	tests := []struct {
+		progress Progress
+		want     float64
+	}{
+		{Progress{Bytes: 10}, 0},
+		{Progress{Bytes: 25, TotalBytes: 100}, 0.25},
+		{Progress{Bytes: 200, TotalBytes: 100}, 1},
+	}
+	for _, tc := range tests {
+		if got := tc.progress.Fraction(); got != tc.want {
+			t.Errorf("Fraction of %+v = %v, want %v", tc.progress, got, tc.want)
+		}
+	}
+}
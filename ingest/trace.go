@@ -0,0 +1,164 @@
+package ingest
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/trustleast/groupurl"
+)
+
+// _urlAttributes are the span attributes URLs are read from, in order of preference.
+// url.full and url.path follow the current OpenTelemetry semantic conventions, the others are their older names.
+var _urlAttributes = []string{"url.full", "http.url", "url.path", "http.target"}
+
+// Span is the subset of a trace span needed to learn route patterns.
+type Span struct {
+	TraceID string
+	SpanID  string
+	Name    string
+	URL     *url.URL
+}
+
+type otlpAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type otlpSpans struct {
+	Spans []struct {
+		TraceID    string          `json:"traceId"`
+		SpanID     string          `json:"spanId"`
+		Name       string          `json:"name"`
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"spans"`
+}
+
+type otlpExport struct {
+	ResourceSpans []struct {
+		ScopeSpans                  []otlpSpans `json:"scopeSpans"`
+		InstrumentationLibrarySpans []otlpSpans `json:"instrumentationLibrarySpans"`
+	} `json:"resourceSpans"`
+}
+
+// ReadOTLPSpans reads spans from an OTLP JSON export.
+// Spans without a URL attribute are returned with a nil URL.
+func ReadOTLPSpans(r io.Reader) ([]Span, error) {
+	var export otlpExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, err
+	}
+
+	var spans []Span
+	for _, resource := range export.ResourceSpans {
+		for _, scope := range append(resource.ScopeSpans, resource.InstrumentationLibrarySpans...) {
+			for _, s := range scope.Spans {
+				attributes := make(map[string]string, len(s.Attributes))
+				for _, a := range s.Attributes {
+					attributes[a.Key] = a.Value.StringValue
+				}
+				spans = append(spans, Span{
+					TraceID: s.TraceID,
+					SpanID:  s.SpanID,
+					Name:    s.Name,
+					URL:     spanURL(attributes),
+				})
+			}
+		}
+	}
+	return spans, nil
+}
+
+type jaegerExport struct {
+	Data []struct {
+		Spans []struct {
+			TraceID       string `json:"traceID"`
+			SpanID        string `json:"spanID"`
+			OperationName string `json:"operationName"`
+			Tags          []struct {
+				Key   string `json:"key"`
+				Value any    `json:"value"`
+			} `json:"tags"`
+		} `json:"spans"`
+	} `json:"data"`
+}
+
+// ReadJaegerSpans reads spans from a Jaeger JSON export, as returned by the Jaeger query API.
+// Spans without a URL tag are returned with a nil URL.
+func ReadJaegerSpans(r io.Reader) ([]Span, error) {
+	var export jaegerExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, err
+	}
+
+	var spans []Span
+	for _, trace := range export.Data {
+		for _, s := range trace.Spans {
+			tags := make(map[string]string, len(s.Tags))
+			for _, tag := range s.Tags {
+				if v, ok := tag.Value.(string); ok {
+					tags[tag.Key] = v
+				}
+			}
+			spans = append(spans, Span{
+				TraceID: s.TraceID,
+				SpanID:  s.SpanID,
+				Name:    s.OperationName,
+				URL:     spanURL(tags),
+			})
+		}
+	}
+	return spans, nil
+}
+
+func spanURL(attributes map[string]string) *url.URL {
+	for _, key := range _urlAttributes {
+		if v := attributes[key]; v != "" {
+			if u, err := url.Parse(v); err == nil {
+				return u
+			}
+		}
+	}
+	return nil
+}
+
+// TrainSpans adds the URLs of spans to a Grouper and returns how many were added.
+func TrainSpans(g groupurl.Grouper, spans []Span) int {
+	var added int
+	for _, s := range spans {
+		if s.URL != nil {
+			g.Add(s.URL)
+			added++
+		}
+	}
+	return added
+}
+
+// SpanNames returns a mapping from original span names to names built from the simplified path of the span URL.
+// A leading HTTP method in the original name, as in "GET /users/42", is preserved.
+// Spans without a URL are left out of the mapping.
+func SpanNames(g groupurl.Grouper, spans []Span) map[string]string {
+	names := make(map[string]string)
+	for _, s := range spans {
+		if s.URL == nil {
+			continue
+		}
+		name := g.SimplifyPath(s.URL)
+		if method, _, ok := strings.Cut(s.Name, " "); ok && isHTTPMethod(method) {
+			name = method + " " + name
+		}
+		names[s.Name] = name
+	}
+	return names
+}
+
+func isHTTPMethod(s string) bool {
+	switch s {
+	case "GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "CONNECT", "OPTIONS", "TRACE":
+		return true
+	}
+	return false
+}
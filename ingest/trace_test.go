@@ -0,0 +1,40 @@
+package ingest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/trustleast/groupurl"
+)
+
+func TestTraceSpans(t *testing.T) {
+	var otlp, jaeger []string
+	for i := 0; i < 60; i++ {
+		otlp = append(otlp, fmt.Sprintf(`{"spanId":"%d","name":"GET /users/%d","attributes":[{"key":"url.full","value":{"stringValue":"https://api.example.com/users/%d"}}]}`, i, i, i))
+		jaeger = append(jaeger, fmt.Sprintf(`{"spanID":"%d","operationName":"GET /users/%d","tags":[{"key":"http.url","type":"string","value":"https://api.example.com/users/%d"}]}`, i+60, i+60, i+60))
+	}
+
+	otlpSpans, err := ReadOTLPSpans(strings.NewReader(`{"resourceSpans":[{"scopeSpans":[{"spans":[` + strings.Join(otlp, ",") + `]}]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jaegerSpans, err := ReadJaegerSpans(strings.NewReader(`{"data":[{"spans":[` + strings.Join(jaeger, ",") + `]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	spans := append(otlpSpans, jaegerSpans...)
+	if added := TrainSpans(g, spans); added != 120 {
+		t.Fatalf("expected 120 spans added, got %d", added)
+	}
+
+	names := SpanNames(g, spans)
+	if name := names["GET /users/99"]; name != "GET /users/Number" {
+		t.Fatalf("expected GET /users/Number, got %s", name)
+	}
+}
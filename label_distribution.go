@@ -0,0 +1,110 @@
+package groupurl
+
+import (
+	"fmt"
+	"sort"
+)
+
+// _labelHysteresis is the share a label needs above WithMajorityLabels for a node that fell back to the parent label
+// to take it again, so that nodes near the threshold do not flap between labels.
+const _labelHysteresis = 0.05
+
+// LabelCount is the number of URLs whose segment at a node was classified under a label.
+type LabelCount struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// WithMajorityLabels resolves the label of nodes whose segments are classified under several labels sharing a parent,
+// such as Words and Number under AlphaNumeric, by the distribution of labels counted at each node rather than by
+// falling back to the parent label for good as soon as a second label is seen. A node keeps its label as long as at
+// least minShare of its URLs had it, and falls back to the parent label otherwise. A node that fell back takes the
+// most frequent label again once its share reaches minShare plus a margin, so that it does not flap.
+func WithMajorityLabels(minShare float64) Option {
+	return func(g *Grouper) error {
+		if minShare <= 0 || minShare > 1 {
+			return fmt.Errorf("label share must be in (0, 1], got %f", minShare)
+		}
+		g.tree.labelShare = minShare
+		return nil
+	}
+}
+
+// observeLabel counts the label a segment of the node was classified under.
+func (n *urlNode) observeLabel(label LabelFields, weight int) {
+	if n.labels == nil {
+		n.labels = make(map[LabelFields]int, 1)
+	}
+	n.labels[label] += weight
+}
+
+// labelDistribution returns the labels counted at the node, by decreasing count.
+func (n *urlNode) labelDistribution() []LabelCount {
+	counts := make(map[string]int, len(n.labels))
+	for label, count := range n.labels {
+		counts[label.Value] += count
+	}
+	distribution := make([]LabelCount, 0, len(counts))
+	for label, count := range counts {
+		distribution = append(distribution, LabelCount{Label: label, Count: count})
+	}
+	sort.Slice(distribution, func(i, j int) bool {
+		if distribution[i].Count != distribution[j].Count {
+			return distribution[i].Count > distribution[j].Count
+		}
+		return distribution[i].Label < distribution[j].Label
+	})
+	return distribution
+}
+
+// resolveLabel returns the label a node should have after counting a segment classified under label. Without
+// WithMajorityLabels, a node falls back to the parent label as soon as it sees a label other than its own.
+func (t urlTree) resolveLabel(n *urlNode, label Label) LabelFields {
+	parent := label.parentOrSelf()
+	if t.labelShare == 0 {
+		if n.specificLabel.Value != label.Value && n.specificLabel != parent {
+			return parent
+		}
+		return n.specificLabel
+	}
+
+	var top LabelFields
+	var topCount, total int
+	for l, count := range n.labels {
+		total += count
+		if count > topCount || (count == topCount && lessLabelFields(l, top)) {
+			top, topCount = l, count
+		}
+	}
+	if total == 0 {
+		return n.specificLabel
+	}
+	if n.specificLabel != parent {
+		if float64(n.labels[n.specificLabel]) < t.labelShare*float64(total) {
+			return parent
+		}
+		return n.specificLabel
+	}
+	if top != parent && float64(topCount) >= minFloat(t.labelShare+_labelHysteresis, 1)*float64(total) {
+		return top
+	}
+	return n.specificLabel
+}
+
+func cloneLabelCounts(labels map[LabelFields]int) map[LabelFields]int {
+	if labels == nil {
+		return nil
+	}
+	clone := make(map[LabelFields]int, len(labels))
+	for label, count := range labels {
+		clone[label] = count
+	}
+	return clone
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
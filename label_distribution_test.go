@@ -0,0 +1,97 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// letters spells i in base 26 with the letters a to z, so that every i gives a distinct word.
+func letters(i int) string {
+	var sb strings.Builder
+	for {
+		sb.WriteByte(byte('a' + i%26))
+		i /= 26
+		if i == 0 {
+			return sb.String()
+		}
+	}
+}
+
+func TestMajorityLabels(t *testing.T) {
+	majority, err := New(WithMajorityLabels(0.9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	words, numbers := 0, 0
+	add := func(nWords, nNumbers int) {
+		for i := 0; i < nWords; i++ {
+			u := &url.URL{Path: "/items/item" + letters(words)}
+			majority.Add(u)
+			first.Add(u)
+			words++
+		}
+		for i := 0; i < nNumbers; i++ {
+			u := &url.URL{Path: fmt.Sprintf("/items/%d", numbers)}
+			majority.Add(u)
+			first.Add(u)
+			numbers++
+		}
+	}
+	label := func(g Grouper) string {
+		return g.Labels(&url.URL{Path: "/items/itemz"})[1]
+	}
+
+	add(95, 5)
+	if got := label(first); got != "AlphaNumeric" {
+		t.Fatalf("expected the first other label to merge the node, got %s", got)
+	}
+	if got := label(majority); got != "Words" {
+		t.Fatalf("expected the majority label to be kept, got %s", got)
+	}
+	segment := majority.Explain(&url.URL{Path: "/items/itemz"}).Segments[1]
+	if len(segment.Labels) != 2 || segment.Labels[0] != (LabelCount{Label: "Words", Count: 95}) {
+		t.Fatalf("expected the distribution in the explanation, got %+v", segment.Labels)
+	}
+
+	add(0, 20)
+	if got := label(majority); got != "AlphaNumeric" {
+		t.Fatalf("expected the parent label below the share, got %s", got)
+	}
+	add(380, 0)
+	if got := label(majority); got != "AlphaNumeric" {
+		t.Fatalf("expected the parent label to be kept within the margin, got %s", got)
+	}
+	add(100, 0)
+	if got := label(majority); got != "Words" {
+		t.Fatalf("expected the majority label to be taken again, got %s", got)
+	}
+	lineage := majority.Snapshot().Lineage
+	if last := lineage[len(lineage)-1]; last.Reason != "Words became the label of most URLs" {
+		t.Fatalf("expected the change in the lineage, got %+v", last)
+	}
+
+	var buf strings.Builder
+	if err := majority.WriteState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := New(WithMajorityLabels(0.9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.ReadState(strings.NewReader(buf.String())); err != nil {
+		t.Fatal(err)
+	}
+	if got := restored.Explain(&url.URL{Path: "/items/itemz"}).Segments[1].Labels; len(got) != 2 || got[0].Count != 575 {
+		t.Fatalf("expected the distribution to be restored, got %+v", got)
+	}
+
+	if _, err := New(WithMajorityLabels(1.5)); err == nil {
+		t.Fatal("expected error for a share above 1")
+	}
+}
@@ -0,0 +1,65 @@
+package groupurl
+
+import (
+	"net/url"
+	"sync"
+)
+
+const (
+	// OverflowLabel is returned by a LabelGuard once its limit of distinct labels has been reached.
+	OverflowLabel = "other"
+	// DefaultLabelLimit is the number of distinct labels BoundedLabel returns before folding into OverflowLabel.
+	DefaultLabelLimit = 1000
+)
+
+var _defaultLabelGuard = NewLabelGuard(DefaultLabelLimit)
+
+// LabelGuard caps the number of distinct simplified paths handed out as metric labels.
+// Simplified paths are already low cardinality, but they can still grow as a Grouper learns,
+// so a LabelGuard guarantees a hard upper bound for metric backends.
+// It is safe for concurrent use.
+type LabelGuard struct {
+	limit int
+
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+// NewLabelGuard creates a LabelGuard that hands out at most limit distinct labels, plus OverflowLabel.
+func NewLabelGuard(limit int) *LabelGuard {
+	return &LabelGuard{
+		limit: limit,
+		seen:  make(map[string]struct{}),
+	}
+}
+
+// BoundedLabel returns the simplified path of a URL if it has been returned before or the limit has not been reached,
+// otherwise it returns OverflowLabel.
+func (l *LabelGuard) BoundedLabel(g Grouper, u *url.URL) string {
+	label := g.SimplifyPath(u)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.seen[label]; ok {
+		return label
+	}
+	if len(l.seen) >= l.limit {
+		return OverflowLabel
+	}
+	l.seen[label] = struct{}{}
+	return label
+}
+
+// Labels returns the number of distinct labels handed out so far, not counting OverflowLabel.
+func (l *LabelGuard) Labels() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.seen)
+}
+
+// BoundedLabel returns a simplified path suitable for use as a metric label.
+// Across all callers in the process at most DefaultLabelLimit distinct labels are returned,
+// after which new labels are folded into OverflowLabel. Use a LabelGuard for a different limit.
+func BoundedLabel(g Grouper, u *url.URL) string {
+	return _defaultLabelGuard.BoundedLabel(g, u)
+}
@@ -0,0 +1,41 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestLabelGuard(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	guard := NewLabelGuard(2)
+	for i, rawURL := range []string{"/a", "/a/b", "/a/b/c", "/a"} {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		label := guard.BoundedLabel(g, u)
+		expected := rawURL
+		if i == 2 {
+			expected = OverflowLabel
+		}
+		if label != expected {
+			t.Fatalf("expected %s, got %s", expected, label)
+		}
+	}
+	if guard.Labels() != 2 {
+		t.Fatalf("expected 2, got %d", guard.Labels())
+	}
+
+	u, err := url.Parse(fmt.Sprintf("/x/%d", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if label := BoundedLabel(g, u); label != "/x/1" {
+		t.Fatalf("expected /x/1, got %s", label)
+	}
+}
@@ -0,0 +1,130 @@
+package groupurl
+
+import (
+	"strings"
+	"unicode"
+)
+
+// _languageWords are common short words of the languages detected from Latin script tokens.
+var _languageWords = map[string][]string{
+	"en": {"the", "and", "of", "to", "for", "with", "how", "what", "your", "best", "new", "guide", "from", "is", "in", "on"},
+	"de": {"der", "die", "das", "und", "mit", "fur", "ein", "eine", "neue", "wie", "von", "zu", "im", "auf", "ist"},
+	"fr": {"le", "la", "les", "des", "et", "pour", "avec", "du", "un", "une", "au", "aux", "sur", "dans", "est"},
+	"es": {"el", "los", "las", "y", "para", "con", "del", "un", "una", "como", "por", "en", "mejores", "nuevo"},
+	"it": {"il", "gli", "della", "delle", "per", "con", "di", "che", "come", "nuovo", "dei", "nel"},
+	"pt": {"o", "os", "as", "para", "com", "do", "da", "dos", "das", "um", "uma", "como", "em", "novo"},
+	"nl": {"het", "een", "en", "voor", "met", "van", "hoe", "nieuwe", "op", "naar"},
+}
+
+// _languageLetters are letters that only some of the Latin script languages use.
+var _languageLetters = map[rune][]string{
+	'ä': {"de"}, 'ö': {"de"}, 'ü': {"de"}, 'ß': {"de"},
+	'é': {"fr"}, 'è': {"fr", "it"}, 'ê': {"fr", "pt"}, 'ç': {"fr", "pt"}, 'à': {"fr", "it", "pt"},
+	'ñ': {"es"}, 'á': {"es", "pt"}, 'í': {"es", "pt"}, 'ó': {"es", "pt"}, 'ú': {"es", "pt"},
+	'ã': {"pt"}, 'õ': {"pt"}, 'ì': {"it"}, 'ò': {"it"},
+}
+
+// _languageScripts are the languages detected from the script of a token, which are the most common for it.
+var _languageScripts = []struct {
+	script   *unicode.RangeTable
+	language string
+}{
+	{unicode.Cyrillic, "ru"},
+	{unicode.Greek, "el"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Hangul, "ko"},
+	{unicode.Han, "zh"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+}
+
+var _languageWordSet = func() map[string][]string {
+	set := make(map[string][]string)
+	for language, words := range _languageWords {
+		for _, word := range words {
+			set[word] = append(set[word], language)
+		}
+	}
+	return set
+}()
+
+// WithLanguageDetection detects the language of the words in the tokens of added URLs, so that the languages of the
+// slugs of each group can be found with Languages and in exports. Detection is lightweight: languages written in their
+// own script are detected by it, and Latin script languages by common short words and letters. URLs without enough
+// evidence for a single language are not counted.
+func WithLanguageDetection() Option {
+	return func(g *Grouper) error {
+		g.tree.detectLanguages = true
+		return nil
+	}
+}
+
+// Languages returns the ISO 639-1 codes of the languages detected in the URLs of the group with the given pattern,
+// along with the number of URLs of each, when the Grouper was created WithLanguageDetection.
+// It returns nil if there is no such group.
+func (g Grouper) Languages(pattern string) map[string]int {
+	for _, grp := range g.groups() {
+		if grp.pattern == pattern {
+			return copyCounts(grp.languages)
+		}
+	}
+	return nil
+}
+
+// detectLanguage returns the language the words of tokens are written in, or an empty string if there is no single
+// most likely language.
+func detectLanguage(tokens []pathToken) string {
+	scores := make(map[string]int)
+	for _, token := range tokens {
+		for _, word := range strings.FieldsFunc(strings.ToLower(token.token), func(r rune) bool {
+			return !unicode.IsLetter(r)
+		}) {
+			scoreLanguageWord(scores, word)
+		}
+	}
+
+	best, bestScore, tied := "", 0, false
+	for language, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = language, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+	if tied {
+		return ""
+	}
+	return best
+}
+
+func scoreLanguageWord(scores map[string]int, word string) {
+	script := ""
+	for _, r := range word {
+		if r < unicode.MaxASCII {
+			continue
+		}
+		for _, s := range _languageScripts {
+			// Japanese mixes kana with Han characters, so kana takes precedence.
+			if unicode.Is(s.script, r) && (script == "" || s.language == "ja") {
+				script = s.language
+			}
+		}
+		if script == "" {
+			for _, language := range _languageLetters[r] {
+				scores[language]++
+			}
+		}
+	}
+	if script != "" {
+		// A word in a script of its own is strong evidence, outweighing common words.
+		scores[script] += 2
+		return
+	}
+	for _, language := range _languageWordSet[word] {
+		scores[language]++
+	}
+}
@@ -0,0 +1,64 @@
+package groupurl
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestLanguageDetection(t *testing.T) {
+	g, err := New(WithLanguageDetection())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{
+		"/blog/how-to-choose-the-best-laptop",
+		"/blog/wie-man-die-beste-küche-plant",
+		"/blog/les-meilleures-recettes-pour-l-été",
+		"/blog/как-выбрать-ноутбук",
+		"/blog/laptop-review",
+	} {
+		u, err := url.Parse(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+
+	var languages map[string]int
+	for _, grp := range g.Snapshot().Groups {
+		languages = mergeCounts(languages, grp.Languages)
+	}
+	if expected := map[string]int{"en": 1, "de": 1, "fr": 1, "ru": 1}; !reflect.DeepEqual(languages, expected) {
+		t.Fatalf("expected %v, got %v", expected, languages)
+	}
+
+	plain, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse("/blog/how-to-choose-the-best-laptop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain.Add(u)
+	for _, grp := range plain.Snapshot().Groups {
+		if grp.Languages != nil {
+			t.Fatalf("expected no languages without detection, got %v", grp.Languages)
+		}
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	for token, expected := range map[string]string{
+		"los-mejores-consejos-para-viajar": "es",
+		"guida-per-il-viaggio":             "it",
+		"東京の観光":                            "ja",
+		"product-12345":                    "",
+		"de":                               "",
+	} {
+		if got := detectLanguage([]pathToken{{token: token}}); got != expected {
+			t.Fatalf("expected %q for %s, got %q", expected, token, got)
+		}
+	}
+}
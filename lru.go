@@ -0,0 +1,88 @@
+package groupurl
+
+import "container/list"
+
+// lruCache is a bounded map evicting the least recently used entry once full.
+type lruCache[K comparable, V any] struct {
+	size    int
+	order   *list.List
+	entries map[K]*list.Element
+	hits    int
+	misses  int
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+func newLRUCache[K comparable, V any](size int) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[K]*list.Element, size),
+	}
+}
+
+func (c *lruCache[K, V]) get(key K) (V, bool) {
+	v, ok := c.peek(key)
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	return v, ok
+}
+
+// peek looks up a key without counting towards the hit rate, for callers that decide what counts as a hit.
+func (c *lruCache[K, V]) peek(key K) (V, bool) {
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		return e.Value.(*lruEntry[K, V]).value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (c *lruCache[K, V]) put(key K, value V) {
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*lruEntry[K, V]).value = value
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[K, V]).key)
+	}
+}
+
+// reset removes all entries while keeping the statistics.
+func (c *lruCache[K, V]) reset() {
+	c.order.Init()
+	c.entries = make(map[K]*list.Element, c.size)
+}
+
+func (c *lruCache[K, V]) stats() CacheStats {
+	return CacheStats{
+		Hits:   c.hits,
+		Misses: c.misses,
+		Size:   c.order.Len(),
+	}
+}
+
+// CacheStats reports the effectiveness of a cache.
+type CacheStats struct {
+	Hits   int
+	Misses int
+	Size   int
+}
+
+// HitRate returns the share of lookups served from the cache.
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
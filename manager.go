@@ -0,0 +1,519 @@
+package groupurl
+
+import (
+	"bytes"
+	"container/list"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrTooManyTenants is returned by Manager.Add when a new tenant would exceed WithMaxTenants.
+var ErrTooManyTenants = errors.New("too many tenants")
+
+// Manager owns a Grouper per tenant, such as each customer domain of a SaaS, so that one process can group the
+// URLs of many tenants in isolation: every tenant has its own Grouper, lock and quota, and a busy tenant neither
+// blocks nor exhausts the memory of the others. Unlike a Grouper, it is safe for concurrent use.
+//
+// With a TenantStore, only the recently used tenants are kept in memory: tenants idle beyond WithIdleTTL, or the
+// least recently used ones beyond WithMaxTenants, are saved to the store and transparently rehydrated on their next
+// access, so that a single process can serve many more tenants than it could hold.
+type Manager struct {
+	options    []Option
+	quota      TenantQuota
+	maxTenants int
+	idleTTL    time.Duration
+	store      TenantStore
+	now        func() time.Time
+
+	// mu guards the tenants and counters. The store is never used, nor the lock of a tenant waited on, while holding
+	// it, so that a slow store or a busy tenant does not hold up the others.
+	mu         sync.Mutex
+	tenants    map[string]*tenant
+	recency    *list.List // of *tenant, most recently used first
+	evicting   int
+	evicted    int
+	rehydrated int
+}
+
+// tenant guards the Grouper of a single tenant. lastUsed, elem, loading and evicting are guarded by the lock of the
+// Manager.
+type tenant struct {
+	key      string
+	elem     *list.Element
+	lastUsed time.Time
+	// loading is set while the tenant is a placeholder whose Grouper is being created or loaded from the store, with
+	// its lock held, so that callers looking it up wait for it rather than loading it again.
+	loading bool
+	// evicting is set while the tenant is being saved to the store, so that it is only evicted once.
+	evicting bool
+
+	mu sync.Mutex
+	g  Grouper
+	// gone is set once the tenant is evicted or removed, for callers that looked it up before to look it up again.
+	gone bool
+}
+
+// TenantQuota bounds the memory of the Grouper of each tenant. Tokens bounds the distinct tokens recorded by the
+// tenant and Tree those of each of its trees, with Policy applied to URLs beyond them as with Budget. Node is applied
+// with WithCardinalityLimit. Zero values are unlimited.
+type TenantQuota struct {
+	Tokens int
+	Tree   int
+	Node   int
+	Policy SpillPolicy
+}
+
+// ManagerOption configures a Manager.
+type ManagerOption func(*Manager) error
+
+// WithTenantOptions sets the options every tenant's Grouper is created with.
+func WithTenantOptions(options ...Option) ManagerOption {
+	return func(m *Manager) error {
+		m.options = append(m.options, options...)
+		return nil
+	}
+}
+
+// WithTenantQuota bounds the memory of each tenant.
+func WithTenantQuota(q TenantQuota) ManagerOption {
+	return func(m *Manager) error {
+		if q.Tokens < 0 || q.Tree < 0 || q.Node < 0 {
+			return fmt.Errorf("quotas must not be negative, got %+v", q)
+		}
+		if q.Policy != SpillOverflow && q.Policy != SpillDrop {
+			return fmt.Errorf("unknown spill policy %d", q.Policy)
+		}
+		m.quota = q
+		return nil
+	}
+}
+
+// WithMaxTenants bounds the number of tenants in memory, after which Add rejects URLs of new tenants with
+// ErrTooManyTenants, or evicts the least recently used tenant with WithTenantStore.
+func WithMaxTenants(n int) ManagerOption {
+	return func(m *Manager) error {
+		if n <= 0 {
+			return fmt.Errorf("max tenants must be positive, got %d", n)
+		}
+		m.maxTenants = n
+		return nil
+	}
+}
+
+// WithIdleTTL evicts the tenants that were not used for at least ttl whenever a tenant is loaded into memory, in
+// addition to calls to EvictIdle.
+func WithIdleTTL(ttl time.Duration) ManagerOption {
+	return func(m *Manager) error {
+		if ttl <= 0 {
+			return fmt.Errorf("idle TTL must be positive, got %s", ttl)
+		}
+		m.idleTTL = ttl
+		return nil
+	}
+}
+
+// WithTenantStore saves evicted tenants to store and rehydrates them on their next access, instead of dropping
+// what they learned. Tenants are rehydrated with the options of the Manager, which must not change between the
+// processes sharing a store.
+func WithTenantStore(store TenantStore) ManagerOption {
+	return func(m *Manager) error {
+		if store == nil {
+			return errors.New("tenant store must not be nil")
+		}
+		m.store = store
+		return nil
+	}
+}
+
+// NewManager creates a new Manager with the provided options.
+func NewManager(options ...ManagerOption) (*Manager, error) {
+	m := &Manager{
+		now:     time.Now,
+		tenants: make(map[string]*tenant),
+		recency: list.New(),
+	}
+	for _, option := range options {
+		if err := option(m); err != nil {
+			return nil, err
+		}
+	}
+	if m.quota.Node > 0 {
+		m.options = append(m.options, WithCardinalityLimit(m.quota.Node))
+	}
+
+	// Build a Grouper up front so that invalid options are reported here rather than on Add.
+	if _, err := New(m.options...); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Add adds a URL to the Grouper of a tenant, creating or rehydrating it if needed.
+func (m *Manager) Add(key string, u *url.URL) error {
+	if key == "" {
+		return errors.New("tenant key must not be empty")
+	}
+	for {
+		t, err := m.tenant(key, true)
+		if err != nil {
+			return err
+		}
+		t.mu.Lock()
+		if !t.gone {
+			t.g.Add(u)
+			t.mu.Unlock()
+			return nil
+		}
+		t.mu.Unlock()
+	}
+}
+
+// SimplifyPath simplifies the path of a URL with the Grouper of a tenant.
+// Paths of tenants that have never been added to are returned unchanged.
+func (m *Manager) SimplifyPath(key string, u *url.URL) string {
+	simplified := u.Path
+	m.Do(key, func(g Grouper) {
+		simplified = g.SimplifyPath(u)
+	})
+	return simplified
+}
+
+// Do calls f with the Grouper of a tenant while holding its lock, so that any method of the Grouper can be used,
+// and reports whether the tenant exists. Evicted tenants are rehydrated, and reported missing if that fails.
+// f must not retain the Grouper, nor call the Manager.
+func (m *Manager) Do(key string, f func(g Grouper)) bool {
+	for {
+		t, err := m.tenant(key, false)
+		if t == nil || err != nil {
+			return false
+		}
+		t.mu.Lock()
+		if !t.gone {
+			defer t.mu.Unlock()
+			f(t.g)
+			return true
+		}
+		t.mu.Unlock()
+	}
+}
+
+// tenant returns the tenant of a key, marked as used, after rehydrating it from the store or, if create is set,
+// creating it. It returns nil if the tenant does not exist and create is not set. A tenant being loaded by another
+// caller is returned as is, its lock being held until it is loaded.
+func (m *Manager) tenant(key string, create bool) (*tenant, error) {
+	m.mu.Lock()
+	now := m.now()
+	if t, ok := m.tenants[key]; ok {
+		t.lastUsed = now
+		m.recency.MoveToFront(t.elem)
+		m.mu.Unlock()
+		return t, nil
+	}
+	if m.store == nil && !create {
+		m.mu.Unlock()
+		return nil, nil
+	}
+	t := &tenant{key: key, lastUsed: now, loading: true}
+	t.mu.Lock()
+	t.elem = m.recency.PushFront(t)
+	m.tenants[key] = t
+	m.mu.Unlock()
+
+	g, found, err := m.load(key)
+	if err != nil {
+		m.discard(t)
+		return nil, err
+	}
+	if !found && !create {
+		m.discard(t)
+		return nil, nil
+	}
+
+	m.mu.Lock()
+	var victims []*tenant
+	if m.idleTTL > 0 {
+		victims = m.idle(now.Add(-m.idleTTL))
+	}
+	if m.maxTenants > 0 && len(m.tenants)-m.evicting > m.maxTenants {
+		if m.store == nil {
+			m.mu.Unlock()
+			err := m.evictAll(victims, nil)
+			m.discard(t)
+			if err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: cannot add tenant %q beyond %d", ErrTooManyTenants, key, m.maxTenants)
+		}
+		if lru := m.leastRecentlyUsed(); lru != nil {
+			victims = append(victims, lru)
+		}
+	}
+	m.mu.Unlock()
+	if err := m.evictAll(victims, nil); err != nil {
+		m.discard(t)
+		return nil, err
+	}
+
+	m.mu.Lock()
+	t.loading = false
+	if found {
+		m.rehydrated++
+	}
+	m.mu.Unlock()
+	t.g = g
+	t.mu.Unlock()
+	return t, nil
+}
+
+// discard drops a tenant that could not be loaded, and releases the callers waiting for it to look it up again.
+func (m *Manager) discard(t *tenant) {
+	m.mu.Lock()
+	m.drop(t)
+	m.mu.Unlock()
+	t.gone = true
+	t.mu.Unlock()
+}
+
+// drop removes a tenant from memory, unless another tenant of its key replaced it. It must be called with the lock of
+// the Manager held.
+func (m *Manager) drop(t *tenant) {
+	m.recency.Remove(t.elem)
+	if m.tenants[t.key] == t {
+		delete(m.tenants, t.key)
+	}
+}
+
+// load creates the Grouper of a tenant, with the state saved in the store if any, and reports whether it was found.
+func (m *Manager) load(key string) (Grouper, bool, error) {
+	// The options were validated by NewManager.
+	g, _ := New(m.options...)
+	if m.quota.Tokens > 0 || m.quota.Tree > 0 {
+		g.budget = newBudget(Budget{Host: m.quota.Tokens, Tree: m.quota.Tree, Policy: m.quota.Policy}, new(int))
+	}
+	if m.store == nil {
+		return g, false, nil
+	}
+	state, err := m.store.Load(key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return g, false, nil
+	}
+	if err != nil {
+		return g, false, fmt.Errorf("failed to load tenant %q: %w", key, err)
+	}
+	if err := g.ReadState(bytes.NewReader(state)); err != nil {
+		return g, false, fmt.Errorf("failed to rehydrate tenant %q: %w", key, err)
+	}
+	return g, true, nil
+}
+
+// idle marks the tenants last used before cutoff for eviction, and returns them least recently used first. It must be
+// called with the lock of the Manager held.
+func (m *Manager) idle(cutoff time.Time) []*tenant {
+	var idle []*tenant
+	for e := m.recency.Back(); e != nil; e = e.Prev() {
+		t := e.Value.(*tenant)
+		if t.lastUsed.After(cutoff) {
+			break
+		}
+		if !t.loading && !t.evicting {
+			m.markEvicting(t)
+			idle = append(idle, t)
+		}
+	}
+	return idle
+}
+
+// leastRecentlyUsed marks the least recently used tenant that is neither loading nor already being evicted for
+// eviction, and returns it, or nil if there is none. It must be called with the lock of the Manager held.
+func (m *Manager) leastRecentlyUsed() *tenant {
+	for e := m.recency.Back(); e != nil; e = e.Prev() {
+		if t := e.Value.(*tenant); !t.loading && !t.evicting {
+			m.markEvicting(t)
+			return t
+		}
+	}
+	return nil
+}
+
+func (m *Manager) markEvicting(t *tenant) {
+	t.evicting = true
+	m.evicting++
+}
+
+// evictAll evicts tenants marked by idle or leastRecentlyUsed in order, appending their keys to evicted if not nil.
+// It stops at the first tenant that cannot be saved, leaving it and the following ones in memory. It must be called
+// without the lock of the Manager held.
+func (m *Manager) evictAll(victims []*tenant, evicted *[]string) error {
+	for i, t := range victims {
+		if err := m.evict(t); err != nil {
+			m.mu.Lock()
+			for _, t := range victims[i:] {
+				t.evicting = false
+				m.evicting--
+			}
+			m.mu.Unlock()
+			return err
+		}
+		if evicted != nil {
+			*evicted = append(*evicted, t.key)
+		}
+	}
+	return nil
+}
+
+// evict saves a tenant marked for eviction to the store, if any, and drops it from memory. The tenant stays in memory,
+// where callers keep finding it until it is saved, if it cannot be saved. It waits for the tenant to be released.
+func (m *Manager) evict(t *tenant) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.gone && m.store != nil {
+		var buf bytes.Buffer
+		if err := t.g.WriteState(&buf); err != nil {
+			return fmt.Errorf("failed to evict tenant %q: %w", t.key, err)
+		}
+		if err := m.store.Save(t.key, buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to evict tenant %q: %w", t.key, err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t.evicting = false
+	m.evicting--
+	if !t.gone {
+		t.gone = true
+		m.drop(t)
+		m.evicted++
+	}
+	return nil
+}
+
+// Tenants returns the sorted keys of the tenants in memory.
+func (m *Manager) Tenants() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]string, 0, len(m.tenants))
+	for key, t := range m.tenants {
+		if !t.loading {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Remove drops the Grouper of a tenant and its saved state, and reports whether the tenant was in memory.
+func (m *Manager) Remove(key string) (bool, error) {
+	// A placeholder stands for the tenant until its state is deleted, so that callers do not load it meanwhile.
+	placeholder := &tenant{key: key, loading: true}
+	placeholder.mu.Lock()
+	m.mu.Lock()
+	t, ok := m.tenants[key]
+	if ok {
+		m.drop(t)
+	}
+	loaded := ok && !t.loading
+	placeholder.elem = m.recency.PushFront(placeholder)
+	m.tenants[key] = placeholder
+	m.mu.Unlock()
+	defer m.discard(placeholder)
+
+	if ok {
+		t.mu.Lock()
+		t.gone = true
+		t.mu.Unlock()
+	}
+	if m.store != nil {
+		if err := m.store.Delete(key); err != nil {
+			return loaded, fmt.Errorf("failed to remove tenant %q: %w", key, err)
+		}
+	}
+	return loaded, nil
+}
+
+// EvictIdle evicts the tenants that were not used for at least ttl, and returns their sorted keys. It stops at the
+// first tenant that cannot be saved to the store, returning the tenants evicted until then with the error.
+func (m *Manager) EvictIdle(ttl time.Duration) ([]string, error) {
+	m.mu.Lock()
+	victims := m.idle(m.now().Add(-ttl))
+	m.mu.Unlock()
+	var evicted []string
+	err := m.evictAll(victims, &evicted)
+	sort.Strings(evicted)
+	return evicted, err
+}
+
+// ManagerStats aggregates the sizes of the Groupers of all tenants in memory, with tenants ordered by the number of
+// tokens they recorded so that the tenants pressuring memory come first. Evicted and Rehydrated count the tenants
+// dropped from and loaded back into memory since the Manager was created.
+type ManagerStats struct {
+	Tenants    int
+	Size       TreeSize
+	Spilled    int
+	Dropped    int
+	Evicted    int
+	Rehydrated int
+	PerTenant  []TenantStats
+}
+
+// TenantStats reports the size of the Grouper of a single tenant. Spilled and Dropped count the URLs the
+// SpillPolicy of the TenantQuota was applied to.
+type TenantStats struct {
+	Tenant   string
+	LastUsed time.Time
+	Size     TreeSize
+	Spilled  int
+	Dropped  int
+}
+
+// Stats returns the size of every tenant in memory and their total.
+func (m *Manager) Stats() ManagerStats {
+	// Tenants are measured without holding the lock of the Manager, so that other tenants are not held up.
+	m.mu.Lock()
+	tenants := make([]*tenant, 0, len(m.tenants))
+	stats := ManagerStats{
+		Tenants:    len(m.tenants),
+		Evicted:    m.evicted,
+		Rehydrated: m.rehydrated,
+		PerTenant:  make([]TenantStats, 0, len(m.tenants)),
+	}
+	for _, t := range m.tenants {
+		if t.loading {
+			stats.Tenants--
+			continue
+		}
+		tenants = append(tenants, t)
+		stats.PerTenant = append(stats.PerTenant, TenantStats{Tenant: t.key, LastUsed: t.lastUsed})
+	}
+	m.mu.Unlock()
+
+	for i, t := range tenants {
+		ts := &stats.PerTenant[i]
+		t.mu.Lock()
+		ts.Size = t.g.TreeSize()
+		if t.g.budget != nil {
+			ts.Spilled, ts.Dropped = t.g.budget.spilled, t.g.budget.dropped
+		}
+		t.mu.Unlock()
+
+		stats.Size.Trees += ts.Size.Trees
+		stats.Size.Nodes += ts.Size.Nodes
+		stats.Size.Tokens += ts.Size.Tokens
+		stats.Size.Groups += ts.Size.Groups
+		stats.Spilled += ts.Spilled
+		stats.Dropped += ts.Dropped
+	}
+	sort.Slice(stats.PerTenant, func(i, j int) bool {
+		a, b := stats.PerTenant[i], stats.PerTenant[j]
+		if a.Size.Tokens != b.Size.Tokens {
+			return a.Size.Tokens > b.Size.Tokens
+		}
+		return a.Tenant < b.Tenant
+	})
+	return stats
+}
@@ -0,0 +1,239 @@
+package groupurl
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestManager(t *testing.T) {
+	m, err := NewManager(WithTenantQuota(TenantQuota{Tokens: 10, Policy: SpillDrop}), WithMaxTenants(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(0, 0)
+	m.now = func() time.Time { return now }
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"acme", "globex"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				u, err := url.Parse(fmt.Sprintf("/%s/item-%d", key, i))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if err := m.Add(key, u); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(key)
+	}
+	wg.Wait()
+
+	if err := m.Add("initech", &url.URL{Path: "/"}); !errors.Is(err, ErrTooManyTenants) {
+		t.Fatalf("expected ErrTooManyTenants, got %v", err)
+	}
+	if tenants := m.Tenants(); !reflect.DeepEqual(tenants, []string{"acme", "globex"}) {
+		t.Fatalf("expected both tenants, got %v", tenants)
+	}
+
+	stats := m.Stats()
+	if stats.Tenants != 2 || len(stats.PerTenant) != 2 {
+		t.Fatalf("expected stats of both tenants, got %+v", stats)
+	}
+	for _, ts := range stats.PerTenant {
+		if ts.Size.Tokens > 10 || ts.Dropped == 0 {
+			t.Fatalf("expected the quota to drop URLs of %s, got %+v", ts.Tenant, ts)
+		}
+	}
+	if stats.Dropped != stats.PerTenant[0].Dropped+stats.PerTenant[1].Dropped {
+		t.Fatalf("expected dropped URLs to be summed, got %+v", stats)
+	}
+
+	u := &url.URL{Path: "/acme/item-1"}
+	if got := m.SimplifyPath("unknown", u); got != u.Path {
+		t.Fatalf("expected paths of unknown tenants to be unchanged, got %s", got)
+	}
+	if !m.Do("acme", func(g Grouper) {
+		if len(g.Snapshot().Groups) == 0 {
+			t.Error("expected the tenant to have groups")
+		}
+	}) {
+		t.Fatal("expected the tenant to exist")
+	}
+
+	now = now.Add(time.Hour)
+	m.SimplifyPath("globex", u)
+	evicted, err := m.EvictIdle(30 * time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(evicted, []string{"acme"}) {
+		t.Fatalf("expected the idle tenant to be evicted, got %v", evicted)
+	}
+	if m.Do("acme", func(Grouper) {}) {
+		t.Fatal("expected the evicted tenant to be dropped without a store")
+	}
+	if removed, err := m.Remove("globex"); err != nil || !removed {
+		t.Fatalf("expected the tenant to be removed, got %v, %v", removed, err)
+	}
+	if removed, _ := m.Remove("globex"); removed {
+		t.Fatal("expected the tenant to be removed once")
+	}
+	if err := m.Add("initech", &url.URL{Path: "/"}); err != nil {
+		t.Fatalf("expected room for a new tenant, got %v", err)
+	}
+
+	if _, err := NewManager(WithTenantQuota(TenantQuota{Tokens: -1})); err == nil {
+		t.Fatal("expected error for a negative quota")
+	}
+	if err := m.Add("", &url.URL{Path: "/"}); err == nil {
+		t.Fatal("expected error for an empty tenant key")
+	}
+}
+
+func TestManagerTenantStore(t *testing.T) {
+	store := DirTenantStore{Dir: t.TempDir()}
+	m, err := NewManager(WithTenantStore(store), WithMaxTenants(2), WithIdleTTL(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(0, 0)
+	m.now = func() time.Time { return now }
+
+	for _, key := range []string{"acme", "globex"} {
+		for i := 0; i < 100; i++ {
+			if err := m.Add(key, &url.URL{Path: fmt.Sprintf("/%s/%d", key, i)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		now = now.Add(time.Minute)
+	}
+	u := &url.URL{Path: "/acme/7"}
+	want := m.SimplifyPath("acme", u)
+	if want == u.Path {
+		t.Fatalf("expected the tenant to have learned, got %s", want)
+	}
+
+	// A third tenant evicts the least recently used one rather than being rejected.
+	if err := m.Add("initech", &url.URL{Path: "/"}); err != nil {
+		t.Fatal(err)
+	}
+	if tenants := m.Tenants(); !reflect.DeepEqual(tenants, []string{"acme", "initech"}) {
+		t.Fatalf("expected the least recently used tenant to be evicted, got %v", tenants)
+	}
+	if got := m.SimplifyPath("globex", &url.URL{Path: "/globex/7"}); got != "/globex/Number" {
+		t.Fatalf("expected the evicted tenant to be rehydrated, got %s", got)
+	}
+	if tenants := m.Tenants(); !reflect.DeepEqual(tenants, []string{"globex", "initech"}) {
+		t.Fatalf("expected the rehydrated tenant to evict the least recently used one, got %v", tenants)
+	}
+
+	// Loading a tenant evicts the tenants idle beyond the TTL.
+	now = now.Add(2 * time.Hour)
+	if got := m.SimplifyPath("acme", u); got != want {
+		t.Fatalf("expected the rehydrated tenant to simplify as before, got %s", got)
+	}
+	if tenants := m.Tenants(); !reflect.DeepEqual(tenants, []string{"acme"}) {
+		t.Fatalf("expected idle tenants to be evicted, got %v", tenants)
+	}
+	stats := m.Stats()
+	if stats.Evicted != 4 || stats.Rehydrated != 2 {
+		t.Fatalf("expected evictions and rehydrations to be counted, got %+v", stats)
+	}
+
+	if m.Do("unknown", func(Grouper) {}) {
+		t.Fatal("expected unknown tenants not to be created")
+	}
+	if removed, err := m.Remove("globex"); err != nil || removed {
+		t.Fatalf("expected the evicted tenant to be removed from the store only, got %v, %v", removed, err)
+	}
+	if m.Do("globex", func(Grouper) {}) {
+		t.Fatal("expected the removed tenant to be gone from the store")
+	}
+	if _, err := NewManager(WithIdleTTL(0)); err == nil {
+		t.Fatal("expected error for a non-positive TTL")
+	}
+}
+
+// blockingStore is a TenantStore whose loads of a key wait until its channel is closed.
+type blockingStore struct {
+	DirTenantStore
+	key     string
+	release chan struct{}
+
+	mu    sync.Mutex
+	loads map[string]int
+}
+
+func (s *blockingStore) Load(key string) ([]byte, error) {
+	s.mu.Lock()
+	s.loads[key]++
+	s.mu.Unlock()
+	if key == s.key {
+		<-s.release
+	}
+	return s.DirTenantStore.Load(key)
+}
+
+func TestManagerSlowStore(t *testing.T) {
+	store := &blockingStore{
+		DirTenantStore: DirTenantStore{Dir: t.TempDir()},
+		key:            "slow",
+		release:        make(chan struct{}),
+		loads:          make(map[string]int),
+	}
+	m, err := NewManager(WithTenantStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := m.Add("slow", &url.URL{Path: fmt.Sprintf("/slow/%d", i)}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+
+	// Other tenants are served while the slow one is loading.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := m.Add("fast", &url.URL{Path: "/fast/1"}); err != nil {
+			t.Error(err)
+		}
+		if tenants := m.Tenants(); !reflect.DeepEqual(tenants, []string{"fast"}) {
+			t.Errorf("expected the loading tenant to be left out, got %v", tenants)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected other tenants not to wait for the store")
+	}
+
+	close(store.release)
+	wg.Wait()
+	if store.loads["slow"] != 1 {
+		t.Fatalf("expected the tenant to be loaded once, got %d loads", store.loads["slow"])
+	}
+	if !m.Do("slow", func(g Grouper) {
+		if total := g.DepthStats().Total; total != 4 {
+			t.Errorf("expected every URL of the tenant, got %d", total)
+		}
+	}) {
+		t.Fatal("expected the tenant to exist")
+	}
+}
@@ -0,0 +1,118 @@
+package groupurl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const _markdownTopGroups = 20
+
+// ExportMarkdown writes a readable Markdown summary of the groups the Grouper has learned,
+// with a table of the busiest groups followed by a section per tree.
+// Sample URLs are included in code spans so they render verbatim. Groups are listed with their tags when any group
+// has been tagged.
+func (g Grouper) ExportMarkdown(w io.Writer) error {
+	groups := g.groups()
+	var total int
+	for _, grp := range groups {
+		total += grp.count
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# URL groups")
+	fmt.Fprintln(bw)
+	fmt.Fprintf(bw, "%d URLs in %d groups across %d trees.\n", total, len(groups), len(g.trees))
+	if g.tree.privacyK > 0 {
+		fmt.Fprintln(bw)
+		fmt.Fprintf(bw, "Produced in privacy mode: every token shown is allowlisted or was seen at least %d times.\n", g.tree.privacyK)
+	}
+
+	top := append([]group(nil), groups...)
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].count > top[j].count
+	})
+	if len(top) > _markdownTopGroups {
+		top = top[:_markdownTopGroups]
+	}
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, "## Top groups")
+	fmt.Fprintln(bw)
+	writeMarkdownTable(bw, top, total, g.markdownTags())
+
+	for i := 0; i < len(groups); {
+		tree := groups[i].tree
+		j := i
+		for j < len(groups) && groups[j].tree == tree {
+			j++
+		}
+
+		fmt.Fprintln(bw)
+		if tree == _wildcardTree {
+			fmt.Fprintln(bw, "## Wildcard tails")
+		} else {
+			fmt.Fprintf(bw, "## Depth %d\n", tree+1)
+		}
+		fmt.Fprintln(bw)
+		writeMarkdownTable(bw, groups[i:j], total, g.markdownTags())
+		i = j
+	}
+
+	if infos := classifierInfos(g.labelInfo); len(infos) > 0 {
+		fmt.Fprintln(bw)
+		fmt.Fprintln(bw, "## Classifiers")
+		fmt.Fprintln(bw)
+		fmt.Fprintln(bw, "| Classifier | Labels | Description |")
+		fmt.Fprintln(bw, "| --- | --- | --- |")
+		for _, info := range infos {
+			fmt.Fprintf(bw, "| %s | %s | %s |\n", info, strings.Join(mapSlice(info.Labels, markdownCode), ", "), info.Description)
+		}
+	}
+	return bw.Flush()
+}
+
+// writeMarkdownTable writes a table of groups, with a column of their tags if tags is not nil.
+func writeMarkdownTable(w io.Writer, groups []group, total int, tags func(pattern string) map[string]string) {
+	if tags == nil {
+		fmt.Fprintln(w, "| Pattern | URLs | Share | Samples |")
+		fmt.Fprintln(w, "| --- | ---: | ---: | --- |")
+	} else {
+		fmt.Fprintln(w, "| Pattern | URLs | Share | Samples | Tags |")
+		fmt.Fprintln(w, "| --- | ---: | ---: | --- | --- |")
+	}
+	for _, grp := range groups {
+		var share float64
+		if total > 0 {
+			share = 100 * float64(grp.count) / float64(total)
+		}
+		fmt.Fprintf(w, "| %s | %d | %.1f%% | %s |",
+			markdownCode(grp.pattern),
+			grp.count,
+			share,
+			strings.Join(mapSlice(grp.samples, markdownCode), ", "),
+		)
+		if tags != nil {
+			fmt.Fprintf(w, " %s |", strings.ReplaceAll(formatTags(tags(grp.pattern)), "|", `\|`))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// markdownTags returns the tags of groups for writeMarkdownTable, nil if no group has been tagged.
+func (g Grouper) markdownTags() func(pattern string) map[string]string {
+	if len(g.tags.byPattern) == 0 {
+		return nil
+	}
+	return g.Tags
+}
+
+// markdownCode wraps s in a code span that is safe to use inside a table cell.
+func markdownCode(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
@@ -0,0 +1,109 @@
+package groupurl
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Merge is a node whose significant tokens fragment its traffic, as returned by SuggestMerges.
+// Tokens are the tokens that SimplifyPath keeps, Count is the number of URLs counted under them,
+// and Groups is the number of simplified paths that would collapse into those of Label.
+type Merge struct {
+	Tree    int
+	Pattern string
+	Label   string
+	Tokens  []string
+	Count   int
+	Groups  int
+
+	keys []LabelFields
+}
+
+// SuggestMerges finds nodes with at least minTokens significant tokens and nodes below them.
+// The tokens of a node share the nodes below it, so every significant token repeats the same structure
+// and multiplies the number of simplified paths. Merges are ordered by the traffic they affect.
+// Nodes already merged by ApplyMerge are skipped.
+func (g Grouper) SuggestMerges(minTokens int) []Merge {
+	var merges []Merge
+	for key, t := range g.trees {
+		t.walkKeys(func(path []LabelFields, labels []string, n *urlNode) {
+			if n.children.len() == 0 || n.merged {
+				return
+			}
+			tokens := t.significantTokens(n)
+			if len(tokens) < minTokens || len(tokens) == 0 {
+				return
+			}
+			m := Merge{
+				Tree:    key,
+				Pattern: "/" + strings.Join(labels, "/"),
+				Label:   n.specificLabel.Value,
+				Tokens:  tokens,
+				keys:    append([]LabelFields(nil), path...),
+			}
+			for _, token := range tokens {
+				m.Count += n.tokenCounts.get(token)
+			}
+			var below int
+			if n.terminal() > 0 {
+				below++
+			}
+			walkBelow(n, func(child *urlNode) {
+				if child.terminal() > 0 {
+					below++
+				}
+			})
+			m.Groups = len(tokens) * below
+			merges = append(merges, m)
+		})
+	}
+	sort.Slice(merges, func(i, j int) bool {
+		if merges[i].Count != merges[j].Count {
+			return merges[i].Count > merges[j].Count
+		}
+		if merges[i].Tree != merges[j].Tree {
+			return merges[i].Tree < merges[j].Tree
+		}
+		return merges[i].Pattern < merges[j].Pattern
+	})
+	return merges
+}
+
+// ApplyMerge stops the Grouper from keeping any token of the node of a Merge, so they are all grouped under
+// its label. The Grouper keeps counting the tokens, and the merge is kept by Relabel.
+func (g Grouper) ApplyMerge(m Merge) error {
+	if len(m.keys) == 0 {
+		return errors.New("merge was not suggested by SuggestMerges")
+	}
+	t, ok := g.trees[m.Tree]
+	if !ok {
+		return fmt.Errorf("tree %d does not exist", m.Tree)
+	}
+	n := t.Root
+	for _, key := range m.keys {
+		if n, _ = n.children.get(key); n == nil {
+			return fmt.Errorf("%s no longer exists", m.Pattern)
+		}
+	}
+	n.merged = true
+
+	if g.simplifyCache != nil {
+		g.simplifyCache.invalidate(m.Tree)
+	}
+	return nil
+}
+
+// walkBelow visits every node below n.
+func walkBelow(n *urlNode, f func(*urlNode)) {
+	stack := []*urlNode{n}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node.children.each(func(_ LabelFields, child *urlNode) {
+			f(child)
+			stack = append(stack, child)
+		})
+	}
+}
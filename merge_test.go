@@ -0,0 +1,54 @@
+package groupurl
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 60; i++ {
+		for _, page := range []string{"overview", "settings"} {
+			u, err := url.Parse("/teams/" + []string{"red", "blue", "green", "gold"}[i%4] + "/" + page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.Add(u)
+		}
+	}
+
+	u, err := url.Parse("/teams/red/overview")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.SimplifyPath(u); got != "/teams/red/overview" {
+		t.Fatalf("unexpected simplified path %s", got)
+	}
+
+	merges := g.SuggestMerges(3)
+	if len(merges) != 1 {
+		t.Fatalf("expected a single merge, got %+v", merges)
+	}
+	m := merges[0]
+	if m.Pattern != "/Words/Words" || len(m.Tokens) != 4 || m.Count != 120 || m.Groups != 4 {
+		t.Fatalf("unexpected merge %+v", m)
+	}
+	if err := g.ApplyMerge(m); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.SimplifyPath(u); got != "/teams/Words/overview" {
+		t.Fatalf("expected the teams to be merged, got %s", got)
+	}
+	if e := g.Explain(u); e.Segments[1].Reason != "tokens were merged" {
+		t.Fatalf("expected the merge to be explained, got %+v", e.Segments[1])
+	}
+	if len(g.SuggestMerges(3)) != 0 {
+		t.Fatal("expected merged nodes to be skipped")
+	}
+	if err := g.ApplyMerge(Merge{}); err == nil {
+		t.Fatal("expected error for a merge that was not suggested")
+	}
+}
@@ -0,0 +1,75 @@
+package groupurl
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	// MethodOther counts requests of a group once it has seen more than a handful of methods.
+	MethodOther = "OTHER"
+
+	_maxMethods = 16
+)
+
+// AddRequest adds a URL like Add, also counting it by HTTP method, since `GET /users/123` and `DELETE /users/123`
+// are different routes operationally. Groups are shared by every method: Methods returns the split of a group and
+// Snapshot.SplitByMethod that of a whole snapshot. Methods are compared case insensitively.
+func (g Grouper) AddRequest(method string, u *url.URL) {
+	g.add(u, request{method: strings.ToUpper(method)})
+}
+
+// Methods returns the HTTP methods of the requests of the group with the given pattern that were added with
+// AddRequest, along with the number of requests of each. It returns nil if there is no such group.
+func (g Grouper) Methods(pattern string) map[string]int {
+	for _, grp := range g.groups() {
+		if grp.pattern == pattern {
+			return copyCounts(grp.methods)
+		}
+	}
+	return nil
+}
+
+// SplitByMethod returns a Snapshot with a group per method and pattern, whose pattern is prefixed by the method and a
+// space, such as "DELETE /users/Number". URLs added without a method keep their plain pattern. Other fields of the
+// groups are only kept in the plain groups, so the result suits Diff, Compare and Distance rather than reports.
+func (s Snapshot) SplitByMethod() Snapshot {
+	split := s
+	split.Groups = nil
+	for _, grp := range s.Groups {
+		rest := grp.Count
+		methods := make([]string, 0, len(grp.Methods))
+		for method := range grp.Methods {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			split.Groups = append(split.Groups, SnapshotGroup{Pattern: method + " " + grp.Pattern, Count: grp.Methods[method]})
+			rest -= grp.Methods[method]
+		}
+		if rest > 0 {
+			plain := grp
+			plain.Count = rest
+			plain.Methods = nil
+			split.Groups = append(split.Groups, plain)
+		}
+	}
+	return split
+}
+
+// addMethod counts the method of a request that terminates at a node. The number of distinct methods per node is
+// bounded, with the rest counted as MethodOther.
+func addMethod(methods map[string]int, method string, weight int) map[string]int {
+	if method == "" {
+		return methods
+	}
+	if methods == nil {
+		methods = make(map[string]int)
+	}
+	if _, ok := methods[method]; !ok && len(methods) >= _maxMethods {
+		method = MethodOther
+	}
+	methods[method] += weight
+	return methods
+}
@@ -0,0 +1,70 @@
+package groupurl
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestAddRequest(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hooked []string
+	g.addHooks = append(g.addHooks, func(u *url.URL, info AddInfo) {
+		hooked = append(hooked, info.Method)
+	})
+	for i := 0; i < 10; i++ {
+		u := &url.URL{Path: fmt.Sprintf("/users/%d", i)}
+		g.AddRequest("get", u)
+		if i%5 == 0 {
+			g.AddRequest("DELETE", u)
+		}
+	}
+	g.Add(&url.URL{Path: "/users/99"})
+
+	want := map[string]int{"GET": 10, "DELETE": 2}
+	if got := g.Methods("/Words/Number"); len(got) != len(want) || got["GET"] != 10 || got["DELETE"] != 2 {
+		t.Errorf("got methods %v, want %v", got, want)
+	}
+	if got := g.Methods("/Missing"); got != nil {
+		t.Errorf("got methods %v for a missing group", got)
+	}
+	if len(hooked) != 13 || hooked[0] != "GET" || hooked[12] != "" {
+		t.Errorf("unexpected methods passed to hooks %v", hooked)
+	}
+
+	split := g.Snapshot().SplitByMethod()
+	counts := snapshotCounts(split)
+	if len(counts) != 3 || counts["GET /Words/Number"] != 10 || counts["DELETE /Words/Number"] != 2 || counts["/Words/Number"] != 1 {
+		t.Errorf("unexpected split snapshot %v", counts)
+	}
+
+	var state bytes.Buffer
+	if err := g.WriteState(&state); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.ReadState(&state); err != nil {
+		t.Fatal(err)
+	}
+	if got := restored.Methods("/Words/Number"); got["DELETE"] != 2 {
+		t.Errorf("got methods %v after restoring the state", got)
+	}
+}
+
+func TestAddMethodLimit(t *testing.T) {
+	var methods map[string]int
+	for i := 0; i < _maxMethods+5; i++ {
+		methods = addMethod(methods, fmt.Sprintf("M%d", i), 1)
+	}
+	methods = addMethod(methods, "", 1)
+	if len(methods) != _maxMethods+1 || methods[MethodOther] != 5 {
+		t.Errorf("unexpected bounded methods %v", methods)
+	}
+}
@@ -0,0 +1,27 @@
+// Package groupurlecho installs the groupurl middleware on echo servers.
+package groupurlecho
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/trustleast/groupurl/middleware"
+)
+
+// Handler returns an echo middleware that records each request and stores its group
+// in the echo context under middleware.ContextKey and in the request context.
+func Handler(m *middleware.Middleware) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			group := m.RecordRequest(req)
+			c.Set(middleware.ContextKey, group)
+			c.SetRequest(req.WithContext(middleware.WithGroup(req.Context(), group)))
+			return next(c)
+		}
+	}
+}
+
+// Group returns the group stored in the echo context by Handler, if any.
+func Group(c echo.Context) (string, bool) {
+	group, ok := c.Get(middleware.ContextKey).(string)
+	return group, ok
+}
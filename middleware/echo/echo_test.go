@@ -0,0 +1,43 @@
+package groupurlecho
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/trustleast/groupurl"
+	"github.com/trustleast/groupurl/middleware"
+)
+
+func TestHandler(t *testing.T) {
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+	e.Use(Handler(middleware.New(g)))
+
+	var group string
+	e.POST("/orders/:id", func(c echo.Context) error {
+		var ok bool
+		if group, ok = Group(c); !ok {
+			t.Fatal("expected group in the echo context")
+		}
+		if fromRequest, ok := middleware.GroupFromContext(c.Request().Context()); !ok || fromRequest != group {
+			t.Fatalf("expected group %s in the request context, got %s", group, fromRequest)
+		}
+		return c.NoContent(http.StatusOK)
+	})
+	for i := 0; i < 200; i++ {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, fmt.Sprintf("/orders/%d", i), nil))
+	}
+
+	if group != "/orders/Number" {
+		t.Fatalf("expected /orders/Number, got %s", group)
+	}
+	if methods := g.Methods("/Words/Number"); methods[http.MethodPost] != 200 {
+		t.Fatalf("expected 200 POST requests, got %v", methods)
+	}
+}
@@ -0,0 +1,30 @@
+// Package groupurlfiber installs the groupurl middleware on fiber apps.
+package groupurlfiber
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/trustleast/groupurl/middleware"
+)
+
+// Handler returns a fiber handler that records each request and stores its group
+// in the fiber locals under middleware.ContextKey.
+// Requests whose URL cannot be parsed are passed through without being recorded.
+func Handler(m *middleware.Middleware) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		u, err := url.ParseRequestURI(c.OriginalURL())
+		if err != nil {
+			return c.Next()
+		}
+		c.Locals(middleware.ContextKey, m.RecordRequest(&http.Request{Method: c.Method(), URL: u}))
+		return c.Next()
+	}
+}
+
+// Group returns the group stored in the fiber locals by Handler, if any.
+func Group(c *fiber.Ctx) (string, bool) {
+	group, ok := c.Locals(middleware.ContextKey).(string)
+	return group, ok
+}
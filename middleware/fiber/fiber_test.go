@@ -0,0 +1,44 @@
+package groupurlfiber
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/trustleast/groupurl"
+	"github.com/trustleast/groupurl/middleware"
+)
+
+func TestHandler(t *testing.T) {
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := fiber.New()
+	app.Use(Handler(middleware.New(g)))
+
+	var group string
+	app.Post("/orders/:id", func(c *fiber.Ctx) error {
+		var ok bool
+		if group, ok = Group(c); !ok {
+			t.Fatal("expected group in the fiber locals")
+		}
+		return c.SendStatus(http.StatusOK)
+	})
+	for i := 0; i < 200; i++ {
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, fmt.Sprintf("/orders/%d", i), nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	if group != "/orders/Number" {
+		t.Fatalf("expected /orders/Number, got %s", group)
+	}
+	if methods := g.Methods("/Words/Number"); methods[http.MethodPost] != 200 {
+		t.Fatalf("expected 200 POST requests, got %v", methods)
+	}
+}
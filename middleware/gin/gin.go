@@ -0,0 +1,28 @@
+// Package groupurlgin installs the groupurl middleware on gin engines.
+package groupurlgin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/trustleast/groupurl/middleware"
+)
+
+// Handler returns a gin middleware that records each request and stores its group
+// in the gin context under middleware.ContextKey and in the request context.
+func Handler(m *middleware.Middleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		group := m.RecordRequest(c.Request)
+		c.Set(middleware.ContextKey, group)
+		c.Request = c.Request.WithContext(middleware.WithGroup(c.Request.Context(), group))
+		c.Next()
+	}
+}
+
+// Group returns the group stored in the gin context by Handler, if any.
+func Group(c *gin.Context) (string, bool) {
+	group, ok := c.Get(middleware.ContextKey)
+	if !ok {
+		return "", false
+	}
+	s, ok := group.(string)
+	return s, ok
+}
@@ -0,0 +1,43 @@
+package groupurlgin
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trustleast/groupurl"
+	"github.com/trustleast/groupurl/middleware"
+)
+
+func TestHandler(t *testing.T) {
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(Handler(middleware.New(g)))
+
+	var group string
+	engine.POST("/orders/:id", func(c *gin.Context) {
+		var ok bool
+		if group, ok = Group(c); !ok {
+			t.Fatal("expected group in the gin context")
+		}
+		if fromRequest, ok := middleware.GroupFromContext(c.Request.Context()); !ok || fromRequest != group {
+			t.Fatalf("expected group %s in the request context, got %s", group, fromRequest)
+		}
+	})
+	for i := 0; i < 200; i++ {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, fmt.Sprintf("/orders/%d", i), nil))
+	}
+
+	if group != "/orders/Number" {
+		t.Fatalf("expected /orders/Number, got %s", group)
+	}
+	if methods := g.Methods("/Words/Number"); methods[http.MethodPost] != 200 {
+		t.Fatalf("expected 200 POST requests, got %v", methods)
+	}
+}
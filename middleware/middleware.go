@@ -0,0 +1,80 @@
+// Package middleware records incoming requests into a Grouper and exposes the group
+// each request was assigned to through the request context.
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/trustleast/groupurl"
+)
+
+// ContextKey is the key framework adapters store the group under in string keyed contexts.
+const ContextKey = "groupurl.group"
+
+type contextKey struct{}
+
+// Middleware feeds request URLs into a Grouper.
+// Groupers are not safe for concurrent use so the Middleware serializes access to it.
+type Middleware struct {
+	mu sync.Mutex
+	g  groupurl.Grouper
+}
+
+// New creates a Middleware that records into the provided Grouper.
+// The Grouper should not be used directly afterwards, use the methods on the Middleware instead.
+func New(g groupurl.Grouper) *Middleware {
+	return &Middleware{g: g}
+}
+
+// Handler wraps a http.Handler, recording each request and storing its group in the request context.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		group := m.RecordRequest(r)
+		next.ServeHTTP(w, r.WithContext(WithGroup(r.Context(), group)))
+	})
+}
+
+// Record adds a URL to the underlying Grouper and returns its simplified path.
+func (m *Middleware) Record(u *url.URL) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.g.Add(u)
+	return m.g.SimplifyPath(u)
+}
+
+// RecordRequest adds the URL of a request to the underlying Grouper along with its method, and returns its
+// simplified path.
+func (m *Middleware) RecordRequest(r *http.Request) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.g.AddRequest(r.Method, r.URL)
+	return m.g.SimplifyPath(r.URL)
+}
+
+// SimplifyPath simplifies a URL using the underlying Grouper without recording it.
+func (m *Middleware) SimplifyPath(u *url.URL) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.g.SimplifyPath(u)
+}
+
+// String pretty prints the underlying Grouper.
+func (m *Middleware) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.g.String()
+}
+
+// WithGroup returns a copy of ctx carrying the group of a request.
+func WithGroup(ctx context.Context, group string) context.Context {
+	return context.WithValue(ctx, contextKey{}, group)
+}
+
+// GroupFromContext returns the group stored in ctx by the Middleware, if any.
+func GroupFromContext(ctx context.Context) (string, bool) {
+	group, ok := ctx.Value(contextKey{}).(string)
+	return group, ok
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/trustleast/groupurl"
+)
+
+func TestHandler(t *testing.T) {
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := New(g)
+
+	var group string
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ok bool
+		group, ok = GroupFromContext(r.Context())
+		if !ok {
+			t.Fatal("expected group in context")
+		}
+	}))
+
+	for i := 0; i < 200; i++ {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/orders/%d", i), nil)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if group != "/orders/Number" {
+		t.Fatalf("expected /orders/Number, got %s", group)
+	}
+	if methods := g.Methods("/Words/Number"); methods[http.MethodGet] != 200 {
+		t.Fatalf("expected 200 GET requests, got %v", methods)
+	}
+}
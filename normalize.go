@@ -0,0 +1,37 @@
+package groupurl
+
+import "strings"
+
+// WithNumericNormalization counts numeric tokens that only differ by leading zeros or thousand separators as the same
+// token, so that `/invoice/000123`, `/invoice/123` and `/invoice/1,234` are counted as 123, 123 and 1234.
+// Simplified paths keep the original token.
+func WithNumericNormalization() Option {
+	return func(g *Grouper) error {
+		g.tree.normalizeNumbers = true
+		return nil
+	}
+}
+
+// normalizeNumber removes leading zeros and thousand separators from a token made of digits and commas.
+// Other tokens are returned as is.
+func normalizeNumber(s string) string {
+	if s == "" || s[0] == ',' || s[len(s)-1] == ',' {
+		return s
+	}
+	digits := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] >= '0' && s[i] <= '9':
+			digits++
+		case s[i] != ',':
+			return s
+		}
+	}
+	if digits != len(s) {
+		s = strings.ReplaceAll(s, ",", "")
+	}
+	if trimmed := strings.TrimLeft(s, "0"); trimmed != "" {
+		return trimmed
+	}
+	return "0"
+}
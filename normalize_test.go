@@ -0,0 +1,59 @@
+package groupurl
+
+import (
+	"net/url"
+	"regexp"
+	"testing"
+)
+
+func TestNumericNormalization(t *testing.T) {
+	invoice := RegexPathTokenClassifier{
+		Regex: regexp.MustCompile(`^[0-9,]+(/|$)`),
+		Label: Label{LabelFields: LabelFields{Important: true, Value: "Invoice"}},
+	}
+	g, err := New(WithClassifiers(append([]PathTokenClassifier{invoice}, DefaultClassifiers()...)), WithNumericNormalization())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"/invoice/000123", "/invoice/123", "/invoice/0123", "/invoice/1,234", "/invoice/1234"} {
+		u, err := url.Parse(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+
+	var n *urlNode
+	for _, child := range g.trees[1].Root.sortedChildren() {
+		n, _ = child.children.get(invoice.Label.LabelFields)
+	}
+	if got := n.tokenCounts.get("123"); got != 3 {
+		t.Fatalf("expected 3 URLs counted for 123, got %d", got)
+	}
+	if got := n.tokenCounts.get("1234"); got != 2 {
+		t.Fatalf("expected 2 URLs counted for 1234, got %d", got)
+	}
+
+	u, err := url.Parse("/invoice/000123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.SimplifyPath(u); got != "/invoice/000123" {
+		t.Fatalf("expected the original token to be kept, got %s", got)
+	}
+}
+
+func TestNormalizeNumber(t *testing.T) {
+	for s, expected := range map[string]string{
+		"000123":    "123",
+		"0000":      "0",
+		"1,234,567": "1234567",
+		"12a":       "12a",
+		",12":       ",12",
+		"v007":      "v007",
+	} {
+		if got := normalizeNumber(s); got != expected {
+			t.Fatalf("expected %s for %s, got %s", expected, s, got)
+		}
+	}
+}
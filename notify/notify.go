@@ -0,0 +1,283 @@
+// Package notify posts webhook notifications when new URL groups appear or groups cross a traffic threshold,
+// which is handy for alerting on new endpoints showing up in production.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/trustleast/groupurl"
+)
+
+const (
+	// EventNewGroup is sent the first time a group is seen.
+	EventNewGroup = "new_group"
+	// EventThreshold is sent when the count of a group reaches the configured threshold.
+	EventThreshold = "threshold"
+
+	_defaultBatchSize     = 50
+	_defaultFlushInterval = 10 * time.Second
+	_defaultMaxPending    = 10000
+	_defaultRetries       = 3
+	_defaultBackoff       = time.Second
+)
+
+type (
+	// Event is a single notification.
+	Event struct {
+		Type      string    `json:"type"`
+		Pattern   string    `json:"pattern"`
+		FirstURL  string    `json:"first_url"`
+		Timestamp time.Time `json:"timestamp"`
+		Count     int       `json:"count"`
+		// Owners are the owners of the group, if the Grouper was built WithOwnership.
+		Owners []string `json:"owners,omitempty"`
+	}
+
+	// Notifier records URLs into a Grouper and queues an Event whenever a group is first seen
+	// or reaches the traffic threshold. Events are posted to the webhook in batches as a JSON object
+	// with an "events" array. It is safe for concurrent use.
+	Notifier struct {
+		webhook       string
+		client        *http.Client
+		threshold     int
+		batchSize     int
+		flushInterval time.Duration
+		retries       int
+		backoff       time.Duration
+		maxPending    int
+		now           func() time.Time
+		// full wakes Run up when a batch of events is queued.
+		full chan struct{}
+
+		mu      sync.Mutex
+		g       groupurl.Grouper
+		groups  map[string]*groupState
+		pending []Event
+	}
+
+	groupState struct {
+		firstURL string
+		count    int
+		owners   []string
+	}
+
+	Option func(*Notifier) error
+)
+
+// WithThreshold sends an EventThreshold once a group has been seen count times.
+func WithThreshold(count int) Option {
+	return func(n *Notifier) error {
+		n.threshold = count
+		return nil
+	}
+}
+
+// WithBatching sets how many events are sent per request and how often Run flushes queued events.
+func WithBatching(size int, interval time.Duration) Option {
+	return func(n *Notifier) error {
+		if size <= 0 || interval <= 0 {
+			return fmt.Errorf("invalid batching of %d events every %s", size, interval)
+		}
+		n.batchSize = size
+		n.flushInterval = interval
+		return nil
+	}
+}
+
+// WithMaxPending sets how many events may be queued while the webhook is unreachable, 10000 by default. The oldest
+// events are dropped beyond it.
+func WithMaxPending(size int) Option {
+	return func(n *Notifier) error {
+		if size <= 0 {
+			return fmt.Errorf("max pending events must be positive, got %d", size)
+		}
+		n.maxPending = size
+		return nil
+	}
+}
+
+// WithRetries sets how often a failed request is retried, doubling the backoff after each attempt.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(n *Notifier) error {
+		n.retries = retries
+		n.backoff = backoff
+		return nil
+	}
+}
+
+// WithHTTPClient sets the client used to post to the webhook.
+func WithHTTPClient(client *http.Client) Option {
+	return func(n *Notifier) error {
+		n.client = client
+		return nil
+	}
+}
+
+// New creates a Notifier that records into g and posts events to webhook.
+// The Grouper should not be used directly afterwards.
+func New(g groupurl.Grouper, webhook string, options ...Option) (*Notifier, error) {
+	if _, err := url.ParseRequestURI(webhook); err != nil {
+		return nil, fmt.Errorf("invalid webhook: %w", err)
+	}
+
+	n := &Notifier{
+		webhook:       webhook,
+		client:        http.DefaultClient,
+		batchSize:     _defaultBatchSize,
+		flushInterval: _defaultFlushInterval,
+		retries:       _defaultRetries,
+		backoff:       _defaultBackoff,
+		maxPending:    _defaultMaxPending,
+		now:           time.Now,
+		full:          make(chan struct{}, 1),
+		g:             g,
+		groups:        make(map[string]*groupState),
+	}
+	for _, option := range options {
+		if err := option(n); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// Record adds a URL to the Grouper, queues any resulting events, and returns the pattern of the group of the URL, as
+// returned by Grouper.Pattern. Groups are keyed on their pattern rather than on the simplified path, so that a new
+// significant token of a known group does not send an EventNewGroup.
+func (n *Notifier) Record(u *url.URL) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.g.Add(u)
+	pattern := n.g.Pattern(u)
+	state, ok := n.groups[pattern]
+	if !ok {
+		state = &groupState{firstURL: u.String(), owners: n.g.Owners(u)}
+		n.groups[pattern] = state
+	}
+	state.count++
+
+	if !ok {
+		n.queue(EventNewGroup, pattern, state)
+	}
+	if n.threshold > 0 && state.count == n.threshold {
+		n.queue(EventThreshold, pattern, state)
+	}
+	return pattern
+}
+
+// queue adds an event to the pending events, and wakes Run up once a batch is full. The caller must hold n.mu.
+func (n *Notifier) queue(eventType, pattern string, state *groupState) {
+	n.pending = append(n.pending, Event{
+		Type:      eventType,
+		Pattern:   pattern,
+		FirstURL:  state.firstURL,
+		Timestamp: n.now(),
+		Count:     state.count,
+		Owners:    state.owners,
+	})
+	n.trim()
+	if len(n.pending) >= n.batchSize {
+		select {
+		case n.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// trim drops the oldest pending events beyond maxPending. The caller must hold n.mu.
+func (n *Notifier) trim() {
+	if excess := len(n.pending) - n.maxPending; excess > 0 {
+		n.pending = append(n.pending[:0], n.pending[excess:]...)
+	}
+}
+
+// Flush posts all queued events. Events of batches that could not be delivered are queued again, up to the limit set
+// WithMaxPending.
+func (n *Notifier) Flush(ctx context.Context) error {
+	n.mu.Lock()
+	pending := n.pending
+	n.pending = nil
+	n.mu.Unlock()
+
+	for len(pending) > 0 {
+		size := n.batchSize
+		if size > len(pending) {
+			size = len(pending)
+		}
+		if err := n.post(ctx, pending[:size]); err != nil {
+			n.mu.Lock()
+			n.pending = append(pending, n.pending...)
+			n.trim()
+			n.mu.Unlock()
+			return err
+		}
+		pending = pending[size:]
+	}
+	return nil
+}
+
+// Run flushes queued events each flush interval, and as soon as a batch is full, until the context is cancelled,
+// then flushes what is left. Delivery errors are retried on the next flush.
+func (n *Notifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(n.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			_ = n.Flush(context.Background())
+			return
+		case <-ticker.C:
+			_ = n.Flush(ctx)
+		case <-n.full:
+			_ = n.Flush(ctx)
+		}
+	}
+}
+
+func (n *Notifier) post(ctx context.Context, events []Event) error {
+	body, err := json.Marshal(map[string][]Event{"events": events})
+	if err != nil {
+		return err
+	}
+
+	backoff := n.backoff
+	for attempt := 0; ; attempt++ {
+		err = n.send(ctx, body)
+		if err == nil || attempt >= n.retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (n *Notifier) send(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/trustleast/groupurl"
+)
+
+func TestNotifier(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+		events   []Event
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var body struct {
+			Events []Event `json:"events"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		events = append(events, body.Events...)
+	}))
+	defer server.Close()
+
+	owners, err := groupurl.NewOwnership(groupurl.OwnerRule{Glob: "/*", Owners: []string{"@sre"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := groupurl.New(groupurl.WithOwnership(owners))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := New(g, server.URL, WithThreshold(3), WithRetries(2, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse("https://example.com/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if got := n.Record(u); got != "/Words" {
+			t.Fatalf("expected the pattern of the group, got %s", got)
+		}
+	}
+	// Another token of the same group does not make a new group.
+	n.Record(&url.URL{Path: "/status"})
+	if err := n.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", attempts)
+	}
+	var types []string
+	for _, e := range events {
+		types = append(types, e.Type+" "+e.Pattern)
+	}
+	// Events are keyed on the pattern of the group, which does not change once the token becomes significant.
+	expected := []string{"new_group /Words", "threshold /Words"}
+	if len(types) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, types)
+	}
+	for i := range expected {
+		if types[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, types)
+		}
+	}
+	if len(events[0].Owners) != 1 || events[0].Owners[0] != "@sre" || events[1].Count != 3 {
+		t.Fatalf("expected events to carry the owners of their group, got %+v", events)
+	}
+}
+
+func TestNotifierRun(t *testing.T) {
+	received := make(chan Event, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Events []Event `json:"events"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		for _, e := range body.Events {
+			received <- e
+		}
+	}))
+	defer server.Close()
+
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := New(g, server.URL, WithBatching(2, time.Hour), WithMaxPending(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(g, server.URL, WithMaxPending(0)); err == nil {
+		t.Fatal("expected error for no pending events")
+	}
+
+	// Every depth makes a new group, and the oldest events are dropped beyond the limit.
+	for depth := 1; depth <= 5; depth++ {
+		n.Record(&url.URL{Path: strings.Repeat("/a", depth)})
+	}
+	if len(n.pending) != 3 || n.pending[0].Pattern != "/Words/Words/Words" {
+		t.Fatalf("expected the 3 newest events to be queued, got %+v", n.pending)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		n.Run(ctx)
+		close(done)
+	}()
+	// Full batches are sent without waiting for the flush interval.
+	for i := 0; i < 3; i++ {
+		select {
+		case <-received:
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected full batches to be sent right away")
+		}
+	}
+
+	n.Record(&url.URL{Path: strings.Repeat("/a", 6)})
+	cancel()
+	<-done
+	// A batch interrupted by the cancellation is sent again, so the last event may not come first.
+	for {
+		select {
+		case e := <-received:
+			if e.Pattern == strings.Repeat("/Words", 6) {
+				return
+			}
+		default:
+			t.Fatal("expected queued events to be sent once the context is cancelled")
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// StatusOther counts responses whose status code is outside of the 1xx to 5xx classes.
+const StatusOther = "other"
+
+// _latencyBounds are the upper bounds of the buckets of latency histograms.
+var _latencyBounds = []time.Duration{
+	time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// GroupStats aggregates the responses of the requests of a group added with Observe.
+type GroupStats struct {
+	// Statuses counts responses by status class, such as "2xx", or StatusOther.
+	Statuses map[string]int   `json:"statuses"`
+	Latency  LatencyHistogram `json:"latency"`
+}
+
+// LatencyHistogram counts latencies in the buckets bounded by LatencyBounds.
+type LatencyHistogram struct {
+	// Counts holds the number of latencies of each bucket, with one more bucket than LatencyBounds.
+	Counts []int `json:"counts"`
+	Count  int   `json:"count"`
+	// Sum is the total of the latencies, for computing their mean.
+	Sum time.Duration `json:"sum"`
+}
+
+// LatencyBounds returns the upper bounds of the buckets of latency histograms. Latencies above the last bound are
+// counted in an extra, unbounded bucket.
+func LatencyBounds() []time.Duration {
+	return append([]time.Duration(nil), _latencyBounds...)
+}
+
+// Observe adds a URL like Add, also accumulating the status code and latency of its response in the stats of its
+// group, which makes the Grouper a lightweight route-level SLO aggregator. Stats returns them.
+func (g Grouper) Observe(u *url.URL, status int, latency time.Duration) {
+	g.add(u, request{observed: true, status: status, latency: latency})
+}
+
+// Stats returns the stats of the responses of the group with the given pattern that were added with Observe, and
+// whether there are any.
+func (g Grouper) Stats(pattern string) (GroupStats, bool) {
+	for _, grp := range g.groups() {
+		if grp.pattern == pattern {
+			if grp.stats == nil {
+				return GroupStats{}, false
+			}
+			return grp.stats.clone(), true
+		}
+	}
+	return GroupStats{}, false
+}
+
+// ErrorRate returns the share of responses with a 5xx status.
+func (s GroupStats) ErrorRate() float64 {
+	if s.Latency.Count == 0 {
+		return 0
+	}
+	return float64(s.Statuses["5xx"]) / float64(s.Latency.Count)
+}
+
+// Mean returns the mean latency.
+func (h LatencyHistogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Quantile estimates the latency below which a fraction q of the latencies fall, interpolating linearly within the
+// bucket it falls in. Quantiles in the unbounded bucket are reported as the last bound.
+func (h LatencyHistogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	rank := q * float64(h.Count)
+	var seen float64
+	for i, count := range h.Counts {
+		if count == 0 || seen+float64(count) < rank {
+			seen += float64(count)
+			continue
+		}
+		if i == len(_latencyBounds) {
+			break
+		}
+		var lower time.Duration
+		if i > 0 {
+			lower = _latencyBounds[i-1]
+		}
+		return lower + time.Duration((rank-seen)/float64(count)*float64(_latencyBounds[i]-lower))
+	}
+	return _latencyBounds[len(_latencyBounds)-1]
+}
+
+// observe accumulates the response of a request, creating the stats if needed.
+func (s *GroupStats) observe(req request, weight int) *GroupStats {
+	if s == nil {
+		s = &GroupStats{
+			Statuses: make(map[string]int),
+			Latency:  LatencyHistogram{Counts: make([]int, len(_latencyBounds)+1)},
+		}
+	}
+	s.Statuses[statusClass(req.status)] += weight
+
+	bucket := len(_latencyBounds)
+	for i, bound := range _latencyBounds {
+		if req.latency <= bound {
+			bucket = i
+			break
+		}
+	}
+	s.Latency.Counts[bucket] += weight
+	s.Latency.Count += weight
+	s.Latency.Sum += req.latency * time.Duration(weight)
+	return s
+}
+
+// merge adds the stats of src to s, creating s if needed.
+func (s *GroupStats) merge(src *GroupStats) *GroupStats {
+	if src == nil {
+		return s
+	}
+	if s == nil {
+		c := src.clone()
+		return &c
+	}
+	s.Statuses = mergeCounts(s.Statuses, src.Statuses)
+	for i, count := range src.Latency.Counts {
+		s.Latency.Counts[i] += count
+	}
+	s.Latency.Count += src.Latency.Count
+	s.Latency.Sum += src.Latency.Sum
+	return s
+}
+
+func (s GroupStats) clone() GroupStats {
+	return GroupStats{
+		Statuses: copyCounts(s.Statuses),
+		Latency: LatencyHistogram{
+			Counts: append([]int(nil), s.Latency.Counts...),
+			Count:  s.Latency.Count,
+			Sum:    s.Latency.Sum,
+		},
+	}
+}
+
+// cloneStats copies stats, which may be nil.
+func cloneStats(s *GroupStats) *GroupStats {
+	if s == nil {
+		return nil
+	}
+	c := s.clone()
+	return &c
+}
+
+func statusClass(status int) string {
+	if status < 100 || status > 599 {
+		return StatusOther
+	}
+	return fmt.Sprintf("%dxx", status/100)
+}
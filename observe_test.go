@@ -0,0 +1,76 @@
+package groupurl
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestObserve(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		status := 200
+		switch {
+		case i%10 == 0:
+			status = 503
+		case i%10 == 1:
+			status = 404
+		}
+		g.Observe(&url.URL{Path: fmt.Sprintf("/users/%d", i)}, status, time.Duration(i+1)*time.Millisecond)
+	}
+	g.Add(&url.URL{Path: "/users/1000"})
+	g.Add(&url.URL{Path: "/about"})
+
+	s, ok := g.Stats("/Words/Number")
+	if !ok {
+		t.Fatal("expected stats for /Words/Number")
+	}
+	if s.Statuses["2xx"] != 80 || s.Statuses["4xx"] != 10 || s.Statuses["5xx"] != 10 {
+		t.Errorf("unexpected statuses %v", s.Statuses)
+	}
+	if rate := s.ErrorRate(); rate != 0.1 {
+		t.Errorf("got error rate %v, want 0.1", rate)
+	}
+	if s.Latency.Count != 100 || s.Latency.Mean() != 50500*time.Microsecond {
+		t.Errorf("got %d latencies with mean %s", s.Latency.Count, s.Latency.Mean())
+	}
+	// 50 latencies are at most 50ms and 100 at most 100ms, so the median is the 50ms bound.
+	if median := s.Latency.Quantile(0.5); median != 50*time.Millisecond {
+		t.Errorf("got median %s, want 50ms", median)
+	}
+	if p90 := s.Latency.Quantile(0.9); p90 != 90*time.Millisecond {
+		t.Errorf("got 90th percentile %s, want 90ms", p90)
+	}
+
+	if _, ok := g.Stats("/Words"); ok {
+		t.Error("expected no stats for a group without observed requests")
+	}
+
+	var state bytes.Buffer
+	if err := g.WriteState(&state); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.ReadState(&state); err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := restored.Stats("/Words/Number"); !ok || r.Latency.Sum != s.Latency.Sum || r.Statuses["5xx"] != 10 {
+		t.Errorf("got stats %+v after restoring the state", r)
+	}
+}
+
+func TestStatusClass(t *testing.T) {
+	for status, want := range map[int]string{0: StatusOther, 101: "1xx", 204: "2xx", 301: "3xx", 499: "4xx", 599: "5xx", 600: StatusOther} {
+		if got := statusClass(status); got != want {
+			t.Errorf("%d: got %s, want %s", status, got, want)
+		}
+	}
+}
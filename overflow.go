@@ -0,0 +1,190 @@
+package groupurl
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// _overflowSketchBits sets the 256 registers of overflow sketches, for an error of about 6.5%.
+const _overflowSketchBits = 8
+
+// NodeOverflow reports the URLs of a node whose tokens came beyond its cardinality limit, which SimplifyPath
+// replaces with the label however frequent they are. A large Share, or a Distinct estimate close to the Limit,
+// means the limit is too tight for the traffic of the node.
+type NodeOverflow struct {
+	// Tree is the key of the tree the node is in, the number of segments of its URLs minus one.
+	Tree int `json:"tree"`
+	// Pattern is the label path from the root of the tree to the node, such as /shop/Letters.
+	Pattern string `json:"pattern"`
+	Limit   int    `json:"limit"`
+	// Total is the number of URLs that passed through the node, Count those in the overflow and Share its fraction.
+	Total int     `json:"total"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"`
+	// Distinct estimates the number of distinct tokens in the overflow.
+	Distinct int `json:"distinct"`
+}
+
+// Overflows returns the nodes with a cardinality limit whose overflow counted URLs, by decreasing count. Tokens
+// counted under the overflow by the SpillOverflow policy of a Budget are included.
+func (g Grouper) Overflows() []NodeOverflow {
+	var overflows []NodeOverflow
+	for key, t := range g.trees {
+		t.walk(func(path []*urlNode) {
+			node := path[len(path)-1]
+			count := node.tokenCounts.overflow
+			if count == 0 || node.tokenCounts.limit < 0 {
+				return
+			}
+			labels := make([]string, 0, len(path))
+			for _, p := range path {
+				labels = append(labels, p.specificLabel.Value)
+			}
+			overflows = append(overflows, NodeOverflow{
+				Tree:     key,
+				Pattern:  "/" + strings.Join(labels, "/"),
+				Limit:    node.tokenCounts.limit,
+				Total:    node.tokenCounts.total,
+				Count:    count,
+				Share:    float64(count) / float64(node.tokenCounts.total),
+				Distinct: node.overflowSketch.estimate(),
+			})
+		})
+	}
+	sort.Slice(overflows, func(i, j int) bool {
+		a, b := overflows[i], overflows[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Tree != b.Tree {
+			return a.Tree < b.Tree
+		}
+		return a.Pattern < b.Pattern
+	})
+	return overflows
+}
+
+// WriteOverflowMetrics writes the Overflows in the Prometheus text exposition format, as gauges labeled by tree and
+// pattern, so that a scrape can alert when limits are too tight.
+func (g Grouper) WriteOverflowMetrics(w io.Writer) error {
+	overflows := g.Overflows()
+	metrics := []struct {
+		name, help string
+		value      func(NodeOverflow) string
+	}{
+		{"groupurl_overflow_urls", "URLs whose token came beyond the cardinality limit of the node.", func(o NodeOverflow) string {
+			return strconv.Itoa(o.Count)
+		}},
+		{"groupurl_overflow_share", "Share of the URLs of the node in its overflow.", func(o NodeOverflow) string {
+			return strconv.FormatFloat(o.Share, 'g', -1, 64)
+		}},
+		{"groupurl_overflow_distinct_tokens", "Estimated distinct tokens in the overflow of the node.", func(o NodeOverflow) string {
+			return strconv.Itoa(o.Distinct)
+		}},
+		{"groupurl_cardinality_limit", "Cardinality limit of the node.", func(o NodeOverflow) string {
+			return strconv.Itoa(o.Limit)
+		}},
+	}
+	var sb strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&sb, "# HELP %s %s\n# TYPE %s gauge\n", m.name, m.help, m.name)
+		for _, o := range overflows {
+			fmt.Fprintf(&sb, "%s{tree=\"%d\",pattern=\"%s\"} %s\n", m.name, o.Tree, escapeMetricLabel(o.Pattern), m.value(o))
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+var _metricLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeMetricLabel(s string) string {
+	return _metricLabelEscaper.Replace(s)
+}
+
+// overflow records a token the node could not count, in its sketch if the node has a cardinality limit and in its
+// log with WithPromotionRecount.
+func (n *urlNode) overflow(token string, logSize int) {
+	if n.tokenCounts.limit >= 0 {
+		if n.overflowSketch == nil {
+			n.overflowSketch = newOverflowSketch()
+		}
+		n.overflowSketch.add(token)
+	}
+	if logSize > 0 {
+		n.logOverflow(token, logSize)
+	}
+}
+
+// overflowSketch is a HyperLogLog estimating the distinct tokens of the overflow of a node. It is only allocated
+// once a node overflows.
+type overflowSketch []uint8
+
+func newOverflowSketch() overflowSketch {
+	return make(overflowSketch, 1<<_overflowSketchBits)
+}
+
+func (s overflowSketch) add(token string) {
+	h := fnv.New64a()
+	h.Write([]byte(token))
+	x := mix64(h.Sum64())
+	register := x >> (64 - _overflowSketchBits)
+	// The register bits are shifted out and a sentinel bounds the rank.
+	rank := uint8(bits.LeadingZeros64(x<<_overflowSketchBits|1<<(_overflowSketchBits-1))) + 1
+	if rank > s[register] {
+		s[register] = rank
+	}
+}
+
+// merge adds the tokens of another sketch, returning the receiver or a copy of other if it is nil.
+func (s overflowSketch) merge(other overflowSketch) overflowSketch {
+	if other == nil {
+		return s
+	}
+	if s == nil {
+		return append(overflowSketch(nil), other...)
+	}
+	for i, r := range other {
+		if r > s[i] {
+			s[i] = r
+		}
+	}
+	return s
+}
+
+// estimate returns the estimated number of distinct tokens added, with the small range correction of HyperLogLog.
+func (s overflowSketch) estimate() int {
+	if len(s) == 0 {
+		return 0
+	}
+	m := float64(len(s))
+	var sum float64
+	var zeros int
+	for _, r := range s {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	e := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if e <= 2.5*m && zeros > 0 {
+		e = m * math.Log(m/float64(zeros))
+	}
+	return int(math.Round(e))
+}
+
+// mix64 is the finalizer of SplitMix64, spreading the bits of FNV hashes of similar tokens.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,74 @@
+package groupurl
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestOverflows(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2000; i++ {
+		g.Add(&url.URL{Path: "/users/user" + letters(i%1000)})
+	}
+
+	overflows := g.Overflows()
+	if len(overflows) != 1 {
+		t.Fatalf("expected a single overflowing node, got %+v", overflows)
+	}
+	o := overflows[0]
+	if o.Pattern != "/Words/Words" || o.Limit != 50 || o.Total != 2000 || o.Count != 1900 {
+		t.Fatalf("expected the tokens beyond the limit in the overflow, got %+v", o)
+	}
+	if o.Share != 0.95 {
+		t.Fatalf("expected the share of the overflow, got %v", o.Share)
+	}
+	if o.Distinct < 800 || o.Distinct > 1100 {
+		t.Fatalf("expected about 950 distinct tokens in the overflow, got %d", o.Distinct)
+	}
+	if !strings.Contains(g.String(), "overflow 1900 (~") {
+		t.Fatalf("expected the overflow in the printed trees, got %s", g.String())
+	}
+
+	var metrics strings.Builder
+	if err := g.WriteOverflowMetrics(&metrics); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(metrics.String(), `groupurl_overflow_urls{tree="1",pattern="/Words/Words"} 1900`) {
+		t.Fatalf("expected the overflow in the metrics, got %s", metrics.String())
+	}
+
+	var state strings.Builder
+	if err := g.WriteState(&state); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.ReadState(strings.NewReader(state.String())); err != nil {
+		t.Fatal(err)
+	}
+	if got := restored.Overflows(); len(got) != 1 || got[0] != o {
+		t.Fatalf("expected the overflow to be restored, got %+v", got)
+	}
+}
+
+func TestOverflowCardinalityToken(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 500; i++ {
+		g.Add(&url.URL{Path: "/metrics/cardinality"})
+		g.Add(&url.URL{Path: "/metrics/metric" + letters(i%100)})
+	}
+
+	overflows := g.Overflows()
+	if len(overflows) != 1 || overflows[0].Count != 255 || overflows[0].Total != 1000 {
+		t.Fatalf("expected a segment named cardinality to be counted apart from the overflow, got %+v", overflows)
+	}
+}
@@ -0,0 +1,157 @@
+package groupurl
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// OverrideRule forces the paths matching Glob to simplify to Pattern, regardless of what the Grouper learned.
+// Globs are matched against the path segment by segment: `*` matches within a single segment and a `**` segment
+// matches any number of segments, so `/legacy/**` covers every path under `/legacy`. Name optionally identifies
+// the rule in Explain.
+type OverrideRule struct {
+	Name    string
+	Glob    string
+	Pattern string
+}
+
+// overrideRules holds the ordered rules of a Grouper. It is shared by copies of the Grouper so that rules can be
+// changed at runtime.
+type overrideRules struct {
+	rules []OverrideRule
+}
+
+// WithOverrideRules checks the given rules, in order, before the learned trees, so that operators can force
+// mappings such as `/legacy/*` to `/legacy/Archive`. URLs matching a rule are still learned, so that removing
+// the rule restores the learned grouping.
+func WithOverrideRules(rules ...OverrideRule) Option {
+	return func(g *Grouper) error {
+		return g.SetOverrideRules(append(g.OverrideRules(), rules...))
+	}
+}
+
+// SetOverrideRules replaces the override rules of the Grouper, as with WithOverrideRules.
+// The rules are left unchanged if any of them is invalid.
+func (g Grouper) SetOverrideRules(rules []OverrideRule) error {
+	for _, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+	g.overrides.rules = append([]OverrideRule(nil), rules...)
+	return nil
+}
+
+// AddOverrideRule appends a rule, which is checked after the existing ones.
+func (g Grouper) AddOverrideRule(rule OverrideRule) error {
+	if err := rule.validate(); err != nil {
+		return err
+	}
+	g.overrides.rules = append(g.overrides.rules, rule)
+	return nil
+}
+
+// RemoveOverrideRule removes the rules with the given glob, and reports whether there were any.
+func (g Grouper) RemoveOverrideRule(glob string) bool {
+	rules := g.overrides.rules[:0]
+	for _, rule := range g.overrides.rules {
+		if rule.Glob != glob {
+			rules = append(rules, rule)
+		}
+	}
+	removed := len(rules) != len(g.overrides.rules)
+	g.overrides.rules = rules
+	return removed
+}
+
+// OverrideRules returns the override rules of the Grouper in the order they are checked.
+func (g Grouper) OverrideRules() []OverrideRule {
+	return append([]OverrideRule(nil), g.overrides.rules...)
+}
+
+func (r OverrideRule) validate() error {
+	if r.Glob == "" {
+		return errors.New("override rule glob must not be empty")
+	}
+	for _, segment := range splitSegments(r.Glob) {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid override rule glob %q: %w", r.Glob, err)
+		}
+	}
+	if !strings.HasPrefix(r.Pattern, "/") {
+		return fmt.Errorf("override rule pattern %q must start with /", r.Pattern)
+	}
+	return nil
+}
+
+// String describes the rule as shown by Explain.
+func (r OverrideRule) String() string {
+	if r.Name == "" {
+		return r.Glob + " -> " + r.Pattern
+	}
+	return fmt.Sprintf("%s (%s -> %s)", r.Name, r.Glob, r.Pattern)
+}
+
+// match returns the first rule matching a path.
+func (o *overrideRules) match(p string) (OverrideRule, bool) {
+	if o == nil {
+		return OverrideRule{}, false
+	}
+	for _, rule := range o.rules {
+		if matchGlob(rule.Glob, p) {
+			return rule, true
+		}
+	}
+	return OverrideRule{}, false
+}
+
+func (o *overrideRules) clone() *overrideRules {
+	return &overrideRules{rules: append([]OverrideRule(nil), o.rules...)}
+}
+
+// explain describes the segments of the pattern of the rule, which keep the token of the path where they equal it.
+func (r OverrideRule) explain(path string, c treeConfig) []SegmentExplanation {
+	reason := "overridden by rule " + r.String()
+	segments := pathSegments(r.Pattern)
+	tokens := pathSegments(path)
+	explanation := make([]SegmentExplanation, 0, len(segments))
+	for i, s := range segments {
+		kept := keptByOverride(segments, tokens, i)
+		output := c.encodeLabel(s)
+		if kept {
+			output = c.encodeToken(s)
+		}
+		explanation = append(explanation, SegmentExplanation{
+			Label:  s,
+			Output: output,
+			Kept:   kept,
+			Reason: reason,
+		})
+	}
+	return explanation
+}
+
+// simplify returns the Pattern of the rule as the simplified path of a matching path. With WithEncodedLabels,
+// segments of the Pattern found at the same position in the path are encoded as kept tokens and others as labels.
+func (r OverrideRule) simplify(path string, c treeConfig) string {
+	if !c.encodeLabels {
+		return r.Pattern
+	}
+	segments := pathSegments(r.Pattern)
+	tokens := pathSegments(path)
+	for i, s := range segments {
+		if keptByOverride(segments, tokens, i) {
+			segments[i] = c.encodeToken(s)
+		} else {
+			segments[i] = c.encodeLabel(s)
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// keptByOverride reports whether the segment at i of the Pattern of a rule keeps the token of the path there.
+func keptByOverride(segments, tokens []string, i int) bool {
+	return i < len(tokens) && tokens[i] == segments[i]
+}
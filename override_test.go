@@ -0,0 +1,80 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestOverrideRules(t *testing.T) {
+	g, err := New(WithOverrideRules(OverrideRule{Name: "legacy", Glob: "/legacy/**", Pattern: "/legacy/Archive"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		for _, p := range []string{fmt.Sprintf("/legacy/page-%d", i), fmt.Sprintf("/users/%d", i)} {
+			u, err := url.Parse(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.Add(u)
+		}
+	}
+
+	legacy, err := url.Parse("/legacy/old/page-7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.SimplifyPath(legacy); got != "/legacy/Archive" {
+		t.Fatalf("expected the rule to apply, got %s", got)
+	}
+	if got := g.Freeze().SimplifyPath(legacy); got != "/legacy/Archive" {
+		t.Fatalf("expected the rule to apply when frozen, got %s", got)
+	}
+	if got := g.Pattern(legacy); got != "/legacy/Archive" {
+		t.Fatalf("expected the rule to apply to the pattern, got %s", got)
+	}
+	explanation := g.Explain(legacy)
+	if explanation.Override == nil || explanation.Override.Name != "legacy" || explanation.Simplified != "/legacy/Archive" {
+		t.Fatalf("expected the rule in the explanation, got %+v", explanation)
+	}
+	if reason := explanation.Segments[1].Reason; reason != "overridden by rule legacy (/legacy/** -> /legacy/Archive)" {
+		t.Fatalf("expected the rule as the reason, got %s", reason)
+	}
+	if _, err := g.DecisionTable(); err == nil {
+		t.Fatal("expected error for override rules")
+	}
+
+	user, err := url.Parse("/users/7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddOverrideRule(OverrideRule{Glob: "/users/*", Pattern: "/users/Account"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.SimplifyPath(user); got != "/users/Account" {
+		t.Fatalf("expected the rule added at runtime to apply, got %s", got)
+	}
+	if !g.RemoveOverrideRule("/users/*") || g.RemoveOverrideRule("/users/*") {
+		t.Fatal("expected the rule to be removed once")
+	}
+	if got := g.SimplifyPath(user); got != "/users/AlphaNumeric" {
+		t.Fatalf("expected the learned grouping once the rule is removed, got %s", got)
+	}
+
+	if err := g.SetOverrideRules([]OverrideRule{{Glob: "/[", Pattern: "/x"}}); err == nil {
+		t.Fatal("expected error for a malformed glob")
+	}
+	if err := g.AddOverrideRule(OverrideRule{Glob: "/x", Pattern: "x"}); err == nil {
+		t.Fatal("expected error for a pattern without a leading slash")
+	}
+	if rules := g.OverrideRules(); len(rules) != 1 || rules[0].Name != "legacy" {
+		t.Fatalf("expected invalid rules to be rejected, got %+v", rules)
+	}
+	if err := g.SetOverrideRules(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.SimplifyPath(legacy); got == "/legacy/Archive" {
+		t.Fatal("expected the rules to be cleared")
+	}
+}
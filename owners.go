@@ -0,0 +1,136 @@
+package groupurl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// OwnerRule assigns the groups whose simplified path matches Glob to Owners, such as teams or people to notify.
+// Globs are matched segment by segment: `*` matches within a single segment and a `**` segment matches any number
+// of segments, so `/checkout/**` covers every group under `/checkout`. A rule without owners leaves the groups it
+// matches unowned.
+type OwnerRule struct {
+	Glob   string
+	Owners []string
+}
+
+// Ownership maps groups to their owners with rules in the spirit of CODEOWNERS files: the last rule matching a group
+// wins, so that broad rules come first and are refined by the more specific rules after them. It is immutable and
+// safe for concurrent use.
+type Ownership struct {
+	rules []OwnerRule
+}
+
+// NewOwnership creates an Ownership from rules, in order.
+func NewOwnership(rules ...OwnerRule) (*Ownership, error) {
+	o := &Ownership{rules: make([]OwnerRule, 0, len(rules))}
+	for _, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return nil, err
+		}
+		rule.Owners = append([]string(nil), rule.Owners...)
+		o.rules = append(o.rules, rule)
+	}
+	return o, nil
+}
+
+// ParseOwnership reads rules in a CODEOWNERS-like format: each line holds a glob followed by its owners, separated by
+// spaces, and `#` starts a comment.
+//
+//	# Everything defaults to the platform team.
+//	/**                  @platform
+//	/checkout/**         @payments @sre
+//	/users/Number/avatar @media
+func ParseOwnership(r io.Reader) (*Ownership, error) {
+	var rules []OwnerRule
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		rule := OwnerRule{Glob: fields[0], Owners: fields[1:]}
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ownership rules: %w", err)
+	}
+	return &Ownership{rules: rules}, nil
+}
+
+// Rules returns the rules of the Ownership in order.
+func (o *Ownership) Rules() []OwnerRule {
+	return mapSlice(o.rules, func(rule OwnerRule) OwnerRule {
+		rule.Owners = append([]string(nil), rule.Owners...)
+		return rule
+	})
+}
+
+// Owners returns the owners of the group of a simplified path, as returned by SimplifyPath without
+// WithEncodedLabels, or nil if no rule assigns it.
+func (o *Ownership) Owners(simplified string) []string {
+	if o == nil {
+		return nil
+	}
+	for i := len(o.rules) - 1; i >= 0; i-- {
+		if matchGlob(o.rules[i].Glob, simplified) {
+			if len(o.rules[i].Owners) == 0 {
+				return nil
+			}
+			return append([]string(nil), o.rules[i].Owners...)
+		}
+	}
+	return nil
+}
+
+func (r OwnerRule) validate() error {
+	if r.Glob == "" {
+		return errors.New("owner rule glob must not be empty")
+	}
+	if !strings.HasPrefix(r.Glob, "/") {
+		return fmt.Errorf("owner rule glob %q must start with /", r.Glob)
+	}
+	for _, segment := range splitSegments(r.Glob) {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid owner rule glob %q: %w", r.Glob, err)
+		}
+	}
+	return nil
+}
+
+// WithOwnership resolves the owners of groups with o, for Owners and the alerts of WithAlert.
+func WithOwnership(o *Ownership) Option {
+	return func(g *Grouper) error {
+		g.ownership = o
+		return nil
+	}
+}
+
+// Owners returns the owners of the group a URL falls into, according to the Ownership set WithOwnership, or nil
+// if there is none or no rule assigns the group.
+func (g Grouper) Owners(u *url.URL) []string {
+	if g.ownership == nil {
+		return nil
+	}
+	return g.ownership.Owners(g.tree.decode(g.SimplifyPath(u)))
+}
+
+// Owners returns the owners of the group a URL falls into, the same way Grouper.Owners does.
+func (f FrozenGrouper) Owners(u *url.URL) []string {
+	if f.ownership == nil {
+		return nil
+	}
+	return f.ownership.Owners(f.tree.decode(f.SimplifyPath(u)))
+}
@@ -0,0 +1,93 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOwnership(t *testing.T) {
+	o, err := ParseOwnership(strings.NewReader(`
+# Everything defaults to the platform team.
+/**                  @platform
+/export/**           @data @sre   # exports page the SRE team too
+/export/Number/raw   @data
+/health
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for simplified, owners := range map[string][]string{
+		"/":                    {"@platform"},
+		"/users/Number":        {"@platform"},
+		"/export/Number":       {"@data", "@sre"},
+		"/export/Number/raw":   {"@data"},
+		"/export/Number/raw/x": {"@data", "@sre"},
+		"/health":              nil,
+	} {
+		if got := o.Owners(simplified); !reflect.DeepEqual(got, owners) {
+			t.Errorf("%s: expected owners %v, got %v", simplified, owners, got)
+		}
+	}
+	if rules := o.Rules(); len(rules) != 4 || rules[1].Glob != "/export/**" {
+		t.Fatalf("unexpected rules %v", rules)
+	}
+	if got := (*Ownership)(nil).Owners("/"); got != nil {
+		t.Fatalf("expected no owners without rules, got %v", got)
+	}
+
+	for _, invalid := range []string{"export/** @data", "/export/[ @data"} {
+		if _, err := ParseOwnership(strings.NewReader("/** @platform\n" + invalid)); err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("%s: expected an error on line 2, got %v", invalid, err)
+		}
+	}
+	if _, err := NewOwnership(OwnerRule{Owners: []string{"@data"}}); err == nil {
+		t.Fatal("expected error for an empty glob")
+	}
+}
+
+func TestGrouperOwners(t *testing.T) {
+	o, err := NewOwnership(
+		OwnerRule{Glob: "/**", Owners: []string{"@platform"}},
+		OwnerRule{Glob: "/export/**", Owners: []string{"@data"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var alerts []Alert
+	g, err := New(
+		WithOwnership(o),
+		WithEncodedLabels(),
+		WithAlert(RuleSpec{PatternGlob: "/**", MinRate: 1, Window: time.Second}, func(a Alert) {
+			alerts = append(alerts, a)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/export/%d", i)})
+	}
+
+	u := &url.URL{Path: "/export/42"}
+	if got := g.Owners(u); !reflect.DeepEqual(got, []string{"@data"}) {
+		t.Fatalf("expected the owners of the group, got %v", got)
+	}
+	if got := g.Freeze().Owners(u); !reflect.DeepEqual(got, []string{"@data"}) {
+		t.Fatalf("expected frozen copies to resolve owners, got %v", got)
+	}
+	if len(alerts) == 0 || !reflect.DeepEqual(alerts[len(alerts)-1].Owners, []string{"@data"}) {
+		t.Fatalf("expected alerts to carry the owners of their group, got %+v", alerts)
+	}
+
+	plain, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := plain.Owners(u); got != nil {
+		t.Fatalf("expected no owners without WithOwnership, got %v", got)
+	}
+}
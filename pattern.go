@@ -0,0 +1,177 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const _unknownLabel = "Unknown"
+
+// PatternMatcher matches URLs against a simplified path or group pattern, such as `/users/Number/posts`, without a
+// Grouper. It is safe for concurrent use, as long as the classifiers it was compiled with are.
+type PatternMatcher struct {
+	pattern     string
+	segments    []patternSegment
+	classifiers []PathTokenClassifier
+}
+
+type patternSegment struct {
+	value string
+	label bool
+	// param is the name the segment is returned under by Match, for labels.
+	param string
+}
+
+// CompilePattern compiles a pattern emitted by a Grouper with the default classifiers into a matcher, so that stored
+// patterns can be applied to new URLs without the Grouper that learned them.
+func CompilePattern(pattern string) (*PatternMatcher, error) {
+	return CompilePatternWithClassifiers(pattern, DefaultClassifiers())
+}
+
+// CompilePatternWithClassifiers compiles a pattern emitted by a Grouper with the given classifiers into a matcher.
+//
+// Segments that are labels the classifiers are known to emit, along with Unknown and the `**` of wildcard tails, are
+// matched by classifying the URL the same way the Grouper does, while the other segments must be equal to the segment
+// of the URL, ignoring case. A label also matches the tokens of its children, so that AlphaNumeric matches numbers.
+// Patterns simplified WithEncodedLabels are recognized by their angle brackets and decoded with ParseSimplified,
+// which is the only way to match labels of classifiers that are not a DescribedClassifier, or segments literally
+// named like a label.
+func CompilePatternWithClassifiers(pattern string, classifiers []PathTokenClassifier) (*PatternMatcher, error) {
+	var (
+		segments []patternSegment
+		err      error
+	)
+	if strings.Contains(pattern, _labelOpen) {
+		segments, err = encodedPatternSegments(pattern)
+	} else {
+		segments, err = plainPatternSegments(pattern, classifiers)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]int)
+	for i, s := range segments {
+		if !s.label {
+			continue
+		}
+		if s.value == _wildcardTail && i != len(segments)-1 {
+			return nil, fmt.Errorf("wildcard %s must be the last segment of %q", _wildcardTail, pattern)
+		}
+		params[s.value]++
+		segments[i].param = s.value
+		if n := params[s.value]; n > 1 {
+			segments[i].param += strconv.Itoa(n)
+		}
+	}
+	return &PatternMatcher{
+		pattern:     pattern,
+		segments:    segments,
+		classifiers: classifiers,
+	}, nil
+}
+
+func encodedPatternSegments(pattern string) ([]patternSegment, error) {
+	decoded, err := ParseSimplified(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return mapSlice(decoded, func(s SimplifiedSegment) patternSegment {
+		return patternSegment{value: s.Value, label: s.Label}
+	}), nil
+}
+
+// plainPatternSegments splits a pattern into segments, recognizing labels that span several segments such as
+// YYYY/MM/DD.
+func plainPatternSegments(pattern string, classifiers []PathTokenClassifier) ([]patternSegment, error) {
+	infos, _ := describeLabels(classifiers)
+	labels := make([]string, 0, len(infos)+2)
+	for label := range infos {
+		labels = append(labels, label)
+	}
+	labels = append(labels, _unknownLabel, _wildcardTail)
+	// Longer labels are tried first so that YYYY/MM/DD is not taken for YYYY.
+	sort.Slice(labels, func(i, j int) bool {
+		if len(labels[i]) != len(labels[j]) {
+			return len(labels[i]) > len(labels[j])
+		}
+		return labels[i] < labels[j]
+	})
+
+	var segments []patternSegment
+	rest := strings.Trim(pattern, "/")
+	for rest != "" {
+		label := ""
+		for _, l := range labels {
+			if after, ok := strings.CutPrefix(rest, l); ok && (after == "" || after[0] == '/') {
+				label = l
+				break
+			}
+		}
+		if label != "" {
+			segments = append(segments, patternSegment{value: label, label: true})
+			rest = strings.TrimLeft(rest[len(label):], "/")
+			continue
+		}
+		segment, next, _ := strings.Cut(rest, "/")
+		if segment == "" {
+			return nil, fmt.Errorf("empty segment in %q", pattern)
+		}
+		segments = append(segments, patternSegment{value: segment})
+		rest = next
+	}
+	return segments, nil
+}
+
+// Match reports whether a URL matches the pattern, along with the tokens of the URL matched by each label, keyed by
+// the label. Labels that appear several times are numbered from their second occurrence on, such as Number2.
+func (m *PatternMatcher) Match(u *url.URL) (params map[string]string, ok bool) {
+	params = make(map[string]string)
+	rest := u.Path
+	for _, s := range m.segments {
+		rest = strings.TrimLeft(rest, "/")
+		if rest == "" {
+			return nil, false
+		}
+
+		var token string
+		switch {
+		case !s.label:
+			token, rest, _ = strings.Cut(rest, "/")
+			if !strings.EqualFold(token, s.value) {
+				return nil, false
+			}
+			continue
+		case s.value == _wildcardTail:
+			token, rest = strings.TrimRight(rest, "/"), ""
+		default:
+			label, match := labelPathToken(rest, m.classifiers)
+			if match == "" || !strings.HasPrefix(rest, match) {
+				// As in labelPathTokens, the rest of a path classifiers cannot split is a single Unknown token.
+				label, match = Label{LabelFields: LabelFields{Value: _unknownLabel}}, rest
+			}
+			if label.Value != s.value && label.parentOrSelf().Value != s.value {
+				return nil, false
+			}
+			token, rest = strings.TrimRight(match, "/"), rest[len(match):]
+		}
+		params[s.param] = token
+	}
+	if strings.Trim(rest, "/") != "" {
+		return nil, false
+	}
+	return params, true
+}
+
+// String returns the pattern the matcher was compiled from.
+func (m *PatternMatcher) String() string {
+	return m.pattern
+}
+
+// CompilePattern compiles a pattern with the classifiers of the Grouper, as with CompilePatternWithClassifiers.
+func (g Grouper) CompilePattern(pattern string) (*PatternMatcher, error) {
+	return CompilePatternWithClassifiers(pattern, g.classifiers)
+}
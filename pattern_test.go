@@ -0,0 +1,81 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestCompilePattern(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		u, err := url.Parse(fmt.Sprintf("/users/%d/posts/%d", i, i*7))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+	u, err := url.Parse("/users/12/posts/84")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pattern := g.SimplifyPath(u)
+	if pattern != "/users/Number/posts/Number" {
+		t.Fatalf("expected /users/Number/posts/Number, got %s", pattern)
+	}
+
+	m, err := CompilePattern(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, expected := range map[string]map[string]string{
+		"/users/5/posts/9":        {"Number": "5", "Number2": "9"},
+		"/Users/5/posts/9/":       {"Number": "5", "Number2": "9"},
+		"/users/bob/posts/9":      nil,
+		"/users/5/posts":          nil,
+		"/users/5/posts/9/extra":  nil,
+		"/accounts/5/posts/9":     nil,
+		"/users/5/posts/2013/1/1": nil,
+	} {
+		params, ok := m.Match(&url.URL{Path: path})
+		if ok != (expected != nil) || !reflect.DeepEqual(params, expected) {
+			t.Fatalf("expected %v for %s, got %v, %t", expected, path, params, ok)
+		}
+	}
+	if m.String() != pattern {
+		t.Fatalf("expected the pattern, got %s", m)
+	}
+}
+
+func TestCompilePatternLabels(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		path    string
+		params  map[string]string
+	}{
+		{"/Words/AlphaNumeric", "/orders/42", map[string]string{"Words": "orders", "AlphaNumeric": "42"}},
+		{"/archive/YYYY/MM/DD", "/archive/2013/11/20", map[string]string{"YYYY/MM/DD": "2013/11/20"}},
+		{"/static/**", "/static/css/site.css", map[string]string{"**": "css/site.css"}},
+		{"/Number/<Number>", "/Number/7", map[string]string{"Number": "7"}},
+	} {
+		m, err := CompilePattern(test.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		params, ok := m.Match(&url.URL{Path: test.path})
+		if !ok || !reflect.DeepEqual(params, test.params) {
+			t.Fatalf("expected %v for %s with %s, got %v, %t", test.params, test.path, test.pattern, params, ok)
+		}
+	}
+
+	if _, err := CompilePattern("/static/**/more"); err == nil {
+		t.Fatal("expected error for a wildcard before the last segment")
+	}
+	if _, err := CompilePattern("/<Number"); err == nil {
+		t.Fatal("expected error for an unterminated label")
+	}
+}
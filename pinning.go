@@ -0,0 +1,74 @@
+package groupurl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WithPinnedTokens always preserves the given tokens in simplified paths, wherever they appear and however rare they
+// are, such as `admin` or `api`. Tokens are compared case insensitively.
+func WithPinnedTokens(tokens ...string) Option {
+	return func(g *Grouper) error {
+		return addTokenOverrides(&g.tree.pinned, g.tree.redacted, tokens, "pinned", "redacted")
+	}
+}
+
+// WithRedactedTokens never preserves the given tokens in simplified paths, wherever they appear and however frequent
+// they are, such as the names of customers. They are replaced by their label, including in segments the Grouper has
+// not learned. Tokens are compared case insensitively.
+func WithRedactedTokens(tokens ...string) Option {
+	return func(g *Grouper) error {
+		return addTokenOverrides(&g.tree.redacted, g.tree.pinned, tokens, "redacted", "pinned")
+	}
+}
+
+func addTokenOverrides(set *map[string]bool, other map[string]bool, tokens []string, name, otherName string) error {
+	if *set == nil {
+		*set = make(map[string]bool, len(tokens))
+	}
+	for _, token := range tokens {
+		token = strings.ToLower(token)
+		if token == "" {
+			return fmt.Errorf("cannot add an empty %s token", name)
+		}
+		if other[token] {
+			return fmt.Errorf("token %q cannot be both %s and %s", token, name, otherName)
+		}
+		(*set)[token] = true
+	}
+	return nil
+}
+
+// override returns whether a token is kept regardless of what the tree learned, and whether that is the case.
+// Redacted tokens are never kept and pinned tokens always are.
+func (c treeConfig) override(token string) (keep bool, ok bool) {
+	if len(c.pinned) == 0 && len(c.redacted) == 0 {
+		return false, false
+	}
+	token = strings.ToLower(token)
+	switch {
+	case c.redacted[token]:
+		return false, true
+	case c.pinned[token]:
+		return true, true
+	}
+	return false, false
+}
+
+// keeps reports whether SimplifyPath preserves a token at a node.
+func (t urlTree) keeps(n *urlNode, token string) bool {
+	if keep, ok := t.override(token); ok {
+		return keep
+	}
+	return t.important(n) && t.isSignificant(n, token)
+}
+
+func sortedTokens(set map[string]bool) []string {
+	tokens := make([]string, 0, len(set))
+	for token := range set {
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+	return tokens
+}
@@ -0,0 +1,58 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestPinnedAndRedactedTokens(t *testing.T) {
+	g, err := New(WithPinnedTokens("Admin", "42"), WithRedactedTokens("acme"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 200; i++ {
+		g.Add(&url.URL{Path: "/customers/acme/orders"})
+		g.Add(&url.URL{Path: fmt.Sprintf("/tools/tool%c%c", 'a'+i%26, 'a'+i/26)})
+		g.Add(&url.URL{Path: fmt.Sprintf("/orders/%d", i)})
+	}
+	g.Add(&url.URL{Path: "/tools/admin"})
+
+	for path, want := range map[string]string{
+		"/customers/acme/orders": "/customers/Words/orders",
+		"/customers/ACME/orders": "/customers/Words/orders",
+		"/tools/admin":           "/tools/admin",
+		"/tools/ADMIN":           "/tools/ADMIN",
+		"/tools/toolab":          "/tools/AlphaNumeric",
+		"/orders/42":             "/orders/42",
+		"/orders/43":             "/orders/AlphaNumeric",
+		"/unseen/acme/path/here": "/unseen/Words/path/here",
+	} {
+		if got := g.SimplifyPath(&url.URL{Path: path}); got != want {
+			t.Errorf("%s: got %s, want %s", path, got, want)
+		}
+	}
+
+	segments := g.Explain(&url.URL{Path: "/tools/admin"}).Segments
+	if got := segments[1].Reason; got != "token is pinned" {
+		t.Errorf("got reason %q for a pinned token", got)
+	}
+
+	table, err := g.DecisionTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewDecisionEvaluator(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/customers/acme/orders", "/tools/admin", "/orders/42", "/unseen/acme/path/here"} {
+		if got, want := e.SimplifyPath(path), g.SimplifyPath(&url.URL{Path: path}); got != want {
+			t.Errorf("%s: decision table gave %s, want %s", path, got, want)
+		}
+	}
+
+	if _, err := New(WithPinnedTokens("acme"), WithRedactedTokens("ACME")); err == nil {
+		t.Error("expected an error for a token both pinned and redacted")
+	}
+}
@@ -0,0 +1,149 @@
+package groupurl
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+var (
+	regexUUID       = regexp.MustCompile(`(?i)^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}(/|$)`)
+	regexObjectID   = regexp.MustCompile(`(?i)^[0-9a-f]{24}(/|$)`)
+	regexVersion    = regexp.MustCompile(`(?i)^v\d+(\.\d+)*(/|$)`)
+	regexPagination = regexp.MustCompile(`(?i)^(page|p)[-_]?\d+(/|$)`)
+	regexPrice      = regexp.MustCompile(`^\d+\.\d{2}(/|$)`)
+	regexSKU        = regexp.MustCompile(`^([A-Z]+-?\d+|\d+-?[A-Z]+)[A-Z0-9]*(-[A-Z0-9]+)*(/|$)`)
+	regexYYYYMM     = regexp.MustCompile(`^\d{4}/((0[1-9])|(1[0-2]))(/|$)`)
+	regexSlug       = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+){2,}(/|$)`)
+)
+
+var _presets = map[string]func() []PathTokenClassifier{
+	"default": DefaultClassifiers,
+	"strict":  StrictDefaultClassifiers,
+	"loose":   LooseDefaultClassifiers,
+	"assets": func() []PathTokenClassifier {
+		return append(AssetClassifiers(), DefaultClassifiers()...)
+	},
+	"probes": func() []PathTokenClassifier {
+		return append([]PathTokenClassifier{ProbeClassifier()}, DefaultClassifiers()...)
+	},
+	"api":      APIClassifiers,
+	"commerce": CommerceClassifiers,
+	"blog":     BlogClassifiers,
+}
+
+// WithPreset uses a named built-in set of classifiers, as listed by Presets.
+func WithPreset(name string) Option {
+	return func(g *Grouper) error {
+		preset, ok := _presets[name]
+		if !ok {
+			return fmt.Errorf("unknown preset %q", name)
+		}
+		g.classifiers = preset()
+		return nil
+	}
+}
+
+// Presets returns the sorted names of the built-in sets of classifiers accepted by WithPreset.
+func Presets() []string {
+	names := make([]string, 0, len(_presets))
+	for name := range _presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// APIClassifiers returns the default classifiers preceded by classifiers for the identifiers and versions of JSON APIs,
+// so that `/v2/users/507f1f77bcf86cd799439011` is grouped as `/v2/users/ObjectID`.
+func APIClassifiers() []PathTokenClassifier {
+	return append([]PathTokenClassifier{
+		UUIDClassifier(),
+		ObjectIDClassifier(),
+		VersionClassifier(),
+	}, DefaultClassifiers()...)
+}
+
+// CommerceClassifiers returns the default classifiers preceded by classifiers for the pagination, prices and
+// product codes of shops, so that `/products/AB-1234` is grouped as `/products/SKU`.
+func CommerceClassifiers() []PathTokenClassifier {
+	return append([]PathTokenClassifier{
+		PaginationClassifier(),
+		PriceClassifier(),
+		SKUClassifier(),
+	}, DefaultClassifiers()...)
+}
+
+// BlogClassifiers returns classifiers for the dated permalinks of blogs and content management systems, which
+// group dates by their precision and article slugs as Slug while keeping short section names,
+// so that `/2024/05/my-first-post` is grouped as `/YYYY/MM/Slug`.
+func BlogClassifiers() []PathTokenClassifier {
+	return []PathTokenClassifier{
+		YYYYMMDDClassifier(),
+		YYYYMMClassifier(),
+		YearPathTokenClassifier{
+			Start: _yyyyStart,
+			End:   _yyyyEnd,
+		},
+		SlugClassifier(),
+		NestedPathTokenClassifier{
+			Parent: AlphaNumericClassifier(),
+			Children: []PathTokenClassifier{
+				NumberClassifier(),
+				WordsClassifier(),
+				LettersClassifier(),
+			},
+		},
+	}
+}
+
+// UUIDClassifier returns a classifier that matches segments that are UUIDs.
+func UUIDClassifier() RegexPathTokenClassifier {
+	return presetClassifier(regexUUID, LabelFields{Value: "UUID"}, builtinInfo("uuid", "UUIDs"))
+}
+
+// ObjectIDClassifier returns a classifier that matches segments that are MongoDB ObjectIDs, 24 hexadecimal digits.
+func ObjectIDClassifier() RegexPathTokenClassifier {
+	return presetClassifier(regexObjectID, LabelFields{Value: "ObjectID"}, builtinInfo("objectid", "MongoDB ObjectIDs"))
+}
+
+// VersionClassifier returns a classifier that matches API versions such as `v2` or `v1.1`.
+// Versions are few, so they are kept in simplified paths.
+func VersionClassifier() RegexPathTokenClassifier {
+	return presetClassifier(regexVersion, LabelFields{Important: true, CardinalityLimit: 20, Value: "Version"}, builtinInfo("version", "API versions"))
+}
+
+// PaginationClassifier returns a classifier that matches page segments such as `page-2` or `p3`.
+func PaginationClassifier() RegexPathTokenClassifier {
+	return presetClassifier(regexPagination, LabelFields{Value: "Page"}, builtinInfo("pagination", "page segments"))
+}
+
+// PriceClassifier returns a classifier that matches prices with two decimals such as `19.99`.
+func PriceClassifier() RegexPathTokenClassifier {
+	return presetClassifier(regexPrice, LabelFields{Value: "Price"}, builtinInfo("price", "prices"))
+}
+
+// SKUClassifier returns a classifier that matches upper case product codes mixing letters and digits, such as
+// `AB-1234` or `12345XL`.
+func SKUClassifier() RegexPathTokenClassifier {
+	return presetClassifier(regexSKU, LabelFields{Value: "SKU"}, builtinInfo("sku", "product codes"))
+}
+
+// YYYYMMClassifier returns a classifier that matches segments that are a month in the format YYYY/MM.
+func YYYYMMClassifier() RegexPathTokenClassifier {
+	return presetClassifier(regexYYYYMM, LabelFields{Value: "YYYY/MM"}, builtinInfo("yyyymm", "months in the format YYYY/MM"))
+}
+
+// SlugClassifier returns a classifier that matches lower case slugs of at least three words, such as
+// `my-first-post`, which name individual articles rather than sections.
+func SlugClassifier() RegexPathTokenClassifier {
+	return presetClassifier(regexSlug, LabelFields{Value: "Slug"}, builtinInfo("slug", "article slugs"))
+}
+
+func presetClassifier(regex *regexp.Regexp, label LabelFields, info ClassifierInfo) RegexPathTokenClassifier {
+	return RegexPathTokenClassifier{
+		Regex: regex,
+		Label: Label{LabelFields: label},
+		Info:  info,
+	}
+}
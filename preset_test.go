@@ -0,0 +1,133 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestPresets(t *testing.T) {
+	for _, tt := range []struct {
+		preset   string
+		corpus   []func(i int) string
+		expected map[string]string
+	}{
+		{
+			preset: "api",
+			corpus: []func(i int) string{
+				func(i int) string { return fmt.Sprintf("/v1/users/%08x-1234-4abc-8def-0123456789ab", i) },
+				func(i int) string { return fmt.Sprintf("/v2/orders/507f1f77bcf86cd7%08x", i) },
+				func(i int) string { return fmt.Sprintf("/v2/orders/507f1f77bcf86cd7%08x/items", i) },
+			},
+			expected: map[string]string{
+				"/v1/users/3f2504e0-4f89-11d3-9a0c-0305e82c3301": "/v1/users/UUID",
+				"/v2/orders/5f1d7f77bcf86cd799439011":            "/v2/orders/ObjectID",
+				"/v2/orders/5f1d7f77bcf86cd799439011/items":      "/v2/orders/ObjectID/items",
+				"/v1/users/3F2504E0-4F89-11D3-9A0C-0305E82C3301": "/v1/users/UUID",
+				"/v3/users/3f2504e0-4f89-11d3-9a0c-0305e82c3301": "/Version/users/UUID",
+			},
+		},
+		{
+			preset: "commerce",
+			corpus: []func(i int) string{
+				func(i int) string { return fmt.Sprintf("/products/AB-%d", i) },
+				func(i int) string { return fmt.Sprintf("/shop/page-%d", i) },
+				func(i int) string { return fmt.Sprintf("/deals/%d.99", i) },
+			},
+			expected: map[string]string{
+				"/products/XY-42": "/products/SKU",
+				"/shop/p7":        "/shop/Page",
+				"/deals/5.49":     "/deals/Price",
+			},
+		},
+		{
+			preset: "blog",
+			corpus: []func(i int) string{
+				func(i int) string { return fmt.Sprintf("/blog/2024/05/how-we-scaled-part-%d", i) },
+				func(i int) string { return fmt.Sprintf("/archive/%d", 1900+i) },
+				func(i int) string { return fmt.Sprintf("/2023/01/%02d/release-notes-v%d", i%28+1, i) },
+			},
+			expected: map[string]string{
+				"/blog/2019/12/a-new-beginning": "/blog/YYYY/MM/Slug",
+				"/archive/2001":                 "/archive/YYYY",
+				"/2020/02/29/year-in-review":    "/YYYY/MM/DD/Slug",
+			},
+		},
+	} {
+		t.Run(tt.preset, func(t *testing.T) {
+			g, err := New(WithPreset(tt.preset))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 100; i++ {
+				for _, p := range tt.corpus {
+					u, err := url.Parse(p(i))
+					if err != nil {
+						t.Fatal(err)
+					}
+					g.Add(u)
+				}
+			}
+			for p, expected := range tt.expected {
+				u, err := url.Parse(p)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := g.SimplifyPath(u); got != expected {
+					t.Fatalf("expected %s for %s, got %s", expected, p, got)
+				}
+			}
+		})
+	}
+
+	if _, err := New(WithPreset("missing")); err == nil {
+		t.Fatal("expected an unknown preset to fail")
+	}
+}
+
+func TestDefaultClassifierVariants(t *testing.T) {
+	var paths []string
+	for i := 0; i < 20; i++ {
+		paths = append(paths, fmt.Sprintf("/colors/%s", budgetWord(i)))
+	}
+	for _, color := range []string{"red", "green", "blue"} {
+		paths = append(paths, "/mixed/colors/"+color)
+	}
+	for i := 1; i <= 3; i++ {
+		paths = append(paths, fmt.Sprintf("/mixed/colors/%d", i))
+	}
+
+	for _, tt := range []struct {
+		preset   string
+		expected map[string]string
+	}{
+		{"default", map[string]string{"/colors/" + budgetWord(3): "/colors/" + budgetWord(3), "/mixed/colors/red": "/mixed/colors/AlphaNumeric"}},
+		{"strict", map[string]string{"/colors/" + budgetWord(3): "/colors/Words", "/mixed/colors/red": "/mixed/colors/AlphaNumeric"}},
+		{"loose", map[string]string{"/colors/" + budgetWord(3): "/colors/" + budgetWord(3), "/mixed/colors/red": "/mixed/colors/red"}},
+	} {
+		t.Run(tt.preset, func(t *testing.T) {
+			g, err := New(WithPreset(tt.preset))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 120; i++ {
+				for _, p := range paths {
+					u, err := url.Parse(p)
+					if err != nil {
+						t.Fatal(err)
+					}
+					g.Add(u)
+				}
+			}
+			for p, expected := range tt.expected {
+				u, err := url.Parse(p)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := g.SimplifyPath(u); got != expected {
+					t.Fatalf("expected %s for %s, got %s", expected, p, got)
+				}
+			}
+		})
+	}
+}
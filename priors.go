@@ -0,0 +1,78 @@
+package groupurl
+
+import "fmt"
+
+// Importance overrides whether labels are Important at some positions of paths.
+type Importance int
+
+const (
+	// ImportanceFromLabel leaves the importance to the labels. This is the default.
+	ImportanceFromLabel Importance = iota
+	// AlwaysImportant keeps the significant tokens of every label, as for a section name that may look like an
+	// identifier, such as `/v2`.
+	AlwaysImportant
+	// NeverImportant replaces the tokens of every label with the label, as for parameters that may look like words.
+	// Pinned tokens are still kept.
+	NeverImportant
+)
+
+// PositionPrior adjusts how the segments at positions From to To of paths are simplified, counted from 0 for the
+// first segment. A negative To extends the range to every deeper position. Significance, if set, replaces the
+// Significance of the Grouper at those positions.
+type PositionPrior struct {
+	From         int
+	To           int
+	Importance   Importance
+	Significance Significance
+}
+
+// contains reports whether the prior applies to a position.
+func (p PositionPrior) contains(depth int) bool {
+	return depth >= p.From && (p.To < 0 || depth <= p.To)
+}
+
+// WithPositionPriors adjusts the importance of labels and the significance of tokens by position, since the first
+// segment of paths is usually a static section name and deep segments are usually parameters. Later priors take
+// precedence over earlier ones at the positions they share. Decision tables cannot be exported with priors.
+func WithPositionPriors(priors ...PositionPrior) Option {
+	return func(g *Grouper) error {
+		for _, p := range priors {
+			if p.From < 0 || (p.To >= 0 && p.To < p.From) {
+				return fmt.Errorf("invalid positions %d to %d", p.From, p.To)
+			}
+			if p.Importance < ImportanceFromLabel || p.Importance > NeverImportant {
+				return fmt.Errorf("unknown importance %d", p.Importance)
+			}
+		}
+		g.tree.priors = append(g.tree.priors, priors...)
+		return nil
+	}
+}
+
+// atPosition returns a label with its importance adjusted by the priors of a position.
+func (t urlTree) atPosition(label LabelFields, depth int) LabelFields {
+	for i := len(t.priors) - 1; i >= 0; i-- {
+		p := t.priors[i]
+		if p.Importance == ImportanceFromLabel || !p.contains(depth) {
+			continue
+		}
+		label.Important = p.Importance == AlwaysImportant
+		break
+	}
+	return label
+}
+
+// important reports whether the tokens of a node may be kept.
+func (t urlTree) important(n *urlNode) bool {
+	return t.atPosition(n.specificLabel, n.depth).Important
+}
+
+// significanceAt returns the Significance applied at a position.
+func (t urlTree) significanceAt(depth int) Significance {
+	for i := len(t.priors) - 1; i >= 0; i-- {
+		if p := t.priors[i]; p.Significance != nil && p.contains(depth) {
+			return p.Significance
+		}
+	}
+	return t.significance
+}
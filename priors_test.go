@@ -0,0 +1,57 @@
+package groupurl
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestPositionPriors(t *testing.T) {
+	g, err := New(WithPositionPriors(
+		PositionPrior{From: 0, To: 0, Importance: AlwaysImportant},
+		PositionPrior{From: 2, To: -1, Importance: NeverImportant},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		for _, p := range []string{"/v2/users/alice", "/v2/users/bob", "/v3/orders/carol", "/42/orders/dave"} {
+			u, err := url.Parse(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.Add(u)
+		}
+	}
+
+	u, err := url.Parse("/v2/users/alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.SimplifyPath(u); got != "/v2/users/Words" {
+		t.Fatalf("expected the first segment kept and deep ones replaced, got %s", got)
+	}
+	if reason := g.Explain(u).Segments[2].Reason; reason != "label is not important" {
+		t.Fatalf("expected the prior to make the label unimportant, got %s", reason)
+	}
+	if _, err := g.DecisionTable(); err == nil {
+		t.Fatal("expected error for position priors")
+	}
+
+	strict, err := New(WithPositionPriors(PositionPrior{From: 1, To: 1, Significance: MinCountShare{MinCount: 100}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		strict.Add(u)
+	}
+	if got := strict.SimplifyPath(u); got != "/v2/Words/alice" {
+		t.Fatalf("expected the significance of the prior at its position only, got %s", got)
+	}
+
+	if _, err := New(WithPositionPriors(PositionPrior{From: 2, To: 1})); err == nil {
+		t.Fatal("expected error for an empty range")
+	}
+	if _, err := New(WithPositionPriors(PositionPrior{Importance: NeverImportant + 1})); err == nil {
+		t.Fatal("expected error for an unknown importance")
+	}
+}
@@ -0,0 +1,34 @@
+package groupurl
+
+import "fmt"
+
+// WithPrivacyMode guarantees that no raw token is emitted unless it is on the allowlist or was seen at least k times
+// at its position, for k-anonymity. It changes the preservation rules of SimplifyPath as follows:
+//
+//   - Tokens on the allowlist are kept like those of WithPinnedTokens.
+//   - Other tokens must also have been counted k times at their node on top of being significant.
+//   - Segments the Grouper has not learned are replaced with their label, whatever the Fallback.
+//   - Sample paths are not recorded.
+//
+// Snapshot, TreeJSON, ExportMarkdown and DecisionTable mark their output as produced in privacy mode. The state
+// written by WriteState holds the counts of every token and is not covered by the guarantee.
+func WithPrivacyMode(k int, allowlist []string) Option {
+	return func(g *Grouper) error {
+		if k < 1 {
+			return fmt.Errorf("privacy mode k must be positive, got %d", k)
+		}
+		g.tree.privacyK = k
+		g.tails.private = true
+		return addTokenOverrides(&g.tree.pinned, g.tree.redacted, allowlist, "allowlisted", "redacted")
+	}
+}
+
+// PrivacyK returns the k of WithPrivacyMode, or 0 when the Grouper is not in privacy mode.
+func (g Grouper) PrivacyK() int {
+	return g.tree.privacyK
+}
+
+// anonymous reports whether a token was seen often enough at a node to be emitted in privacy mode.
+func (c treeConfig) anonymous(n *urlNode, token string) bool {
+	return c.privacyK == 0 || n.tokenCounts.get(token) >= c.privacyK
+}
@@ -0,0 +1,73 @@
+package groupurl
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestPrivacyMode(t *testing.T) {
+	g, err := New(WithPrivacyMode(50, []string{"admin"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		g.Add(&url.URL{Path: "/customers/bigco/orders"})
+		if i < 30 {
+			g.Add(&url.URL{Path: "/customers/acme/orders"})
+		}
+	}
+	g.Add(&url.URL{Path: "/admin"})
+
+	paths := map[string]string{
+		"/customers/bigco/orders": "/customers/bigco/orders",
+		"/customers/acme/orders":  "/customers/Words/orders",
+		"/admin":                  "/admin",
+		"/unseen/path":            "/Words/Words",
+	}
+	for path, want := range paths {
+		if got := g.SimplifyPath(&url.URL{Path: path}); got != want {
+			t.Errorf("%s: got %s, want %s", path, got, want)
+		}
+	}
+
+	if reason := g.Explain(&url.URL{Path: "/customers/acme/orders"}).Segments[1].Reason; reason != "token was seen fewer than 50 times" {
+		t.Errorf("got reason %q for a token below k", reason)
+	}
+
+	s := g.Snapshot()
+	if s.PrivacyK != 50 {
+		t.Errorf("got snapshot PrivacyK %d, want 50", s.PrivacyK)
+	}
+	for _, grp := range s.Groups {
+		if len(grp.Samples) > 0 {
+			t.Errorf("%s has samples in privacy mode: %v", grp.Pattern, grp.Samples)
+		}
+	}
+
+	var md strings.Builder
+	if err := g.ExportMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md.String(), "privacy mode") || strings.Contains(md.String(), "acme") {
+		t.Errorf("unexpected markdown in privacy mode:\n%s", md.String())
+	}
+
+	table, err := g.DecisionTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewDecisionEvaluator(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range paths {
+		if got := e.SimplifyPath(path); got != want {
+			t.Errorf("%s: decision table gave %s, want %s", path, got, want)
+		}
+	}
+
+	if _, err := New(WithPrivacyMode(0, nil)); err == nil {
+		t.Error("expected an error for k of 0")
+	}
+}
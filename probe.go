@@ -0,0 +1,49 @@
+package groupurl
+
+import (
+	"regexp"
+	"strings"
+)
+
+// regexProbe matches a segment commonly requested by vulnerability scanners, or a path traversal, along with the
+// rest of the path.
+var regexProbe = regexp.MustCompile(`(?i)^(?:\.env|\.git|\.svn|\.hg|\.ds_store|\.htaccess|\.htpasswd|\.aws|\.ssh|` +
+	`wp-admin|wp-login\.php|wp-content|wp-includes|xmlrpc\.php|phpmyadmin|pma|myadmin|cgi-bin|server-status|` +
+	`\.\.|%2e%2e|\.%2e|%2e\.)(?:[/.;\\].*)?$`)
+
+// ProbeClassifier returns a classifier that labels scanner probes such as `/wp-admin`, `/.env`, `/.git/config`,
+// `/phpmyadmin` and path traversals as Probe. A probe consumes the rest of the path, so every probe below a prefix
+// falls into a single group however deep it goes. It should come before other classifiers, for example:
+//
+//	WithClassifiers(append([]PathTokenClassifier{ProbeClassifier()}, DefaultClassifiers()...))
+func ProbeClassifier() RegexPathTokenClassifier {
+	return RegexPathTokenClassifier{
+		Regex: regexProbe,
+		Label: Label{
+			LabelFields: LabelFields{
+				Important: false,
+				Value:     "Probe",
+			},
+		},
+		Info: builtinInfo("probe", "paths requested by vulnerability scanners"),
+	}
+}
+
+// IsProbe reports whether any segment of a path is matched by ProbeClassifier, so that scanner noise can be
+// excluded before it is added to a Grouper.
+func IsProbe(path string) bool {
+	for {
+		path = strings.TrimLeft(path, "/")
+		if path == "" {
+			return false
+		}
+		if regexProbe.MatchString(path) {
+			return true
+		}
+		i := strings.IndexByte(path, '/')
+		if i < 0 {
+			return false
+		}
+		path = path[i:]
+	}
+}
@@ -0,0 +1,65 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestProbeClassifier(t *testing.T) {
+	g, err := newFromEnv(func(key string) (string, bool) {
+		if key == EnvPreset {
+			return "probes", true
+		}
+		return "", false
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		u, err := url.Parse(fmt.Sprintf("/users/%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+	for _, p := range []string{"/wp-admin/setup-config.php", "/.env", "/.git/config", "/phpMyAdmin/index.php", "/../../etc/passwd"} {
+		u, err := url.Parse(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+
+	for p, expected := range map[string]string{
+		"/.env.local":           "/Probe",
+		"/WP-ADMIN/install.php": "/Probe",
+		"/.git/HEAD":            "/Probe",
+		"/..%2f..%2fetc/shadow": "/Probe",
+		"/users/42":             "/users/Number",
+		"/environment":          "/environment",
+	} {
+		u, err := url.Parse(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := g.SimplifyPath(u); got != expected {
+			t.Fatalf("expected %s for %s, got %s", expected, p, got)
+		}
+	}
+}
+
+func TestIsProbe(t *testing.T) {
+	for p, expected := range map[string]bool{
+		"/.git/config":            true,
+		"/blog/wp-login.php":      true,
+		"/static/../../etc/hosts": true,
+		"/users/42":               false,
+		"/gitlab/settings":        false,
+		"/":                       false,
+	} {
+		if got := IsProbe(p); got != expected {
+			t.Fatalf("expected %t for %s, got %t", expected, p, got)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package proxy records the upstream requests of an httputil.ReverseProxy into a Grouper
+// and tags the proxied responses with the group each request was assigned to.
+package proxy
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+
+	"github.com/trustleast/groupurl"
+)
+
+// DefaultHeader is the response header the group is written to if no other header is configured.
+const DefaultHeader = "X-Url-Group"
+
+// Recorder feeds the URLs of proxied requests into a Grouper.
+// Groupers are not safe for concurrent use so the Recorder serializes access to it.
+type Recorder struct {
+	// Header is the response header the simplified path is written to.
+	// Responses are not tagged if it is empty.
+	Header string
+
+	mu sync.Mutex
+	g  groupurl.Grouper
+}
+
+// NewRecorder creates a Recorder that records into the provided Grouper.
+// The Grouper should not be used directly afterwards, use the methods on the Recorder instead.
+func NewRecorder(g groupurl.Grouper) *Recorder {
+	return &Recorder{
+		Header: DefaultHeader,
+		g:      g,
+	}
+}
+
+// Wrap installs the Recorder on a ReverseProxy.
+// Existing Director or Rewrite hooks are preserved and run before the upstream URL is recorded,
+// and an existing ModifyResponse hook runs after the response is tagged.
+// A ReverseProxy with neither hook gets a Rewrite hook that only records the URL, so requests go to the URL they
+// were sent for, as with a forward proxy.
+func (r *Recorder) Wrap(p *httputil.ReverseProxy) {
+	if p.Rewrite != nil {
+		rewrite := p.Rewrite
+		p.Rewrite = func(pr *httputil.ProxyRequest) {
+			rewrite(pr)
+			r.AddRequest(pr.Out.Method, pr.Out.URL)
+		}
+	} else if p.Director != nil {
+		director := p.Director
+		p.Director = func(req *http.Request) {
+			director(req)
+			r.AddRequest(req.Method, req.URL)
+		}
+	} else {
+		p.Rewrite = func(pr *httputil.ProxyRequest) {
+			r.AddRequest(pr.Out.Method, pr.Out.URL)
+		}
+	}
+
+	modifyResponse := p.ModifyResponse
+	p.ModifyResponse = func(resp *http.Response) error {
+		if r.Header != "" && resp.Request != nil {
+			resp.Header.Set(r.Header, r.SimplifyPath(resp.Request.URL))
+		}
+		if modifyResponse != nil {
+			return modifyResponse(resp)
+		}
+		return nil
+	}
+}
+
+// Add records a URL in the underlying Grouper.
+func (r *Recorder) Add(u *url.URL) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.g.Add(u)
+}
+
+// AddRequest records a URL in the underlying Grouper along with its HTTP method. See Grouper.AddRequest.
+func (r *Recorder) AddRequest(method string, u *url.URL) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.g.AddRequest(method, u)
+}
+
+// SimplifyPath simplifies a URL using the underlying Grouper.
+func (r *Recorder) SimplifyPath(u *url.URL) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.g.SimplifyPath(u)
+}
+
+// String pretty prints the underlying Grouper.
+func (r *Recorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.g.String()
+}
@@ -0,0 +1,99 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"testing"
+
+	"github.com/trustleast/groupurl"
+)
+
+func TestRecorder(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	target, err := url.Parse(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRecorder(g)
+	p := httputil.NewSingleHostReverseProxy(target)
+	r.Wrap(p)
+
+	front := httptest.NewServer(p)
+	defer front.Close()
+
+	var last *http.Response
+	for i := 0; i < 200; i++ {
+		resp, err := http.Get(fmt.Sprintf("%s/users/%d", front.URL, i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		last = resp
+	}
+
+	if got := last.Header.Get(DefaultHeader); got != "/users/Number" {
+		t.Fatalf("expected /users/Number, got %s", got)
+	}
+	if got := r.g.Methods("/Words/Number"); got["GET"] != 200 {
+		t.Fatalf("expected requests to be counted by method, got %v", got)
+	}
+}
+
+func TestRecorderBareProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRecorder(g)
+	p := &httputil.ReverseProxy{}
+	r.Wrap(p)
+	if p.Rewrite == nil {
+		t.Fatal("expected a Rewrite hook on a bare proxy")
+	}
+
+	front := httptest.NewServer(p)
+	defer front.Close()
+	frontURL, err := url.Parse(front.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without a Director or Rewrite, requests go to the URL they were sent for, so the proxy is used as a forward proxy.
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(frontURL)}}
+
+	var last *http.Response
+	for i := 0; i < 200; i++ {
+		resp, err := client.Get(fmt.Sprintf("%s/users/%d", upstream.URL, i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected the request to be proxied, got %s", resp.Status)
+		}
+		last = resp
+	}
+
+	if got := last.Header.Get(DefaultHeader); got != "/users/Number" {
+		t.Fatalf("expected /users/Number, got %s", got)
+	}
+	if got := r.SimplifyPath(&url.URL{Path: "/users/7"}); got != "/users/Number" {
+		t.Fatalf("expected the requests to be recorded, got %s", got)
+	}
+}
@@ -0,0 +1,168 @@
+// Package ratelimit applies token bucket rate limits per URL group rather than per raw path, so that abuse of a route
+// such as /api/export/{id} is limited collectively regardless of which ids are hit.
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const _defaultMaxGroups = 10000
+
+type (
+	// Limit is the rate of a token bucket in requests per second, and the number of requests it allows in a burst.
+	Limit struct {
+		Rate  float64
+		Burst int
+	}
+
+	// GroupLimiter limits requests per group. It is safe for concurrent use, as long as the group function it was
+	// created with is, such as the SimplifyPath method of a FrozenGrouper or of a watch.Watcher.
+	GroupLimiter struct {
+		group     func(*url.URL) string
+		limit     Limit
+		limits    map[string]Limit
+		maxGroups int
+		now       func() time.Time
+
+		mu      sync.Mutex
+		buckets map[string]*bucket
+	}
+
+	bucket struct {
+		limit  Limit
+		tokens float64
+		last   time.Time
+	}
+
+	Option func(*GroupLimiter) error
+)
+
+// WithGroupLimit sets the limit of the group with the given key, as returned by the group function, instead of the
+// default limit.
+func WithGroupLimit(group string, limit Limit) Option {
+	return func(l *GroupLimiter) error {
+		if err := limit.check(); err != nil {
+			return fmt.Errorf("%s: %w", group, err)
+		}
+		l.limits[group] = limit
+		return nil
+	}
+}
+
+// WithMaxGroups bounds the number of groups whose buckets are tracked, 10000 by default. Once reached, buckets that
+// have refilled are forgotten, and requests of new groups are allowed without being tracked until there is room.
+func WithMaxGroups(n int) Option {
+	return func(l *GroupLimiter) error {
+		if n <= 0 {
+			return fmt.Errorf("max groups must be positive, got %d", n)
+		}
+		l.maxGroups = n
+		return nil
+	}
+}
+
+// New creates a GroupLimiter that applies limit to each group returned by group.
+func New(group func(*url.URL) string, limit Limit, options ...Option) (*GroupLimiter, error) {
+	if err := limit.check(); err != nil {
+		return nil, err
+	}
+	l := &GroupLimiter{
+		group:     group,
+		limit:     limit,
+		limits:    make(map[string]Limit),
+		maxGroups: _defaultMaxGroups,
+		now:       time.Now,
+		buckets:   make(map[string]*bucket),
+	}
+	for _, option := range options {
+		if err := option(l); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Allow reports whether a request for a URL is allowed, taking a token from the bucket of its group if so.
+func (l *GroupLimiter) Allow(u *url.URL) bool {
+	ok, _ := l.reserve(l.group(u))
+	return ok
+}
+
+// Handler wraps a http.Handler, responding 429 Too Many Requests with a Retry-After header to requests over the limit
+// of their group.
+func (l *GroupLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ok, wait := l.reserve(l.group(r.URL)); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// reserve takes a token from the bucket of a group, or returns how long until one is available.
+func (l *GroupLimiter) reserve(group string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[group]
+	if !ok {
+		if len(l.buckets) >= l.maxGroups && !l.evict(now) {
+			return true, 0
+		}
+		limit, ok := l.limits[group]
+		if !ok {
+			limit = l.limit
+		}
+		b = &bucket{limit: limit, tokens: float64(limit.Burst), last: now}
+		l.buckets[group] = b
+	}
+
+	b.refill(now)
+	if b.tokens < 1 {
+		if b.limit.Rate == 0 {
+			return false, time.Duration(math.MaxInt64)
+		}
+		return false, time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// evict forgets the buckets that have refilled, and reports whether any were.
+func (l *GroupLimiter) evict(now time.Time) bool {
+	evicted := false
+	for group, b := range l.buckets {
+		if b.refill(now); b.tokens >= float64(b.limit.Burst) {
+			delete(l.buckets, group)
+			evicted = true
+		}
+	}
+	return evicted
+}
+
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed.Seconds()*b.limit.Rate)
+		b.last = now
+	}
+}
+
+func (l Limit) check() error {
+	if l.Rate < 0 || math.IsNaN(l.Rate) || math.IsInf(l.Rate, 0) {
+		return fmt.Errorf("invalid rate %v", l.Rate)
+	}
+	if l.Burst < 1 {
+		return errors.New("burst must be at least 1")
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// exportGroup groups every export together, as a Grouper would once it has learned the route.
+func exportGroup(u *url.URL) string {
+	if strings.HasPrefix(u.Path, "/api/export/") {
+		return "/api/export/Number"
+	}
+	return u.Path
+}
+
+func TestAllow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l, err := New(exportGroup, Limit{Rate: 1, Burst: 3}, WithGroupLimit("/health", Limit{Rate: 100, Burst: 100}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.now = func() time.Time { return now }
+
+	var allowed int
+	for i := 0; i < 10; i++ {
+		if l.Allow(&url.URL{Path: fmt.Sprintf("/api/export/%d", i)}) {
+			allowed++
+		}
+	}
+	if allowed != 3 {
+		t.Errorf("allowed %d exports of distinct ids, want the burst of 3", allowed)
+	}
+	for i := 0; i < 10; i++ {
+		if !l.Allow(&url.URL{Path: "/health"}) {
+			t.Fatal("expected the health group to have its own limit")
+		}
+	}
+
+	now = now.Add(2 * time.Second)
+	if !l.Allow(&url.URL{Path: "/api/export/99"}) || !l.Allow(&url.URL{Path: "/api/export/100"}) {
+		t.Error("expected two tokens after two seconds")
+	}
+	if l.Allow(&url.URL{Path: "/api/export/101"}) {
+		t.Error("expected no third token after two seconds")
+	}
+}
+
+func TestMaxGroups(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l, err := New(exportGroup, Limit{Rate: 1, Burst: 1}, WithMaxGroups(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.now = func() time.Time { return now }
+
+	l.Allow(&url.URL{Path: "/a"})
+	if !l.Allow(&url.URL{Path: "/b"}) || !l.Allow(&url.URL{Path: "/b"}) {
+		t.Error("expected untracked groups to be allowed while the limiter is full")
+	}
+	now = now.Add(time.Second)
+	l.Allow(&url.URL{Path: "/b"})
+	if l.Allow(&url.URL{Path: "/b"}) {
+		t.Error("expected /b to be tracked once /a refilled")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	l, err := New(exportGroup, Limit{Rate: 0.5, Burst: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := l.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	codes := make([]int, 2)
+	var retryAfter string
+	for i := range codes {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/export/%d", i), nil))
+		codes[i] = rec.Code
+		retryAfter = rec.Header().Get("Retry-After")
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests || retryAfter != "2" {
+		t.Errorf("got codes %v and Retry-After %q", codes, retryAfter)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	for _, limit := range []Limit{{Rate: -1, Burst: 1}, {Rate: 1}} {
+		if _, err := New(exportGroup, limit); err == nil {
+			t.Errorf("expected an error for %+v", limit)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package groupurl
+
+import (
+	"fmt"
+	"sort"
+)
+
+type (
+	// Promotion describes a node whose label changed while adding a URL, such as Number promoted to AlphaNumeric
+	// once Words show up at the same position. Recounted is the number of URLs recounted from the overflow log of
+	// the node with WithPromotionRecount.
+	Promotion struct {
+		Tree      int
+		Lineage   Lineage
+		Recounted int
+	}
+
+	// PromotionHook is called synchronously from Add for every promotion.
+	PromotionHook func(Promotion)
+)
+
+// WithPromotionHook registers a hook called whenever the label of a node changes while adding a URL.
+func WithPromotionHook(hook PromotionHook) Option {
+	return func(g *Grouper) error {
+		g.promotionHooks = append(g.promotionHooks, hook)
+		return nil
+	}
+}
+
+// WithPromotionRecount keeps, at every node, a log of the last size tokens its label could not count, such as the
+// tokens of labels that are not Important. Without it, a node promoted to a label that counts tokens only knows the
+// tokens added since, and the URLs added before are lumped together, which skews the significance of its tokens.
+// With it, those URLs are spread over the tokens of the log in proportion when the node is promoted, so that its
+// statistics are consistent with its new label. The log costs up to size tokens of memory per node.
+func WithPromotionRecount(size int) Option {
+	return func(g *Grouper) error {
+		if size <= 0 {
+			return fmt.Errorf("recount log size must be positive, got %d", size)
+		}
+		g.tree.recountLog = size
+		return nil
+	}
+}
+
+// Recount spreads the URLs that nodes could not count under the tokens of their overflow log, for nodes that can
+// count them now, such as after Relabel, and returns the number of URLs recounted. It is done on promotion already
+// with WithPromotionRecount, which the logs require.
+func (g Grouper) Recount() int {
+	var recounted int
+	for _, t := range g.trees {
+		walkBelow(t.Root, func(n *urlNode) {
+			recounted += n.recount()
+		})
+	}
+	if recounted > 0 && g.simplifyCache != nil {
+		for key := range g.simplifyCache.versions {
+			g.simplifyCache.versions[key]++
+		}
+	}
+	return recounted
+}
+
+// logOverflow records a token the node could not count, overwriting the oldest one once the log holds size tokens.
+func (n *urlNode) logOverflow(token string, size int) {
+	if len(n.overflowLog) < size {
+		n.overflowLog = append(n.overflowLog, token)
+		return
+	}
+	n.overflowLog[n.overflowNext] = token
+	n.overflowNext = (n.overflowNext + 1) % size
+}
+
+// recount moves the URLs of the overflow to the logged tokens the node can now count, in proportion to how often
+// each of them was logged, and returns the number of URLs moved. The total of the node is unchanged.
+func (n *urlNode) recount() int {
+	overflow := n.tokenCounts.overflow
+	if overflow == 0 || len(n.overflowLog) == 0 {
+		return 0
+	}
+	logged := make(map[string]int, len(n.overflowLog))
+	for _, token := range n.overflowLog {
+		logged[token]++
+	}
+	tokens := make([]string, 0, len(logged))
+	for token := range logged {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if logged[tokens[i]] != logged[tokens[j]] {
+			return logged[tokens[i]] > logged[tokens[j]]
+		}
+		return tokens[i] < tokens[j]
+	})
+
+	var moved int
+	for _, token := range tokens {
+		if moved == overflow || !n.tokenCounts.fits(token) {
+			continue
+		}
+		share := overflow * logged[token] / len(n.overflowLog)
+		if share == 0 {
+			share = 1
+		}
+		if share > overflow-moved {
+			share = overflow - moved
+		}
+		n.tokenCounts.counts.Add(token, share)
+		moved += share
+	}
+	if moved == 0 {
+		return 0
+	}
+	n.tokenCounts.overflow -= moved
+	n.overflowLog, n.overflowNext = nil, 0
+	return moved
+}
@@ -0,0 +1,53 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestPromotionRecount(t *testing.T) {
+	var promotions []Promotion
+	g, err := New(WithPromotionRecount(100), WithPromotionHook(func(p Promotion) {
+		promotions = append(promotions, p)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 200; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/items/%d", i%10)})
+	}
+	g.Add(&url.URL{Path: "/items/featured"})
+
+	if len(promotions) != 1 {
+		t.Fatalf("expected a single promotion, got %+v", promotions)
+	}
+	p := promotions[0]
+	if p.Lineage.From != "/Words/Number" || p.Lineage.To != "/Words/AlphaNumeric" || p.Recounted != 200 {
+		t.Fatalf("expected Number to be promoted and its URLs recounted, got %+v", p)
+	}
+	segment := g.Explain(&url.URL{Path: "/items/7"}).Segments[1]
+	if segment.Count != 20 || segment.Total != 201 {
+		t.Fatalf("expected the token to be recounted from the log, got %+v", segment)
+	}
+
+	if g.Recount() != 0 {
+		t.Fatal("expected nothing left to recount")
+	}
+
+	without, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 200; i++ {
+		without.Add(&url.URL{Path: fmt.Sprintf("/items/%d", i%10)})
+	}
+	without.Add(&url.URL{Path: "/items/featured"})
+	if segment := without.Explain(&url.URL{Path: "/items/7"}).Segments[1]; segment.Count != 0 {
+		t.Fatalf("expected earlier URLs to stay uncounted without the log, got %+v", segment)
+	}
+
+	if _, err := New(WithPromotionRecount(0)); err == nil {
+		t.Fatal("expected error for a non-positive log size")
+	}
+}
@@ -0,0 +1,237 @@
+package groupurl
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// ClassifierFactory builds a classifier from its configuration, typically decoded from JSON.
+type ClassifierFactory func(config map[string]any) (PathTokenClassifier, error)
+
+// ClassifierConfig references a registered classifier by name along with its configuration.
+type ClassifierConfig struct {
+	Name   string         `json:"name"`
+	Config map[string]any `json:"config,omitempty"`
+}
+
+var (
+	_registryMu sync.RWMutex
+	_registry   = make(map[string]ClassifierFactory)
+)
+
+// The built-in classifiers are registered in init since the nested factory refers back to the registry.
+func init() {
+	_registry["yyyymmdd"] = staticFactory(YYYYMMDDClassifier())
+	_registry["alphanumeric"] = staticFactory(AlphaNumericClassifier())
+	_registry["number"] = staticFactory(NumberClassifier())
+	_registry["words"] = staticFactory(WordsClassifier())
+	_registry["letters"] = staticFactory(LettersClassifier())
+	_registry["image"] = staticFactory(ImageClassifier())
+	_registry["script"] = staticFactory(ScriptClassifier())
+	_registry["style"] = staticFactory(StyleClassifier())
+	_registry["font"] = staticFactory(FontClassifier())
+	_registry["probe"] = staticFactory(ProbeClassifier())
+	_registry["uuid"] = staticFactory(UUIDClassifier())
+	_registry["objectid"] = staticFactory(ObjectIDClassifier())
+	_registry["version"] = staticFactory(VersionClassifier())
+	_registry["pagination"] = staticFactory(PaginationClassifier())
+	_registry["price"] = staticFactory(PriceClassifier())
+	_registry["sku"] = staticFactory(SKUClassifier())
+	_registry["yyyymm"] = staticFactory(YYYYMMClassifier())
+	_registry["slug"] = staticFactory(SlugClassifier())
+	_registry["random"] = staticFactory(RandomTokenClassifier{})
+	_registry["year"] = yearFactory
+	_registry["numberbucket"] = numberBucketFactory
+	_registry["regex"] = regexFactory
+	_registry["nested"] = nestedFactory
+}
+
+// RegisterClassifier makes a classifier available by name to NewClassifier and ClassifiersFromConfig.
+// Third party packages typically call it from an init function. Registering a name twice is an error.
+func RegisterClassifier(name string, factory ClassifierFactory) error {
+	_registryMu.Lock()
+	defer _registryMu.Unlock()
+	if _, ok := _registry[name]; ok {
+		return fmt.Errorf("classifier %q is already registered", name)
+	}
+	_registry[name] = factory
+	return nil
+}
+
+// RegisteredClassifiers returns the sorted names of all registered classifiers.
+func RegisteredClassifiers() []string {
+	_registryMu.RLock()
+	defer _registryMu.RUnlock()
+	names := make([]string, 0, len(_registry))
+	for name := range _registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewClassifier builds a registered classifier by name.
+func NewClassifier(name string, config map[string]any) (PathTokenClassifier, error) {
+	_registryMu.RLock()
+	factory, ok := _registry[name]
+	_registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown classifier %q", name)
+	}
+
+	c, err := factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build classifier %q: %w", name, err)
+	}
+	return c, nil
+}
+
+// ClassifiersFromConfig builds the classifiers referenced by configs, in order, for use with WithClassifiers.
+func ClassifiersFromConfig(configs []ClassifierConfig) ([]PathTokenClassifier, error) {
+	classifiers := make([]PathTokenClassifier, 0, len(configs))
+	for _, c := range configs {
+		classifier, err := NewClassifier(c.Name, c.Config)
+		if err != nil {
+			return nil, err
+		}
+		classifiers = append(classifiers, classifier)
+	}
+	return classifiers, nil
+}
+
+func staticFactory(c PathTokenClassifier) ClassifierFactory {
+	return func(map[string]any) (PathTokenClassifier, error) {
+		return c, nil
+	}
+}
+
+// yearFactory accepts optional "start" and "end" years, defaulting to 1900 and the current year.
+func yearFactory(config map[string]any) (PathTokenClassifier, error) {
+	start, err := configInt(config, "start", _yyyyStart)
+	if err != nil {
+		return nil, err
+	}
+	end, err := configInt(config, "end", int(_yyyyEnd))
+	if err != nil {
+		return nil, err
+	}
+	return YearPathTokenClassifier{Start: int64(start), End: int64(end)}, nil
+}
+
+// numberBucketFactory accepts optional "buckets", each of which is an object with a "max" and a "label",
+// defaulting to SmallNumber and BigNumber.
+func numberBucketFactory(config map[string]any) (PathTokenClassifier, error) {
+	buckets, _ := config["buckets"].([]any)
+	var c NumberBucketClassifier
+	for _, b := range buckets {
+		bucketConfig, ok := b.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid bucket %v", b)
+		}
+		limit, err := configInt(bucketConfig, "max", math.MaxInt)
+		if err != nil {
+			return nil, err
+		}
+		label, err := configString(bucketConfig, "label")
+		if err != nil {
+			return nil, err
+		}
+		c.Buckets = append(c.Buckets, NumberBucket{Max: int64(limit), Label: label})
+	}
+	return c, nil
+}
+
+// regexFactory requires a "pattern" and a "label", and accepts "important", "cardinality_limit", and "namespace".
+// Patterns should be anchored at the start and consume up to the next slash, as described on PathTokenClassifier.
+func regexFactory(config map[string]any) (PathTokenClassifier, error) {
+	pattern, err := configString(config, "pattern")
+	if err != nil {
+		return nil, err
+	}
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	value, err := configString(config, "label")
+	if err != nil {
+		return nil, err
+	}
+	important, _ := config["important"].(bool)
+	namespace, _ := config["namespace"].(string)
+	limit, err := configInt(config, "cardinality_limit", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return RegexPathTokenClassifier{
+		Regex: regex,
+		Label: Label{
+			LabelFields: LabelFields{
+				Important:        important,
+				CardinalityLimit: limit,
+				Value:            value,
+				Namespace:        namespace,
+			},
+		},
+	}, nil
+}
+
+// nestedFactory requires a "parent" and accepts "children", each of which is an object with a "name" and optional "config".
+func nestedFactory(config map[string]any) (PathTokenClassifier, error) {
+	parentConfig, ok := config["parent"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("missing parent")
+	}
+	parent, err := classifierFromAny(parentConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	children, _ := config["children"].([]any)
+	nested := NestedPathTokenClassifier{Parent: parent}
+	for _, c := range children {
+		childConfig, ok := c.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid child %v", c)
+		}
+		child, err := classifierFromAny(childConfig)
+		if err != nil {
+			return nil, err
+		}
+		nested.Children = append(nested.Children, child)
+	}
+	return nested, nil
+}
+
+func classifierFromAny(config map[string]any) (PathTokenClassifier, error) {
+	name, err := configString(config, "name")
+	if err != nil {
+		return nil, err
+	}
+	inner, _ := config["config"].(map[string]any)
+	return NewClassifier(name, inner)
+}
+
+func configString(config map[string]any, key string) (string, error) {
+	s, ok := config[key].(string)
+	if !ok || s == "" {
+		return "", fmt.Errorf("missing %s", key)
+	}
+	return s, nil
+}
+
+func configInt(config map[string]any, key string, fallback int) (int, error) {
+	switch v := config[key].(type) {
+	case nil:
+		return fallback, nil
+	case int:
+		return v, nil
+	case float64:
+		return int(v), nil
+	default:
+		return 0, fmt.Errorf("invalid %s %v", key, v)
+	}
+}
@@ -0,0 +1,47 @@
+package groupurl
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+)
+
+func TestClassifiersFromConfig(t *testing.T) {
+	if err := RegisterClassifier("number", nil); err == nil {
+		t.Fatal("expected error registering a taken name")
+	}
+
+	var configs []ClassifierConfig
+	err := json.Unmarshal([]byte(`[
+		{"name": "regex", "config": {"pattern": "^v[0-9]+(/|$)", "label": "Version", "important": true}},
+		{"name": "nested", "config": {
+			"parent": {"name": "alphanumeric"},
+			"children": [{"name": "number"}, {"name": "words"}]
+		}}
+	]`), &configs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	classifiers, err := ClassifiersFromConfig(configs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := New(WithClassifiers(classifiers))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse("/v2/items/42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels := g.Labels(u)
+	if len(labels) != 3 || labels[0] != "Version" || labels[2] != "Number" {
+		t.Fatalf("unexpected labels %v", labels)
+	}
+
+	if _, err := ClassifiersFromConfig([]ClassifierConfig{{Name: "missing"}}); err == nil {
+		t.Fatal("expected error for unknown classifier")
+	}
+}
@@ -0,0 +1,152 @@
+package groupurl
+
+import "sort"
+
+// Relabel switches the Grouper to a new set of classifiers while keeping the counters it has learned.
+//
+// The Grouper only keeps token counts per node rather than the URLs it has seen, so relabeling is approximate:
+// each node is given the label most of its counted tokens receive from the new classifiers, and sibling nodes
+// that end up with the same label are merged. Tokens folded into the cardinality overflow, and tokens the new
+// classifiers would split into several segments, cannot be reclassified and follow the rest of their node.
+// The labels of the new classifiers are renamed by the vocabulary of WithLabelVocabulary.
+// Caches configured with WithAddCache and WithSimplifyCache are cleared. Unlike New, Relabel does not reject
+// classifiers that emit colliding labels; the first classifier emitting a label is the one described for it.
+func (g *Grouper) Relabel(classifiers []PathTokenClassifier) {
+	classifiers = g.vocabulary.apply(classifiers)
+	g.classifiers = classifiers
+	g.labelInfo, _ = describeLabels(classifiers)
+	for _, t := range g.trees {
+		t.relabel(classifiers)
+	}
+
+	if g.budget != nil {
+		g.budget.recount(g.trees)
+	}
+	if g.addCache != nil {
+		g.addCache.reset()
+	}
+	if g.simplifyCache != nil {
+		g.simplifyCache.entries.reset()
+		for key := range g.simplifyCache.versions {
+			g.simplifyCache.versions[key]++
+		}
+	}
+}
+
+// relabel re-keys the tree top down. Written iteratively for the same reason as add.
+func (t urlTree) relabel(classifiers []PathTokenClassifier) {
+	stack := []*urlNode{t.Root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		keys := node.children.keys()
+		sort.Slice(keys, func(i, j int) bool {
+			return lessLabelFields(keys[i], keys[j])
+		})
+
+		// Splits route tokens by the labels of the old classifiers, so they no longer apply.
+		node.splits = nil
+		children := node.children
+		node.children = childNodes{}
+		for _, oldKey := range keys {
+			child, _ := children.get(oldKey)
+			key, label := child.relabel(oldKey, classifiers)
+			child.specificLabel = label
+			effective := t.atPosition(label, child.depth)
+			child.tokenCounts.limit = t.withTreeLimit(effective, effective.cardinalityLimit())
+			child.tuning = nil
+
+			if existing, ok := node.children.get(key); ok {
+				mergeNodes(existing, child)
+			} else {
+				node.children.set(key, child)
+			}
+		}
+
+		node.children.each(func(_ LabelFields, child *urlNode) {
+			stack = append(stack, child)
+		})
+	}
+}
+
+// relabel returns the key and label a node gets under the new classifiers.
+func (n *urlNode) relabel(oldKey LabelFields, classifiers []PathTokenClassifier) (LabelFields, LabelFields) {
+	type tally struct {
+		count  int
+		labels map[LabelFields]struct{}
+	}
+	tallies := make(map[LabelFields]*tally)
+	for token, count := range counterMap(n.tokenCounts.counts) {
+		tokens := labelPathTokens(token, classifiers)
+		if len(tokens) != 1 {
+			continue
+		}
+
+		key := tokens[0].label.parentOrSelf()
+		t, ok := tallies[key]
+		if !ok {
+			t = &tally{labels: make(map[LabelFields]struct{})}
+			tallies[key] = t
+		}
+		t.count += count
+		t.labels[tokens[0].label.LabelFields] = struct{}{}
+	}
+
+	var (
+		best    *tally
+		bestKey LabelFields
+	)
+	for key, t := range tallies {
+		if best == nil || t.count > best.count || (t.count == best.count && lessLabelFields(key, bestKey)) {
+			best, bestKey = t, key
+		}
+	}
+	if best == nil {
+		return oldKey, n.specificLabel
+	}
+	if len(best.labels) == 1 {
+		for label := range best.labels {
+			return bestKey, label
+		}
+	}
+	return bestKey, bestKey
+}
+
+// mergeNodes adds the counts, samples, content types, languages, and children of src into dst. Written iteratively for the same reason as add.
+func mergeNodes(dst, src *urlNode) {
+	type pair struct {
+		dst, src *urlNode
+	}
+	stack := []pair{{dst, src}}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		p.src.tokenCounts.counts.Range(func(token string, count int) bool {
+			p.dst.tokenCounts.counts.Add(token, count)
+			return true
+		})
+		p.dst.tokenCounts.total += p.src.tokenCounts.total
+		p.dst.tokenCounts.overflow += p.src.tokenCounts.overflow
+		p.dst.merged = p.dst.merged || p.src.merged
+		p.dst.overflowLog = append(p.dst.overflowLog, p.src.overflowLog...)
+		p.dst.overflowSketch = p.dst.overflowSketch.merge(p.src.overflowSketch)
+		for _, sample := range p.src.samples {
+			p.dst.addSample(sample)
+		}
+		p.dst.contentTypes = mergeCounts(p.dst.contentTypes, p.src.contentTypes)
+		p.dst.languages = mergeCounts(p.dst.languages, p.src.languages)
+		p.dst.methods = mergeCounts(p.dst.methods, p.src.methods)
+		p.dst.stats = p.dst.stats.merge(p.src.stats)
+		p.dst.params = mergeParams(p.dst.params, p.src.params)
+
+		p.src.children.each(func(key LabelFields, child *urlNode) {
+			if existing, ok := p.dst.children.get(key); ok {
+				stack = append(stack, pair{existing, child})
+			} else {
+				p.dst.children.set(key, child)
+			}
+		})
+	}
+}
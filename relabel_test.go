@@ -0,0 +1,44 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"testing"
+)
+
+func TestRelabel(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		u, err := url.Parse(fmt.Sprintf("/releases/v%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+
+	u, err := url.Parse("/releases/v7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path := g.SimplifyPath(u); path != "/releases/Words" {
+		t.Fatalf("expected /releases/Words, got %s", path)
+	}
+
+	version := RegexPathTokenClassifier{
+		Regex: regexp.MustCompile(`^v\d+(/|$)`),
+		Label: Label{LabelFields: LabelFields{Value: "Version"}},
+	}
+	g.Relabel(append([]PathTokenClassifier{version}, DefaultClassifiers()...))
+
+	if path := g.SimplifyPath(u); path != "/releases/Version" {
+		t.Fatalf("expected /releases/Version, got %s", path)
+	}
+	groups := g.Snapshot().Groups
+	if len(groups) != 1 || groups[0].Pattern != "/Words/Version" || groups[0].Count != 100 {
+		t.Fatalf("expected counts to be kept, got %+v", groups)
+	}
+}
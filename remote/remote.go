@@ -0,0 +1,310 @@
+// Package remote delegates grouping to a shared instance exposed by the serve package, so that small services can
+// use a central Grouper instead of learning on their own.
+//
+// A Client has the Add and SimplifyPath methods of a Grouper. Added URLs are sent in batches, and simplified paths
+// can be cached locally for a while, so that hot paths do not cost a request each.
+//
+// A Router spreads hosts over several servers by consistent hashing, with a Client per server.
+package remote
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	_defaultBatchSize     = 100
+	_defaultFlushInterval = time.Second
+)
+
+type (
+	// Client records and simplifies URLs through a remote serve.Server. It is safe for concurrent use.
+	Client struct {
+		base          string
+		client        *http.Client
+		batchSize     int
+		flushInterval time.Duration
+		onError       func(error)
+		now           func() time.Time
+
+		mu      sync.Mutex
+		pending []string
+
+		cacheMu sync.Mutex
+		cache   *cache
+	}
+
+	// cache is a bounded map of simplified paths evicting the least recently used entry once full.
+	cache struct {
+		size    int
+		ttl     time.Duration
+		order   *list.List
+		entries map[string]*list.Element
+	}
+
+	cacheEntry struct {
+		key     string
+		group   string
+		expires time.Time
+	}
+
+	Option func(*Client) error
+)
+
+// WithHTTPClient sets the client used to reach the server.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) error {
+		c.client = client
+		return nil
+	}
+}
+
+// WithBatching sets how many added URLs are sent per request and how often Run sends the URLs queued so far.
+func WithBatching(size int, interval time.Duration) Option {
+	return func(c *Client) error {
+		if size <= 0 || interval <= 0 {
+			return fmt.Errorf("invalid batching of %d URLs every %s", size, interval)
+		}
+		c.batchSize = size
+		c.flushInterval = interval
+		return nil
+	}
+}
+
+// WithCache caches the simplified paths of the given number of most recently simplified URLs for ttl.
+// Groups learned by the server in the meantime are only picked up once entries expire.
+func WithCache(size int, ttl time.Duration) Option {
+	return func(c *Client) error {
+		if size <= 0 || ttl <= 0 {
+			return fmt.Errorf("invalid cache of %d entries for %s", size, ttl)
+		}
+		c.cache = &cache{
+			size:    size,
+			ttl:     ttl,
+			order:   list.New(),
+			entries: make(map[string]*list.Element, size),
+		}
+		return nil
+	}
+}
+
+// WithErrorHook calls f with the errors of requests made by Add and SimplifyPath, which cannot return them.
+func WithErrorHook(f func(error)) Option {
+	return func(c *Client) error {
+		c.onError = f
+		return nil
+	}
+}
+
+// New creates a Client of the server at base, such as http://groupurl:8080.
+func New(base string, options ...Option) (*Client, error) {
+	if _, err := url.ParseRequestURI(base); err != nil {
+		return nil, fmt.Errorf("invalid server address: %w", err)
+	}
+	c := &Client{
+		base:          strings.TrimSuffix(base, "/"),
+		client:        http.DefaultClient,
+		batchSize:     _defaultBatchSize,
+		flushInterval: _defaultFlushInterval,
+		now:           time.Now,
+	}
+	for _, option := range options {
+		if err := option(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Add queues a URL to be recorded by the server. A full batch is sent right away, otherwise queued URLs are sent
+// by Run or Flush. URLs of batches that fail to send are dropped and the error is passed to the error hook.
+func (c *Client) Add(u *url.URL) {
+	c.mu.Lock()
+	c.pending = append(c.pending, u.String())
+	var batch []string
+	if len(c.pending) >= c.batchSize {
+		batch = c.pending
+		c.pending = nil
+	}
+	c.mu.Unlock()
+
+	if batch != nil {
+		c.report(c.send(context.Background(), batch))
+	}
+}
+
+// Flush sends the URLs queued so far.
+func (c *Client) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	batch := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	return c.send(ctx, batch)
+}
+
+// Run sends queued URLs every flush interval until the context is done, then sends what is left and returns the
+// context's error.
+func (c *Client) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			c.report(c.Flush(context.Background()))
+			return ctx.Err()
+		case <-ticker.C:
+			c.report(c.Flush(ctx))
+		}
+	}
+}
+
+// SimplifyPath returns the group of a URL as simplified by the server. If the server cannot be reached, the error
+// is passed to the error hook and the path of the URL is returned unchanged.
+func (c *Client) SimplifyPath(u *url.URL) string {
+	group, err := c.Simplify(context.Background(), u)
+	if err != nil {
+		c.report(err)
+		return u.Path
+	}
+	return group
+}
+
+// Simplify returns the group of a URL as simplified by the server, or from the cache.
+func (c *Client) Simplify(ctx context.Context, u *url.URL) (string, error) {
+	groups, err := c.SimplifyAll(ctx, []*url.URL{u})
+	if err != nil {
+		return "", err
+	}
+	return groups[0], nil
+}
+
+// SimplifyAll returns the groups of many URLs, asking the server for those that are not cached in a single request.
+func (c *Client) SimplifyAll(ctx context.Context, urls []*url.URL) ([]string, error) {
+	groups := make([]string, len(urls))
+	var missing []int
+	var body strings.Builder
+	for i, u := range urls {
+		key := u.String()
+		if group, ok := c.cached(key); ok {
+			groups[i] = group
+			continue
+		}
+		missing = append(missing, i)
+		body.WriteString(key)
+		body.WriteByte('\n')
+	}
+	if len(missing) == 0 {
+		return groups, nil
+	}
+
+	var resp struct {
+		Groups []string `json:"groups"`
+	}
+	if err := c.post(ctx, "/simplify", body.String(), &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Groups) != len(missing) {
+		return nil, fmt.Errorf("server returned %d groups for %d URLs", len(resp.Groups), len(missing))
+	}
+	for j, i := range missing {
+		groups[i] = resp.Groups[j]
+		c.store(urls[i].String(), resp.Groups[j])
+	}
+	return groups, nil
+}
+
+func (c *Client) send(ctx context.Context, batch []string) error {
+	return c.post(ctx, "/add", strings.Join(batch, "\n")+"\n", nil)
+}
+
+// post sends body to the server and decodes the JSON response into v if set.
+func (c *Client) post(ctx context.Context, path, body string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base+path, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server responded %s: %s", resp.Status, bytes.TrimSpace(b))
+	}
+	if v == nil {
+		return nil
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) report(err error) {
+	if err != nil && c.onError != nil {
+		c.onError(err)
+	}
+}
+
+func (c *Client) cached(key string) (string, bool) {
+	if c.cache == nil {
+		return "", false
+	}
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+
+	e, ok := c.cache.entries[key]
+	if !ok {
+		return "", false
+	}
+	entry := e.Value.(*cacheEntry)
+	if c.now().After(entry.expires) {
+		c.cache.order.Remove(e)
+		delete(c.cache.entries, key)
+		return "", false
+	}
+	c.cache.order.MoveToFront(e)
+	return entry.group, true
+}
+
+func (c *Client) store(key, group string) {
+	if c.cache == nil {
+		return
+	}
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+
+	expires := c.now().Add(c.cache.ttl)
+	if e, ok := c.cache.entries[key]; ok {
+		entry := e.Value.(*cacheEntry)
+		entry.group = group
+		entry.expires = expires
+		c.cache.order.MoveToFront(e)
+		return
+	}
+	c.cache.entries[key] = c.cache.order.PushFront(&cacheEntry{key: key, group: group, expires: expires})
+	if c.cache.order.Len() > c.cache.size {
+		oldest := c.cache.order.Back()
+		c.cache.order.Remove(oldest)
+		delete(c.cache.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,108 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/trustleast/groupurl"
+	"github.com/trustleast/groupurl/serve"
+)
+
+func newServer(t *testing.T) (*httptest.Server, *int64) {
+	t.Helper()
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := serve.New(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		s.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestClient(t *testing.T) {
+	srv, requests := newServer(t)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c, err := New(srv.URL, WithBatching(10, time.Minute), WithCache(10, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.now = func() time.Time { return now }
+
+	for i := 0; i < 105; i++ {
+		c.Add(&url.URL{Path: fmt.Sprintf("/users/%d", i)})
+	}
+	if got := atomic.LoadInt64(requests); got != 10 {
+		t.Errorf("expected 10 batches to be sent, got %d requests", got)
+	}
+	if err := c.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt64(requests); got != 11 {
+		t.Errorf("expected the rest to be flushed, got %d requests", got)
+	}
+
+	u := &url.URL{Path: "/users/7"}
+	for i := 0; i < 3; i++ {
+		if got := c.SimplifyPath(u); got != "/users/Number" {
+			t.Errorf("got %s, want /users/Number", got)
+		}
+	}
+	if got := atomic.LoadInt64(requests); got != 12 {
+		t.Errorf("expected repeated lookups to be cached, got %d requests", got)
+	}
+
+	groups, err := c.SimplifyAll(context.Background(), []*url.URL{u, {Path: "/users/8"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || groups[0] != "/users/Number" || groups[1] != "/users/Number" {
+		t.Errorf("unexpected groups %v", groups)
+	}
+	if got := atomic.LoadInt64(requests); got != 13 {
+		t.Errorf("expected only the uncached URL to be requested, got %d requests", got)
+	}
+
+	now = now.Add(2 * time.Minute)
+	c.SimplifyPath(u)
+	if got := atomic.LoadInt64(requests); got != 14 {
+		t.Errorf("expected expired entries to be requested again, got %d requests", got)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	srv, _ := newServer(t)
+	srv.Close()
+
+	var errs []error
+	c, err := New(srv.URL, WithBatching(1, time.Minute), WithErrorHook(func(err error) {
+		errs = append(errs, err)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &url.URL{Path: "/users/1"}
+	c.Add(u)
+	if got := c.SimplifyPath(u); got != "/users/1" {
+		t.Errorf("expected the path to be returned unchanged, got %s", got)
+	}
+	if len(errs) != 2 {
+		t.Errorf("expected 2 errors to be reported, got %v", errs)
+	}
+	if _, err := New("not a url"); err == nil {
+		t.Error("expected an error for an invalid address")
+	}
+}
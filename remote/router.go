@@ -0,0 +1,263 @@
+package remote
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const _defaultReplicas = 128
+
+type (
+	// Router spreads hosts, or tenants, over several servers by consistent hashing, for deployments where a single
+	// server cannot hold them all. Every key is handled by one server, which learns all of its URLs, and resharding
+	// only moves the keys of a share of the servers. It is safe for concurrent use.
+	//
+	// Servers do not share what they learned, so the keys moved by Reshard are grouped as new until their new server
+	// learns them again.
+	Router struct {
+		options  []Option
+		replicas int
+
+		mu      sync.RWMutex
+		clients map[string]*Client
+		ring    []ringPoint
+	}
+
+	// ringPoint is one of the replicas of a server on the hash ring.
+	ringPoint struct {
+		hash uint64
+		addr string
+	}
+
+	// Move is a key handled by another server after resharding, as returned by Router.Moves.
+	Move struct {
+		Key  string
+		From string
+		To   string
+	}
+
+	RouterOption func(*Router) error
+)
+
+// WithClientOptions sets the options of the Client of every server.
+func WithClientOptions(options ...Option) RouterOption {
+	return func(r *Router) error {
+		r.options = append(r.options, options...)
+		return nil
+	}
+}
+
+// WithReplicas sets the number of points of each server on the hash ring, 128 by default. More points spread keys
+// more evenly at the cost of memory.
+func WithReplicas(n int) RouterOption {
+	return func(r *Router) error {
+		if n <= 0 {
+			return fmt.Errorf("replicas must be positive, got %d", n)
+		}
+		r.replicas = n
+		return nil
+	}
+}
+
+// NewRouter creates a Router over the servers at addrs, such as http://groupurl-0:8080.
+func NewRouter(addrs []string, options ...RouterOption) (*Router, error) {
+	r := &Router{replicas: _defaultReplicas}
+	for _, option := range options {
+		if err := option(r); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := r.Reshard(context.Background(), addrs); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Key returns the key URLs are routed by, their lowercased host.
+func Key(u *url.URL) string {
+	return strings.ToLower(u.Host)
+}
+
+// Client returns the Client of the server handling a key.
+func (r *Router) Client(key string) *Client {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.clients[lookup(r.ring, key)]
+}
+
+// Add queues a URL to be recorded by the server handling its host. See Client.Add.
+func (r *Router) Add(u *url.URL) {
+	r.Client(Key(u)).Add(u)
+}
+
+// SimplifyPath returns the group of a URL as simplified by the server handling its host. See Client.SimplifyPath.
+func (r *Router) SimplifyPath(u *url.URL) string {
+	return r.Client(Key(u)).SimplifyPath(u)
+}
+
+// Flush sends the URLs queued so far to every server.
+func (r *Router) Flush(ctx context.Context) error {
+	var errs []error
+	for _, c := range r.snapshot() {
+		errs = append(errs, c.Flush(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// Run sends queued URLs to every server each flush interval of the clients until the context is done, then sends
+// what is left and returns the context's error.
+func (r *Router) Run(ctx context.Context) error {
+	interval := _defaultFlushInterval
+	if clients := r.snapshot(); len(clients) > 0 {
+		interval = clients[0].flushInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.report(r.Flush(context.Background()))
+			return ctx.Err()
+		case <-ticker.C:
+			r.report(r.Flush(ctx))
+		}
+	}
+}
+
+// report passes errors to the error hook of the clients, which all share the options of the Router.
+func (r *Router) report(err error) {
+	if clients := r.snapshot(); len(clients) > 0 {
+		clients[0].report(err)
+	}
+}
+
+// Servers returns the sorted addresses of the servers.
+func (r *Router) Servers() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	addrs := make([]string, 0, len(r.clients))
+	for addr := range r.clients {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// Moves returns the keys that resharding to the servers at addrs would move to another server, so that their
+// traffic can be anticipated before calling Reshard.
+func (r *Router) Moves(keys []string, addrs []string) ([]Move, error) {
+	ring, err := r.newRing(addrs)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var moves []Move
+	for _, key := range keys {
+		from, to := lookup(r.ring, key), lookup(ring, key)
+		if from != to {
+			moves = append(moves, Move{Key: key, From: from, To: to})
+		}
+	}
+	return moves, nil
+}
+
+// Reshard routes keys over the servers at addrs from now on, keeping the clients of the servers that remain. The
+// URLs queued for the servers that are removed are sent to them before their clients are dropped, and the servers
+// that were removed are returned.
+//
+// What the servers learned about the keys that move is not transferred: a server holds a single Grouper for all of
+// its keys, which cannot be split per key nor merged into another, so moved keys are learned again from scratch.
+func (r *Router) Reshard(ctx context.Context, addrs []string) ([]string, error) {
+	ring, err := r.newRing(addrs)
+	if err != nil {
+		return nil, err
+	}
+	clients := make(map[string]*Client, len(addrs))
+	r.mu.Lock()
+	for _, addr := range addrs {
+		c, ok := r.clients[addr]
+		if !ok {
+			if c, err = New(addr, r.options...); err != nil {
+				r.mu.Unlock()
+				return nil, err
+			}
+		}
+		clients[addr] = c
+	}
+	previous := r.clients
+	r.clients, r.ring = clients, ring
+	r.mu.Unlock()
+
+	var removed []string
+	var errs []error
+	for addr, c := range previous {
+		if _, ok := clients[addr]; !ok {
+			removed = append(removed, addr)
+			errs = append(errs, c.Flush(ctx))
+		}
+	}
+	sort.Strings(removed)
+	return removed, errors.Join(errs...)
+}
+
+// newRing places the replicas of the servers on the hash ring.
+func (r *Router) newRing(addrs []string) ([]ringPoint, error) {
+	if len(addrs) == 0 {
+		return nil, errors.New("router requires at least one server")
+	}
+	seen := make(map[string]bool, len(addrs))
+	ring := make([]ringPoint, 0, len(addrs)*r.replicas)
+	for _, addr := range addrs {
+		if seen[addr] {
+			return nil, fmt.Errorf("duplicate server %s", addr)
+		}
+		seen[addr] = true
+		for i := 0; i < r.replicas; i++ {
+			ring = append(ring, ringPoint{hash: hash(addr + "#" + strconv.Itoa(i)), addr: addr})
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool {
+		if ring[i].hash != ring[j].hash {
+			return ring[i].hash < ring[j].hash
+		}
+		return ring[i].addr < ring[j].addr
+	})
+	return ring, nil
+}
+
+func (r *Router) snapshot() []*Client {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	clients := make([]*Client, 0, len(r.clients))
+	for _, c := range r.clients {
+		clients = append(clients, c)
+	}
+	return clients
+}
+
+// lookup returns the server of the first point of the ring at or after the hash of the key.
+func lookup(ring []ringPoint, key string) string {
+	h := hash(key)
+	i := sort.Search(len(ring), func(i int) bool { return ring[i].hash >= h })
+	if i == len(ring) {
+		i = 0
+	}
+	return ring[i].addr
+}
+
+// hash returns the first 64 bits of the SHA-256 of s. Unlike FNV, it spreads the points of servers whose addresses
+// only differ by a digit evenly over the ring.
+func hash(s string) uint64 {
+	sum := sha256.Sum256([]byte(s))
+	return binary.BigEndian.Uint64(sum[:8])
+}
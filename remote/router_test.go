@@ -0,0 +1,119 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRouter(t *testing.T) {
+	var addrs []string
+	var requests []*int64
+	for i := 0; i < 3; i++ {
+		srv, n := newServer(t)
+		addrs = append(addrs, srv.URL)
+		requests = append(requests, n)
+	}
+	r, err := NewRouter(addrs, WithClientOptions(WithBatching(1000, time.Minute)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hosts []string
+	for i := 0; i < 100; i++ {
+		hosts = append(hosts, fmt.Sprintf("shop-%d.example.com", i))
+	}
+	for _, host := range hosts {
+		for i := 0; i < 10; i++ {
+			r.Add(&url.URL{Host: host, Path: fmt.Sprintf("/orders/%d", i)})
+		}
+	}
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for i, n := range requests {
+		if atomic.LoadInt64(n) != 1 {
+			t.Errorf("expected server %d to receive a single batch, got %d requests", i, atomic.LoadInt64(n))
+		}
+	}
+	if got := r.SimplifyPath(&url.URL{Host: "SHOP-7.example.com", Path: "/orders/3"}); got != "/orders/Number" {
+		t.Fatalf("expected the server of the host to have learned its URLs, got %s", got)
+	}
+
+	srv, _ := newServer(t)
+	grown := append(append([]string(nil), addrs...), srv.URL)
+	moves, err := r.Moves(hosts, grown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range moves {
+		if m.To != srv.URL || m.From != r.Client(m.Key).base {
+			t.Fatalf("expected hosts to move to the new server only, got %+v", m)
+		}
+	}
+
+	removed, err := r.Reshard(context.Background(), grown[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0] != addrs[0] {
+		t.Fatalf("expected the first server to be removed, got %v", removed)
+	}
+	if servers := r.Servers(); len(servers) != 3 {
+		t.Fatalf("expected 3 servers, got %v", servers)
+	}
+	for _, host := range hosts {
+		if r.Client(host).base == addrs[0] {
+			t.Fatalf("expected %s to move off the removed server", host)
+		}
+	}
+
+	if _, err := NewRouter(nil); err == nil {
+		t.Fatal("expected error without servers")
+	}
+	if _, err := NewRouter([]string{addrs[0], addrs[0]}); err == nil {
+		t.Fatal("expected error for duplicate servers")
+	}
+}
+
+func TestRouterBalance(t *testing.T) {
+	var addrs []string
+	for i := 0; i < 4; i++ {
+		addrs = append(addrs, fmt.Sprintf("http://groupurl-%d:8080", i))
+	}
+	r, err := NewRouter(addrs[:3], WithReplicas(512))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hosts []string
+	for i := 0; i < 10000; i++ {
+		hosts = append(hosts, fmt.Sprintf("shop-%d.example.com", i))
+	}
+
+	counts := make(map[string]int)
+	for _, host := range hosts {
+		counts[r.Client(host).base]++
+	}
+	for _, addr := range addrs[:3] {
+		if share := float64(counts[addr]) / float64(len(hosts)); math.Abs(share-1.0/3) > 0.05 {
+			t.Errorf("expected %s to handle about a third of the hosts, got %.3f", addr, share)
+		}
+	}
+
+	moves, err := r.Moves(hosts, addrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if share := float64(len(moves)) / float64(len(hosts)); math.Abs(share-1.0/4) > 0.05 {
+		t.Fatalf("expected about a quarter of the hosts to move, got %.3f", share)
+	}
+	for _, m := range moves {
+		if m.To != addrs[3] {
+			t.Fatalf("expected hosts to move to the new server only, got %+v", m)
+		}
+	}
+}
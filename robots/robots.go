@@ -0,0 +1,247 @@
+// Package robots applies robots.txt exclusion rules to the URLs fed to a Grouper, so that crawl planning built on
+// groupurl respects them.
+//
+// Parse reads the rules of a robots.txt file that apply to a user agent, following RFC 9309: the most specific
+// matching rule wins, with allow rules winning ties, and patterns may use `*` wildcards and a `$` end anchor.
+// A Filter adds the URLs they allow to a Grouper, and reports the share of each group that is allowed.
+package robots
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/trustleast/groupurl"
+)
+
+type (
+	// Robots holds the rules of a robots.txt file that apply to one user agent.
+	Robots struct {
+		rules []rule
+	}
+
+	rule struct {
+		allow   bool
+		pattern string
+	}
+
+	// Filter adds the URLs allowed by a Robots to a Grouper. Like the Grouper, it is not safe for concurrent use.
+	Filter struct {
+		g              groupurl.Grouper
+		robots         *Robots
+		keepDisallowed bool
+		shares         map[string]*Share
+	}
+
+	// Share counts the URLs of a group by whether they are allowed.
+	Share struct {
+		Allowed    int `json:"allowed"`
+		Disallowed int `json:"disallowed"`
+	}
+
+	Option func(*Filter) error
+)
+
+// Parse reads a robots.txt file and returns the rules that apply to userAgent, which is matched case insensitively
+// against the product token of each group, such as "groupurlbot" for "GroupURLBot/1.0". Groups for "*" apply when no
+// group names the agent.
+func Parse(r io.Reader, userAgent string) (*Robots, error) {
+	agent := strings.ToLower(userAgent)
+	if i := strings.IndexByte(agent, '/'); i >= 0 {
+		agent = agent[:i]
+	}
+
+	var (
+		specific, wildcard []rule
+		agents             []string
+		inRules            bool
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// A user agent line after rules starts a new group.
+			if inRules {
+				agents, inRules = nil, false
+			}
+			agents = append(agents, strings.ToLower(value))
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue
+			}
+			rl := rule{allow: key == "allow", pattern: value}
+			for _, a := range agents {
+				switch a {
+				case agent:
+					specific = append(specific, rl)
+				case "*":
+					wildcard = append(wildcard, rl)
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read robots.txt: %w", err)
+	}
+
+	if specific != nil {
+		return &Robots{rules: specific}, nil
+	}
+	return &Robots{rules: wildcard}, nil
+}
+
+// Allowed reports whether a URL may be crawled. The path and query of the URL are matched against the rules, and
+// /robots.txt itself is always allowed.
+func (r *Robots) Allowed(u *url.URL) bool {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	allowed, longest := true, -1
+	for _, rl := range r.rules {
+		if !match(rl.pattern, path) {
+			continue
+		}
+		if n := len(rl.pattern); n > longest || (n == longest && rl.allow) {
+			allowed, longest = rl.allow, n
+		}
+	}
+	return allowed
+}
+
+// match reports whether a robots.txt pattern matches the start of a path, with `*` matching any sequence of
+// characters and a trailing `$` matching the end of the path.
+func match(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = pattern[:len(pattern)-1]
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(rest, part)
+		}
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+	return !anchored || rest == ""
+}
+
+// WithDisallowed also adds the URLs robots.txt disallows to the Grouper, so that groups cover the whole site and their
+// allowed share can be told apart. By default disallowed URLs are dropped.
+func WithDisallowed() Option {
+	return func(f *Filter) error {
+		f.keepDisallowed = true
+		return nil
+	}
+}
+
+// New creates a Filter adding the URLs allowed by robots to g.
+func New(g groupurl.Grouper, robots *Robots, options ...Option) (*Filter, error) {
+	f := &Filter{
+		g:      g,
+		robots: robots,
+		shares: make(map[string]*Share),
+	}
+	for _, option := range options {
+		if err := option(f); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Add adds a URL to the Grouper if robots.txt allows it, or regardless WithDisallowed, and reports whether it is
+// allowed.
+func (f *Filter) Add(u *url.URL) bool {
+	allowed := f.robots.Allowed(u)
+	if !allowed && !f.keepDisallowed {
+		return false
+	}
+	f.g.Add(u)
+
+	pattern := f.g.Pattern(u)
+	share, ok := f.shares[pattern]
+	if !ok {
+		share = &Share{}
+		f.shares[pattern] = share
+	}
+	if allowed {
+		share.Allowed++
+	} else {
+		share.Disallowed++
+	}
+	return allowed
+}
+
+// Shares returns the counts of allowed and disallowed URLs of each group added through the Filter, keyed by their
+// current pattern.
+func (f *Filter) Shares() map[string]Share {
+	shares := make(map[string]Share, len(f.shares))
+	for pattern, share := range f.shares {
+		current := f.g.CurrentPattern(pattern)
+		merged := shares[current]
+		merged.Allowed += share.Allowed
+		merged.Disallowed += share.Disallowed
+		shares[current] = merged
+	}
+	return shares
+}
+
+// Disallowed returns the patterns of the groups with disallowed URLs, ordered by decreasing number of them, so crawl
+// planners can skip the groups robots.txt mostly excludes.
+func (f *Filter) Disallowed() []string {
+	shares := f.Shares()
+	var patterns []string
+	for pattern, share := range shares {
+		if share.Disallowed > 0 {
+			patterns = append(patterns, pattern)
+		}
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		a, b := shares[patterns[i]], shares[patterns[j]]
+		if a.Disallowed != b.Disallowed {
+			return a.Disallowed > b.Disallowed
+		}
+		return patterns[i] < patterns[j]
+	})
+	return patterns
+}
+
+// Fraction returns the share of the URLs of the group that are allowed.
+func (s Share) Fraction() float64 {
+	total := s.Allowed + s.Disallowed
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Allowed) / float64(total)
+}
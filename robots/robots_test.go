@@ -0,0 +1,97 @@
+package robots
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/trustleast/groupurl"
+)
+
+const _robotsTxt = `# example
+User-agent: *
+Disallow: /admin
+Disallow: /*.pdf$
+Allow: /admin/public
+
+User-agent: GroupURLBot
+User-agent: other
+Disallow: /private/
+Allow: /private/ok
+Disallow: /search?*q=
+
+Sitemap: https://example.com/sitemap.xml
+`
+
+func TestAllowed(t *testing.T) {
+	for _, test := range []struct {
+		agent string
+		path  string
+		want  bool
+	}{
+		{"anybot", "/", true},
+		{"anybot", "/admin/users", false},
+		{"anybot", "/admin/public/page", true},
+		{"anybot", "/files/report.pdf", false},
+		{"anybot", "/files/report.pdf?download=1", true},
+		{"anybot", "/robots.txt", true},
+		{"GroupURLBot/1.0", "/admin/users", true},
+		{"groupurlbot", "/private/data", false},
+		{"groupurlbot", "/private/ok", true},
+		{"groupurlbot", "/search?lang=en&q=shoes", false},
+		{"groupurlbot", "/search?lang=en", true},
+	} {
+		r, err := Parse(strings.NewReader(_robotsTxt), test.agent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := url.Parse(test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := r.Allowed(u); got != test.want {
+			t.Errorf("%s %s: got allowed %v, want %v", test.agent, test.path, got, test.want)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	r, err := Parse(strings.NewReader("User-agent: *\nDisallow: /users/1\n"), "anybot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, options := range [][]Option{nil, {WithDisallowed()}} {
+		g, err := groupurl.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := New(g, r, options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var allowed int
+		for i := 0; i < 20; i++ {
+			if f.Add(&url.URL{Path: fmt.Sprintf("/users/%d", i)}) {
+				allowed++
+			}
+		}
+
+		// /users/1 and /users/10 to /users/19 are disallowed.
+		if allowed != 9 {
+			t.Errorf("got %d allowed URLs, want 9", allowed)
+		}
+		share := f.Shares()["/Words/Number"]
+		switch {
+		case options == nil && (share != Share{Allowed: 9}):
+			t.Errorf("got share %+v when dropping disallowed URLs", share)
+		case options != nil && (share != Share{Allowed: 9, Disallowed: 11} || share.Fraction() != 0.45):
+			t.Errorf("got share %+v when keeping disallowed URLs", share)
+		}
+		if options != nil {
+			if got := f.Disallowed(); len(got) != 1 || got[0] != "/Words/Number" {
+				t.Errorf("got disallowed groups %v", got)
+			}
+		}
+	}
+}
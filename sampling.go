@@ -0,0 +1,49 @@
+package groupurl
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// sampler decides which added URLs are processed and how many URLs each processed one stands for.
+type sampler struct {
+	rate float64
+	rand *rand.Rand
+}
+
+// WithSampling processes only a random fraction of added URLs, given by rate, to bound the CPU spent in Add.
+// Each processed URL is counted as 1/rate URLs so counts stay approximately correct. The fractional part of
+// 1/rate is applied probabilistically so the scaled counts are unbiased.
+// Rare tokens are likely to be missed entirely, so sampling is best suited to high volume streams.
+func WithSampling(rate float64) Option {
+	return func(g *Grouper) error {
+		if rate <= 0 || rate > 1 || math.IsNaN(rate) {
+			return fmt.Errorf("sampling rate must be in (0, 1], got %v", rate)
+		}
+		g.sampling = &sampler{
+			rate: rate,
+			rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+		}
+		return nil
+	}
+}
+
+// sampleWeight returns the number of URLs the current Add stands for, or 0 if it should be skipped.
+func (g Grouper) sampleWeight() int {
+	if g.sampling == nil {
+		return 1
+	}
+	s := g.sampling
+	if s.rand.Float64() >= s.rate {
+		return 0
+	}
+
+	scale := 1 / s.rate
+	weight := int(scale)
+	if s.rand.Float64() < scale-float64(weight) {
+		weight++
+	}
+	return weight
+}
@@ -0,0 +1,41 @@
+package groupurl
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"testing"
+)
+
+func TestWithSampling(t *testing.T) {
+	if _, err := New(WithSampling(0)); err == nil {
+		t.Fatal("expected error for rate 0")
+	}
+
+	g, err := New(WithSampling(0.25))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.sampling.rand = rand.New(rand.NewSource(1))
+
+	for i := 0; i < 10000; i++ {
+		u, err := url.Parse(fmt.Sprintf("/items/%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+
+	total := g.DepthStats().Total
+	if total < 9000 || total > 11000 {
+		t.Fatalf("expected roughly 10000 URLs, got %d", total)
+	}
+
+	u, err := url.Parse("/items/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path := g.SimplifyPath(u); path != "/items/Number" {
+		t.Fatalf("expected /items/Number, got %s", path)
+	}
+}
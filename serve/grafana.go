@@ -0,0 +1,94 @@
+package serve
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The Grafana JSON datasource contract consists of a health check on the datasource URL,
+// a search endpoint listing available targets, and a query endpoint returning time series for targets.
+// Targets are group patterns. When the Server keeps a window, queries return a datapoint per bucket,
+// otherwise a single datapoint holding the total count at the end of the queried range.
+
+type grafanaSearchRequest struct {
+	Target string `json:"target"`
+}
+
+type grafanaQueryRequest struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	Targets []struct {
+		Target string `json:"target"`
+	} `json:"targets"`
+}
+
+type grafanaTimeSeries struct {
+	Target     string   `json:"target"`
+	Datapoints [][2]any `json:"datapoints"`
+}
+
+func (s *Server) handleGrafanaHealth(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/grafana/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleGrafanaSearch(w http.ResponseWriter, r *http.Request) {
+	var req grafanaSearchRequest
+	if r.Body != nil {
+		// An empty body searches for everything.
+		_ = json.NewDecoder(r.Body).Decode(&req)
+	}
+
+	s.mu.Lock()
+	groups := make([]string, 0, len(s.totals))
+	for group := range s.totals {
+		if strings.Contains(group, req.Target) {
+			groups = append(groups, group)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Strings(groups)
+	writeJSON(w, groups)
+}
+
+func (s *Server) handleGrafanaQuery(w http.ResponseWriter, r *http.Request) {
+	var req grafanaQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid query: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Range.To.IsZero() {
+		req.Range.To = s.now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	series := make([]grafanaTimeSeries, 0, len(req.Targets))
+	for _, target := range req.Targets {
+		ts := grafanaTimeSeries{
+			Target:     target.Target,
+			Datapoints: [][2]any{},
+		}
+		if s.window == 0 {
+			ts.Datapoints = append(ts.Datapoints, [2]any{s.totals[target.Target], req.Range.To.UnixMilli()})
+		}
+		for _, b := range s.buckets {
+			if b.start.Before(req.Range.From.Truncate(s.window)) || b.start.After(req.Range.To) {
+				continue
+			}
+			ts.Datapoints = append(ts.Datapoints, [2]any{b.counts[target.Target], b.start.UnixMilli()})
+		}
+		series = append(series, ts)
+	}
+	writeJSON(w, series)
+}
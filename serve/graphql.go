@@ -0,0 +1,498 @@
+package serve
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/trustleast/groupurl"
+)
+
+// The GraphQL endpoint implements the query subset of GraphQL needed to select fields of the following schema:
+// fields, aliases, arguments and variables, but no fragments, directives or mutations.
+//
+//	type Query {
+//	  stats: Stats
+//	  groups(limit: Int, minCount: Int, prefix: String): [Group]
+//	  tree(depth: Int!): TreeNode
+//	  simplify(url: String!): String
+//	}
+//	type Stats { urls: Int, groups: Int, trees: Int }
+//	type Group { id: String, pattern: String, count: Int, tokens: [[String]], samples: [String] }
+//	type TreeNode { label: String, depth: Int, count: Int, terminal: Int, tokens: [String], children: [TreeNode] }
+//
+// Groups are ordered by count, and ids are strings as they do not fit in a GraphQL Int.
+
+// WithGraphQL serves a GraphQL endpoint under /graphql.
+func WithGraphQL() Option {
+	return func(s *Server) error {
+		s.mux.HandleFunc("/graphql", s.handleGraphQL)
+		return nil
+	}
+}
+
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+type graphQLError struct {
+	Message string `json:"message"`
+}
+
+type graphQLStats struct {
+	URLs   int `json:"urls"`
+	Groups int `json:"groups"`
+	Trees  int `json:"trees"`
+}
+
+type graphQLGroup struct {
+	ID      string     `json:"id"`
+	Pattern string     `json:"pattern"`
+	Count   int        `json:"count"`
+	Tokens  [][]string `json:"tokens"`
+	Samples []string   `json:"samples"`
+}
+
+// graphQLField is a field of a selection set.
+type graphQLField struct {
+	alias      string
+	name       string
+	args       map[string]any
+	selections []graphQLField
+}
+
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphQLRequest
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		if v := r.URL.Query().Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				writeGraphQLError(w, http.StatusBadRequest, fmt.Errorf("failed to parse variables: %w", err))
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeGraphQLError(w, http.StatusBadRequest, fmt.Errorf("failed to parse request: %w", err))
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	fields, err := parseGraphQL(req.Query, req.Variables)
+	if err != nil {
+		writeGraphQLError(w, http.StatusBadRequest, err)
+		return
+	}
+	data, err := s.executeGraphQL(fields)
+	if err != nil {
+		writeGraphQLError(w, http.StatusOK, err)
+		return
+	}
+	writeJSON(w, map[string]any{"data": data})
+}
+
+func writeGraphQLError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]any{"errors": []graphQLError{{Message: err.Error()}}})
+}
+
+func (s *Server) executeGraphQL(fields []graphQLField) (map[string]any, error) {
+	data := make(map[string]any, len(fields))
+	for _, f := range fields {
+		v, err := s.resolveGraphQL(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+		projected, err := projectGraphQL(v, f.selections)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+		data[f.alias] = projected
+	}
+	return data, nil
+}
+
+// resolveGraphQL resolves a field of the query type to a value that is projected through its JSON encoding.
+func (s *Server) resolveGraphQL(f graphQLField) (any, error) {
+	switch f.name {
+	case "stats":
+		s.mu.Lock()
+		snapshot := s.g.Snapshot()
+		trees := len(s.g.TreeJSON().Trees)
+		s.mu.Unlock()
+
+		stats := graphQLStats{Groups: len(snapshot.Groups), Trees: trees}
+		for _, grp := range snapshot.Groups {
+			stats.URLs += grp.Count
+		}
+		return stats, nil
+	case "groups":
+		limit, err := graphQLInt(f.args, "limit", 0)
+		if err != nil {
+			return nil, err
+		}
+		minCount, err := graphQLInt(f.args, "minCount", 0)
+		if err != nil {
+			return nil, err
+		}
+		prefix, err := graphQLString(f.args, "prefix", "")
+		if err != nil {
+			return nil, err
+		}
+
+		s.mu.Lock()
+		snapshot := s.g.Snapshot()
+		s.mu.Unlock()
+
+		groups := make([]graphQLGroup, 0, len(snapshot.Groups))
+		for _, grp := range snapshot.Groups {
+			if grp.Count < minCount || !strings.HasPrefix(grp.Pattern, prefix) {
+				continue
+			}
+			groups = append(groups, graphQLGroup{
+				ID:      strconv.FormatUint(groupurl.GroupID(grp.Pattern), 10),
+				Pattern: grp.Pattern,
+				Count:   grp.Count,
+				Tokens:  grp.Tokens,
+				Samples: grp.Samples,
+			})
+		}
+		sort.SliceStable(groups, func(i, j int) bool {
+			return groups[i].Count > groups[j].Count
+		})
+		if limit > 0 && len(groups) > limit {
+			groups = groups[:limit]
+		}
+		return groups, nil
+	case "tree":
+		depth, err := graphQLInt(f.args, "depth", -1)
+		if err != nil {
+			return nil, err
+		}
+		if depth < 0 {
+			return nil, errors.New("argument depth is required")
+		}
+
+		s.mu.Lock()
+		doc := s.g.TreeJSON()
+		s.mu.Unlock()
+
+		for _, t := range doc.Trees {
+			if t.Depth == depth {
+				return t, nil
+			}
+		}
+		return nil, nil
+	case "simplify":
+		raw, err := graphQLString(f.args, "url", "")
+		if err != nil {
+			return nil, err
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse URL: %w", err)
+		}
+		return s.SimplifyPath(u), nil
+	default:
+		return nil, errors.New("unknown field")
+	}
+}
+
+// projectGraphQL keeps the selected fields of a value, using the names of its JSON encoding.
+func projectGraphQL(v any, selections []graphQLField) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return nil, err
+	}
+	return project(generic, selections)
+}
+
+// project is recursive, but the depth is bounded by the nesting of the query and the trees.
+func project(v any, selections []graphQLField) (any, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			p, err := project(item, selections)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = p
+		}
+		return out, nil
+	case map[string]any:
+		if len(selections) == 0 {
+			return nil, errors.New("objects require a selection of fields")
+		}
+		out := make(map[string]any, len(selections))
+		for _, f := range selections {
+			p, err := project(v[f.name], f.selections)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.name, err)
+			}
+			out[f.alias] = p
+		}
+		return out, nil
+	default:
+		if len(selections) > 0 {
+			return nil, errors.New("fields cannot be selected on scalars")
+		}
+		return v, nil
+	}
+}
+
+func graphQLInt(args map[string]any, name string, fallback int) (int, error) {
+	v, ok := args[name]
+	if !ok || v == nil {
+		return fallback, nil
+	}
+	switch v := v.(type) {
+	case int:
+		return v, nil
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	}
+	return 0, fmt.Errorf("argument %s must be an Int", name)
+}
+
+func graphQLString(args map[string]any, name string, fallback string) (string, error) {
+	v, ok := args[name]
+	if !ok || v == nil {
+		return fallback, nil
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return "", fmt.Errorf("argument %s must be a String", name)
+}
+
+// graphQLParser is a recursive descent parser over the query subset described above.
+type graphQLParser struct {
+	src       string
+	pos       int
+	variables map[string]any
+}
+
+func parseGraphQL(src string, variables map[string]any) ([]graphQLField, error) {
+	p := &graphQLParser{src: src, variables: variables}
+	p.skip()
+	if name := p.peekName(); name == "query" {
+		p.name()
+		p.skip()
+		if p.peekName() != "" {
+			p.name()
+		}
+		if err := p.skipVariableDefinitions(); err != nil {
+			return nil, err
+		}
+	} else if name != "" {
+		return nil, fmt.Errorf("unsupported operation %q", name)
+	}
+
+	fields, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	p.skip()
+	if p.pos != len(p.src) {
+		return nil, fmt.Errorf("unexpected %q at %d", p.src[p.pos], p.pos)
+	}
+	return fields, nil
+}
+
+// skip moves past white space, commas and comments, which GraphQL ignores.
+func (p *graphQLParser) skip() {
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			p.pos++
+		case c == '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *graphQLParser) expect(c byte) error {
+	p.skip()
+	if p.pos >= len(p.src) || p.src[p.pos] != c {
+		return fmt.Errorf("expected %q at %d", c, p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *graphQLParser) peek(c byte) bool {
+	p.skip()
+	return p.pos < len(p.src) && p.src[p.pos] == c
+}
+
+func (p *graphQLParser) peekName() string {
+	start := p.pos
+	name := p.name()
+	p.pos = start
+	return name
+}
+
+func (p *graphQLParser) name() string {
+	p.skip()
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || p.pos > start && c >= '0' && c <= '9' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.src[start:p.pos]
+}
+
+// skipVariableDefinitions ignores the types of variables, whose values are checked when used as arguments.
+func (p *graphQLParser) skipVariableDefinitions() error {
+	if !p.peek('(') {
+		return nil
+	}
+	end := strings.IndexByte(p.src[p.pos:], ')')
+	if end < 0 {
+		return errors.New("unterminated variable definitions")
+	}
+	p.pos += end + 1
+	return nil
+}
+
+func (p *graphQLParser) selectionSet() ([]graphQLField, error) {
+	if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+	var fields []graphQLField
+	for !p.peek('}') {
+		f, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	p.pos++
+	if len(fields) == 0 {
+		return nil, errors.New("empty selection set")
+	}
+	return fields, nil
+}
+
+func (p *graphQLParser) field() (graphQLField, error) {
+	name := p.name()
+	if name == "" {
+		return graphQLField{}, fmt.Errorf("expected a field at %d", p.pos)
+	}
+	f := graphQLField{alias: name, name: name}
+	if p.peek(':') {
+		p.pos++
+		if f.name = p.name(); f.name == "" {
+			return graphQLField{}, fmt.Errorf("expected a field at %d", p.pos)
+		}
+	}
+
+	if p.peek('(') {
+		p.pos++
+		f.args = make(map[string]any)
+		for !p.peek(')') {
+			arg := p.name()
+			if arg == "" {
+				return graphQLField{}, fmt.Errorf("expected an argument at %d", p.pos)
+			}
+			if err := p.expect(':'); err != nil {
+				return graphQLField{}, err
+			}
+			v, err := p.value()
+			if err != nil {
+				return graphQLField{}, err
+			}
+			f.args[arg] = v
+		}
+		p.pos++
+	}
+
+	if p.peek('{') {
+		selections, err := p.selectionSet()
+		if err != nil {
+			return graphQLField{}, err
+		}
+		f.selections = selections
+	}
+	return f, nil
+}
+
+func (p *graphQLParser) value() (any, error) {
+	p.skip()
+	if p.pos >= len(p.src) {
+		return nil, errors.New("expected a value")
+	}
+	switch c := p.src[p.pos]; {
+	case c == '$':
+		p.pos++
+		name := p.name()
+		v, ok := p.variables[name]
+		if !ok {
+			return nil, fmt.Errorf("variable $%s is not defined", name)
+		}
+		return v, nil
+	case c == '"':
+		end := p.pos + 1
+		for end < len(p.src) && p.src[end] != '"' {
+			if p.src[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.src) {
+			return nil, errors.New("unterminated string")
+		}
+		s, err := strconv.Unquote(p.src[p.pos : end+1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid string at %d: %w", p.pos, err)
+		}
+		p.pos = end + 1
+		return s, nil
+	case c == '-' || c >= '0' && c <= '9':
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+			p.pos++
+		}
+		return strconv.Atoi(p.src[start:p.pos])
+	default:
+		switch name := p.name(); name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("unsupported value %q at %d", name, p.pos)
+		}
+	}
+}
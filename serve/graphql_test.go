@@ -0,0 +1,63 @@
+package serve
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/trustleast/groupurl"
+)
+
+func TestGraphQL(t *testing.T) {
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(g, WithGraphQL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		u, err := url.Parse(fmt.Sprintf("https://example.com/users/%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Record(u)
+	}
+
+	query := func(body string) (int, map[string]any) {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+		var resp map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return rec.Code, resp
+	}
+
+	code, resp := query(`{"query": "query Top($n: Int) { stats { urls } top: groups(limit: $n) { pattern count } tree(depth: 2) { count children { label } } }", "variables": {"n": 1}}`)
+	if code != http.StatusOK || resp["errors"] != nil {
+		t.Fatalf("unexpected response %d %v", code, resp)
+	}
+	b, err := json.Marshal(resp["data"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"stats":{"urls":100},"top":[{"count":100,"pattern":"/Words/Number"}],"tree":{"children":[{"label":"Words"}],"count":100}}`
+	if string(b) != expected {
+		t.Fatalf("expected %s, got %s", expected, b)
+	}
+
+	code, resp = query(`{"query": "{ stats }"}`)
+	if code != http.StatusOK || resp["errors"] == nil {
+		t.Fatalf("expected an error selecting an object without fields, got %d %v", code, resp)
+	}
+	code, _ = query(`{"query": "{ stats { urls }"}`)
+	if code != http.StatusBadRequest {
+		t.Fatalf("expected a parse error, got %d", code)
+	}
+}
@@ -0,0 +1,237 @@
+// Package serve exposes a Grouper over HTTP so that other services can record and simplify URLs
+// through a shared instance.
+//
+// The following endpoints are served:
+//
+//	POST /add        records the newline separated URLs in the request body
+//	GET  /simplify   simplifies the URL given in the url query parameter
+//	POST /simplify   simplifies the newline separated URLs in the request body, without recording them
+//	GET  /grouper    pretty prints the learned trees
+//	GET  /metrics    exposes the cardinality overflow of the learned trees to Prometheus
+//	     /grafana/   implements the Grafana JSON datasource, see grafana.go
+//	     /graphql    answers GraphQL queries when enabled with WithGraphQL, see graphql.go
+package serve
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trustleast/groupurl"
+)
+
+type (
+	// Server records URLs into a Grouper and keeps per group counts of the URLs it recorded.
+	// It is safe for concurrent use.
+	Server struct {
+		mux *http.ServeMux
+		now func() time.Time
+
+		window    time.Duration
+		retention int
+
+		mu      sync.Mutex
+		g       groupurl.Grouper
+		totals  map[string]int
+		buckets []bucket
+	}
+
+	// bucket holds the per group counts recorded in one window.
+	bucket struct {
+		start  time.Time
+		counts map[string]int
+	}
+
+	Option func(*Server) error
+)
+
+// WithWindow keeps per group counts in time buckets of the given size, retaining the most recent buckets.
+// Without it only totals since the Server started are kept.
+func WithWindow(size time.Duration, retention int) Option {
+	return func(s *Server) error {
+		if size <= 0 || retention <= 0 {
+			return fmt.Errorf("invalid window of %d buckets of %s", retention, size)
+		}
+		s.window = size
+		s.retention = retention
+		return nil
+	}
+}
+
+// New creates a Server that records into the provided Grouper.
+// The Grouper should not be used directly afterwards.
+func New(g groupurl.Grouper, options ...Option) (*Server, error) {
+	s := &Server{
+		mux:    http.NewServeMux(),
+		now:    time.Now,
+		g:      g,
+		totals: make(map[string]int),
+	}
+	for _, option := range options {
+		if err := option(s); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mux.HandleFunc("/add", s.handleAdd)
+	s.mux.HandleFunc("/simplify", s.handleSimplify)
+	s.mux.HandleFunc("/grouper", s.handleGrouper)
+	s.mux.HandleFunc("/metrics", s.handleMetrics)
+	s.mux.HandleFunc("/grafana/", s.handleGrafanaHealth)
+	s.mux.HandleFunc("/grafana/search", s.handleGrafanaSearch)
+	s.mux.HandleFunc("/grafana/query", s.handleGrafanaQuery)
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Record adds a URL to the Grouper, counts it towards its group, and returns the group.
+func (s *Server) Record(u *url.URL) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.g.Add(u)
+	group := s.g.SimplifyPath(u)
+	s.totals[group]++
+	if s.window > 0 {
+		s.currentBucket().counts[group]++
+	}
+	return group
+}
+
+// Reload switches the Grouper to new classifiers, keeping what it has learned. See Grouper.Relabel.
+// Per group counts recorded so far keep the groups they were recorded under.
+func (s *Server) Reload(classifiers []groupurl.PathTokenClassifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.g.Relabel(classifiers)
+}
+
+// SimplifyPath simplifies a URL without recording it.
+func (s *Server) SimplifyPath(u *url.URL) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.g.SimplifyPath(u)
+}
+
+// currentBucket returns the bucket for the current time, rotating out expired buckets.
+// The caller must hold s.mu.
+func (s *Server) currentBucket() bucket {
+	start := s.now().Truncate(s.window)
+	if n := len(s.buckets); n > 0 && s.buckets[n-1].start.Equal(start) {
+		return s.buckets[n-1]
+	}
+
+	b := bucket{
+		start:  start,
+		counts: make(map[string]int),
+	}
+	s.buckets = append(s.buckets, b)
+	if len(s.buckets) > s.retention {
+		s.buckets = s.buckets[len(s.buckets)-s.retention:]
+	}
+	return b
+}
+
+func (s *Server) handleAdd(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var added int
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		if scanner.Text() == "" {
+			continue
+		}
+		u, err := url.Parse(scanner.Text())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to parse URL: %s", err), http.StatusBadRequest)
+			return
+		}
+		s.Record(u)
+		added++
+	}
+	if err := scanner.Err(); err != nil {
+		http.Error(w, fmt.Sprintf("failed to read body: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, map[string]int{"added": added})
+}
+
+func (s *Server) handleSimplify(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		s.handleSimplifyBatch(w, r)
+		return
+	}
+	u, err := url.Parse(r.URL.Query().Get("url"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse URL: %s", err), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, map[string]string{
+		"url":   u.String(),
+		"group": s.SimplifyPath(u),
+	})
+}
+
+// handleSimplifyBatch answers with the groups of the URLs of the request body, in the same order.
+func (s *Server) handleSimplifyBatch(w http.ResponseWriter, r *http.Request) {
+	groups := []string{}
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		if scanner.Text() == "" {
+			continue
+		}
+		u, err := url.Parse(scanner.Text())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to parse URL: %s", err), http.StatusBadRequest)
+			return
+		}
+		groups = append(groups, s.SimplifyPath(u))
+	}
+	if err := scanner.Err(); err != nil {
+		http.Error(w, fmt.Sprintf("failed to read body: %s", err), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, map[string][]string{"groups": groups})
+}
+
+func (s *Server) handleGrouper(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	out := s.g.String()
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, out)
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	var sb strings.Builder
+	s.mu.Lock()
+	err := s.g.WriteOverflowMetrics(&sb)
+	s.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fmt.Fprint(w, sb.String())
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,131 @@
+package serve
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trustleast/groupurl"
+)
+
+func TestGrafana(t *testing.T) {
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(g, WithWindow(time.Minute, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	var body strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&body, "https://example.com/users/%d\n", i)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/add", strings.NewReader(body.String())))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	now = now.Add(time.Minute)
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/add", strings.NewReader("https://example.com/users/1000\n")))
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/grafana/search", strings.NewReader(`{"target":"users"}`)))
+	var groups []string
+	if err := json.NewDecoder(rec.Body).Decode(&groups); err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) == 0 || groups[len(groups)-1] != "/users/Number" {
+		t.Fatalf("expected /users/Number in %v", groups)
+	}
+
+	query := `{"range":{"from":"2024-01-01T11:00:00Z","to":"2024-01-01T13:00:00Z"},"targets":[{"target":"/users/Number"}]}`
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/grafana/query", strings.NewReader(query)))
+	var series []struct {
+		Target     string       `json:"target"`
+		Datapoints [][2]float64 `json:"datapoints"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&series); err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 1 || len(series[0].Datapoints) != 2 {
+		t.Fatalf("expected one series with two datapoints, got %+v", series)
+	}
+	if series[0].Datapoints[1][0] != 1 {
+		t.Fatalf("expected 1 request in the second bucket, got %v", series[0].Datapoints[1][0])
+	}
+}
+
+func TestSimplifyBatch(t *testing.T) {
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&body, "https://example.com/users/%d\n", i)
+	}
+	s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/add", strings.NewReader(body.String())))
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/simplify", strings.NewReader("/users/5\n\n/users/6\n")))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp struct {
+		Groups []string `json:"groups"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Groups) != 2 || resp.Groups[0] != "/users/Number" || resp.Groups[1] != "/users/Number" {
+		t.Errorf("unexpected groups %v", resp.Groups)
+	}
+	var recorded int
+	for _, count := range s.totals {
+		recorded += count
+	}
+	if recorded != 100 {
+		t.Errorf("expected simplifying not to record URLs, got %d recorded", recorded)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body strings.Builder
+	for i := 0; i < 60; i++ {
+		fmt.Fprintf(&body, "https://example.com/page%c%c\n", 'a'+i%26, 'a'+i/26)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/add", strings.NewReader(body.String())))
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `groupurl_overflow_urls{tree="0",pattern="/Words"} 10`) {
+		t.Fatalf("expected the overflow of the node, got %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,115 @@
+package groupurl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	_defaultMinShare = 0.01
+	_defaultZ        = 1.645
+	// _minExpectedCount is the usual rule of thumb for when the normal approximation of a proportion holds.
+	_minExpectedCount = 5
+)
+
+// Significance decides whether a token of an Important label is counted often enough at a node to be
+// preserved by SimplifyPath rather than replaced by its label. count is the number of times the token was
+// counted at the node, out of total, across distinct tokens. Cardinality limits are applied before it.
+//
+// AverageShare, the default, learns quickly and keeps every token of nodes with few distinct tokens, but with
+// little traffic it keeps tokens that are only slightly more frequent than the rest. ProportionTest only keeps
+// tokens whose share of traffic is statistically above a minimum, so it needs more traffic before it keeps
+// anything and keeps at most 1/MinShare tokens per node. MinCountShare is a simpler, predictable middle ground.
+type Significance interface {
+	Significant(count, total, distinct int) bool
+}
+
+// AverageShare keeps every token once tokens are counted more than 1/Threshold times on average,
+// and otherwise keeps tokens counted more times than there are distinct tokens.
+type AverageShare struct {
+	Threshold float64
+}
+
+// Significant implements Significance.
+func (a AverageShare) Significant(count, total, distinct int) bool {
+	averageCountPerToken := float64(distinct) / float64(total)
+	tokenShareOfCounts := float64(count) / float64(total)
+	return averageCountPerToken < a.Threshold || tokenShareOfCounts > averageCountPerToken
+}
+
+// ProportionTest keeps tokens whose share of the counts at a node is above MinShare according to a one-sided
+// proportion z-test at the critical value Z. Nothing is kept until total*MinShare reaches 5, as the test is
+// unreliable below that. MinShare defaults to 0.01 and Z to 1.645, a 95% confidence level.
+type ProportionTest struct {
+	MinShare float64
+	Z        float64
+}
+
+// Significant implements Significance.
+func (p ProportionTest) Significant(count, total, _ int) bool {
+	minShare, z := p.MinShare, p.Z
+	if minShare == 0 {
+		minShare = _defaultMinShare
+	}
+	if z == 0 {
+		z = _defaultZ
+	}
+	n := float64(total)
+	if n*minShare < _minExpectedCount {
+		return false
+	}
+	share := float64(count) / n
+	return (share-minShare)/math.Sqrt(minShare*(1-minShare)/n) >= z
+}
+
+// MinCountShare keeps tokens counted at least MinCount times that make up at least MinShare of the counts at a node.
+type MinCountShare struct {
+	MinCount int
+	MinShare float64
+}
+
+// Significant implements Significance.
+func (m MinCountShare) Significant(count, total, _ int) bool {
+	return count >= m.MinCount && float64(count) >= m.MinShare*float64(total)
+}
+
+// WithSignificance sets how the Grouper decides which tokens to preserve. The default is AverageShare with a
+// threshold of 0.01.
+func WithSignificance(significance Significance) Option {
+	return func(g *Grouper) error {
+		if err := validateSignificance(significance); err != nil {
+			return err
+		}
+		g.tree.significance = significance
+		return nil
+	}
+}
+
+// WithSignificanceThreshold sets the threshold of AverageShare. Lower values group more aggressively.
+func WithSignificanceThreshold(threshold float64) Option {
+	return WithSignificance(AverageShare{Threshold: threshold})
+}
+
+func validateSignificance(significance Significance) error {
+	switch s := significance.(type) {
+	case nil:
+		return errors.New("significance must not be nil")
+	case AverageShare:
+		if s.Threshold <= 0 || s.Threshold > 1 || math.IsNaN(s.Threshold) {
+			return fmt.Errorf("significance threshold must be in (0, 1], got %v", s.Threshold)
+		}
+	case ProportionTest:
+		if s.MinShare < 0 || s.MinShare >= 1 || math.IsNaN(s.MinShare) {
+			return fmt.Errorf("minimum share must be in [0, 1), got %v", s.MinShare)
+		}
+		if s.Z < 0 || math.IsNaN(s.Z) {
+			return fmt.Errorf("critical value must not be negative, got %v", s.Z)
+		}
+	case MinCountShare:
+		if s.MinCount < 0 || s.MinShare < 0 || s.MinShare > 1 || math.IsNaN(s.MinShare) {
+			return fmt.Errorf("minimum count and share must be non negative with a share of at most 1, got %+v", s)
+		}
+	}
+	return nil
+}
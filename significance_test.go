@@ -0,0 +1,64 @@
+package groupurl
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestSignificanceEngines(t *testing.T) {
+	build := func(urls int, options ...Option) Grouper {
+		g, err := New(options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < urls; i++ {
+			// Most URLs are for a popular item, the rest are each seen once.
+			p := "/items/" + budgetWord(i)
+			if i%4 != 0 {
+				p = "/items/popular"
+			}
+			u, err := url.Parse(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.Add(u)
+		}
+		return g
+	}
+	simplify := func(g Grouper, p string) string {
+		u, err := url.Parse(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return g.SimplifyPath(u)
+	}
+
+	small := build(12)
+	if got := simplify(small, "/items/popular"); got != "/items/popular" {
+		t.Fatalf("expected the default to keep the token from a small sample, got %s", got)
+	}
+	small = build(12, WithSignificance(ProportionTest{MinShare: 0.1}))
+	if got := simplify(small, "/items/popular"); got != "/Words/Words" {
+		t.Fatalf("expected the proportion test to wait for more traffic, got %s", got)
+	}
+
+	large := build(160, WithSignificance(ProportionTest{MinShare: 0.1}))
+	if got := simplify(large, "/items/popular"); got != "/items/popular" {
+		t.Fatalf("expected the proportion test to keep a frequent token, got %s", got)
+	}
+	if got := simplify(large, "/items/"+budgetWord(1)); got != "/items/Words" {
+		t.Fatalf("expected the proportion test to group rare tokens, got %s", got)
+	}
+
+	large = build(160, WithSignificance(MinCountShare{MinCount: 150}))
+	if got := simplify(large, "/items/popular"); got != "/items/Words" {
+		t.Fatalf("expected the minimum count to group the token, got %s", got)
+	}
+
+	if _, err := New(WithSignificance(nil)); err == nil {
+		t.Fatal("expected error for a nil significance")
+	}
+	if _, err := New(WithSignificance(ProportionTest{MinShare: 2})); err == nil {
+		t.Fatal("expected error for an out of range share")
+	}
+}
@@ -0,0 +1,46 @@
+package groupurl
+
+import "net/url"
+
+// SamePattern reports whether two URLs fall into the same group, that is whether they are the same kind of page.
+func (g Grouper) SamePattern(a, b *url.URL) bool {
+	return g.SimplifyPath(a) == g.SimplifyPath(b)
+}
+
+// Similarity grades how alike two URLs are from 0 to 1, as the share of the segments of their groups that they have
+// in common from the start, out of the segments of the longer group. URLs of the same group have a similarity of 1,
+// `/users/Number/posts` and `/users/Number/likes` a similarity of 2/3, and URLs without a common first segment 0.
+func (g Grouper) Similarity(a, b *url.URL) float64 {
+	return patternSimilarity(g.SimplifyPath(a), g.SimplifyPath(b))
+}
+
+// SamePattern reports whether two URLs fall into the same group, the same way Grouper.SamePattern does.
+func (f FrozenGrouper) SamePattern(a, b *url.URL) bool {
+	return f.SimplifyPath(a) == f.SimplifyPath(b)
+}
+
+// Similarity grades how alike two URLs are, the same way Grouper.Similarity does.
+func (f FrozenGrouper) Similarity(a, b *url.URL) float64 {
+	return patternSimilarity(f.SimplifyPath(a), f.SimplifyPath(b))
+}
+
+// patternSimilarity returns the share of the segments of the longer pattern in the common prefix of two patterns.
+func patternSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	as := pathSegments(a)
+	bs := pathSegments(b)
+	longest := len(as)
+	if len(bs) > longest {
+		longest = len(bs)
+	}
+	if longest == 0 {
+		return 1
+	}
+	var shared int
+	for shared < len(as) && shared < len(bs) && as[shared] == bs[shared] {
+		shared++
+	}
+	return float64(shared) / float64(longest)
+}
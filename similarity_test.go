@@ -0,0 +1,45 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/users/%d/posts", i)})
+		g.Add(&url.URL{Path: fmt.Sprintf("/users/%d/likes", i)})
+		g.Add(&url.URL{Path: fmt.Sprintf("/items/%d/reviews", i)})
+		g.Add(&url.URL{Path: fmt.Sprintf("/users/%d", i)})
+	}
+	frozen := g.Freeze()
+
+	tests := []struct {
+		a, b string
+		same bool
+		want float64
+	}{
+		{"/users/1/posts", "/users/2/posts", true, 1},
+		{"/users/1/posts", "/users/2/likes", false, 2.0 / 3},
+		{"/users/1/posts", "/users/2", false, 2.0 / 3},
+		{"/users/1/posts", "/items/1/reviews", false, 0},
+		{"/", "", true, 1},
+	}
+	for _, tc := range tests {
+		a, b := &url.URL{Path: tc.a}, &url.URL{Path: tc.b}
+		if same := g.SamePattern(a, b); same != tc.same {
+			t.Errorf("SamePattern(%s, %s) = %v, want %v", tc.a, tc.b, same, tc.same)
+		}
+		if got := g.Similarity(a, b); got != tc.want {
+			t.Errorf("Similarity(%s, %s) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+		if frozen.SamePattern(a, b) != tc.same || frozen.Similarity(a, b) != tc.want {
+			t.Errorf("frozen Grouper disagrees on %s and %s", tc.a, tc.b)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// simplifyCache remembers simplified paths along with the version of the tree they were computed against.
+// The version of a tree is bumped when an Add reshapes it, creating or promoting a node, recording a new token or
+// changing whether the added token is kept, and entries of older versions are recomputed from their cached tokens
+// without reclassifying them. URLs that only add to the counts of known tokens keep the cache valid, so the share of
+// other tokens can drift past their significance until the next reshaping Add.
+type simplifyCache struct {
+	entries  *lruCache[string, simplifyCacheEntry]
+	versions map[int]int
+}
+
+type simplifyCacheEntry struct {
+	version    int
+	tokens     []pathToken
+	simplified string
+}
+
+// WithSimplifyCache caches the results of SimplifyPath for the given number of most recently simplified paths.
+// Hot paths are then simplified without running the classifiers, and without walking the tree at all
+// as long as no URL reshaped the tree since, by creating a node or recording a token it had not seen. Use SimplifyCacheStats to check whether the cache pays off.
+func WithSimplifyCache(size int) Option {
+	return func(g *Grouper) error {
+		if size <= 0 {
+			return fmt.Errorf("simplify cache size must be positive, got %d", size)
+		}
+		g.simplifyCache = &simplifyCache{
+			entries:  newLRUCache[string, simplifyCacheEntry](size),
+			versions: make(map[int]int),
+		}
+		return nil
+	}
+}
+
+// SimplifyCacheStats returns the statistics of the cache configured with WithSimplifyCache.
+// Only lookups answered without walking the tree count as hits.
+func (g Grouper) SimplifyCacheStats() CacheStats {
+	if g.simplifyCache == nil {
+		return CacheStats{}
+	}
+	return g.simplifyCache.entries.stats()
+}
+
+func (c *simplifyCache) invalidate(key int) {
+	c.versions[key]++
+}
+
+func (g Grouper) cachedSimplifyPath(u *url.URL) string {
+	c := g.simplifyCache
+	key := treeKey(u.Path)
+	if _, ok := g.trees[key]; !ok && g.tree.fallback == FallbackNearestTree {
+		// The result depends on another tree, whose changes do not invalidate entries of this one.
+		return "/" + strings.Join(lookupTree(g.trees, u.Path, g.tree).path(labelPathTokens(u.Path, g.classifiers)), "/")
+	}
+	version := c.versions[key]
+
+	entry, ok := c.entries.peek(u.Path)
+	if ok && entry.version == version {
+		c.entries.hits++
+		return entry.simplified
+	}
+	c.entries.misses++
+	if !ok {
+		entry.tokens = labelPathTokens(u.Path, g.classifiers)
+	}
+
+	entry.version = version
+	entry.simplified = "/" + strings.Join(lookupTree(g.trees, u.Path, g.tree).path(entry.tokens), "/")
+	c.entries.put(u.Path, entry)
+	return entry.simplified
+}
@@ -0,0 +1,80 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestWithSimplifyCache(t *testing.T) {
+	g, err := New(WithSimplifyCache(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	add := func(rawURL string) {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+
+	u, err := url.Parse("/pages/about")
+	if err != nil {
+		t.Fatal(err)
+	}
+	add("/pages/about")
+	if path := g.SimplifyPath(u); path != "/Words/Words" {
+		t.Fatalf("expected /Words/Words, got %s", path)
+	}
+
+	// Adding to the tree invalidates the cached result.
+	for i := 0; i < 10; i++ {
+		add("/pages/about")
+	}
+	if path := g.SimplifyPath(u); path != "/pages/about" {
+		t.Fatalf("expected /pages/about, got %s", path)
+	}
+	for i := 0; i < 3; i++ {
+		g.SimplifyPath(u)
+	}
+
+	// Adding to another tree keeps it valid.
+	add(fmt.Sprintf("/a/b/%d", 1))
+	g.SimplifyPath(u)
+
+	stats := g.SimplifyCacheStats()
+	if stats.Hits != 4 || stats.Misses != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestSimplifyCacheKnownTokens(t *testing.T) {
+	g, err := New(WithSimplifyCache(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &url.URL{Path: "/pages/about"}
+	for i := 0; i < 20; i++ {
+		g.Add(u)
+	}
+	if path := g.SimplifyPath(u); path != "/pages/about" {
+		t.Fatalf("expected /pages/about, got %s", path)
+	}
+
+	// URLs adding to known tokens do not reshape the tree, so the cached result stays valid.
+	for i := 0; i < 10; i++ {
+		g.Add(u)
+		g.SimplifyPath(u)
+	}
+	if stats := g.SimplifyCacheStats(); stats.Hits != 10 || stats.Misses != 1 {
+		t.Fatalf("expected the cache to survive adds of known tokens, got %+v", stats)
+	}
+
+	// A new token reshapes the tree.
+	g.Add(&url.URL{Path: "/pages/contact"})
+	g.SimplifyPath(u)
+	if stats := g.SimplifyCacheStats(); stats.Misses != 2 {
+		t.Fatalf("expected a new token to invalidate the cache, got %+v", stats)
+	}
+}
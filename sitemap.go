@@ -0,0 +1,100 @@
+package groupurl
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+)
+
+const (
+	_sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	// _maxSitemapURLs is the number of URLs a single sitemap may hold according to the protocol.
+	_maxSitemapURLs = 50000
+)
+
+// SitemapOptions controls the URLs written by ExportSitemap and ExportURLList.
+type SitemapOptions struct {
+	// BaseURL is the scheme and host the sample paths are resolved against. ExportSitemap requires it, since sitemaps
+	// hold absolute URLs.
+	BaseURL *url.URL
+	// PerGroup caps the number of sample URLs of each group, 0 keeps every sample.
+	PerGroup int
+	// MinCount omits groups with fewer URLs.
+	MinCount int
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc string `xml:"loc"`
+}
+
+// ExportSitemap writes a sitemap.xml of the sample URLs recorded for each group, so that site owners can generate a
+// representative sitemap from traffic rather than from their CMS. Busier groups come first, and the output stops at
+// the 50000 URLs a sitemap may hold. Groupers in privacy mode record no samples.
+func (g Grouper) ExportSitemap(w io.Writer, opts SitemapOptions) error {
+	if opts.BaseURL == nil {
+		return errors.New("sitemaps require a base URL")
+	}
+	set := sitemapURLSet{Xmlns: _sitemapNamespace}
+	for _, loc := range g.sitemapURLs(opts) {
+		set.URLs = append(set.URLs, sitemapURL{Loc: loc})
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header)
+	enc := xml.NewEncoder(bw)
+	enc.Indent("", "  ")
+	if err := enc.Encode(set); err != nil {
+		return fmt.Errorf("failed to encode sitemap: %w", err)
+	}
+	bw.WriteString("\n")
+	return bw.Flush()
+}
+
+// ExportURLList writes the sample URLs recorded for each group one per line, in the order of ExportSitemap. Paths are
+// written as is when the options have no BaseURL.
+func (g Grouper) ExportURLList(w io.Writer, opts SitemapOptions) error {
+	bw := bufio.NewWriter(w)
+	for _, loc := range g.sitemapURLs(opts) {
+		fmt.Fprintln(bw, loc)
+	}
+	return bw.Flush()
+}
+
+// sitemapURLs returns the sample URLs of the groups, busiest group first.
+func (g Grouper) sitemapURLs(opts SitemapOptions) []string {
+	groups := g.groups()
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].count > groups[j].count
+	})
+
+	var urls []string
+	for _, grp := range groups {
+		if grp.count < opts.MinCount {
+			continue
+		}
+		samples := grp.samples
+		if opts.PerGroup > 0 && len(samples) > opts.PerGroup {
+			samples = samples[:opts.PerGroup]
+		}
+		for _, sample := range samples {
+			if len(urls) == _maxSitemapURLs {
+				return urls
+			}
+			if opts.BaseURL != nil {
+				sample = opts.BaseURL.ResolveReference(&url.URL{Path: sample}).String()
+			}
+			urls = append(urls, sample)
+		}
+	}
+	return urls
+}
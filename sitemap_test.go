@@ -0,0 +1,56 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestExportSitemap(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/users/%d", i)})
+	}
+	g.Add(&url.URL{Path: "/about us"})
+
+	base, err := url.Parse("https://example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	if err := g.ExportSitemap(&sb, SitemapOptions{BaseURL: base, PerGroup: 2}); err != nil {
+		t.Fatal(err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://example.com/users/0</loc>
+  </url>
+  <url>
+    <loc>https://example.com/users/1</loc>
+  </url>
+  <url>
+    <loc>https://example.com/about%20us</loc>
+  </url>
+</urlset>
+`
+	if sb.String() != want {
+		t.Errorf("got sitemap\n%s\nwant\n%s", sb.String(), want)
+	}
+
+	sb.Reset()
+	if err := g.ExportURLList(&sb, SitemapOptions{MinCount: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "/users/0\n/users/1\n/users/2\n"; sb.String() != want {
+		t.Errorf("got URL list %q, want %q", sb.String(), want)
+	}
+
+	if err := g.ExportSitemap(&sb, SitemapOptions{}); err == nil {
+		t.Error("expected an error without a base URL")
+	}
+}
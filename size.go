@@ -0,0 +1,25 @@
+package groupurl
+
+// TreeSize describes how large the trees of a Grouper have grown, which is what its memory grows with.
+type TreeSize struct {
+	Trees int
+	Nodes int
+	// Tokens is the number of distinct tokens recorded across all nodes.
+	Tokens int
+	Groups int
+}
+
+// TreeSize returns the size of the trees learned so far, to compare configurations on the same traffic.
+func (g Grouper) TreeSize() TreeSize {
+	size := TreeSize{
+		Trees:  len(g.trees),
+		Groups: len(g.groups()),
+	}
+	for _, t := range g.trees {
+		t.walk(func(path []*urlNode) {
+			size.Nodes++
+			size.Tokens += path[len(path)-1].tokenCounts.population()
+		})
+	}
+	return size
+}
@@ -0,0 +1,28 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestTreeSize(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := g.TreeSize(); size != (TreeSize{}) {
+		t.Errorf("got %+v for an empty Grouper", size)
+	}
+
+	for i := 0; i < 10; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/users/%d", i)})
+	}
+	g.Add(&url.URL{Path: "/about"})
+
+	// Numbers are not Important, so their tokens are counted under a single generic token.
+	want := TreeSize{Trees: 2, Nodes: 3, Tokens: 3, Groups: 2}
+	if size := g.TreeSize(); size != want {
+		t.Errorf("got %+v, want %+v", size, want)
+	}
+}
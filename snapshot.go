@@ -0,0 +1,193 @@
+package groupurl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const _snapshotVersion = 1
+
+// Snapshot is a point in time summary of the groups a Grouper has learned.
+// Snapshots are plain data so they can be stored, compared with Diff, and shared with other tools.
+type Snapshot struct {
+	Version int             `json:"version"`
+	Groups  []SnapshotGroup `json:"groups"`
+	// Lineage records how group patterns changed while the Grouper was learning,
+	// so that consumers keyed on old patterns can migrate.
+	Lineage []Lineage `json:"lineage,omitempty"`
+	// Classifiers describes the classifiers emitting the labels of the patterns, for those that provide metadata.
+	Classifiers []ClassifierInfo `json:"classifiers,omitempty"`
+	// PrivacyK is the k of WithPrivacyMode when the Grouper was in privacy mode: every token in the snapshot is
+	// allowlisted or was seen at least PrivacyK times, and there are no samples.
+	PrivacyK int `json:"privacy_k,omitempty"`
+}
+
+// SnapshotGroup is a single group in a Snapshot.
+type SnapshotGroup struct {
+	Pattern string `json:"pattern"`
+	Count   int    `json:"count"`
+	// Tokens holds the significant tokens of each segment of the pattern.
+	Tokens  [][]string `json:"tokens,omitempty"`
+	Samples []string   `json:"samples,omitempty"`
+	// ContentTypes counts the URLs of the group by the content type inferred from their final segment,
+	// as returned by Grouper.ContentTypes.
+	ContentTypes map[string]int `json:"content_types,omitempty"`
+	// Languages counts the URLs of the group by the language detected in their tokens, as returned by
+	// Grouper.Languages.
+	Languages map[string]int `json:"languages,omitempty"`
+	// Methods counts the requests of the group added with Grouper.AddRequest by HTTP method, as returned by
+	// Grouper.Methods.
+	Methods map[string]int `json:"methods,omitempty"`
+	// Stats aggregates the responses of the requests of the group added with Grouper.Observe, as returned by
+	// Grouper.Stats.
+	Stats *GroupStats `json:"stats,omitempty"`
+	// Tags holds the tags attached to the group with Grouper.Tag.
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// Lineage records that groups under the From pattern prefix are now found under the To pattern prefix.
+type Lineage struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+}
+
+// Snapshot returns a summary of the groups learned so far.
+func (g Grouper) Snapshot() Snapshot {
+	return Snapshot{
+		Version: _snapshotVersion,
+		Groups: mapSlice(g.groups(), func(grp group) SnapshotGroup {
+			return SnapshotGroup{
+				Pattern: grp.pattern,
+				Count:   grp.count,
+				Tokens: mapSlice(grp.segments, func(s groupSegment) []string {
+					return s.tokens
+				}),
+				Samples:      grp.samples,
+				ContentTypes: copyCounts(grp.contentTypes),
+				Languages:    copyCounts(grp.languages),
+				Methods:      copyCounts(grp.methods),
+				Stats:        cloneStats(grp.stats),
+				Tags:         g.Tags(grp.pattern),
+			}
+		}),
+		Lineage:     append([]Lineage(nil), *g.lineage...),
+		Classifiers: classifierInfos(g.labelInfo),
+		PrivacyK:    g.tree.privacyK,
+	}
+}
+
+// WriteTo writes the Snapshot as JSON.
+func (s Snapshot) WriteTo(w io.Writer) (int64, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(b, '\n'))
+	return int64(n), err
+}
+
+// ReadSnapshot reads a Snapshot written by Snapshot.WriteTo.
+func ReadSnapshot(r io.Reader) (Snapshot, error) {
+	var s Snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	if s.Version != _snapshotVersion {
+		return Snapshot{}, fmt.Errorf("unsupported snapshot version %d", s.Version)
+	}
+	return s, nil
+}
+
+// SnapshotDiff describes how the groups of two snapshots differ.
+type SnapshotDiff struct {
+	// Added holds groups only present in the later snapshot.
+	Added []SnapshotGroup
+	// Removed holds groups only present in the earlier snapshot.
+	Removed []SnapshotGroup
+	// Changed holds groups present in both snapshots whose count differs.
+	Changed []GroupChange
+	// Renamed holds groups of the earlier snapshot that the later snapshot's lineage maps onto one of its groups.
+	Renamed []Rename
+}
+
+// GroupChange is a group whose count differs between snapshots.
+type GroupChange struct {
+	Pattern string
+	Before  int
+	After   int
+}
+
+// Rename is a group whose pattern changed between snapshots.
+type Rename struct {
+	From   string
+	To     string
+	Reason string
+}
+
+// Diff compares two snapshots. Renamed groups are reported as renames instead of additions and removals.
+func Diff(before, after Snapshot) SnapshotDiff {
+	beforeCounts := snapshotCounts(before)
+	afterCounts := snapshotCounts(after)
+
+	var diff SnapshotDiff
+	renamedTo := make(map[string]bool)
+	for _, grp := range before.Groups {
+		if _, ok := afterCounts[grp.Pattern]; ok {
+			continue
+		}
+		if to, reason, ok := applyLineage(grp.Pattern, after.Lineage); ok {
+			if _, exists := afterCounts[to]; exists {
+				diff.Renamed = append(diff.Renamed, Rename{From: grp.Pattern, To: to, Reason: reason})
+				renamedTo[to] = true
+				continue
+			}
+		}
+		diff.Removed = append(diff.Removed, grp)
+	}
+
+	for _, grp := range after.Groups {
+		before, ok := beforeCounts[grp.Pattern]
+		switch {
+		case !ok && !renamedTo[grp.Pattern]:
+			diff.Added = append(diff.Added, grp)
+		case ok && before != grp.Count:
+			diff.Changed = append(diff.Changed, GroupChange{Pattern: grp.Pattern, Before: before, After: grp.Count})
+		}
+	}
+
+	sort.Slice(diff.Renamed, func(i, j int) bool {
+		return diff.Renamed[i].From < diff.Renamed[j].From
+	})
+	return diff
+}
+
+func snapshotCounts(s Snapshot) map[string]int {
+	counts := make(map[string]int, len(s.Groups))
+	for _, grp := range s.Groups {
+		counts[grp.Pattern] += grp.Count
+	}
+	return counts
+}
+
+// CurrentPattern returns the pattern that groups recorded under an earlier pattern are now found under, following
+// the Lineage, so that consumers keeping counts by pattern can merge them into the current groups.
+func (g Grouper) CurrentPattern(pattern string) string {
+	current, _, _ := applyLineage(pattern, *g.lineage)
+	return current
+}
+
+// applyLineage rewrites a pattern through every lineage entry whose From is a segment prefix of it.
+func applyLineage(pattern string, lineage []Lineage) (string, string, bool) {
+	var reasons []string
+	for _, l := range lineage {
+		if pattern == l.From || strings.HasPrefix(pattern, l.From+"/") {
+			pattern = l.To + pattern[len(l.From):]
+			reasons = append(reasons, l.Reason)
+		}
+	}
+	return pattern, strings.Join(reasons, "; "), len(reasons) > 0
+}
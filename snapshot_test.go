@@ -0,0 +1,100 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotDiff(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	add := func(rawURL string) {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+
+	for i := 0; i < 100; i++ {
+		add(fmt.Sprintf("/orders/%d", i))
+	}
+	before := g.Snapshot()
+
+	var sb strings.Builder
+	if _, err := before.WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+	before, err = ReadSnapshot(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Alphanumeric order IDs demote the Number group to its AlphaNumeric parent.
+	for i := 0; i < 100; i++ {
+		add(fmt.Sprintf("/orders/A-%d.b", i))
+	}
+	add("/status")
+	after := g.Snapshot()
+
+	diff := Diff(before, after)
+	if len(diff.Renamed) != 1 {
+		t.Fatalf("expected 1 rename, got %+v", diff)
+	}
+	if diff.Renamed[0].From != "/Words/Number" || diff.Renamed[0].To != "/Words/AlphaNumeric" {
+		t.Fatalf("unexpected rename %+v", diff.Renamed[0])
+	}
+	if len(diff.Added) != 1 || diff.Added[0].Pattern != "/Words" {
+		t.Fatalf("expected /Words to be added, got %+v", diff.Added)
+	}
+	if len(diff.Removed) != 0 {
+		t.Fatalf("expected no removals, got %+v", diff.Removed)
+	}
+}
+
+func TestPattern(t *testing.T) {
+	g, err := New(WithWildcardTails("/static"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/users/%d", i)})
+		g.Add(&url.URL{Path: fmt.Sprintf("/static/js/%d/app.js", i)})
+	}
+	patterns := make(map[string]bool)
+	for _, grp := range g.Snapshot().Groups {
+		patterns[grp.Pattern] = true
+	}
+	frozen := g.Freeze()
+	for _, path := range []string{"/users/5", "/static/js/1/app.js"} {
+		u := &url.URL{Path: path}
+		pattern := g.Pattern(u)
+		if !patterns[pattern] {
+			t.Errorf("pattern %s of %s is not a group of the snapshot %v", pattern, path, patterns)
+		}
+		if frozen.Pattern(u) != pattern {
+			t.Errorf("frozen Grouper disagrees on the pattern of %s", path)
+		}
+	}
+}
+
+func TestCurrentPattern(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	*g.lineage = append(*g.lineage, Lineage{From: "/Words/Number", To: "/Words/AlphaNumeric", Reason: "merged"})
+	for pattern, want := range map[string]string{
+		"/Words/Number":       "/Words/AlphaNumeric",
+		"/Words/Number/Words": "/Words/AlphaNumeric/Words",
+		"/Words/NumberWords":  "/Words/NumberWords",
+	} {
+		if got := g.CurrentPattern(pattern); got != want {
+			t.Errorf("%s: got %s, want %s", pattern, got, want)
+		}
+	}
+}
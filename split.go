@@ -0,0 +1,244 @@
+package groupurl
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Split is a node whose tokens form distinct populations when classified by secondary classifiers,
+// as returned by SuggestSplits. Pattern is the group pattern of the node, and Populations holds each
+// population the split would create, followed by the tokens that would remain under the original label.
+type Split struct {
+	Tree        int
+	Pattern     string
+	Populations []SplitPopulation
+
+	keys        []LabelFields
+	classifiers []PathTokenClassifier
+}
+
+// SplitPopulation is one population of a Split.
+type SplitPopulation struct {
+	Label    string
+	Count    int
+	Distinct int
+}
+
+// SuggestSplits finds nodes whose tokens fall into at least two populations that each make up at least minShare
+// of the node's counts, where the populations are the labels the secondary classifiers give tokens, plus the tokens
+// none of them match. Only populations above minShare are split out. Splits are ordered by tree and pattern.
+// The labels of the secondary classifiers are renamed by the vocabulary of WithLabelVocabulary.
+func (g Grouper) SuggestSplits(classifiers []PathTokenClassifier, minShare float64) []Split {
+	classifiers = g.vocabulary.apply(classifiers)
+	keys := make([]int, 0, len(g.trees))
+	for key := range g.trees {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	var splits []Split
+	for _, key := range keys {
+		g.trees[key].walkKeys(func(path []LabelFields, labels []string, n *urlNode) {
+			if s, ok := suggestSplit(path, n, classifiers, minShare); ok {
+				s.Tree = key
+				s.Pattern = "/" + strings.Join(labels, "/")
+				splits = append(splits, s)
+			}
+		})
+	}
+	return splits
+}
+
+// ApplySplit moves the tokens of each population of a Split into a node of its own, next to the original node.
+// Later URLs are routed to the new nodes by the secondary classifiers.
+// The children of the original node are not split, as the Grouper does not know which tokens they were seen with,
+// so the new nodes learn their children from later URLs. The split is undone by Relabel.
+func (g Grouper) ApplySplit(s Split) error {
+	if len(s.keys) == 0 {
+		return errors.New("split was not suggested by SuggestSplits")
+	}
+	t, ok := g.trees[s.Tree]
+	if !ok {
+		return fmt.Errorf("tree %d does not exist", s.Tree)
+	}
+	parent := t.Root
+	for _, key := range s.keys[:len(s.keys)-1] {
+		if parent, _ = parent.children.get(key); parent == nil {
+			return fmt.Errorf("%s no longer exists", s.Pattern)
+		}
+	}
+	key := s.keys[len(s.keys)-1]
+	n, ok := parent.children.get(key)
+	if !ok {
+		return fmt.Errorf("%s no longer exists", s.Pattern)
+	}
+
+	split := make(map[string]bool, len(s.Populations))
+	for _, p := range s.Populations[:len(s.Populations)-1] {
+		split[p.Label] = true
+	}
+	for token, count := range counterMap(n.tokenCounts.counts) {
+		label, ok := classifySplitToken(token, s.classifiers)
+		if !ok || !split[label.Value] {
+			continue
+		}
+		childKey := label.parentOrSelf()
+		child, ok := parent.children.get(childKey)
+		if !ok {
+			child = t.newNode(label.LabelFields, n.depth)
+			parent.children.set(childKey, child)
+			*g.lineage = append(*g.lineage, Lineage{
+				From:   s.Pattern,
+				To:     s.Pattern[:strings.LastIndex(s.Pattern, "/")+1] + label.Value,
+				Reason: fmt.Sprintf("%s split into %s", n.specificLabel.Value, label.Value),
+			})
+		}
+		child.tokenCounts.addN(token, count)
+		n.tokenCounts.total -= count
+		n.tokenCounts.counts.Delete(token)
+	}
+
+	if parent.splits == nil {
+		parent.splits = make(map[LabelFields][]PathTokenClassifier)
+	}
+	parent.splits[key.identity()] = s.classifiers
+
+	if g.simplifyCache != nil {
+		g.simplifyCache.invalidate(s.Tree)
+	}
+	if g.budget != nil {
+		g.budget.recount(g.trees)
+	}
+	return nil
+}
+
+// SplitAll applies every split suggested by SuggestSplits and returns them.
+func (g Grouper) SplitAll(classifiers []PathTokenClassifier, minShare float64) ([]Split, error) {
+	splits := g.SuggestSplits(classifiers, minShare)
+	for _, s := range splits {
+		if err := g.ApplySplit(s); err != nil {
+			return nil, err
+		}
+	}
+	return splits, nil
+}
+
+func suggestSplit(path []LabelFields, n *urlNode, classifiers []PathTokenClassifier, minShare float64) (Split, bool) {
+	key := path[len(path)-1]
+	// The overflow cannot be classified and stays with the remainder.
+	remainder := SplitPopulation{Label: n.specificLabel.Value, Count: n.tokenCounts.overflow}
+	populations := make(map[string]*SplitPopulation)
+	for token, count := range counterMap(n.tokenCounts.counts) {
+		label, ok := classifySplitToken(token, classifiers)
+		if !ok || label.parentOrSelf() == key {
+			remainder.Count += count
+			remainder.Distinct++
+			continue
+		}
+		p, ok := populations[label.Value]
+		if !ok {
+			p = &SplitPopulation{Label: label.Value}
+			populations[label.Value] = p
+		}
+		p.Count += count
+		p.Distinct++
+	}
+
+	total := float64(n.tokenCounts.total)
+	var split []SplitPopulation
+	for _, p := range populations {
+		if float64(p.Count) >= minShare*total {
+			split = append(split, *p)
+		} else {
+			remainder.Count += p.Count
+			remainder.Distinct += p.Distinct
+		}
+	}
+	// A split needs at least two populations, one of which may be the remainder.
+	if len(split) == 0 || (len(split) == 1 && float64(remainder.Count) < minShare*total) {
+		return Split{}, false
+	}
+	sort.Slice(split, func(i, j int) bool {
+		return split[i].Label < split[j].Label
+	})
+	return Split{
+		Populations: append(split, remainder),
+		keys:        append([]LabelFields(nil), path...),
+		classifiers: classifiers,
+	}, true
+}
+
+// classifySplitToken returns the label of the first classifier that matches the whole token.
+func classifySplitToken(token string, classifiers []PathTokenClassifier) (Label, bool) {
+	for _, c := range classifiers {
+		if label, match := c.Check(token); !label.isZero() && strings.TrimRight(match, "/") == token {
+			return label.qualified(), true
+		}
+	}
+	return Label{}, false
+}
+
+// route relabels a token with the secondary classifiers of a split child, if one of them matches.
+func (n *urlNode) route(token pathToken) pathToken {
+	classifiers, ok := n.splits[token.label.parentOrSelf().identity()]
+	if !ok {
+		return token
+	}
+	if label, ok := classifySplitToken(token.token, classifiers); ok {
+		token.label = label
+	}
+	return token
+}
+
+func cloneSplits(splits map[LabelFields][]PathTokenClassifier) map[LabelFields][]PathTokenClassifier {
+	if splits == nil {
+		return nil
+	}
+	c := make(map[LabelFields][]PathTokenClassifier, len(splits))
+	for key, classifiers := range splits {
+		c[key] = classifiers
+	}
+	return c
+}
+
+func (t urlTree) hasSplits() bool {
+	found := len(t.Root.splits) > 0
+	t.walkKeys(func(_ []LabelFields, _ []string, n *urlNode) {
+		found = found || len(n.splits) > 0
+	})
+	return found
+}
+
+// walkKeys visits every node below the root in a stable order with the keys and labels leading to it.
+func (t urlTree) walkKeys(f func(keys []LabelFields, labels []string, n *urlNode)) {
+	type entry struct {
+		keys   []LabelFields
+		labels []string
+		node   *urlNode
+	}
+	var stack []entry
+	push := func(e entry) {
+		keys := e.node.children.keys()
+		sort.Slice(keys, func(i, j int) bool {
+			return lessLabelFields(keys[j], keys[i])
+		})
+		for _, key := range keys {
+			child, _ := e.node.children.get(key)
+			stack = append(stack, entry{
+				keys:   append(append([]LabelFields(nil), e.keys...), key),
+				labels: append(append([]string(nil), e.labels...), child.specificLabel.Value),
+				node:   child,
+			})
+		}
+	}
+
+	push(entry{node: t.Root})
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		f(e.keys, e.labels, e.node)
+		push(e)
+	}
+}
@@ -0,0 +1,66 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	add := func(p string) {
+		u, err := url.Parse(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+	for i := 0; i < 40; i++ {
+		add("/shop/" + []string{"shoes", "shirts", "hats"}[i%3])
+		add(fmt.Sprintf("/shop/cmp-%d", i))
+	}
+
+	campaigns := []PathTokenClassifier{RegexPathTokenClassifier{
+		Regex: regexp.MustCompile(`^cmp-\d+(/|$)`),
+		Label: Label{LabelFields: LabelFields{Value: "Campaign"}},
+	}}
+	splits := g.SuggestSplits(campaigns, 0.2)
+	if len(splits) != 1 {
+		t.Fatalf("expected a single split, got %+v", splits)
+	}
+	s := splits[0]
+	if s.Pattern != "/Words/Words" || len(s.Populations) != 2 || s.Populations[0].Label != "Campaign" ||
+		s.Populations[0].Count != 40 || s.Populations[1].Distinct != 3 {
+		t.Fatalf("unexpected split %+v", s)
+	}
+	if err := g.ApplySplit(s); err != nil {
+		t.Fatal(err)
+	}
+
+	add("/shop/cmp-99")
+	for p, expected := range map[string]string{
+		"/shop/cmp-7": "/shop/Campaign",
+		"/shop/hats":  "/shop/hats",
+	} {
+		u, err := url.Parse(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := g.SimplifyPath(u); got != expected {
+			t.Fatalf("expected %s for %s, got %s", expected, p, got)
+		}
+	}
+	if len(g.SuggestSplits(campaigns, 0.2)) != 0 {
+		t.Fatal("expected no further splits")
+	}
+	if _, err := g.DecisionTable(); err == nil {
+		t.Fatal("expected split trees to be rejected by the decision table")
+	}
+	if err := g.ApplySplit(Split{}); err == nil {
+		t.Fatal("expected error for a split that was not suggested")
+	}
+}
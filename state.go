@@ -0,0 +1,360 @@
+package groupurl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+const (
+	_stateVersion = 2
+	// _stateVersionOverflowToken is the version whose nodes counted their overflow under the cardinality token.
+	_stateVersionOverflowToken = 1
+	_overflowToken             = "cardinality"
+)
+
+// grouperState is everything a Grouper has learned, as written by WriteState.
+type grouperState struct {
+	Version int               `json:"version"`
+	Trees   map[int]nodeState `json:"trees"`
+	Depths  map[int]int       `json:"depths,omitempty"`
+	Lineage []Lineage         `json:"lineage,omitempty"`
+	Tails   []tailState       `json:"tails,omitempty"`
+	// Transitions holds the transitions under the patterns they were counted with.
+	Transitions []Transition `json:"transitions,omitempty"`
+	// Tags holds the tags attached with Tag by pattern.
+	Tags map[string]map[string]string `json:"tags,omitempty"`
+}
+
+// nodeState is a node of a tree. Key is the label the node is found under in its parent, which differs from Label
+// for nodes of nested classifiers that have not been promoted to their parent label yet.
+type nodeState struct {
+	Key          LabelFields           `json:"key"`
+	Label        LabelFields           `json:"label"`
+	Limit        int                   `json:"limit,omitempty"`
+	Total        int                   `json:"total"`
+	Tokens       map[string]int        `json:"tokens,omitempty"`
+	Overflowed   int                   `json:"overflowed,omitempty"`
+	Samples      []string              `json:"samples,omitempty"`
+	Merged       bool                  `json:"merged,omitempty"`
+	Tuning       *tuningState          `json:"tuning,omitempty"`
+	ContentTypes map[string]int        `json:"content_types,omitempty"`
+	Languages    map[string]int        `json:"languages,omitempty"`
+	Methods      map[string]int        `json:"methods,omitempty"`
+	Stats        *GroupStats           `json:"stats,omitempty"`
+	Params       map[string]paramState `json:"params,omitempty"`
+	Labels       []labelState          `json:"labels,omitempty"`
+	Overflow     []string              `json:"overflow,omitempty"`
+	Sketch       []byte                `json:"overflow_sketch,omitempty"`
+	Children     []nodeState           `json:"children,omitempty"`
+}
+
+type labelState struct {
+	Label LabelFields `json:"label"`
+	Count int         `json:"count"`
+}
+
+type paramState struct {
+	Count  int            `json:"count"`
+	Values map[string]int `json:"values"`
+}
+
+type tuningState struct {
+	WindowStart int    `json:"window_start"`
+	Population  int    `json:"population"`
+	Decision    string `json:"decision"`
+}
+
+type tailState struct {
+	Prefix       []string       `json:"prefix"`
+	Count        int            `json:"count,omitempty"`
+	Samples      []string       `json:"samples,omitempty"`
+	ContentTypes map[string]int `json:"content_types,omitempty"`
+	Methods      map[string]int `json:"methods,omitempty"`
+	Stats        *GroupStats    `json:"stats,omitempty"`
+}
+
+// WriteState writes everything the Grouper has learned as JSON, so that ReadState can restore it later, for
+// example to resume a long training job. The options of the Grouper, such as its classifiers, are not written.
+// Groupers with nodes split by ApplySplit cannot be written, since their secondary classifiers are code.
+func (g Grouper) WriteState(w io.Writer) error {
+	state := grouperState{
+		Version: _stateVersion,
+		Trees:   make(map[int]nodeState, len(g.trees)),
+		Depths:  g.depths,
+		Lineage: *g.lineage,
+		Tags:    g.tags.byPattern,
+	}
+	for key, t := range g.trees {
+		if t.hasSplits() {
+			return errors.New("trees with split nodes cannot be written")
+		}
+		state.Trees[key] = t.Root.state(LabelFields{})
+	}
+	for _, prefix := range g.tails.prefixes {
+		grp := g.tails.groups[wildcardPattern(prefix)]
+		state.Tails = append(state.Tails, tailState{
+			Prefix:       prefix,
+			Count:        grp.count,
+			Samples:      grp.samples,
+			ContentTypes: grp.contentTypes,
+			Methods:      grp.methods,
+			Stats:        grp.stats,
+		})
+	}
+	for key, count := range g.transitions.counts {
+		state.Transitions = append(state.Transitions, Transition{From: key[0], To: key[1], Count: count})
+	}
+	sort.Slice(state.Transitions, func(i, j int) bool {
+		a, b := state.Transitions[i], state.Transitions[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return json.NewEncoder(w).Encode(state)
+}
+
+// ReadState replaces what the Grouper has learned with the state written by WriteState. The Grouper must be built
+// with the options of the one that wrote the state for the restored trees to be consistent with its classifiers.
+// The estimate of DistinctPathEstimate is not restored and starts over.
+func (g Grouper) ReadState(r io.Reader) error {
+	var state grouperState
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return fmt.Errorf("failed to decode state: %w", err)
+	}
+	if state.Version != _stateVersion && state.Version != _stateVersionOverflowToken {
+		return fmt.Errorf("unsupported state version %d", state.Version)
+	}
+
+	for key := range g.trees {
+		if g.simplifyCache != nil {
+			g.simplifyCache.invalidate(key)
+		}
+		delete(g.trees, key)
+	}
+	for key, root := range state.Trees {
+		t := newURLTree(g.tree)
+		if state.Version == _stateVersionOverflowToken {
+			migrateOverflowToken(&root)
+		}
+		t.Root = t.restore(root, -1)
+		g.trees[key] = t
+		if g.simplifyCache != nil {
+			g.simplifyCache.invalidate(key)
+		}
+	}
+	for depth := range g.depths {
+		delete(g.depths, depth)
+	}
+	for depth, count := range state.Depths {
+		g.depths[depth] = count
+	}
+	*g.lineage = append([]Lineage(nil), state.Lineage...)
+
+	tails := newWildcardTails()
+	for _, tail := range state.Tails {
+		tails.prefixes = append(tails.prefixes, tail.Prefix)
+		tails.groups[wildcardPattern(tail.Prefix)] = &wildcardGroup{
+			count:        tail.Count,
+			samples:      tail.Samples,
+			contentTypes: tail.ContentTypes,
+			methods:      tail.Methods,
+			stats:        tail.Stats,
+		}
+	}
+	*g.tails = *tails
+
+	transitions := newTransitions()
+	for _, t := range state.Transitions {
+		transitions.counts[[2]string{t.From, t.To}] += t.Count
+	}
+	*g.transitions = *transitions
+
+	tags := newGroupTags()
+	for pattern, pairs := range state.Tags {
+		for key, value := range pairs {
+			if err := tags.set(pattern, key, value); err != nil {
+				return fmt.Errorf("invalid tag: %w", err)
+			}
+		}
+	}
+	*g.tags = *tags
+
+	if g.budget != nil {
+		g.budget.recount(g.trees)
+	}
+	return nil
+}
+
+// state returns the state of a node and its children, sorted by key. Written iteratively for the same reason as add.
+func (n *urlNode) state(key LabelFields) nodeState {
+	root := n.shallowState(key)
+	type pair struct {
+		from *urlNode
+		to   *nodeState
+	}
+	stack := []pair{{n, &root}}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		type keyedNode struct {
+			key  LabelFields
+			node *urlNode
+		}
+		children := make([]keyedNode, 0, p.from.children.len())
+		p.from.children.each(func(childKey LabelFields, child *urlNode) {
+			children = append(children, keyedNode{childKey, child})
+		})
+		sort.Slice(children, func(i, j int) bool {
+			return lessLabelFields(children[i].key, children[j].key)
+		})
+		// Children are pushed once the slice holding them is complete, so that the pointers to them stay valid.
+		for _, child := range children {
+			p.to.Children = append(p.to.Children, child.node.shallowState(child.key))
+		}
+		for i, child := range children {
+			stack = append(stack, pair{child.node, &p.to.Children[i]})
+		}
+	}
+	return root
+}
+
+// shallowState returns the state of a node without its children.
+func (n *urlNode) shallowState(key LabelFields) nodeState {
+	s := nodeState{
+		Key:          key,
+		Label:        n.specificLabel,
+		Limit:        n.tokenCounts.limit,
+		Total:        n.tokenCounts.total,
+		Tokens:       counterMap(n.tokenCounts.counts),
+		Overflowed:   n.tokenCounts.overflow,
+		Samples:      n.samples,
+		Merged:       n.merged,
+		ContentTypes: n.contentTypes,
+		Languages:    n.languages,
+		Methods:      n.methods,
+		Stats:        n.stats,
+		Params:       paramStates(n.params),
+	}
+	s.Sketch = n.overflowSketch
+	// The overflow log is written oldest first so that it is restored without its position.
+	s.Overflow = append(append(s.Overflow, n.overflowLog[n.overflowNext:]...), n.overflowLog[:n.overflowNext]...)
+	for label, count := range n.labels {
+		s.Labels = append(s.Labels, labelState{Label: label, Count: count})
+	}
+	sort.Slice(s.Labels, func(i, j int) bool {
+		return lessLabelFields(s.Labels[i].Label, s.Labels[j].Label)
+	})
+	if n.tuning != nil {
+		s.Tuning = &tuningState{
+			WindowStart: n.tuning.windowStart,
+			Population:  n.tuning.population,
+			Decision:    n.tuning.decision,
+		}
+	}
+	return s
+}
+
+// restore rebuilds a node and its children, applying the tree's options to their counters. Written iteratively for the
+// same reason as add.
+func (t urlTree) restore(s nodeState, depth int) *urlNode {
+	root := t.restoreShallow(s, depth)
+	type pair struct {
+		from *nodeState
+		to   *urlNode
+	}
+	stack := []pair{{&s, root}}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for i := range p.from.Children {
+			child := &p.from.Children[i]
+			restored := t.restoreShallow(*child, p.to.depth+1)
+			// States written before children were keyed by identity can hold siblings only differing by their limit.
+			// Their children are restored into the existing node, merging with its own.
+			if existing, ok := p.to.children.get(child.Key); ok {
+				mergeNodes(existing, restored)
+				restored = existing
+			} else {
+				p.to.children.set(child.Key, restored)
+			}
+			stack = append(stack, pair{child, restored})
+		}
+	}
+	return root
+}
+
+// restoreShallow rebuilds a node without its children.
+func (t urlTree) restoreShallow(s nodeState, depth int) *urlNode {
+	n := t.newNode(s.Label, depth)
+	n.tokenCounts.limit = s.Limit
+	n.tokenCounts.total = s.Total
+	n.tokenCounts.overflow = s.Overflowed
+	for token, count := range s.Tokens {
+		n.tokenCounts.counts.Add(token, count)
+	}
+	n.samples = s.Samples
+	n.merged = s.Merged
+	n.contentTypes = s.ContentTypes
+	n.languages = s.Languages
+	n.methods = s.Methods
+	n.stats = s.Stats
+	n.overflowLog = s.Overflow
+	if len(s.Sketch) == 1<<_overflowSketchBits {
+		n.overflowSketch = s.Sketch
+	}
+	for _, l := range s.Labels {
+		n.observeLabel(l.Label, l.Count)
+	}
+	for name, p := range s.Params {
+		if n.params == nil {
+			n.params = make(map[string]*queryParam, len(s.Params))
+		}
+		values := newCaseInsensitiveStringCounter(_paramValueLimit)
+		for v, count := range p.Values {
+			values.counts.Add(v, count)
+			values.total += count
+		}
+		n.params[name] = &queryParam{count: p.Count, values: values}
+	}
+	if s.Tuning != nil {
+		n.tuning = &nodeTuning{
+			windowStart: s.Tuning.WindowStart,
+			population:  s.Tuning.Population,
+			decision:    s.Tuning.Decision,
+		}
+	}
+	return n
+}
+
+// migrateOverflowToken moves the overflow that states of the first version counted under a reserved token out of the
+// tokens of every node.
+func migrateOverflowToken(root *nodeState) {
+	stack := []*nodeState{root}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if count, ok := s.Tokens[_overflowToken]; ok {
+			s.Overflowed += count
+			delete(s.Tokens, _overflowToken)
+		}
+		for i := range s.Children {
+			stack = append(stack, &s.Children[i])
+		}
+	}
+}
+
+func paramStates(params map[string]*queryParam) map[string]paramState {
+	if len(params) == 0 {
+		return nil
+	}
+	states := make(map[string]paramState, len(params))
+	for name, p := range params {
+		states[name] = paramState{Count: p.count, Values: counterMap(p.values.counts)}
+	}
+	return states
+}
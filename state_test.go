@@ -0,0 +1,164 @@
+package groupurl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	g, err := New(WithWildcardTails("/static"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 200; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/users/%d/profile", i)})
+		g.Add(&url.URL{Path: fmt.Sprintf("/docs/%s", budgetWord(i%5))})
+		g.Add(&url.URL{Path: fmt.Sprintf("/static/js/%d/app.js", i)})
+	}
+
+	var buf bytes.Buffer
+	if err := g.WriteState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := New(WithWildcardTails("/static"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored.Add(&url.URL{Path: "/forgotten/by/restore"})
+	if err := restored.ReadState(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := restored.String(), g.String(); got != want {
+		t.Errorf("restored trees differ\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	var before, after strings.Builder
+	if err := g.ExportText(&before, TextOptions{Counts: true, Tokens: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.ExportText(&after, TextOptions{Counts: true, Tokens: true}); err != nil {
+		t.Fatal(err)
+	}
+	if before.String() != after.String() {
+		t.Errorf("restored groups differ\ngot:\n%s\nwant:\n%s", after.String(), before.String())
+	}
+	if got, want := restored.DepthStats(), g.DepthStats(); got.Total != want.Total {
+		t.Errorf("restored %d URLs, want %d", got.Total, want.Total)
+	}
+
+	// The restored Grouper keeps learning where the original left off.
+	for _, grouper := range []Grouper{g, restored} {
+		grouper.Add(&url.URL{Path: "/docs/" + budgetWord(7)})
+	}
+	if restored.String() != g.String() {
+		t.Error("restored Grouper learned differently from the original")
+	}
+}
+
+func TestReadStateErrors(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range []string{"not json", `{"version": 99}`} {
+		if err := g.ReadState(strings.NewReader(input)); err == nil {
+			t.Errorf("expected an error reading %q", input)
+		}
+	}
+}
+
+func TestReadStateOverflowToken(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 200; i++ {
+		g.Add(&url.URL{Path: "/users/user" + letters(i%100)})
+	}
+	var buf bytes.Buffer
+	if err := g.WriteState(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first version counted the overflow under a reserved token.
+	var state grouperState
+	if err := json.Unmarshal(buf.Bytes(), &state); err != nil {
+		t.Fatal(err)
+	}
+	state.Version = _stateVersionOverflowToken
+	for key, root := range state.Trees {
+		reserveOverflowToken(&root)
+		state.Trees[key] = root
+	}
+	v1, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.ReadState(bytes.NewReader(v1)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := restored.Overflows(), g.Overflows(); len(got) != 1 || got[0].Count != want[0].Count {
+		t.Fatalf("expected the overflow of the first version to be migrated, got %+v", got)
+	}
+}
+
+func reserveOverflowToken(s *nodeState) {
+	if s.Overflowed > 0 {
+		if s.Tokens == nil {
+			s.Tokens = make(map[string]int)
+		}
+		s.Tokens[_overflowToken] = s.Overflowed
+		s.Overflowed = 0
+	}
+	for i := range s.Children {
+		reserveOverflowToken(&s.Children[i])
+	}
+}
+
+func TestReadStateLegacySiblings(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/users/%d", i)})
+	}
+	var buf bytes.Buffer
+	if err := g.WriteState(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// States written before children were keyed by identity can hold siblings only differing by their limit.
+	var state grouperState
+	if err := json.Unmarshal(buf.Bytes(), &state); err != nil {
+		t.Fatal(err)
+	}
+	for key, root := range state.Trees {
+		sibling := root.Children[0]
+		sibling.Key.CardinalityLimit++
+		root.Children = append(root.Children, sibling)
+		state.Trees[key] = root
+	}
+	b, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.ReadState(bytes.NewReader(b)); err != nil {
+		t.Fatal(err)
+	}
+	if got := restored.Groups(); len(got) != 1 || got[0].Count != 100 || got[0].Segments[0].Tokens[0].Count != 100 {
+		t.Fatalf("expected the siblings and their children to be merged, got %+v", got)
+	}
+}
@@ -0,0 +1,136 @@
+// Package statsd emits per-group counters and new group events to StatsD or DogStatsD.
+package statsd
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/trustleast/groupurl"
+)
+
+const _defaultPrefix = "groupurl"
+
+type (
+	// Emitter records URLs into a Grouper and emits a counter for the group of every URL.
+	// When a group is seen for the first time an event is emitted as well, so dashboards can highlight new endpoints.
+	// Groups are told apart by their pattern, as returned by Grouper.Pattern, so that a token becoming significant
+	// does not emit another event. It is safe for concurrent use.
+	Emitter struct {
+		w         io.Writer
+		prefix    string
+		tags      []string
+		dogStatsD bool
+		// writeMu serializes writes to w, which need not be safe for concurrent use.
+		writeMu sync.Mutex
+
+		mu   sync.Mutex
+		g    groupurl.Grouper
+		seen map[string]struct{}
+	}
+
+	Option func(*Emitter) error
+)
+
+// WithPrefix sets the prefix of emitted metric names. Defaults to "groupurl".
+func WithPrefix(prefix string) Option {
+	return func(e *Emitter) error {
+		e.prefix = prefix
+		return nil
+	}
+}
+
+// WithDogStatsD emits metrics in the DogStatsD format, with the group and any extra tags as tags.
+// Without it the group is encoded into the metric name, since plain StatsD has no tags.
+func WithDogStatsD(tags ...string) Option {
+	return func(e *Emitter) error {
+		e.dogStatsD = true
+		e.tags = tags
+		return nil
+	}
+}
+
+// New creates an Emitter that records into g and writes metrics to w.
+// The Grouper should not be used directly afterwards, use the methods on the Emitter instead.
+func New(w io.Writer, g groupurl.Grouper, options ...Option) (*Emitter, error) {
+	e := &Emitter{
+		w:      w,
+		prefix: _defaultPrefix,
+		g:      g,
+		seen:   make(map[string]struct{}),
+	}
+	for _, option := range options {
+		if err := option(e); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// Dial creates an Emitter writing to a StatsD server over UDP.
+func Dial(addr string, g groupurl.Grouper, options ...Option) (*Emitter, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial statsd: %w", err)
+	}
+	return New(conn, g, options...)
+}
+
+// Record adds a URL to the Grouper and emits a counter for its group.
+// It returns the simplified path of the URL.
+func (e *Emitter) Record(u *url.URL) (string, error) {
+	e.mu.Lock()
+	e.g.Add(u)
+	group := e.g.SimplifyPath(u)
+	pattern := e.g.Pattern(u)
+	_, seen := e.seen[pattern]
+	if !seen {
+		e.seen[pattern] = struct{}{}
+	}
+	e.mu.Unlock()
+
+	var sb strings.Builder
+	if e.dogStatsD {
+		fmt.Fprintf(&sb, "%s.requests:1|c|#%s\n", e.prefix, e.tagList(group))
+		if !seen {
+			title := e.prefix + " new group"
+			fmt.Fprintf(&sb, "_e{%d,%d}:%s|%s|#%s\n", len(title), len(pattern), title, pattern, e.tagList(group))
+		}
+	} else {
+		fmt.Fprintf(&sb, "%s.requests.%s:1|c\n", e.prefix, metricName(group))
+		if !seen {
+			fmt.Fprintf(&sb, "%s.new_groups:1|c\n", e.prefix)
+		}
+	}
+
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	_, err := io.WriteString(e.w, sb.String())
+	return group, err
+}
+
+// SimplifyPath simplifies a URL using the underlying Grouper without recording it.
+func (e *Emitter) SimplifyPath(u *url.URL) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.g.SimplifyPath(u)
+}
+
+func (e *Emitter) tagList(group string) string {
+	return strings.Join(append([]string{"group:" + tagValue(group)}, e.tags...), ",")
+}
+
+func tagValue(s string) string {
+	return strings.NewReplacer("|", "_", ",", "_", "#", "_").Replace(s)
+}
+
+func metricName(group string) string {
+	name := strings.Trim(group, "/")
+	if name == "" {
+		return "root"
+	}
+	return strings.NewReplacer("/", ".", ":", "_", "|", "_", "@", "_", " ", "_").Replace(name)
+}
@@ -0,0 +1,103 @@
+package statsd
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/trustleast/groupurl"
+)
+
+func TestEmitter(t *testing.T) {
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	e, err := New(&buf, g, WithDogStatsD("env:test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse("https://example.com/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := e.Record(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The first request is counted under its label until the token is seen often enough to be significant, which
+	// does not make a new group.
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %q", lines)
+	}
+	if !strings.HasPrefix(lines[1], "_e{18,6}:groupurl new group|/Words") {
+		t.Fatalf("unexpected event %s", lines[1])
+	}
+	if lines[2] != "groupurl.requests:1|c|#group:/status,env:test" {
+		t.Fatalf("unexpected counter %s", lines[2])
+	}
+	if lines[3] != lines[2] {
+		t.Fatalf("expected no event for a known group, got %s", lines[3])
+	}
+
+	buf.Reset()
+	e, err = New(&buf, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Record(u); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "groupurl.requests.status:1|c\ngroupurl.new_groups:1|c\n" {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+func TestEmitterConcurrentWrites(t *testing.T) {
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bytes.Buffer is not safe for concurrent use, so lines are only whole if the Emitter serializes its writes.
+	var buf bytes.Buffer
+	e, err := New(&buf, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if _, err := e.Record(&url.URL{Path: fmt.Sprintf("/orders/%d", worker*100+i)}); err != nil {
+					t.Error(err)
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	var counters int
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if !strings.HasPrefix(line, "groupurl.") || !strings.HasSuffix(line, ":1|c") {
+			t.Fatalf("unexpected line %q", line)
+		}
+		if strings.HasPrefix(line, "groupurl.requests.") {
+			counters++
+		}
+	}
+	if counters != 800 {
+		t.Fatalf("expected a counter per request, got %d", counters)
+	}
+}
@@ -0,0 +1,155 @@
+// Package stream feeds URLs from message streams such as Kafka, NATS, or SQS into a Grouper.
+//
+// A Consumer reads messages from a Source, extracts a URL from each one, and adds it to a Grouper
+// in batches. After every batch the Checkpoint hook is called with the last message of the batch,
+// which is the natural place to commit offsets and persist the Grouper.
+package stream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+
+	"github.com/trustleast/groupurl"
+)
+
+const _defaultBatchSize = 100
+
+// Source produces messages for a Consumer.
+// Next should return io.EOF once there are no more messages.
+type Source[M any] interface {
+	Next(ctx context.Context) (M, error)
+}
+
+// SourceFunc adapts a function to the Source interface.
+type SourceFunc[M any] func(ctx context.Context) (M, error)
+
+func (f SourceFunc[M]) Next(ctx context.Context) (M, error) {
+	return f(ctx)
+}
+
+// ChannelSource returns a Source reading from a channel until it is closed.
+func ChannelSource[M any](ch <-chan M) Source[M] {
+	return SourceFunc[M](func(ctx context.Context) (M, error) {
+		var zero M
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case msg, ok := <-ch:
+			if !ok {
+				return zero, io.EOF
+			}
+			return msg, nil
+		}
+	})
+}
+
+// ErrorPolicy decides what happens when a URL cannot be extracted from a message.
+// Returning nil skips the message, returning an error stops the Consumer with that error.
+type ErrorPolicy[M any] func(msg M, err error) error
+
+// Skip returns an ErrorPolicy that skips every message that fails extraction.
+func Skip[M any]() ErrorPolicy[M] {
+	return func(M, error) error {
+		return nil
+	}
+}
+
+// Stop returns an ErrorPolicy that stops the Consumer on the first message that fails extraction.
+func Stop[M any]() ErrorPolicy[M] {
+	return func(_ M, err error) error {
+		return err
+	}
+}
+
+// Consumer reads messages from a Source and adds the URLs extracted from them to a Grouper.
+type Consumer[M any] struct {
+	// Source produces the messages to consume.
+	Source Source[M]
+	// Extract returns the URL of a message.
+	// Returning a nil URL and nil error skips the message silently.
+	Extract func(msg M) (*url.URL, error)
+	// BatchSize is the number of messages processed between checkpoints. Defaults to 100.
+	BatchSize int
+	// OnError is consulted when Extract fails. Defaults to Stop.
+	OnError ErrorPolicy[M]
+	// Checkpoint, if set, is called after each batch with the last message of the batch.
+	// Returning an error stops the Consumer.
+	Checkpoint func(ctx context.Context, last M, g groupurl.Grouper) error
+}
+
+// Stats reports the work done by a Consumer.
+type Stats struct {
+	Messages int
+	Added    int
+	Skipped  int
+	Batches  int
+}
+
+// Run consumes messages until the Source is exhausted, the context is cancelled, or an error stops it.
+// A final checkpoint is made for any partial batch when the Source is exhausted.
+func (c Consumer[M]) Run(ctx context.Context, g groupurl.Grouper) (Stats, error) {
+	batchSize := c.BatchSize
+	if batchSize <= 0 {
+		batchSize = _defaultBatchSize
+	}
+	onError := c.OnError
+	if onError == nil {
+		onError = Stop[M]()
+	}
+
+	var (
+		stats   Stats
+		last    M
+		pending int
+	)
+	checkpoint := func() error {
+		if pending == 0 {
+			return nil
+		}
+		pending = 0
+		stats.Batches++
+		if c.Checkpoint == nil {
+			return nil
+		}
+		return c.Checkpoint(ctx, last, g)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		msg, err := c.Source.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return stats, checkpoint()
+		}
+		if err != nil {
+			return stats, err
+		}
+		stats.Messages++
+
+		u, err := c.Extract(msg)
+		switch {
+		case err != nil:
+			if err := onError(msg, err); err != nil {
+				return stats, err
+			}
+			stats.Skipped++
+		case u == nil:
+			stats.Skipped++
+		default:
+			g.Add(u)
+			stats.Added++
+		}
+
+		last = msg
+		pending++
+		if pending >= batchSize {
+			if err := checkpoint(); err != nil {
+				return stats, err
+			}
+		}
+	}
+}
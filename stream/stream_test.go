@@ -0,0 +1,74 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/trustleast/groupurl"
+)
+
+type message struct {
+	offset int
+	value  string
+}
+
+func TestConsumer(t *testing.T) {
+	ch := make(chan message, 250)
+	for i := 0; i < 250; i++ {
+		value := fmt.Sprintf("https://example.com/items/%d", i)
+		if i%50 == 0 {
+			value = "://bad"
+		}
+		ch <- message{offset: i, value: value}
+	}
+	close(ch)
+
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var offsets []int
+	c := Consumer[message]{
+		Source: ChannelSource(ch),
+		Extract: func(m message) (*url.URL, error) {
+			return url.Parse(m.value)
+		},
+		OnError:   Skip[message](),
+		BatchSize: 100,
+		Checkpoint: func(_ context.Context, last message, _ groupurl.Grouper) error {
+			offsets = append(offsets, last.offset)
+			return nil
+		},
+	}
+
+	stats, err := c.Run(context.Background(), g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Added != 245 || stats.Skipped != 5 || stats.Batches != 3 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if fmt.Sprint(offsets) != "[99 199 249]" {
+		t.Fatalf("unexpected checkpoints %v", offsets)
+	}
+
+	ch = make(chan message, 1)
+	ch <- message{value: "://bad"}
+	close(ch)
+	c.Source = ChannelSource(ch)
+	c.OnError = Stop[message]()
+	if _, err := c.Run(context.Background(), g); err == nil {
+		t.Fatal("expected error")
+	}
+
+	c.Source = SourceFunc[message](func(context.Context) (message, error) {
+		return message{}, errors.New("broker down")
+	})
+	if _, err := c.Run(context.Background(), g); err == nil {
+		t.Fatal("expected error")
+	}
+}
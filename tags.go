@@ -0,0 +1,135 @@
+package groupurl
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// groupTags holds the tags people attached to groups, by group pattern. It is shared by copies of the Grouper.
+type groupTags struct {
+	byPattern map[string]map[string]string
+}
+
+func newGroupTags() *groupTags {
+	return &groupTags{byPattern: make(map[string]map[string]string)}
+}
+
+// Tag attaches a key and value to the group of a pattern, as found in Snapshot and the exports, such as the team
+// owning `/Words/Number`, its SLO tier or that it is deprecated, so that the Grouper doubles as a route catalog.
+// Tagging a key again replaces its value. Patterns do not need to have been learned yet; their tags appear once they
+// are, and follow them when the Lineage of the Grouper renames them. Tags are written by WriteState, and with their
+// group by Snapshot, ExportText and ExportMarkdown.
+func (g Grouper) Tag(pattern, key, value string) error {
+	return g.tags.set(pattern, key, value)
+}
+
+func (t *groupTags) set(pattern, key, value string) error {
+	if !strings.HasPrefix(pattern, "/") {
+		return fmt.Errorf("tagged pattern %q must start with /", pattern)
+	}
+	if key == "" {
+		return errors.New("tag key must not be empty")
+	}
+	tags, ok := t.byPattern[pattern]
+	if !ok {
+		tags = make(map[string]string)
+		t.byPattern[pattern] = tags
+	}
+	tags[key] = value
+	return nil
+}
+
+// Untag removes a tag from the group of a pattern, and reports whether there was one.
+func (g Grouper) Untag(pattern, key string) bool {
+	tags, ok := g.tags.byPattern[pattern]
+	if !ok {
+		return false
+	}
+	if _, ok := tags[key]; !ok {
+		return false
+	}
+	delete(tags, key)
+	if len(tags) == 0 {
+		delete(g.tags.byPattern, pattern)
+	}
+	return true
+}
+
+// Tags returns the tags of the group of a pattern, including those attached to the patterns the Lineage of the
+// Grouper renamed into it, which are overridden by its own. It returns nil if the group has no tags.
+func (g Grouper) Tags(pattern string) map[string]string {
+	return g.tags.of(pattern, *g.lineage)
+}
+
+// TaggedPatterns returns the sorted patterns with a tag of the given key and value, or of any value if value is
+// empty, such as every pattern owned by a team. Patterns are reported as they were tagged.
+func (g Grouper) TaggedPatterns(key, value string) []string {
+	var patterns []string
+	for pattern, tags := range g.tags.byPattern {
+		if v, ok := tags[key]; ok && (value == "" || v == value) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	sort.Strings(patterns)
+	return patterns
+}
+
+// WithSnapshotTags tags the groups of the Grouper with the tags of the groups of a Snapshot, so that a catalog built on
+// a previous Grouper carries over to a new one.
+func WithSnapshotTags(s Snapshot) Option {
+	return func(g *Grouper) error {
+		for _, grp := range s.Groups {
+			for key, value := range grp.Tags {
+				if err := g.Tag(grp.Pattern, key, value); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// of returns the tags of a pattern merged with those of the patterns lineage renames into it.
+func (t *groupTags) of(pattern string, lineage []Lineage) map[string]string {
+	if len(t.byPattern) == 0 {
+		return nil
+	}
+	var merged map[string]string
+	merge := func(tags map[string]string) {
+		if merged == nil {
+			merged = make(map[string]string, len(tags))
+		}
+		for key, value := range tags {
+			merged[key] = value
+		}
+	}
+	if len(lineage) > 0 {
+		renamed := make([]string, 0, len(t.byPattern))
+		for from := range t.byPattern {
+			if to, _, ok := applyLineage(from, lineage); ok && to == pattern && from != pattern {
+				renamed = append(renamed, from)
+			}
+		}
+		// Merge in a stable order for patterns that set the same key.
+		sort.Strings(renamed)
+		for _, from := range renamed {
+			merge(t.byPattern[from])
+		}
+	}
+	if tags, ok := t.byPattern[pattern]; ok {
+		merge(tags)
+	}
+	return merged
+}
+
+// formatTags writes tags as sorted, comma separated key=value pairs.
+func formatTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for key, value := range tags {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
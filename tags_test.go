@@ -0,0 +1,113 @@
+package groupurl
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		g.Add(&url.URL{Path: fmt.Sprintf("/checkout/%d", i)})
+		g.Add(&url.URL{Path: fmt.Sprintf("/legacy/%d/profile", i)})
+	}
+
+	for _, tag := range [][3]string{
+		{"/Words/Number", "team", "payments"},
+		{"/Words/Number", "tier", "gold"},
+		{"/Words/Number/Words", "team", "identity"},
+		{"/Words/Number/Words", "deprecated", ""},
+		{"/search", "team", "payments"},
+	} {
+		if err := g.Tag(tag[0], tag[1], tag[2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Tag("checkout", "team", "payments"); err == nil {
+		t.Fatal("expected error for a pattern without a leading /")
+	}
+	if err := g.Tag("/checkout", "", "payments"); err == nil {
+		t.Fatal("expected error for an empty key")
+	}
+
+	if got := g.Tags("/Words/Number"); !reflect.DeepEqual(got, map[string]string{"team": "payments", "tier": "gold"}) {
+		t.Fatalf("unexpected tags %v", got)
+	}
+	if got := g.TaggedPatterns("team", "payments"); !reflect.DeepEqual(got, []string{"/Words/Number", "/search"}) {
+		t.Fatalf("unexpected patterns %v", got)
+	}
+	if got := g.TaggedPatterns("deprecated", ""); !reflect.DeepEqual(got, []string{"/Words/Number/Words"}) {
+		t.Fatalf("unexpected patterns %v", got)
+	}
+	if !g.Untag("/search", "team") || g.Untag("/search", "team") || g.Tags("/search") != nil {
+		t.Fatal("expected the tag to be removed once")
+	}
+
+	// Tags follow the groups the lineage renames.
+	*g.lineage = append(*g.lineage, Lineage{From: "/Words/Number", To: "/Words/AlphaNumeric", Reason: "merged"})
+	if err := g.Tag("/Words/AlphaNumeric", "tier", "silver"); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Tags("/Words/AlphaNumeric"); !reflect.DeepEqual(got, map[string]string{"team": "payments", "tier": "silver"}) {
+		t.Fatalf("expected the tags of the renamed group, got %v", got)
+	}
+	*g.lineage = nil
+	g.Untag("/Words/AlphaNumeric", "tier")
+
+	var text strings.Builder
+	if err := g.ExportText(&text, TextOptions{Counts: true, Tags: true}); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"/Words/Number\t20\tteam=payments,tier=gold\n", "/Words/Number/Words\t20\tdeprecated=,team=identity\n"} {
+		if !strings.Contains(text.String(), expected) {
+			t.Fatalf("expected %q in %s", expected, text.String())
+		}
+	}
+	var md strings.Builder
+	if err := g.ExportMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md.String(), "| Tags |") || !strings.Contains(md.String(), "| team=payments,tier=gold |") {
+		t.Fatalf("expected tags in the markdown export, got\n%s", md.String())
+	}
+
+	// Tags persist through snapshots and states.
+	snapshot := g.Snapshot()
+	var buf bytes.Buffer
+	if _, err := snapshot.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seeded, err := New(WithSnapshotTags(read))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := seeded.Tags("/Words/Number/Words"); !reflect.DeepEqual(got, g.Tags("/Words/Number/Words")) {
+		t.Fatalf("expected the tags of the snapshot, got %v", got)
+	}
+
+	buf.Reset()
+	if err := g.WriteState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.ReadState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := restored.Tags("/Words/Number"); !reflect.DeepEqual(got, g.Tags("/Words/Number")) {
+		t.Fatalf("expected the tags of the state, got %v", got)
+	}
+}
@@ -0,0 +1,65 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// TenantStore persists the state of the tenants a Manager evicts, as written by WriteState, until they are used again.
+type TenantStore interface {
+	// Save stores the state of a tenant, replacing any previous one.
+	Save(key string, state []byte) error
+	// Load returns the state of a tenant, or an error matching fs.ErrNotExist if none was saved.
+	Load(key string) ([]byte, error)
+	// Delete removes the state of a tenant, and succeeds if none was saved.
+	Delete(key string) error
+}
+
+// DirTenantStore is a TenantStore keeping the state of each tenant in a file of a directory, created if needed.
+type DirTenantStore struct {
+	Dir string
+}
+
+// path escapes the key so that any tenant key maps to a file of the directory.
+func (s DirTenantStore) path(key string) string {
+	return filepath.Join(s.Dir, url.PathEscape(key)+".json")
+}
+
+// Save writes the state to a temporary file renamed over the previous one, so that a crash never leaves a partial
+// state behind.
+func (s DirTenantStore) Save(key string, state []byte) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create tenant store: %w", err)
+	}
+	f, err := os.CreateTemp(s.Dir, ".tenant-*")
+	if err != nil {
+		return fmt.Errorf("failed to save tenant %q: %w", key, err)
+	}
+	if _, err := f.Write(state); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to save tenant %q: %w", key, err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to save tenant %q: %w", key, err)
+	}
+	if err := os.Rename(f.Name(), s.path(key)); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to save tenant %q: %w", key, err)
+	}
+	return nil
+}
+
+func (s DirTenantStore) Load(key string) ([]byte, error) {
+	return os.ReadFile(s.path(key))
+}
+
+func (s DirTenantStore) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package groupurl
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+)
+
+func TestDirTenantStore(t *testing.T) {
+	store := DirTenantStore{Dir: t.TempDir() + "/tenants"}
+	if _, err := store.Load("acme"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected fs.ErrNotExist for a missing tenant, got %v", err)
+	}
+	for _, key := range []string{"acme", "../acme", "a/b"} {
+		if err := store.Save(key, []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, key := range []string{"acme", "../acme", "a/b"} {
+		state, err := store.Load(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(state) != key {
+			t.Fatalf("expected the state of %s, got %s", key, state)
+		}
+	}
+	if err := store.Delete("acme"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("acme"); err != nil {
+		t.Fatalf("expected deleting a missing tenant to succeed, got %v", err)
+	}
+	if _, err := store.Load("acme"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected the tenant to be deleted, got %v", err)
+	}
+}
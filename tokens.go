@@ -0,0 +1,119 @@
+package groupurl
+
+import (
+	"sort"
+	"strings"
+)
+
+// NodeTokens holds the significant tokens at one position of a tree, with their counts.
+type NodeTokens struct {
+	// Tree is the key of the tree the node is in, the number of segments of its URLs minus one.
+	Tree int `json:"tree"`
+	// Pattern is the label path from the root of the tree to the node, such as /shop/Letters.
+	Pattern string `json:"pattern"`
+	// Total is the number of URLs that passed through the node.
+	Total  int          `json:"total"`
+	Tokens []TokenCount `json:"tokens"`
+	// Other is the number of URLs through the node whose token is not listed, either because it is not significant
+	// or because of the limit on the number of tokens.
+	Other int `json:"other"`
+	// Labels is the distribution of the labels the tokens of the node were classified under.
+	Labels []LabelCount `json:"labels,omitempty"`
+}
+
+// TokenCount is the number of URLs with a significant token at a position of a tree.
+type TokenCount struct {
+	Token string `json:"token"`
+	Count int    `json:"count"`
+	// Share is Count as a fraction of the Total of the node.
+	Share float64 `json:"share"`
+}
+
+// SignificantTokens returns, for every node of every tree that preserves significant tokens, the n busiest of them
+// with their counts, or all of them when n is 0. Unlike the output of String, the list is not capped at 20 tokens.
+// Nodes are ordered by tree and pattern, and tokens by decreasing count.
+func (g Grouper) SignificantTokens(n int) []NodeTokens {
+	keys := make([]int, 0, len(g.trees))
+	for key := range g.trees {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	var nodes []NodeTokens
+	for _, key := range keys {
+		t := g.trees[key]
+		t.walk(func(path []*urlNode) {
+			node := path[len(path)-1]
+			if !t.important(node) || node.tokenCounts.total == 0 {
+				return
+			}
+
+			labels := make([]string, 0, len(path))
+			for _, p := range path {
+				labels = append(labels, p.specificLabel.Value)
+			}
+			nt := NodeTokens{
+				Tree:    key,
+				Pattern: "/" + strings.Join(labels, "/"),
+				Total:   node.tokenCounts.total,
+				Other:   node.tokenCounts.total,
+				Labels:  node.labelDistribution(),
+			}
+			for _, token := range node.tokenCounts.topN(node.tokenCounts.population()) {
+				if n > 0 && len(nt.Tokens) == n {
+					break
+				}
+				if !t.isSignificant(node, token) {
+					continue
+				}
+				count := node.tokenCounts.counts.Count(token)
+				nt.Tokens = append(nt.Tokens, TokenCount{
+					Token: token,
+					Count: count,
+					Share: float64(count) / float64(nt.Total),
+				})
+				nt.Other -= count
+			}
+			if len(nt.Tokens) > 0 {
+				nodes = append(nodes, nt)
+			}
+		})
+	}
+	return nodes
+}
+
+// TokensAt returns the n busiest significant tokens at the node with the given pattern across every tree, such as the
+// categories driving traffic under /shop/Letters, or all of them when n is 0. Counts of the same token in different
+// trees are summed, and Tree is -1 as the result spans trees.
+func (g Grouper) TokensAt(pattern string, n int) NodeTokens {
+	at := NodeTokens{Tree: -1, Pattern: pattern}
+	counts := make(map[string]int)
+	for _, nt := range g.SignificantTokens(0) {
+		if nt.Pattern != pattern {
+			continue
+		}
+		at.Total += nt.Total
+		for _, tc := range nt.Tokens {
+			counts[tc.Token] += tc.Count
+		}
+	}
+
+	for token, count := range counts {
+		at.Tokens = append(at.Tokens, TokenCount{Token: token, Count: count})
+	}
+	sort.Slice(at.Tokens, func(i, j int) bool {
+		if at.Tokens[i].Count != at.Tokens[j].Count {
+			return at.Tokens[i].Count > at.Tokens[j].Count
+		}
+		return at.Tokens[i].Token < at.Tokens[j].Token
+	})
+	if n > 0 && len(at.Tokens) > n {
+		at.Tokens = at.Tokens[:n]
+	}
+	at.Other = at.Total
+	for i := range at.Tokens {
+		at.Tokens[i].Share = float64(at.Tokens[i].Count) / float64(at.Total)
+		at.Other -= at.Tokens[i].Count
+	}
+	return at
+}
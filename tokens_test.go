@@ -0,0 +1,49 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestSignificantTokens(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 300; i++ {
+		category := "shoes"
+		switch {
+		case i%3 == 1:
+			category = "hats"
+		case i%10 == 2:
+			category = fmt.Sprintf("rare%d", i)
+		}
+		g.Add(&url.URL{Path: fmt.Sprintf("/shop/%s/%d", category, i)})
+	}
+
+	nodes := g.SignificantTokens(0)
+	if len(nodes) != 2 {
+		t.Fatalf("got %d nodes, want 2: %+v", len(nodes), nodes)
+	}
+	got := nodes[1]
+	if got.Tree != 2 || got.Pattern != "/Words/Words" || got.Total != 300 || got.Other != 20 {
+		t.Errorf("unexpected node %+v", got)
+	}
+	want := []TokenCount{{Token: "shoes", Count: 180, Share: 0.6}, {Token: "hats", Count: 100, Share: 1.0 / 3}}
+	if len(got.Tokens) != len(want) || got.Tokens[0] != want[0] || got.Tokens[1] != want[1] {
+		t.Errorf("got tokens %+v, want %+v", got.Tokens, want)
+	}
+
+	if top := g.SignificantTokens(1); len(top[1].Tokens) != 1 || top[1].Other != 120 {
+		t.Errorf("unexpected top token %+v", top[1])
+	}
+
+	at := g.TokensAt("/Words/Words", 1)
+	if at.Total != 300 || len(at.Tokens) != 1 || at.Tokens[0] != want[0] || at.Other != 120 {
+		t.Errorf("unexpected tokens at /Words/Words %+v", at)
+	}
+	if at := g.TokensAt("/Missing", 0); at.Total != 0 || len(at.Tokens) != 0 {
+		t.Errorf("unexpected tokens at a missing node %+v", at)
+	}
+}
@@ -0,0 +1,85 @@
+package groupurl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// _maxTransitions bounds the number of distinct pairs of groups whose transitions are counted.
+const _maxTransitions = 10000
+
+// Transition is the number of times visitors went from a page of one group to a page of another.
+type Transition struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Count int    `json:"count"`
+}
+
+// transitions counts transitions by the patterns of their groups at the time they were added.
+type transitions struct {
+	counts map[[2]string]int
+}
+
+func newTransitions() *transitions {
+	return &transitions{counts: make(map[[2]string]int)}
+}
+
+// AddTransition adds a URL like Add, also counting a transition to its group from the group of the page that linked
+// to it, such as the referrer of a request, to build a group to group transition matrix. Transitions returns the
+// matrix and ExportTransitionsDOT draws it as a graph of navigation flows. The referrer is only grouped, not added.
+// Transitions between up to 10000 distinct pairs of groups are counted, and later pairs are ignored.
+func (g Grouper) AddTransition(from, to *url.URL) {
+	weight := g.add(to, request{})
+	if weight == 0 {
+		return
+	}
+	key := [2]string{g.Pattern(from), g.Pattern(to)}
+	if _, ok := g.transitions.counts[key]; !ok && len(g.transitions.counts) >= _maxTransitions {
+		return
+	}
+	g.transitions.counts[key] += weight
+}
+
+// Transitions returns the transitions added with AddTransition, ordered by decreasing count. Patterns that changed as
+// the Grouper learned are updated through its Lineage, so transitions counted under old patterns are merged into the
+// current ones.
+func (g Grouper) Transitions() []Transition {
+	merged := make(map[[2]string]int, len(g.transitions.counts))
+	for key, count := range g.transitions.counts {
+		merged[[2]string{g.CurrentPattern(key[0]), g.CurrentPattern(key[1])}] += count
+	}
+
+	list := make([]Transition, 0, len(merged))
+	for key, count := range merged {
+		list = append(list, Transition{From: key[0], To: key[1], Count: count})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		if list[i].From != list[j].From {
+			return list[i].From < list[j].From
+		}
+		return list[i].To < list[j].To
+	})
+	return list
+}
+
+// ExportTransitionsDOT writes the transitions added with AddTransition as a Graphviz DOT graph, with a node per group
+// and an edge labelled with its count per transition. Transitions with fewer than minCount visits are left out.
+func (g Grouper) ExportTransitionsDOT(w io.Writer, minCount int) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph transitions {")
+	for _, t := range g.Transitions() {
+		if t.Count < minCount {
+			continue
+		}
+		fmt.Fprintf(bw, "\t%s -> %s [label=%d];\n", strconv.Quote(t.From), strconv.Quote(t.To), t.Count)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
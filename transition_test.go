@@ -0,0 +1,71 @@
+package groupurl
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestTransitions(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		g.AddTransition(&url.URL{Path: "/"}, &url.URL{Path: fmt.Sprintf("/users/%d", i)})
+		if i%2 == 0 {
+			g.AddTransition(&url.URL{Path: fmt.Sprintf("/users/%d", i)}, &url.URL{Path: fmt.Sprintf("/users/%d/posts", i)})
+		}
+	}
+
+	want := []Transition{
+		{From: "/", To: "/Words/Number", Count: 20},
+		{From: "/Words/Number", To: "/Words/Number/Words", Count: 10},
+	}
+	got := g.Transitions()
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got transitions %+v, want %+v", got, want)
+	}
+	if total := g.Snapshot().Groups; len(total) != 2 {
+		t.Errorf("expected only the targets of transitions to be added, got groups %+v", total)
+	}
+
+	var dot strings.Builder
+	if err := g.ExportTransitionsDOT(&dot, 15); err != nil {
+		t.Fatal(err)
+	}
+	if wantDOT := "digraph transitions {\n\t\"/\" -> \"/Words/Number\" [label=20];\n}\n"; dot.String() != wantDOT {
+		t.Errorf("got DOT %q, want %q", dot.String(), wantDOT)
+	}
+
+	var state bytes.Buffer
+	if err := g.WriteState(&state); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.ReadState(&state); err != nil {
+		t.Fatal(err)
+	}
+	if got := restored.Transitions(); len(got) != 2 || got[0] != want[0] {
+		t.Errorf("got transitions %+v after restoring the state", got)
+	}
+}
+
+func TestTransitionsLineage(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.transitions.counts[[2]string{"/", "/old/Number"}] = 3
+	g.transitions.counts[[2]string{"/", "/new/Number"}] = 2
+	*g.lineage = append(*g.lineage, Lineage{From: "/old", To: "/new", Reason: "merged"})
+
+	if got := g.Transitions(); len(got) != 1 || got[0] != (Transition{From: "/", To: "/new/Number", Count: 5}) {
+		t.Errorf("got transitions %+v, want them merged through the lineage", got)
+	}
+}
@@ -0,0 +1,89 @@
+package groupurl
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+const _treeJSONVersion = 1
+
+// TreeJSON is the document written by ExportTreeJSON, holding one root per tree ordered by depth.
+type TreeJSON struct {
+	Version int        `json:"version"`
+	Trees   []TreeNode `json:"trees"`
+	// PrivacyK is the k of WithPrivacyMode when the Grouper was in privacy mode.
+	PrivacyK int `json:"privacy_k,omitempty"`
+}
+
+// TreeNode is a node of the nested structure written by ExportTreeJSON.
+// Count is the number of URLs that passed through the node and Terminal the number that ended at it.
+// Tokens holds the significant tokens of the node, which SimplifyPath keeps rather than replacing with Label.
+// Roots have no Label and hold the Depth of their tree, the number of segments of the paths in it.
+type TreeNode struct {
+	Label    string   `json:"label,omitempty"`
+	Depth    int      `json:"depth,omitempty"`
+	Count    int      `json:"count"`
+	Terminal int      `json:"terminal,omitempty"`
+	Tokens   []string `json:"tokens,omitempty"`
+	// Classifier is the name of the classifier that emitted Label, if it provides metadata.
+	Classifier string `json:"classifier,omitempty"`
+	// ContentTypes counts the URLs that ended at the node by the content type inferred from their final segment.
+	ContentTypes map[string]int `json:"content_types,omitempty"`
+	// Languages counts the URLs that ended at the node by the language detected in their tokens.
+	Languages map[string]int `json:"languages,omitempty"`
+	// Methods counts the requests that ended at the node by HTTP method.
+	Methods  map[string]int `json:"methods,omitempty"`
+	Children []TreeNode     `json:"children,omitempty"`
+}
+
+// ExportTreeJSON writes the nested structure of the trees as JSON, for front-ends rendering collapsible trees.
+// Children are ordered by label so that the output is stable.
+func (g Grouper) ExportTreeJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(g.TreeJSON())
+}
+
+// TreeJSON returns the document written by ExportTreeJSON.
+func (g Grouper) TreeJSON() TreeJSON {
+	keys := make([]int, 0, len(g.trees))
+	for key := range g.trees {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	doc := TreeJSON{
+		Version:  _treeJSONVersion,
+		Trees:    make([]TreeNode, 0, len(keys)),
+		PrivacyK: g.tree.privacyK,
+	}
+	for _, key := range keys {
+		t := g.trees[key]
+		root := t.treeNode(t.Root, g.labelInfo)
+		root.Depth = key + 1
+		for _, child := range root.Children {
+			root.Count += child.Count
+		}
+		doc.Trees = append(doc.Trees, root)
+	}
+	return doc
+}
+
+// treeNode converts a node and its children. The depth of trees is bounded by the number of path segments.
+func (t urlTree) treeNode(n *urlNode, labelInfo map[string]ClassifierInfo) TreeNode {
+	tn := TreeNode{
+		Label:      n.specificLabel.Value,
+		Count:      n.tokenCounts.total,
+		Tokens:     t.significantTokens(n),
+		Classifier: labelInfo[n.specificLabel.Value].Name,
+	}
+	if terminal := n.terminal(); terminal > 0 && n != t.Root {
+		tn.Terminal = terminal
+		tn.ContentTypes = copyCounts(n.contentTypes)
+		tn.Languages = copyCounts(n.languages)
+		tn.Methods = copyCounts(n.methods)
+	}
+	for _, child := range n.sortedChildren() {
+		tn.Children = append(tn.Children, t.treeNode(child, labelInfo))
+	}
+	return tn
+}
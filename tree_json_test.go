@@ -0,0 +1,41 @@
+package groupurl
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestExportTreeJSON(t *testing.T) {
+	g, err := loadFixture("examples/test.urls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := g.ExportTreeJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var doc TreeJSON
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Version != _treeJSONVersion || len(doc.Trees) != 3 {
+		t.Fatalf("unexpected document %+v", doc)
+	}
+
+	var total, terminal int
+	stack := append([]TreeNode(nil), doc.Trees...)
+	for _, root := range doc.Trees {
+		total += root.Count
+	}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		terminal += n.Terminal
+		stack = append(stack, n.Children...)
+	}
+	if total != 1402 || terminal != total {
+		t.Fatalf("expected every URL to end at a node, got %d of %d", terminal, total)
+	}
+}
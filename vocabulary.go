@@ -0,0 +1,116 @@
+package groupurl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LabelVocabulary renames the labels emitted by classifiers. It maps the label a classifier emits, such as `Number`,
+// to the word used in its place, such as `num`. Labels of namespaced classifiers are keyed by their qualified value,
+// such as `acme:Tenant`.
+type LabelVocabulary map[string]string
+
+// WithLabelVocabulary renames the labels emitted by the classifiers, so that patterns can use a team's own words, such
+// as `/users/num` rather than `/users/Number`, or be localized without writing custom classifiers. The renamed labels
+// are used everywhere labels appear, including SimplifyPath, String, the exports and the decision table. Labels missing
+// from the vocabulary are kept, as is the `Unknown` label of segments no classifier matches. Renaming two labels to
+// the same word groups their tokens together, so New rejects it like any other label collision.
+func WithLabelVocabulary(vocabulary LabelVocabulary) Option {
+	return func(g *Grouper) error {
+		for label, word := range vocabulary {
+			if word == "" {
+				return fmt.Errorf("label %q is renamed to an empty word", label)
+			}
+		}
+		g.vocabulary = vocabulary
+		return nil
+	}
+}
+
+// ParseLabelVocabulary parses a vocabulary written as comma separated `Label=word` pairs, such as
+// `Number=num,Words=slug,YYYY=date`.
+func ParseLabelVocabulary(s string) (LabelVocabulary, error) {
+	vocabulary := make(LabelVocabulary)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		label, word, ok := strings.Cut(pair, "=")
+		label, word = strings.TrimSpace(label), strings.TrimSpace(word)
+		if !ok || label == "" || word == "" {
+			return nil, fmt.Errorf("invalid label vocabulary entry %q, expected Label=word", pair)
+		}
+		vocabulary[label] = word
+	}
+	return vocabulary, nil
+}
+
+// apply wraps every classifier so that the labels it emits are renamed. The children of NestedPathTokenClassifier are
+// wrapped individually so that nested classifiers keep being recognized.
+func (v LabelVocabulary) apply(classifiers []PathTokenClassifier) []PathTokenClassifier {
+	if len(v) == 0 || classifiers == nil {
+		return classifiers
+	}
+	wrapped := make([]PathTokenClassifier, 0, len(classifiers))
+	for _, c := range classifiers {
+		wrapped = append(wrapped, v.wrap(c))
+	}
+	return wrapped
+}
+
+func (v LabelVocabulary) wrap(c PathTokenClassifier) PathTokenClassifier {
+	switch c := c.(type) {
+	case NestedPathTokenClassifier:
+		return NestedPathTokenClassifier{
+			Parent:   v.wrap(c.Parent),
+			Children: v.apply(c.Children),
+		}
+	case vocabularyClassifier:
+		// Classifiers are already wrapped when a Grouper is relabeled with its own classifiers.
+		return vocabularyClassifier{classifier: c.classifier, vocabulary: v}
+	default:
+		return vocabularyClassifier{classifier: c, vocabulary: v}
+	}
+}
+
+// rename returns the label with its value renamed, folding in its namespace when it is renamed.
+func (v LabelVocabulary) rename(l LabelFields) LabelFields {
+	if word, ok := v[l.qualified().Value]; ok {
+		l.Value, l.Namespace = word, ""
+	}
+	return l
+}
+
+// vocabularyClassifier renames the labels of the classifier it wraps.
+type vocabularyClassifier struct {
+	classifier PathTokenClassifier
+	vocabulary LabelVocabulary
+}
+
+func (c vocabularyClassifier) Check(path string) (Label, string) {
+	label, match := c.classifier.Check(path)
+	if label.isZero() {
+		return label, match
+	}
+	label.LabelFields = c.vocabulary.rename(label.LabelFields)
+	if label.parent.Value != "" {
+		label.parent = c.vocabulary.rename(label.parent)
+	}
+	return label, match
+}
+
+// Describe describes the wrapped classifier with its labels renamed.
+func (c vocabularyClassifier) Describe() ClassifierInfo {
+	described, ok := c.classifier.(DescribedClassifier)
+	if !ok {
+		return ClassifierInfo{}
+	}
+	info := described.Describe()
+	labels := make([]string, 0, len(info.Labels))
+	for _, label := range info.Labels {
+		labels = append(labels, c.vocabulary.rename(LabelFields{Value: label}).Value)
+	}
+	info.Labels = labels
+	return info
+}
@@ -0,0 +1,74 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestLabelVocabulary(t *testing.T) {
+	vocabulary, err := ParseLabelVocabulary("Number=num, Words=slug,YYYY=date")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := New(WithLabelVocabulary(vocabulary))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		for _, p := range []string{fmt.Sprintf("/users/%d", i), fmt.Sprintf("/archive/%d", 1990+i%30)} {
+			u, err := url.Parse(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.Add(u)
+		}
+	}
+
+	for path, expected := range map[string]string{
+		"/users/42":     "/users/num",
+		"/archive/2001": "/archive/date",
+	} {
+		u, err := url.Parse(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := g.SimplifyPath(u); got != expected {
+			t.Fatalf("expected %s for %s, got %s", expected, path, got)
+		}
+	}
+
+	for _, group := range g.Snapshot().Groups {
+		if strings.Contains(group.Pattern, "Number") || strings.Contains(group.Pattern, "YYYY") {
+			t.Fatalf("expected renamed labels in patterns, got %s", group.Pattern)
+		}
+	}
+	if s := g.String(); !strings.Contains(s, "num") || strings.Contains(s, "Number") {
+		t.Fatalf("expected renamed labels when printed, got\n%s", s)
+	}
+
+	table, err := g.DecisionTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewDecisionEvaluator(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := e.SimplifyPath("/users/7"); got != "/users/num" {
+		t.Fatalf("expected the decision table to use renamed labels, got %s", got)
+	}
+}
+
+func TestLabelVocabularyErrors(t *testing.T) {
+	if _, err := ParseLabelVocabulary("Number"); err == nil {
+		t.Fatal("expected error for a pair without a word")
+	}
+	if _, err := New(WithLabelVocabulary(LabelVocabulary{"Number": ""})); err == nil {
+		t.Fatal("expected error for an empty word")
+	}
+	if _, err := New(WithLabelVocabulary(LabelVocabulary{"Number": "Letters"})); err == nil {
+		t.Fatal("expected error for labels renamed into a collision")
+	}
+}
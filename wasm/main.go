@@ -0,0 +1,86 @@
+//go:build js && wasm
+
+// Command wasm exposes groupurl to JavaScript, so browser based log explorers and Node pipelines
+// group URLs exactly like Go services do.
+//
+// Build it with:
+//
+//	GOOS=js GOARCH=wasm go build -o groupurl.wasm ./wasm
+//
+// and load it with the wasm_exec.js shipped with Go. Once running it defines a global groupurl object:
+//
+//	const g = groupurl.newGrouper();
+//	g.add("https://example.com/users/1");
+//	g.simplify("https://example.com/users/2"); // "/users/Number" once enough URLs were added
+//	g.groups(); // [{pattern: "/Words/Number", count: 1}, ...]
+//
+// Functions given an invalid URL return an Error object instead of their result,
+// since panics cannot cross into JavaScript as exceptions.
+package main
+
+import (
+	"errors"
+	"net/url"
+	"syscall/js"
+
+	"github.com/trustleast/groupurl"
+)
+
+func main() {
+	js.Global().Set("groupurl", js.ValueOf(map[string]any{
+		"newGrouper": js.FuncOf(newGrouper),
+	}))
+
+	// Keep the Go runtime alive so the exported functions stay callable.
+	select {}
+}
+
+func newGrouper(js.Value, []js.Value) any {
+	g, err := groupurl.New()
+	if err != nil {
+		return jsError(err)
+	}
+
+	return js.ValueOf(map[string]any{
+		"add": js.FuncOf(func(_ js.Value, args []js.Value) any {
+			u, err := parseArg(args)
+			if err != nil {
+				return jsError(err)
+			}
+			g.Add(u)
+			return js.Undefined()
+		}),
+		"simplify": js.FuncOf(func(_ js.Value, args []js.Value) any {
+			u, err := parseArg(args)
+			if err != nil {
+				return jsError(err)
+			}
+			return g.SimplifyPath(u)
+		}),
+		"groups": js.FuncOf(func(js.Value, []js.Value) any {
+			snapshot := g.Snapshot()
+			groups := make([]any, 0, len(snapshot.Groups))
+			for _, grp := range snapshot.Groups {
+				groups = append(groups, map[string]any{
+					"pattern": grp.Pattern,
+					"count":   grp.Count,
+				})
+			}
+			return js.ValueOf(groups)
+		}),
+	})
+}
+
+func parseArg(args []js.Value) (*url.URL, error) {
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		return nil, errExpectedURL
+	}
+	return url.Parse(args[0].String())
+}
+
+var errExpectedURL = errors.New("expected a single URL string argument")
+
+// jsError converts an error into a JavaScript Error object.
+func jsError(err error) js.Value {
+	return js.Global().Get("Error").New(err.Error())
+}
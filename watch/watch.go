@@ -0,0 +1,196 @@
+// Package watch keeps a FrozenGrouper up to date with the state written by Grouper.WriteState, so that serving fleets
+// pick up retrained groupings without restarts.
+//
+// A Watcher polls a Store for new versions of the state, and swaps the FrozenGrouper it serves once a new version
+// has been loaded. Lookups never wait for a reload, and keep using the previous version if loading fails.
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trustleast/groupurl"
+)
+
+const _defaultInterval = 30 * time.Second
+
+type (
+	// Store holds the state of a Grouper.
+	Store interface {
+		// Version identifies the stored state, and must change whenever the state does.
+		Version(ctx context.Context) (string, error)
+		// Open returns a reader for the stored state.
+		Open(ctx context.Context) (io.ReadCloser, error)
+	}
+
+	// FileStore is a Store backed by a file, versioned by its modification time and size.
+	// Write new versions to a temporary file renamed over the old one, so that a Watcher never reads a partial state.
+	FileStore struct {
+		Path string
+	}
+
+	// Watcher serves the latest version of the state of a Store as a FrozenGrouper.
+	// It is safe for concurrent use.
+	Watcher struct {
+		store    Store
+		options  []groupurl.Option
+		interval time.Duration
+		onReload func(version string)
+		onError  func(error)
+
+		// mu serializes reloads, lookups only load current.
+		mu      sync.Mutex
+		current atomic.Pointer[loaded]
+	}
+
+	loaded struct {
+		grouper groupurl.FrozenGrouper
+		version string
+	}
+
+	Option func(*Watcher) error
+)
+
+func (s FileStore) Version(context.Context) (string, error) {
+	info, err := os.Stat(s.Path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size()), nil
+}
+
+func (s FileStore) Open(context.Context) (io.ReadCloser, error) {
+	return os.Open(s.Path)
+}
+
+// WithInterval sets how often Run checks the Store for a new version, every 30 seconds by default.
+func WithInterval(interval time.Duration) Option {
+	return func(w *Watcher) error {
+		if interval <= 0 {
+			return fmt.Errorf("interval must be positive, got %s", interval)
+		}
+		w.interval = interval
+		return nil
+	}
+}
+
+// WithGrouperOptions sets the options Groupers are built with before their state is read, which must match the
+// options of the Grouper that wrote it, such as its classifiers.
+func WithGrouperOptions(options ...groupurl.Option) Option {
+	return func(w *Watcher) error {
+		w.options = options
+		return nil
+	}
+}
+
+// WithReloadHook calls f with the version of the state each time a new one is served.
+func WithReloadHook(f func(version string)) Option {
+	return func(w *Watcher) error {
+		w.onReload = f
+		return nil
+	}
+}
+
+// WithErrorHook calls f with the errors Run encounters checking for and loading new versions.
+// Without it they are ignored, and the previous version keeps being served.
+func WithErrorHook(f func(error)) Option {
+	return func(w *Watcher) error {
+		w.onError = f
+		return nil
+	}
+}
+
+// New creates a Watcher serving the current state of the Store, which must be loadable.
+func New(ctx context.Context, store Store, options ...Option) (*Watcher, error) {
+	w := &Watcher{
+		store:    store,
+		interval: _defaultInterval,
+	}
+	for _, option := range options {
+		if err := option(w); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := w.Check(ctx); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Grouper returns the FrozenGrouper of the latest version loaded.
+func (w *Watcher) Grouper() groupurl.FrozenGrouper {
+	return w.current.Load().grouper
+}
+
+// Version returns the latest version loaded.
+func (w *Watcher) Version() string {
+	return w.current.Load().version
+}
+
+// SimplifyPath simplifies a URL with the latest version loaded.
+func (w *Watcher) SimplifyPath(u *url.URL) string {
+	return w.Grouper().SimplifyPath(u)
+}
+
+// Check loads the state of the Store if its version changed, and reports whether it did.
+// The version served is left unchanged if loading fails.
+func (w *Watcher) Check(ctx context.Context) (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	version, err := w.store.Version(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to check version: %w", err)
+	}
+	if current := w.current.Load(); current != nil && current.version == version {
+		return false, nil
+	}
+
+	g, err := w.load(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to load version %s: %w", version, err)
+	}
+	w.current.Store(&loaded{grouper: g, version: version})
+	if w.onReload != nil {
+		w.onReload(version)
+	}
+	return true, nil
+}
+
+func (w *Watcher) load(ctx context.Context) (groupurl.FrozenGrouper, error) {
+	g, err := groupurl.New(w.options...)
+	if err != nil {
+		return groupurl.FrozenGrouper{}, err
+	}
+	rc, err := w.store.Open(ctx)
+	if err != nil {
+		return groupurl.FrozenGrouper{}, err
+	}
+	defer rc.Close()
+	if err := g.ReadState(rc); err != nil {
+		return groupurl.FrozenGrouper{}, err
+	}
+	return g.Freeze(), nil
+}
+
+// Run checks the Store for new versions every interval until the context is done, and returns its error.
+func (w *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := w.Check(ctx); err != nil && w.onError != nil {
+				w.onError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/trustleast/groupurl"
+)
+
+// writeState trains a Grouper on paths and writes its state to path with the given modification time.
+func writeState(t *testing.T, path string, modTime time.Time, paths ...string) groupurl.Grouper {
+	t.Helper()
+	g, err := groupurl.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range paths {
+		g.Add(&url.URL{Path: p})
+	}
+	var buf bytes.Buffer
+	if err := g.WriteState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestWatcher(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+	start := time.Now()
+	before := writeState(t, path, start, "/docs/intro")
+
+	var reloads []string
+	w, err := New(ctx, FileStore{Path: path}, WithReloadHook(func(version string) {
+		reloads = append(reloads, version)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &url.URL{Path: "/docs/intro"}
+	if got, want := w.SimplifyPath(u), before.SimplifyPath(u); got != want {
+		t.Errorf("got %s before the reload, want %s", got, want)
+	}
+
+	var paths []string
+	for i := 0; i < 100; i++ {
+		paths = append(paths, fmt.Sprintf("/docs/page%d", i))
+	}
+	after := writeState(t, path, start.Add(time.Second), paths...)
+	want := after.SimplifyPath(u)
+	if want == before.SimplifyPath(u) {
+		t.Fatalf("expected the versions to simplify %s differently", u)
+	}
+	if reloaded, err := w.Check(ctx); err != nil || !reloaded {
+		t.Fatalf("expected a reload, got %v, %v", reloaded, err)
+	}
+	if got := w.SimplifyPath(u); got != want {
+		t.Errorf("got %s after the reload, want %s", got, want)
+	}
+	if reloaded, err := w.Check(ctx); err != nil || reloaded {
+		t.Errorf("expected no reload of an unchanged version, got %v, %v", reloaded, err)
+	}
+	if len(reloads) != 2 || reloads[1] != w.Version() {
+		t.Errorf("unexpected reloads %v", reloads)
+	}
+
+	// A broken state keeps the previous version.
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Check(ctx); err == nil {
+		t.Error("expected an error loading a broken state")
+	}
+	if got := w.SimplifyPath(u); got != want {
+		t.Errorf("got %s after a failed reload, want %s", got, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	start := time.Now()
+	writeState(t, path, start, "/a")
+
+	reloaded := make(chan string, 1)
+	w, err := New(context.Background(), FileStore{Path: path}, WithInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.onReload = func(version string) { reloaded <- version }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	writeState(t, path, start.Add(time.Second), "/b")
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a reload")
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expected Run to return the context error, got %v", err)
+	}
+}
+
+func TestNewFailsWithoutState(t *testing.T) {
+	if _, err := New(context.Background(), FileStore{Path: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("expected an error")
+	}
+}
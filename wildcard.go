@@ -0,0 +1,251 @@
+package groupurl
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	_wildcardTail = "**"
+	// _wildcardTree is the tree key of the groups of wildcard tails, which span trees.
+	_wildcardTree = -1
+)
+
+// wildcardTails holds the prefixes whose paths are grouped into a single wildcard tail group regardless of depth.
+type wildcardTails struct {
+	prefixes [][]string
+	groups   map[string]*wildcardGroup
+	// private skips recording samples, as set by WithPrivacyMode.
+	private bool
+}
+
+type wildcardGroup struct {
+	count        int
+	samples      []string
+	contentTypes map[string]int
+	methods      map[string]int
+	stats        *GroupStats
+}
+
+// WildcardTail is a prefix that paths of many depths are found under, as returned by SuggestWildcardTails.
+// Depths is the number of trees the prefix was found in and Count the number of URLs under it.
+type WildcardTail struct {
+	Prefix string
+	Depths int
+	Count  int
+}
+
+// WithWildcardTails groups paths under each prefix into a single group, such as `/static/**` for the prefix
+// `/static`, instead of one group per depth. This suits file serving routes with unbounded depth variety.
+// Prefixes are made of literal segments and are matched case insensitively.
+func WithWildcardTails(prefixes ...string) Option {
+	return func(g *Grouper) error {
+		for _, prefix := range prefixes {
+			if err := g.AddWildcardTail(prefix); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// AddWildcardTail groups later paths under prefix into a single group, as with WithWildcardTails.
+// The prefix may be given with or without the trailing `/**`. URLs added before are kept in the groups they
+// were added to.
+func (g Grouper) AddWildcardTail(prefix string) error {
+	segments := pathSegments(prefix)
+	if n := len(segments); n > 0 && segments[n-1] == _wildcardTail {
+		segments = segments[:n-1]
+	}
+	if len(segments) == 0 {
+		return errors.New("wildcard tail prefix must not be empty")
+	}
+	for _, s := range segments {
+		if strings.ContainsAny(s, "*?[") {
+			return fmt.Errorf("wildcard tail prefix %q must be made of literal segments", prefix)
+		}
+	}
+	pattern := wildcardPattern(segments)
+	if _, ok := g.tails.groups[pattern]; ok {
+		return nil
+	}
+	g.tails.prefixes = append(g.tails.prefixes, segments)
+	g.tails.groups[pattern] = &wildcardGroup{}
+	if g.simplifyCache != nil {
+		g.simplifyCache.entries.reset()
+	}
+	return nil
+}
+
+// SuggestWildcardTails finds first segments that are kept by SimplifyPath in at least minDepths trees,
+// which makes them candidates for AddWildcardTail. Suggestions are ordered by the number of URLs under them.
+func (g Grouper) SuggestWildcardTails(minDepths int) []WildcardTail {
+	tails := make(map[string]*WildcardTail)
+	for _, t := range g.trees {
+		t.Root.children.each(func(_ LabelFields, child *urlNode) {
+			for _, token := range t.significantTokens(child) {
+				tail, ok := tails[token]
+				if !ok {
+					tail = &WildcardTail{Prefix: "/" + token}
+					tails[token] = tail
+				}
+				tail.Depths++
+				tail.Count += child.tokenCounts.get(token)
+			}
+		})
+	}
+
+	var suggestions []WildcardTail
+	for _, tail := range tails {
+		if _, ok := g.tails.groups[tail.Prefix+"/"+_wildcardTail]; tail.Depths >= minDepths && !ok {
+			suggestions = append(suggestions, *tail)
+		}
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Count != suggestions[j].Count {
+			return suggestions[i].Count > suggestions[j].Count
+		}
+		return suggestions[i].Prefix < suggestions[j].Prefix
+	})
+	return suggestions
+}
+
+func newWildcardTails() *wildcardTails {
+	return &wildcardTails{groups: make(map[string]*wildcardGroup)}
+}
+
+// clone copies the prefixes, which is all that lookups need.
+func (w *wildcardTails) clone() *wildcardTails {
+	c := newWildcardTails()
+	for _, prefix := range w.prefixes {
+		c.prefixes = append(c.prefixes, prefix)
+		c.groups[wildcardPattern(prefix)] = &wildcardGroup{}
+	}
+	return c
+}
+
+// match returns the pattern of the wildcard tail a path falls under.
+func (w *wildcardTails) match(path string) (string, bool) {
+	prefix, ok := w.prefix(path)
+	if !ok {
+		return "", false
+	}
+	return wildcardPattern(prefix), true
+}
+
+func (w *wildcardTails) prefix(path string) ([]string, bool) {
+	if w == nil || len(w.prefixes) == 0 {
+		return nil, false
+	}
+	segments := pathSegments(path)
+	for _, prefix := range w.prefixes {
+		if len(segments) < len(prefix) {
+			continue
+		}
+		matched := true
+		for i, s := range prefix {
+			if !strings.EqualFold(s, segments[i]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return prefix, true
+		}
+	}
+	return nil, false
+}
+
+// labels returns the segments of the prefix a path falls under followed by the wildcard.
+func (w *wildcardTails) labels(path string) []string {
+	prefix, _ := w.prefix(path)
+	return append(append([]string(nil), prefix...), _wildcardTail)
+}
+
+func (w *wildcardTails) explain(path string, c treeConfig) []SegmentExplanation {
+	prefix, _ := w.prefix(path)
+	segments := pathSegments(path)
+	explanation := make([]SegmentExplanation, 0, len(prefix)+1)
+	for i, s := range prefix {
+		explanation = append(explanation, SegmentExplanation{
+			Token:  segments[i],
+			Label:  s,
+			Output: c.encodeToken(s),
+			Kept:   true,
+			Reason: "prefix of a wildcard tail",
+		})
+	}
+	return append(explanation, SegmentExplanation{
+		Token:  strings.Join(segments[len(prefix):], "/"),
+		Label:  _wildcardTail,
+		Output: c.encodeLabel(_wildcardTail),
+		Reason: "wildcard tail",
+	})
+}
+
+func (w *wildcardTails) add(pattern, path string, req request, weight int) {
+	grp := w.groups[pattern]
+	grp.count += weight
+	grp.contentTypes = addContentType(grp.contentTypes, path, weight)
+	grp.methods = addMethod(grp.methods, req.method, weight)
+	if req.observed {
+		grp.stats = grp.stats.observe(req, weight)
+	}
+	if !w.private && len(grp.samples) < _maxSamples {
+		for _, sample := range grp.samples {
+			if sample == path {
+				return
+			}
+		}
+		grp.samples = append(grp.samples, path)
+	}
+}
+
+// groupList returns the groups of the wildcard tails that URLs were added to, ordered by pattern.
+func (w *wildcardTails) groupList() []group {
+	var groups []group
+	for _, prefix := range w.prefixes {
+		pattern := wildcardPattern(prefix)
+		grp := w.groups[pattern]
+		if grp.count == 0 {
+			continue
+		}
+		segments := make([]groupSegment, 0, len(prefix)+1)
+		for _, s := range prefix {
+			segments = append(segments, groupSegment{
+				label:  LabelFields{Important: true, Value: s},
+				tokens: []string{s},
+				counts: []int{grp.count},
+				total:  grp.count,
+			})
+		}
+		segments = append(segments, groupSegment{label: LabelFields{Value: _wildcardTail}, total: grp.count})
+		groups = append(groups, group{
+			tree:     _wildcardTree,
+			pattern:  pattern,
+			count:    grp.count,
+			segments: segments,
+			samples:  grp.samples,
+
+			contentTypes: grp.contentTypes,
+			methods:      grp.methods,
+			stats:        grp.stats,
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].pattern < groups[j].pattern
+	})
+	return groups
+}
+
+func wildcardPattern(prefix []string) string {
+	return "/" + strings.Join(prefix, "/") + "/" + _wildcardTail
+}
+
+func pathSegments(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/'
+	})
+}
@@ -0,0 +1,65 @@
+package groupurl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestWildcardTails(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	add := func(p string) {
+		u, err := url.Parse(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Add(u)
+	}
+	for i := 0; i < 50; i++ {
+		add(fmt.Sprintf("/static/app%d.js", i))
+		add(fmt.Sprintf("/static/css/v%d/site.css", i))
+		add(fmt.Sprintf("/static/img/icons/%d/logo.png", i))
+		add(fmt.Sprintf("/users/%d", i))
+	}
+
+	suggestions := g.SuggestWildcardTails(3)
+	if len(suggestions) != 1 || suggestions[0].Prefix != "/static" || suggestions[0].Depths != 3 || suggestions[0].Count != 150 {
+		t.Fatalf("unexpected suggestions %+v", suggestions)
+	}
+	if err := g.AddWildcardTail(suggestions[0].Prefix); err != nil {
+		t.Fatal(err)
+	}
+	add("/static/a/b/c.js")
+	add("/STATIC/x.css")
+
+	for _, p := range []string{"/static/a/b/c.js", "/static/x.css", "/static"} {
+		u, err := url.Parse(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := g.SimplifyPath(u); got != "/static/**" {
+			t.Fatalf("expected %s to be grouped into /static/**, got %s", p, got)
+		}
+		if got := g.Freeze().SimplifyPath(u); got != "/static/**" {
+			t.Fatalf("expected %s to be grouped into /static/** by a frozen copy, got %s", p, got)
+		}
+	}
+
+	var sb strings.Builder
+	if err := g.ExportText(&sb, TextOptions{Counts: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "/static/**\t2\n") {
+		t.Fatalf("expected the wildcard tail group in %s", sb.String())
+	}
+	if len(g.SuggestWildcardTails(3)) != 0 {
+		t.Fatal("expected added tails not to be suggested again")
+	}
+	if err := g.AddWildcardTail("/static/*.js"); err == nil {
+		t.Fatal("expected error for a prefix with wildcards")
+	}
+}